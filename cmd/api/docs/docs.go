@@ -19,9 +19,40 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/login": {
+        "/admin/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Lista todas as chaves de API com escopos, expiração, revogação e último uso. Os valores das chaves nunca são retornados.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Lista as chaves de API",
+                "responses": {
+                    "200": {
+                        "description": "API keys retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.APIKeyResponseDTO"
+                            }
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Autentica um usuário com base em e-mail e senha, retornando um token JWT válido para endpoints protegidos.",
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Cria uma chave de API com escopos (products:read, products:write) e expiração opcional. A chave atua em nome do usuário dono (por padrão, o administrador que a criou) dentro de uma organização (por padrão, a organização ativa do administrador) e é exibida apenas nesta resposta.",
                 "consumes": [
                     "application/json"
                 ],
@@ -29,215 +60,417 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin"
                 ],
-                "summary": "Autentica um usuário",
+                "summary": "Cria uma chave de API",
                 "parameters": [
                     {
-                        "description": "User credentials (email and password)",
-                        "name": "credentials",
+                        "description": "API key data",
+                        "name": "key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.UserLoginDTO"
+                            "$ref": "#/definitions/dtos.CreateAPIKeyDTO"
                         }
                     }
                 ],
+                "responses": {
+                    "201": {
+                        "description": "API key created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateAPIKeyResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Revoga permanentemente uma chave de API; requisições futuras com ela são rejeitadas.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Revoga uma chave de API",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "Successful authentication with JWT token",
+                        "description": "API key revoked",
                         "schema": {
-                            "$ref": "#/definitions/dtos.LoginResponse"
+                            "$ref": "#/definitions/dtos.MessageResponse"
                         }
                     }
                 }
             }
         },
-        "/products": {
+        "/admin/dead-letters/{queue}": {
             "get": {
                 "security": [
                     {
                         "bearerAuth": []
                     }
                 ],
-                "description": "Recupera uma lista de todos os produtos do banco de dados",
+                "description": "Retorna as mensagens mais antigas da fila de mensagens mortas (dead-letter) de uma fila de trabalho (product_events ou account_events), com o número de tentativas e o último erro. As mensagens permanecem na fila.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Admin"
+                ],
+                "summary": "Lista as mensagens de uma fila de mensagens mortas",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Work queue name",
+                        "name": "queue",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of messages (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Recupera todos os produtos",
                 "responses": {
                     "200": {
-                        "description": "Products retrieved successfully",
+                        "description": "Dead-lettered messages retrieved successfully",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dtos.ProductResponseDTO"
-                            }
+                            "$ref": "#/definitions/dtos.DeadLetterListResponse"
                         }
                     }
                 }
             },
-            "put": {
+            "delete": {
                 "security": [
                     {
                         "bearerAuth": []
                     }
                 ],
-                "description": "Atualiza produtos existentes com base em um único objeto ou em uma matriz de objetos no corpo da solicitação",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Remove definitivamente todas as mensagens da fila de mensagens mortas de uma fila de trabalho.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Admin"
                 ],
-                "summary": "Atualiza um ou mais produtos",
+                "summary": "Descarta as mensagens mortas de uma fila",
                 "parameters": [
                     {
-                        "description": "Product data to update",
-                        "name": "products",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dtos.UpdateProductDTO"
-                            }
-                        }
+                        "type": "string",
+                        "description": "Work queue name",
+                        "name": "queue",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Product(s) updated successfully",
+                        "description": "Dead-letter queue purged",
                         "schema": {
-                            "$ref": "#/definitions/dtos.UpdateProductResponse"
+                            "$ref": "#/definitions/dtos.DeadLetterCountResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/dead-letters/{queue}/requeue": {
             "post": {
                 "security": [
                     {
                         "bearerAuth": []
                     }
                 ],
-                "description": "Cria novos produtos com base em um único objeto ou em uma matriz de objetos no corpo da solicitação",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Move as mensagens da fila de mensagens mortas de volta para a fila de trabalho, com a contagem de tentativas zerada. Sem limite, move todas as mensagens presentes no início da operação. Use depois de corrigir a causa das falhas.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Admin"
                 ],
-                "summary": "Cria um ou mais produtos",
+                "summary": "Reenvia as mensagens mortas para a fila de trabalho",
                 "parameters": [
                     {
-                        "description": "Product data to create",
-                        "name": "products",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dtos.CreateProductDTO"
-                            }
-                        }
+                        "type": "string",
+                        "description": "Work queue name",
+                        "name": "queue",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of messages (default all)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Product(s) created successfully",
+                        "description": "Dead-lettered messages requeued",
                         "schema": {
-                            "$ref": "#/definitions/dtos.CreateProductResponse"
+                            "$ref": "#/definitions/dtos.DeadLetterCountResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/email-deliveries": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
                     }
                 ],
-                "description": "Exclui produtos do banco de dados com base em um único SKU ou em uma matriz de SKUs no corpo da solicitação",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lista os e-mails de notificação enviados ou tentados, dos mais recentes para os mais antigos, com a resposta do servidor SMTP e o número de tentativas. Filtra por destinatário, ID de evento, tipo, status e período da primeira tentativa.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Admin"
                 ],
-                "summary": "Deleta um ou mais produtos",
+                "summary": "Pesquisa o registro de envios de e-mail",
                 "parameters": [
                     {
-                        "description": "SKUs of products to delete",
-                        "name": "skus",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "Recipient address, or part of it",
+                        "name": "recipient",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of an event included in the email",
+                        "name": "event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_notification, product_digest or account_locked",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sent or failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First attempt at or after, RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First attempt before, RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email deliveries retrieved successfully",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "integer"
-                            }
+                            "$ref": "#/definitions/dtos.EmailDeliveryListResponseDTO"
                         }
                     }
+                }
+            }
+        },
+        "/admin/email-deliveries/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Retorna um envio do registro com o conteúdo renderizado (texto e HTML) que foi enviado. Com format=html, retorna apenas o HTML do e-mail. O conteúdo não está mais disponível depois da anonimização.",
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Exibe um envio de e-mail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "json",
+                        "description": "json or html",
+                        "name": "format",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Product(s) deleted successfully",
+                        "description": "Email delivery retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/dtos.DeleteProductResponse"
+                            "$ref": "#/definitions/dtos.EmailDeliveryDetailDTO"
                         }
                     }
                 }
             }
         },
-        "/products/{sku}": {
-            "get": {
+        "/admin/email-deliveries/{id}/resend": {
+            "post": {
                 "security": [
                     {
                         "bearerAuth": []
                     }
                 ],
-                "description": "Recupera os detalhes de um único produto usando seu SKU",
+                "description": "Envia de novo o conteúdo registrado de um envio aos mesmos destinatários. O reenvio é registrado como um novo envio, ligado ao original por resend_of. Envios anonimizados não podem ser reenviados.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Admin"
                 ],
-                "summary": "Recupera um produto pelo SKU",
+                "summary": "Reenvia um e-mail",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product SKU",
-                        "name": "sku",
+                        "description": "Delivery ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
+                "responses": {
+                    "201": {
+                        "description": "Email resent",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.EmailDeliveryDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/email-preview": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Renderiza um e-mail de produtos com notificações de exemplo, como seria enviado para a frequência, o idioma e o fuso horário informados. Com format=html, retorna apenas o HTML do e-mail.",
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Pré-visualiza o e-mail de notificação",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "immediate",
+                        "description": "immediate, hourly or daily",
+                        "name": "frequency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "pt-BR, en or es",
+                        "name": "locale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone, e.g. America/Sao_Paulo",
+                        "name": "time_zone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "json",
+                        "description": "json or html",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "Product retrieved successfully",
+                        "description": "Email rendered successfully",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ProductResponseDTO"
+                            "$ref": "#/definitions/dtos.EmailPreviewResponse"
                         }
                     }
                 }
             }
         },
-        "/register": {
-            "post": {
-                "description": "Registra um novo usuário com nome, e-mail e senha. O e-mail deve ser único e a senha deve atender aos critérios de validação.",
+        "/admin/mfa/policies": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Informa, para cada papel, se a autenticação em dois fatores é obrigatória. Restrito a administradores.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Lista as políticas de autenticação em dois fatores",
+                "responses": {
+                    "200": {
+                        "description": "Policies by role",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.MFAPolicyDTO"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/mfa/policies/{role}": {
+            "put": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Torna a autenticação em dois fatores obrigatória (ou opcional) para um papel. Usuários do papel sem cadastro precisarão configurá-la no próximo login. Restrito a administradores.",
                 "consumes": [
                     "application/json"
                 ],
@@ -245,145 +478,3019 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin"
                 ],
-                "summary": "Cria um usuário",
+                "summary": "Define a política de autenticação em dois fatores de um papel",
                 "parameters": [
                     {
-                        "description": "User data for registration (name, email, password)",
-                        "name": "user",
+                        "type": "string",
+                        "description": "Role (admin, editor or viewer)",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Whether 2FA is required",
+                        "name": "policy",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.UserCreateDTO"
+                            "$ref": "#/definitions/dtos.SetMFAPolicyDTO"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "User created successfully",
+                    "200": {
+                        "description": "Policy updated",
                         "schema": {
-                            "$ref": "#/definitions/dtos.CreateUserResponse"
+                            "$ref": "#/definitions/dtos.MFAPolicyDTO"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dtos.BatchResult": {
+        },
+        "/admin/orgs": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Lista todas as organizações da plataforma. Restrito a administradores da plataforma.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Lista as organizações",
+                "responses": {
+                    "200": {
+                        "description": "Organizations retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.OrganizationResponseDTO"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Cria uma organização vazia com nome e slug únicos (letras minúsculas, números e hífens). Os membros são adicionados depois. Restrito a administradores da plataforma.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Cria uma organização",
+                "parameters": [
+                    {
+                        "description": "Organization data",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateOrganizationDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Organization created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OrganizationResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Lista os usuários de forma paginada, com busca opcional por nome ou e-mail. Restrito a administradores.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Lista usuários",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search by name or email",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Users retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UserListResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Retorna os dados de um usuário pelo ID. Restrito a administradores.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Busca um usuário",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UserResponseDTO"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Altera o nome e/ou o e-mail de um usuário. Um novo e-mail precisa ser verificado novamente; os produtos criados passam para o novo nome. Restrito a administradores.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Edita um usuário",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New name and/or email",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UpdateUserDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UserResponseDTO"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Exclui a conta; seus tokens deixam de ser aceitos e o e-mail pode ser usado em um novo cadastro. Restrito a administradores.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Exclui um usuário",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/disable": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Desativa a conta: o usuário não consegue mais fazer login, renovar tokens nem usar os tokens e chaves de API existentes. Restrito a administradores.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Desativa um usuário",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User disabled successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Reativa uma conta desativada. Restrito a administradores.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Reativa um usuário",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User enabled successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/mfa": {
+            "delete": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Remove o segredo TOTP e os códigos de recuperação de um usuário que perdeu o acesso ao autenticador. Se o papel exigir, o usuário fará um novo cadastro no próximo login. Restrito a administradores.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Redefine a autenticação em dois fatores de um usuário",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication reset",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Define o papel (admin, editor ou viewer) de um usuário. O novo papel passa a valer no próximo login ou renovação de token. Restrito a administradores.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Altera o papel de um usuário",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AssignRoleDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role assigned successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Remove o bloqueio temporário por tentativas de login malsucedidas e zera o contador de falhas da conta. Restrito a administradores.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Desbloqueia um usuário",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User unlocked successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/email/verify": {
+            "get": {
+                "description": "Valida o token assinado enviado por e-mail após o cadastro e marca a conta como verificada.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Confirma o e-mail do usuário",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email verified successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/email/verify/resend": {
+            "post": {
+                "description": "Envia um novo link de verificação. A resposta é a mesma para e-mails desconhecidos, já verificados ou pedidos dentro do intervalo; novos envios para a mesma conta são limitados por intervalo.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Reenvia o e-mail de verificação",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResendVerificationDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Request accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Informa o estado das conexões com o RabbitMQ (connected, reconnecting ou closed), incluindo as dos consumidores quando o worker roda embutido na API. Retorna 503 enquanto alguma conexão não está disponível; as escritas de produtos continuam funcionando e seus eventos aguardam no outbox.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Verifica a saúde da API",
+                "responses": {
+                    "200": {
+                        "description": "All connections are available",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "RabbitMQ is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations/{token}/accept": {
+            "post": {
+                "description": "Cria a conta do convidado com o e-mail do convite, o nome e a senha informados, já verificada e vinculada à organização com o papel do convite. O código pode ser usado apenas uma vez.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Aceita um convite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invitation token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Account data",
+                        "name": "account",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AcceptInvitationDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Account created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Autentica um usuário com base em e-mail e senha, retornando um token JWT de curta duração e um refresh token rotativo. Contas com e-mail não verificado recebem 403 com o código \"email_not_verified\". Falhas repetidas por conta ou IP impõem atrasos progressivos e bloqueio temporário (429 com Retry-After); e-mail inexistente e senha incorreta recebem a mesma resposta. Usuários com autenticação em dois fatores (ou cujo papel a exige) recebem, em vez dos tokens, um desafio (dtos.MFAChallengeResponse) a ser concluído em /login/mfa.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Autentica um usuário",
+                "parameters": [
+                    {
+                        "description": "User credentials (email and password)",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UserLoginDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful authentication with JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LoginResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/login/mfa": {
+            "post": {
+                "description": "Conclui o login iniciado em /login com o token de desafio e um código TOTP do aplicativo autenticador ou um código de recuperação. Quando o desafio é de cadastro obrigatório, o código confirma o autenticador configurado em /login/mfa/enroll e os códigos de recuperação são retornados uma única vez. Códigos incorretos contam como tentativas de login malsucedidas.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Conclui o login com o segundo fator",
+                "parameters": [
+                    {
+                        "description": "MFA challenge token and code",
+                        "name": "mfa",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LoginMFADTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful authentication with JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MFALoginResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid MFA token or code",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/login/mfa/enroll": {
+            "post": {
+                "description": "Gera o segredo TOTP de um usuário cujo papel exige autenticação em dois fatores e que ainda não a configurou. Usa o token de desafio retornado por /login com \"enrollment_required\"; o otpauth_uri deve ser exibido como QR code. O cadastro é concluído em /login/mfa.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Inicia o cadastro obrigatório do segundo fator",
+                "parameters": [
+                    {
+                        "description": "MFA challenge token",
+                        "name": "mfa",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MFATokenDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "TOTP secret and otpauth URI",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MFAEnrollmentResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Revoga o token JWT usado na requisição e, se informado, o refresh token da sessão, que precisa pertencer ao usuário autenticado.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Encerra a sessão atual",
+                "parameters": [
+                    {
+                        "description": "Refresh token of the session",
+                        "name": "refresh",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RefreshTokenDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Logged out successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Refresh token of another user",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Retorna os dados da conta do usuário autenticado.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Retorna o perfil do usuário autenticado",
+                "responses": {
+                    "200": {
+                        "description": "Profile retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UserResponseDTO"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Altera o nome e/ou o e-mail do usuário autenticado. Um novo e-mail precisa ser verificado antes do próximo login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Edita o perfil do usuário autenticado",
+                "parameters": [
+                    {
+                        "description": "New name and/or email",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UpdateUserDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UserResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Retorna, para cada evento de produto (product_created, product_updated, product_deleted), a frequência dos e-mails do usuário autenticado: immediate, hourly, daily ou off. Retorna também o idioma (pt-BR, en ou es) e o fuso horário usados nos e-mails.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Retorna as preferências de notificação",
+                "responses": {
+                    "200": {
+                        "description": "Preferences retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.NotificationPreferencesDTO"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Define a frequência dos e-mails de cada evento de produto: immediate (resumo a cada poucos segundos), hourly (resumo por hora), daily (resumo diário) ou off. Eventos omitidos mantêm a frequência atual. Os campos opcionais locale (pt-BR, en ou es) e time_zone (ex.: America/Sao_Paulo) definem o idioma e o fuso horário dos e-mails.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Altera as preferências de notificação",
+                "parameters": [
+                    {
+                        "description": "Frequency per event",
+                        "name": "preferences",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.NotificationPreferencesDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Preferences updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.NotificationPreferencesDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Confirma o aplicativo autenticador com um código TOTP válido e ativa a autenticação em dois fatores. Os códigos de recuperação de uso único são exibidos apenas nesta resposta.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Ativa a autenticação em dois fatores",
+                "parameters": [
+                    {
+                        "description": "TOTP code",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MFACodeDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication enabled",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RecoveryCodesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Desativa a autenticação em dois fatores do usuário autenticado após validar um código atual. Não é permitido quando o papel do usuário a exige.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Desativa a autenticação em dois fatores",
+                "parameters": [
+                    {
+                        "description": "TOTP or recovery code",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MFACodeDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication disabled",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Gera um novo segredo TOTP para o usuário autenticado. O otpauth_uri deve ser exibido como QR code para o aplicativo autenticador; a autenticação em dois fatores só é ativada após a confirmação com um código válido.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Inicia a autenticação em dois fatores",
+                "responses": {
+                    "200": {
+                        "description": "TOTP secret and otpauth URI",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MFAEnrollmentResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Substitui todos os códigos de recuperação do usuário autenticado após validar um código atual. Os códigos anteriores deixam de funcionar.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Gera novos códigos de recuperação",
+                "parameters": [
+                    {
+                        "description": "TOTP or recovery code",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MFACodeDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New recovery codes",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RecoveryCodesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Lista as notificações de produtos da caixa de entrada do usuário autenticado, das mais recentes para as mais antigas, com o total de não lidas.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Lista as notificações do usuário",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only unread notifications",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Notifications retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.InboxListResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/read-all": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Marca como lidas todas as notificações não lidas da caixa de entrada do usuário autenticado.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Marca todas as notificações como lidas",
+                "responses": {
+                    "200": {
+                        "description": "Notifications marked as read",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.InboxReadAllResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/unsubscribe": {
+            "get": {
+                "description": "Desativa os e-mails de produtos do usuário identificado pelo token do link de cancelamento presente em cada e-mail. Sem o parâmetro event, desativa todos os eventos. Links enviados a endereços sem conta desativam sempre todos os eventos.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Cancela o recebimento de notificações",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unsubscribe token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Single event to turn off",
+                        "name": "event",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Unsubscribed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}/read": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Marca uma notificação da caixa de entrada do usuário autenticado como lida.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Marca uma notificação como lida",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Notification marked as read",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.InboxNotificationDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}/unread": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Volta uma notificação da caixa de entrada do usuário autenticado para não lida.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Marca uma notificação como não lida",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Notification marked as unread",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.InboxNotificationDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/oidc/callback": {
+            "get": {
+                "description": "Troca o código de autorização, valida o ID token pelas chaves JWKS do provedor e emite os tokens da própria API. Usuários são vinculados pelo e-mail verificado ou criados automaticamente, e os grupos do provedor definem o papel conforme OIDC_ROLE_MAPPING.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Conclui o login pelo provedor de identidade",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State returned by the identity provider",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful authentication with JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired state",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Identity provider login failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Account disabled or not provisioned",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/oidc/login": {
+            "get": {
+                "description": "Redireciona o navegador para o provedor OpenID Connect configurado usando o fluxo authorization code com PKCE. O provedor retorna para /oidc/callback.",
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Inicia o login pelo provedor de identidade",
+                "responses": {
+                    "302": {
+                        "description": "Redirect to the identity provider"
+                    }
+                }
+            }
+        },
+        "/orgs": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Lista as organizações das quais o usuário autenticado é membro, com seu papel em cada uma e qual está ativa no token atual.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Lista minhas organizações",
+                "responses": {
+                    "200": {
+                        "description": "Organizations retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.MembershipResponseDTO"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{id}/invitations": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Lista os convites da organização com seu status (pending, accepted, revoked ou expired). Restrito aos administradores da organização.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Lista os convites da organização",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Invitations retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.InvitationResponseDTO"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Cria um convite com papel e expiração opcional (padrão INVITATION_TTL) e o envia por e-mail. Um novo convite para o mesmo e-mail substitui o pendente. Restrito aos administradores da organização.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Convida uma pessoa para a organização",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Invitation data",
+                        "name": "invitation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateInvitationDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Invitation created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.InvitationResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{id}/invitations/{invitationId}": {
+            "delete": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Cancela um convite pendente; seu código deixa de ser aceito. Restrito aos administradores da organização.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Revoga um convite",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Invitation ID",
+                        "name": "invitationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Invitation revoked",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{id}/invitations/{invitationId}/resend": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Envia novamente um convite pendente ou expirado com um novo código, renovando sua validade; o código anterior deixa de funcionar. Restrito aos administradores da organização.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Reenvia um convite",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Invitation ID",
+                        "name": "invitationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Invitation resent successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.InvitationResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{id}/members": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Lista os membros da organização com seus papéis. Restrito aos administradores da organização.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Lista os membros de uma organização",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Members retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.MemberResponseDTO"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Adiciona um usuário existente, identificado pelo email, à organização com o papel informado; se já for membro, seu papel é alterado. Restrito aos administradores da organização.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Adiciona um membro à organização",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member data",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AddMemberDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Member added successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MemberResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{id}/members/{userId}": {
+            "put": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Define o papel (admin, editor ou viewer) de um membro na organização. A organização deve manter ao menos um administrador. Restrito aos administradores da organização.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Altera o papel de um membro",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AssignRoleDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Member updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Remove o usuário da organização; o acesso termina imediatamente, mesmo com tokens já emitidos. A organização deve manter ao menos um administrador. Restrito aos administradores da organização.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Remove um membro da organização",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Member removed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{id}/switch": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Emite novos tokens com a organização informada como ativa. A escolha é lembrada nos próximos logins. Organizações às quais o usuário não tem acesso retornam 404.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Troca a organização ativa",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Organization switched successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LoginResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/change": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Altera a senha do usuário autenticado após confirmar a senha atual.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Altera a senha",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ChangePasswordDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password changed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/forgot": {
+            "post": {
+                "description": "Envia por e-mail um código de uso único e tempo limitado para redefinir a senha. A resposta é sempre a mesma, exista ou não uma conta com o e-mail informado.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Solicita a redefinição de senha",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ForgotPasswordDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Request accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/reset": {
+            "post": {
+                "description": "Define uma nova senha usando o código recebido por e-mail. O código só pode ser usado uma vez e todas as sessões existentes são revogadas.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Redefine a senha",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResetPasswordDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "apiKeyAuth": []
+                    }
+                ],
+                "description": "Recupera uma lista de todos os produtos do banco de dados",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Recupera todos os produtos",
+                "responses": {
+                    "200": {
+                        "description": "Products retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.ProductResponseDTO"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "apiKeyAuth": []
+                    }
+                ],
+                "description": "Atualiza produtos existentes com base em um único objeto ou em uma matriz de objetos no corpo da solicitação",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Atualiza um ou mais produtos",
+                "parameters": [
+                    {
+                        "description": "Product data to update",
+                        "name": "products",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.UpdateProductDTO"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product(s) updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UpdateProductResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "apiKeyAuth": []
+                    }
+                ],
+                "description": "Cria novos produtos com base em um único objeto ou em uma matriz de objetos no corpo da solicitação",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Cria um ou mais produtos",
+                "parameters": [
+                    {
+                        "description": "Product data to create",
+                        "name": "products",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.CreateProductDTO"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product(s) created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateProductResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "apiKeyAuth": []
+                    }
+                ],
+                "description": "Exclui produtos do banco de dados com base em um único SKU ou em uma matriz de SKUs no corpo da solicitação",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Deleta um ou mais produtos",
+                "parameters": [
+                    {
+                        "description": "SKUs of products to delete",
+                        "name": "skus",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product(s) deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.DeleteProductResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{sku}": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "apiKeyAuth": []
+                    }
+                ],
+                "description": "Recupera os detalhes de um único produto usando seu SKU",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Recupera um produto pelo SKU",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product SKU",
+                        "name": "sku",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProductResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Registra um novo usuário com nome, e-mail e senha. O e-mail deve ser único e a senha deve atender aos critérios de validação. A conta recebe o papel viewer, somente leitura, até que um administrador o altere. Um link de verificação é enviado para o e-mail informado. Disponível apenas com OPEN_REGISTRATION habilitado (padrão desabilitado em produção); caso contrário, contas são criadas por convite.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Cria um usuário",
+                "parameters": [
+                    {
+                        "description": "User data for registration (name, email, password)",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UserCreateDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateUserResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/revoke": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    }
+                ],
+                "description": "Invalida todos os refresh tokens e tokens JWT emitidos para o usuário autenticado, encerrando todas as sessões ativas.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Revoga todas as sessões do usuário",
+                "responses": {
+                    "200": {
+                        "description": "All sessions revoked",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "Troca um refresh token válido por um novo token JWT e um novo refresh token. O refresh token antigo é invalidado; reutilizá-lo revoga toda a sessão.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Renova o token de acesso",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "refresh",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RefreshTokenDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New access and refresh tokens",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LoginResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dtos.APIKeyResponseDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "erp-integration"
+                },
+                "org_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "owner_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "pck_Ab12Cd34"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dtos.AcceptInvitationDTO": {
+            "type": "object",
+            "required": [
+                "name",
+                "password"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3,
+                    "example": "Maria Silva"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6,
+                    "example": "s3nh4-f0rt3"
+                }
+            }
+        },
+        "dtos.AddMemberDTO": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "maria@example.com"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "editor",
+                        "viewer"
+                    ],
+                    "example": "editor"
+                }
+            }
+        },
+        "dtos.AssignRoleDTO": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "editor",
+                        "viewer"
+                    ]
+                }
+            }
+        },
+        "dtos.BatchResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "sku": {
+                    "type": "integer",
+                    "example": 12345
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "dtos.ChangePasswordDTO": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string",
+                    "minLength": 6
+                }
+            }
+        },
+        "dtos.CreateAPIKeyDTO": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3,
+                    "example": "erp-integration"
+                },
+                "org_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "owner_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "products:read",
+                        "products:write"
+                    ]
+                }
+            }
+        },
+        "dtos.CreateAPIKeyResponseDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "key": {
+                    "type": "string",
+                    "example": "pck_Ab12Cd34..."
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "erp-integration"
+                },
+                "org_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "owner_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "pck_Ab12Cd34"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dtos.CreateInvitationDTO": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "maria@example.com"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "editor",
+                        "viewer"
+                    ],
+                    "example": "editor"
+                }
+            }
+        },
+        "dtos.CreateOrganizationDTO": {
+            "type": "object",
+            "required": [
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "Acme Store"
+                },
+                "slug": {
+                    "type": "string",
+                    "maxLength": 63,
+                    "minLength": 2,
+                    "example": "acme-store"
+                }
+            }
+        },
+        "dtos.CreateProductDTO": {
+            "type": "object",
+            "required": [
+                "availability",
+                "category",
+                "name",
+                "price",
+                "sku"
+            ],
+            "properties": {
+                "availability": {
+                    "type": "string",
+                    "enum": [
+                        "in stock",
+                        "out of stock"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "image_link": {
+                    "type": "string"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3
+                },
+                "price": {
+                    "type": "number"
+                },
+                "sku": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.CreateProductResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Product(s) created successfully"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.BatchResult"
+                    }
+                }
+            }
+        },
+        "dtos.CreateUserResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "User created successfully"
+                }
+            }
+        },
+        "dtos.DeadLetterCountResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Dead-lettered messages requeued"
+                },
+                "queue": {
+                    "type": "string",
+                    "example": "product_events"
+                }
+            }
+        },
+        "dtos.DeadLetterListResponse": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.DeadLetterResponse"
+                    }
+                },
+                "queue": {
+                    "type": "string",
+                    "example": "product_events"
+                }
+            }
+        },
+        "dtos.DeadLetterResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "body": {
+                    "type": "string",
+                    "example": "{\"event\":\"product_created\"}"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "dial tcp: connection refused"
+                },
+                "message_id": {
+                    "type": "string",
+                    "example": "5f2b6c1e9a7d4e3f8b0c1d2e3f4a5b6c"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.DeleteProductResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Product(s) deleted successfully"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.BatchResult"
+                    }
+                }
+            }
+        },
+        "dtos.EmailDeliveryDTO": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "event_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "0b6f3c1e-9a4d-4f8e-8a57-2d1f0c9e7b31"
+                    ]
+                },
+                "first_attempt_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "product_notification"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "recipients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "joao@example.com"
+                    ]
+                },
+                "redacted": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "redacted_at": {
+                    "type": "string"
+                },
+                "resend_of": {
+                    "type": "integer"
+                },
+                "response": {
+                    "type": "string",
+                    "example": "mailbox unavailable"
+                },
+                "response_code": {
+                    "type": "integer",
+                    "example": 550
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "sent"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "Resumo de Notificações de Produtos: 2 Produtos foram criados"
+                }
+            }
+        },
+        "dtos.EmailDeliveryDetailDTO": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "event_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "0b6f3c1e-9a4d-4f8e-8a57-2d1f0c9e7b31"
+                    ]
+                },
+                "first_attempt_at": {
+                    "type": "string"
+                },
+                "html": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "product_notification"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "recipients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "joao@example.com"
+                    ]
+                },
+                "redacted": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "redacted_at": {
+                    "type": "string"
+                },
+                "resend_of": {
+                    "type": "integer"
+                },
+                "response": {
+                    "type": "string",
+                    "example": "mailbox unavailable"
+                },
+                "response_code": {
+                    "type": "integer",
+                    "example": 550
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "sent"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "Resumo de Notificações de Produtos: 2 Produtos foram criados"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.EmailDeliveryListResponseDTO": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.EmailDeliveryDTO"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "dtos.EmailPreviewResponse": {
+            "type": "object",
+            "properties": {
+                "html": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "Resumo de Notificações de Produtos: 2 Produtos foram criados"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ForgotPasswordDTO": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "rabbitmq": {
+                    "type": "string",
+                    "example": "connected"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "dtos.InboxListResponseDTO": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.InboxNotificationDTO"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "unread": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dtos.InboxNotificationDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string",
+                    "example": "product_created"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Caneta"
+                },
+                "org_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "org_name": {
+                    "type": "string",
+                    "example": "Acme"
+                },
+                "read": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "read_at": {
+                    "type": "string"
+                },
+                "sku": {
+                    "type": "integer",
+                    "example": 1001
+                }
+            }
+        },
+        "dtos.InboxReadAllResponseDTO": {
+            "type": "object",
+            "properties": {
+                "updated": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dtos.InvitationResponseDTO": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "maria@example.com"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "invited_by_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "org_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "editor"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                }
+            }
+        },
+        "dtos.LoginMFADTO": {
             "type": "object",
+            "required": [
+                "code",
+                "mfa_token"
+            ],
             "properties": {
-                "errors": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "code": {
+                    "type": "string",
+                    "example": "123456"
                 },
-                "index": {
+                "mfa_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "dtos.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
                     "type": "integer",
-                    "example": 0
+                    "example": 900
                 },
-                "sku": {
-                    "type": "integer",
-                    "example": 12345
+                "refresh_token": {
+                    "type": "string",
+                    "example": "q8Zk3v2m9YbT0xWc..."
                 },
-                "status": {
+                "token": {
                     "type": "string",
-                    "example": "ok"
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
                 }
             }
         },
-        "dtos.CreateProductDTO": {
+        "dtos.MFACodeDTO": {
             "type": "object",
             "required": [
-                "availability",
-                "category",
-                "name",
-                "price",
-                "sku"
+                "code"
             ],
             "properties": {
-                "availability": {
+                "code": {
                     "type": "string",
-                    "enum": [
-                        "in stock",
-                        "out of stock"
-                    ]
+                    "example": "123456"
+                }
+            }
+        },
+        "dtos.MFAEnrollmentResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/Products%20CRUD:amanda@example.com?algorithm=SHA1\u0026digits=6\u0026issuer=Products+CRUD\u0026period=30\u0026secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
                 },
-                "category": {
+                "secret": {
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 3
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "dtos.MFALoginResponse": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "integer",
+                    "example": 900
                 },
-                "description": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "refresh_token": {
                     "type": "string",
-                    "maxLength": 500
+                    "example": "q8Zk3v2m9YbT0xWc..."
                 },
-                "image_link": {
-                    "type": "string"
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "dtos.MFAPolicyDTO": {
+            "type": "object",
+            "properties": {
+                "required": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "link": {
-                    "type": "string"
+                "role": {
+                    "type": "string",
+                    "example": "editor"
+                }
+            }
+        },
+        "dtos.MFATokenDTO": {
+            "type": "object",
+            "required": [
+                "mfa_token"
+            ],
+            "properties": {
+                "mfa_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "dtos.MemberResponseDTO": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "maria@example.com"
                 },
                 "name": {
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 3
+                    "example": "Maria Silva"
                 },
-                "price": {
-                    "type": "number"
+                "role": {
+                    "type": "string",
+                    "example": "editor"
                 },
-                "sku": {
-                    "type": "integer"
+                "user_id": {
+                    "type": "integer",
+                    "example": 7
                 }
             }
         },
-        "dtos.CreateProductResponse": {
+        "dtos.MembershipResponseDTO": {
             "type": "object",
             "properties": {
-                "message": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
                     "type": "string",
-                    "example": "Product(s) created successfully"
+                    "example": "Acme Store"
                 },
-                "results": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dtos.BatchResult"
-                    }
+                "role": {
+                    "type": "string",
+                    "example": "editor"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "acme-store"
                 }
             }
         },
-        "dtos.CreateUserResponse": {
+        "dtos.MessageResponse": {
             "type": "object",
             "properties": {
                 "message": {
                     "type": "string",
-                    "example": "User created successfully"
+                    "example": "Logged out successfully"
                 }
             }
         },
-        "dtos.DeleteProductResponse": {
+        "dtos.NotificationPreferencesDTO": {
             "type": "object",
             "properties": {
-                "message": {
+                "locale": {
                     "type": "string",
-                    "example": "Product(s) deleted successfully"
+                    "example": "pt-BR"
                 },
-                "results": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dtos.BatchResult"
+                "preferences": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "product_created": "immediate",
+                        "product_deleted": "off",
+                        "product_updated": "daily"
                     }
+                },
+                "time_zone": {
+                    "type": "string",
+                    "example": "America/Sao_Paulo"
                 }
             }
         },
-        "dtos.LoginResponse": {
+        "dtos.OrganizationResponseDTO": {
             "type": "object",
             "properties": {
-                "token": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
                     "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                    "example": "Acme Store"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "acme-store"
                 }
             }
         },
@@ -425,6 +3532,71 @@ const docTemplate = `{
                 }
             }
         },
+        "dtos.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "k3f9q-2mx7d",
+                        "p8w2z-n4c6v"
+                    ]
+                }
+            }
+        },
+        "dtos.RefreshTokenDTO": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ResendVerificationDTO": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ResetPasswordDTO": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "token"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string",
+                    "minLength": 6
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.SetMFAPolicyDTO": {
+            "type": "object",
+            "required": [
+                "required"
+            ],
+            "properties": {
+                "required": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
         "dtos.UpdateProductDTO": {
             "type": "object",
             "required": [
@@ -481,6 +3653,21 @@ const docTemplate = `{
                 }
             }
         },
+        "dtos.UpdateUserDTO": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "amanda@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3,
+                    "example": "Amanda Silva"
+                }
+            }
+        },
         "dtos.UserCreateDTO": {
             "type": "object",
             "required": [
@@ -503,6 +3690,29 @@ const docTemplate = `{
                 }
             }
         },
+        "dtos.UserListResponseDTO": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.UserResponseDTO"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
         "dtos.UserLoginDTO": {
             "type": "object",
             "required": [
@@ -518,9 +3728,52 @@ const docTemplate = `{
                     "minLength": 6
                 }
             }
+        },
+        "dtos.UserResponseDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "amanda@example.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "mfa_enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Amanda Silva"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "editor"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "verified_at": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
+        "apiKeyAuth": {
+            "description": "Type \"ApiKey\" followed by a space and an API key.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        },
         "bearerAuth": {
             "description": "Type \"Bearer\" followed by a space and a JWT token.",
             "type": "apiKey",
@@ -2,16 +2,17 @@ package main
 
 import (
 	"context"
-	"os"
-	"os/signal"
-	"syscall"
 
-	"github.com/Amandasilvbr/products-crud/cmd/consumer"
 	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/consumer"
+	domainmessaging "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
 	"github.com/Amandasilvbr/products-crud/internal/handler"
 	"github.com/Amandasilvbr/products-crud/internal/infrastructure/database"
+	"github.com/Amandasilvbr/products-crud/internal/infrastructure/keyset"
 	"github.com/Amandasilvbr/products-crud/internal/infrastructure/logger"
+	"github.com/Amandasilvbr/products-crud/internal/infrastructure/mail"
 	"github.com/Amandasilvbr/products-crud/internal/infrastructure/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/infrastructure/oidc"
 	"github.com/Amandasilvbr/products-crud/internal/infrastructure/repository"
 	"github.com/Amandasilvbr/products-crud/internal/server"
 	"github.com/Amandasilvbr/products-crud/internal/usecase"
@@ -29,6 +30,10 @@ import (
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and a JWT token.
+// @securityDefinitions.apikey apiKeyAuth
+// @in header
+// @name Authorization
+// @description Type "ApiKey" followed by a space and an API key.
 func main() {
 	// Load application configurations from environment variables or a config file
 	cfg, err := config.New()
@@ -44,9 +49,9 @@ func main() {
 	zapLogger := zap.L()
 	zapLogger.Info("Logger initialized successfully", zap.String("environment", cfg.AppEnv))
 
-	// Set up a context that can be canceled
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Set up a context cancelled on SIGINT or SIGTERM
+	ctx, stop := server.NotifyShutdown(context.Background())
+	defer stop()
 
 	// Establish a connection to the PostgreSQL database
 	db, err := database.ConnectDB(
@@ -64,62 +69,151 @@ func main() {
 	}
 	zapLogger.Info("Database migrations executed successfully")
 
-	// Get the RabbitMQ URL from an environment variable
-	amqpURL := os.Getenv("RABBITMQ_URL")
-	if amqpURL == "" {
-		zapLogger.Fatal("RABBITMQ_URL environment variable is not set")
+	// Connections to the message broker are opened by the configured driver, RabbitMQ or in-process
+	dial, err := messaging.NewDialer(cfg.MessagingDriver, cfg.RabbitMQURL)
+	if err != nil {
+		zapLogger.Fatal("Failed to initialize messaging driver", zap.Error(err))
 	}
 
-	// Initialize RabbitMQ client for publishing messages
-	rabbitMQ, err := messaging.NewRabbitMQClient(amqpURL)
+	// Failed messages are retried through a retry queue, then moved to a dead-letter queue
+	retryPolicy := domainmessaging.RetryPolicy{MaxAttempts: cfg.ConsumerMaxAttempts, Delay: cfg.ConsumerRetryDelay}
+
+	// Connect to the message broker for publishing messages
+	broker, err := dial(retryPolicy)
 	if err != nil {
-		zapLogger.Fatal("Failed to connect to RabbitMQ for API", zap.Error(err))
+		zapLogger.Fatal("Failed to connect to the message broker for API", zap.Error(err))
 	}
-	defer rabbitMQ.Close()
-	zapLogger.Info("RabbitMQ connection for API established")
+	defer broker.Close()
+	zapLogger.Info("Message broker connection for API established", zap.String("driver", cfg.MessagingDriver))
 
-	// Declare the queue for publishing events
-	err = rabbitMQ.DeclareQueue("product_events")
+	// Declare the product events exchange with the notifications queue bound to it, so that no event
+	// is dropped before the consumer starts, and the queue of account events
+	err = broker.DeclareTopology(domainmessaging.ProductNotificationsSubscription.Topology())
+	if err != nil {
+		zapLogger.Fatal("Failed to declare RabbitMQ queue for API", zap.Error(err))
+	}
+	err = broker.DeclareQueue(domainmessaging.AccountEventsQueue)
 	if err != nil {
 		zapLogger.Fatal("Failed to declare RabbitMQ queue for API", zap.Error(err))
 	}
 	zapLogger.Info("RabbitMQ queue declared successfully for API")
 
-	// Initialize RabbitMQ consumer
-	consumer, err := consumer.NewConsumer(zapLogger, amqpURL, "product_events")
+	mailer := mail.NewSMTPMailer(cfg, zapLogger)
+
+	// Load the access token signing keys once; rotation takes effect on restart
+	keySet, err := keyset.Load(cfg, zapLogger)
 	if err != nil {
-		zapLogger.Fatal("Failed to initialize RabbitMQ consumer", zap.Error(err))
+		zapLogger.Fatal("Failed to load JWT signing keys", zap.Error(err))
+	}
+
+	// Notification emails are rendered from templates, which a directory can override
+	emailRenderer, err := mail.NewTemplateRenderer(cfg.EmailTemplateDir, cfg.DefaultTimeZone, zapLogger)
+	if err != nil {
+		zapLogger.Fatal("Failed to load email templates", zap.Error(err))
 	}
-	defer consumer.Close()
-	zapLogger.Info("RabbitMQ consumer initialized successfully")
-
-	// Start RabbitMQ consumer in a goroutine
-	go func() {
-		zapLogger.Info("Starting RabbitMQ consumer")
-		if err := consumer.Start(ctx); err != nil {
-			zapLogger.Error("Consumer stopped with error", zap.Error(err))
-		}
-	}()
 
 	// Dependency Injection
 	userRepo := repository.NewUserRepository(db, zapLogger)
 	productRepo := repository.NewProductRepository(db, zapLogger)
-	authUsecase := usecase.NewAuthUsecase(userRepo, zapLogger)
-	productUsecase := usecase.NewProductUseCase(productRepo, zapLogger, rabbitMQ)
-	authHandler := handler.NewAuthHandler(authUsecase, zapLogger)
-	productHandler := handler.NewProductHandler(productUsecase, zapLogger)
+	tokenRepo := repository.NewTokenRepository(db, zapLogger)
+	apiKeyRepo := repository.NewAPIKeyRepository(db, zapLogger)
+	passwordResetRepo := repository.NewPasswordResetRepository(db, zapLogger)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db, zapLogger)
+	mfaRepo := repository.NewMFARepository(db, zapLogger)
+	oidcStateRepo := repository.NewOIDCStateRepository(db, zapLogger)
+	orgRepo := repository.NewOrganizationRepository(db, zapLogger)
+	invitationRepo := repository.NewInvitationRepository(db, zapLogger)
+	outboxRepo := repository.NewOutboxRepository(db, zapLogger)
+	notificationRepo := repository.NewNotificationRepository(db, zapLogger)
+	inboxRepo := repository.NewInboxRepository(db, zapLogger)
+	processedMessageRepo := repository.NewProcessedMessageRepository(db, zapLogger)
+	emailDeliveryRepo := repository.NewEmailDeliveryRepository(db, zapLogger)
+	orgUsecase := usecase.NewOrganizationUsecase(orgRepo, userRepo, zapLogger)
+	lockoutUsecase := usecase.NewLockoutUsecase(loginAttemptRepo, userRepo, broker, cfg, zapLogger)
+	mfaUsecase := usecase.NewMFAUsecase(userRepo, mfaRepo, orgRepo, cfg, zapLogger)
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, lockoutUsecase, mfaUsecase, orgUsecase, keySet, cfg, zapLogger)
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo, userRepo, orgUsecase, zapLogger)
+	passwordUsecase := usecase.NewPasswordUsecase(userRepo, passwordResetRepo, authUsecase, mailer, cfg, zapLogger)
+	verificationUsecase := usecase.NewVerificationUsecase(userRepo, mailer, cfg, zapLogger)
+	userUsecase := usecase.NewUserUsecase(userRepo, verificationUsecase, zapLogger)
+	productUsecase := usecase.NewProductUseCase(productRepo, zapLogger)
+	invitationUsecase := usecase.NewInvitationUsecase(invitationRepo, userRepo, orgRepo, orgUsecase, mailer, cfg, zapLogger)
+	deadLetterUsecase := usecase.NewDeadLetterUsecase(broker, zapLogger)
+	// Notification emails are sent through the delivery log, which records every attempt
+	emailDeliveryUsecase := usecase.NewEmailDeliveryUsecase(emailDeliveryRepo, mailer, cfg, zapLogger)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo, userRepo, emailDeliveryUsecase, emailRenderer, cfg, zapLogger)
+	inboxUsecase := usecase.NewInboxUsecase(inboxRepo, userRepo, zapLogger)
+
+	// The HTTP server and, when embedded, the worker run in a group that stops on shutdown
+	group := server.NewGroup(ctx, zapLogger)
+	brokers := []domainmessaging.ConnectionMonitor{broker}
+
+	// The consumers, the outbox relay and the digests run here unless cmd/worker is deployed
+	if cfg.EmbeddedWorker {
+		deduplicator := usecase.NewMessageDeduplicator(processedMessageRepo, cfg, zapLogger)
+		dispatcher := consumer.NewDispatcher(cfg, notificationUsecase, inboxUsecase, emailDeliveryUsecase, deduplicator, zapLogger)
+		outboxRelay := usecase.NewOutboxRelay(outboxRepo, broker, cfg, zapLogger)
+		worker, err := consumer.NewWorker(cfg, dial, outboxRelay, notificationUsecase, dispatcher, deduplicator, emailDeliveryUsecase, zapLogger)
+		if err != nil {
+			zapLogger.Fatal("Failed to initialize embedded worker", zap.Error(err))
+		}
+		defer worker.Close()
+		worker.Start(group)
+		brokers = append(brokers, worker.Monitors()...)
+		zapLogger.Info("Embedded worker started")
+	}
 
-	// Initialize and start the HTTP server
-	go func() {
-		if err := server.Start(ctx, authHandler, productHandler, zapLogger); err != nil {
-			zapLogger.Fatal("Failed to start HTTP server", zap.Error(err))
+	// Single sign-on is enabled by configuring an OpenID Connect issuer
+	var oidcHandler *handler.OIDCHandler
+	if cfg.OIDCIssuerURL != "" {
+		provider, err := oidc.NewProvider(ctx, cfg, zapLogger)
+		if err != nil {
+			zapLogger.Fatal("Failed to initialize OIDC provider", zap.Error(err))
 		}
-	}()
+		oidcUsecase := usecase.NewOIDCUsecase(provider, oidcStateRepo, userRepo, authUsecase, lockoutUsecase, cfg, zapLogger)
+		oidcHandler = handler.NewOIDCHandler(oidcUsecase, zapLogger)
+	}
+
+	handlers := &server.Handlers{
+		Auth:         handler.NewAuthHandler(authUsecase, verificationUsecase, zapLogger),
+		Product:      handler.NewProductHandler(productUsecase, zapLogger),
+		User:         handler.NewUserHandler(userUsecase, lockoutUsecase, zapLogger),
+		APIKey:       handler.NewAPIKeyHandler(apiKeyUsecase, zapLogger),
+		Password:     handler.NewPasswordHandler(passwordUsecase, zapLogger),
+		MFA:          handler.NewMFAHandler(mfaUsecase, zapLogger),
+		OIDC:         oidcHandler,
+		JWKS:         handler.NewJWKSHandler(keySet, zapLogger),
+		Org:          handler.NewOrganizationHandler(orgUsecase, authUsecase, zapLogger),
+		Invite:       handler.NewInvitationHandler(invitationUsecase, zapLogger),
+		Health:       handler.NewHealthHandler(brokers, zapLogger),
+		DeadLetter:   handler.NewDeadLetterHandler(deadLetterUsecase, zapLogger),
+		Notification: handler.NewNotificationHandler(notificationUsecase, zapLogger),
+		Inbox:        handler.NewInboxHandler(inboxUsecase, zapLogger),
+		Email:        handler.NewEmailDeliveryHandler(emailDeliveryUsecase, zapLogger),
+
+		LocalLogin:       cfg.LocalLoginEnabled,
+		OpenRegistration: cfg.OpenRegistration,
+
+		KeySet:        keySet,
+		AuthUsecase:   authUsecase,
+		APIKeyUsecase: apiKeyUsecase,
+		OrgUsecase:    orgUsecase,
+	}
+
+	// Promote the configured bootstrap administrators
+	if err := userUsecase.EnsureAdmins(cfg.AdminEmails); err != nil {
+		zapLogger.Fatal("Failed to bootstrap administrators", zap.Error(err))
+	}
+
+	// Initialize and start the HTTP server
+	group.Go("http server", func(ctx context.Context) error {
+		return server.Start(ctx, handlers, zapLogger)
+	})
 
 	// Controlled Shutdown Handling
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	<-c
+	<-group.Context().Done()
 	zapLogger.Info("Shutting down application...")
-	cancel()
+	if err := group.Wait(cfg.ShutdownTimeout); err != nil {
+		zapLogger.Error("Application stopped with error", zap.Error(err))
+	}
 }
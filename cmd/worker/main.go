@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/consumer"
+	domainmessaging "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/handler"
+	"github.com/Amandasilvbr/products-crud/internal/infrastructure/database"
+	"github.com/Amandasilvbr/products-crud/internal/infrastructure/logger"
+	"github.com/Amandasilvbr/products-crud/internal/infrastructure/mail"
+	"github.com/Amandasilvbr/products-crud/internal/infrastructure/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/infrastructure/repository"
+	"github.com/Amandasilvbr/products-crud/internal/server"
+	"github.com/Amandasilvbr/products-crud/internal/usecase"
+
+	"go.uber.org/zap"
+)
+
+// The worker runs the consumers, the outbox relay and the notification digests without the API,
+// so that they can be scaled apart from it; set EMBEDDED_WORKER=false on the API when deploying it
+func main() {
+	// Load application configurations from environment variables or a config file
+	cfg, err := config.New()
+	if err != nil {
+		panic("failed to load configs: " + err.Error())
+	}
+
+	// Initialize the Zap logger
+	isDevelopment := (cfg.AppEnv == "development")
+	if err := logger.Init(isDevelopment); err != nil {
+		panic("failed to initialize logger: " + err.Error())
+	}
+	zapLogger := zap.L()
+	zapLogger.Info("Logger initialized successfully", zap.String("environment", cfg.AppEnv))
+
+	// Set up a context cancelled on SIGINT or SIGTERM
+	ctx, stop := server.NotifyShutdown(context.Background())
+	defer stop()
+
+	// Establish a connection to the PostgreSQL database
+	db, err := database.ConnectDB(
+		cfg,
+		zapLogger,
+	)
+	if err != nil {
+		zapLogger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	zapLogger.Info("Successfully connected to database")
+
+	// Run database migrations
+	if err := database.RunMigrations(db, zapLogger); err != nil {
+		zapLogger.Fatal("Failed to run migrations", zap.Error(err))
+	}
+	zapLogger.Info("Database migrations executed successfully")
+
+	// The in-process broker only reaches consumers running inside the API
+	if err := consumer.CheckStandalone(cfg); err != nil {
+		zapLogger.Fatal("Invalid worker configuration", zap.Error(err))
+	}
+
+	// Connections to the message broker are opened by the configured driver, RabbitMQ or in-process
+	dial, err := messaging.NewDialer(cfg.MessagingDriver, cfg.RabbitMQURL)
+	if err != nil {
+		zapLogger.Fatal("Failed to initialize messaging driver", zap.Error(err))
+	}
+
+	// Connect to the message broker for the outbox relay
+	retryPolicy := domainmessaging.RetryPolicy{MaxAttempts: cfg.ConsumerMaxAttempts, Delay: cfg.ConsumerRetryDelay}
+	broker, err := dial(retryPolicy)
+	if err != nil {
+		zapLogger.Fatal("Failed to connect to the message broker for worker", zap.Error(err))
+	}
+	defer broker.Close()
+	if err := broker.DeclareTopology(domainmessaging.ProductNotificationsSubscription.Topology()); err != nil {
+		zapLogger.Fatal("Failed to declare RabbitMQ queue for worker", zap.Error(err))
+	}
+	zapLogger.Info("Message broker connection for worker established", zap.String("driver", cfg.MessagingDriver))
+
+	// Notification emails are rendered from templates, which a directory can override
+	emailRenderer, err := mail.NewTemplateRenderer(cfg.EmailTemplateDir, cfg.DefaultTimeZone, zapLogger)
+	if err != nil {
+		zapLogger.Fatal("Failed to load email templates", zap.Error(err))
+	}
+
+	// Dependency Injection
+	mailer := mail.NewSMTPMailer(cfg, zapLogger)
+	userRepo := repository.NewUserRepository(db, zapLogger)
+	outboxRepo := repository.NewOutboxRepository(db, zapLogger)
+	notificationRepo := repository.NewNotificationRepository(db, zapLogger)
+	inboxRepo := repository.NewInboxRepository(db, zapLogger)
+	processedMessageRepo := repository.NewProcessedMessageRepository(db, zapLogger)
+	emailDeliveryRepo := repository.NewEmailDeliveryRepository(db, zapLogger)
+	// Notification emails are sent through the delivery log, which records every attempt
+	emailDeliveryUsecase := usecase.NewEmailDeliveryUsecase(emailDeliveryRepo, mailer, cfg, zapLogger)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo, userRepo, emailDeliveryUsecase, emailRenderer, cfg, zapLogger)
+	inboxUsecase := usecase.NewInboxUsecase(inboxRepo, userRepo, zapLogger)
+	deduplicator := usecase.NewMessageDeduplicator(processedMessageRepo, cfg, zapLogger)
+	dispatcher := consumer.NewDispatcher(cfg, notificationUsecase, inboxUsecase, emailDeliveryUsecase, deduplicator, zapLogger)
+	outboxRelay := usecase.NewOutboxRelay(outboxRepo, broker, cfg, zapLogger)
+
+	worker, err := consumer.NewWorker(cfg, dial, outboxRelay, notificationUsecase, dispatcher, deduplicator, emailDeliveryUsecase, zapLogger)
+	if err != nil {
+		zapLogger.Fatal("Failed to initialize worker", zap.Error(err))
+	}
+	defer worker.Close()
+
+	// Run the worker and its health check until shutdown
+	group := server.NewGroup(ctx, zapLogger)
+	worker.Start(group)
+	health := handler.NewHealthHandler(append([]domainmessaging.ConnectionMonitor{broker}, worker.Monitors()...), zapLogger)
+	group.Go("health server", func(ctx context.Context) error {
+		return server.StartHealth(ctx, cfg.WorkerHealthAddr, health, zapLogger)
+	})
+
+	// Controlled Shutdown Handling
+	<-group.Context().Done()
+	zapLogger.Info("Shutting down worker...")
+	if err := group.Wait(cfg.ShutdownTimeout); err != nil {
+		zapLogger.Error("Worker stopped with error", zap.Error(err))
+	}
+}
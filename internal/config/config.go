@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -25,6 +28,126 @@ type Configs struct {
 	SMTPHost     string
 	SMTPPort     string
 	JWTSecret    string
+
+	// AccessTokenTTL is the lifetime of the JWT access tokens issued on login and refresh
+	AccessTokenTTL time.Duration
+	// JWTSigningKeyFile is a PEM private key (RSA or Ed25519) signing access tokens with RS256 or EdDSA
+	// When empty, access tokens are signed with HS256 using JWTSecret
+	JWTSigningKeyFile string
+	// JWTRetiredKeyFiles are PEM keys of previous signing keys, still accepted and published in the JWKS
+	// until the tokens they signed have expired
+	JWTRetiredKeyFiles []string
+	// RefreshTokenTTL is the lifetime of the opaque refresh tokens used to obtain new access tokens
+	RefreshTokenTTL time.Duration
+	// AdminEmails lists the users promoted to the admin role at startup
+	AdminEmails []string
+	// PasswordResetTTL is how long a password reset token stays valid
+	PasswordResetTTL time.Duration
+	// PasswordResetURL is the page that receives the reset token as a "token" query parameter
+	// When empty, the email only carries the token to be sent to POST /api/password/reset
+	PasswordResetURL string
+	// EmailVerificationTTL is how long the link sent after registration stays valid
+	EmailVerificationTTL time.Duration
+	// EmailVerificationURL is the page that receives the verification token as a "token" query parameter
+	// It defaults to the API's own GET /api/email/verify endpoint when API_URL is set
+	EmailVerificationURL string
+	// EmailVerificationResendInterval is the minimum time between two verification emails to the same user
+	EmailVerificationResendInterval time.Duration
+	// AllowUnverifiedLogin lets users log in before verifying their email; it cannot be enabled in production
+	AllowUnverifiedLogin bool
+	// LoginMaxFailures is the number of consecutive failed logins that locks an account
+	LoginMaxFailures int
+	// LoginIPMaxFailures is the number of failed logins from one IP that blocks it, across all accounts
+	LoginIPMaxFailures int
+	// LoginFailureWindow is how long failures are remembered after the last one
+	LoginFailureWindow time.Duration
+	// LoginLockoutDuration is how long a locked account or IP stays locked unless an admin unlocks it
+	LoginLockoutDuration time.Duration
+	// MFAChallengeTTL is how long the challenge returned by the first login step can be completed with a code
+	MFAChallengeTTL time.Duration
+	// MFAIssuer is the account issuer shown by authenticator apps
+	MFAIssuer string
+	// LocalLoginEnabled keeps the email and password login and registration routes available
+	LocalLoginEnabled bool
+	// OpenRegistration lets anyone create an account through POST /api/register; otherwise accounts are
+	// only created from invitations. It defaults to false in production
+	OpenRegistration bool
+	// InvitationTTL is how long an invitation stays valid when no expiry is given
+	InvitationTTL time.Duration
+	// InvitationURL is the page that receives the invitation token as a "token" query parameter
+	// When empty, the email only carries the token to be sent to POST /api/invitations/{token}/accept
+	InvitationURL string
+	// OutboxPollInterval is how often the relay looks for product events waiting in the outbox
+	OutboxPollInterval time.Duration
+	// OutboxBatchSize is the number of events the relay publishes per batch
+	OutboxBatchSize int
+	// OutboxMaxBackoff caps the delay between two attempts to publish the same event
+	OutboxMaxBackoff time.Duration
+	// OutboxRetention is how long published events are kept in the outbox before being removed
+	OutboxRetention time.Duration
+	// CloudEventsMode is how the relay encodes product events: "structured" (the whole CloudEvent as
+	// JSON in the body) or "binary" (attributes in AMQP headers, data in the body)
+	CloudEventsMode string
+	// ConsumerMaxAttempts is the number of deliveries after which a failing message is dead-lettered
+	ConsumerMaxAttempts int
+	// ConsumerRetryDelay is how long a failed message waits in the retry queue before being delivered again
+	ConsumerRetryDelay time.Duration
+	// ProcessedMessageRetention is how long consumers remember the messages they processed, to skip redeliveries
+	// It should exceed the time a message can spend being retried or waiting in a queue
+	ProcessedMessageRetention time.Duration
+	// EmailLogRedactAfter is how long the recipients and content of notification emails are kept in the
+	// delivery log; afterwards only the kind, subject, events and outcome remain
+	EmailLogRedactAfter time.Duration
+	// EmailLogRetention is how long deliveries are kept in the email delivery log before being removed
+	EmailLogRetention time.Duration
+	// MessagingDriver selects the message broker: "rabbitmq", or "memory" for an in-process broker that
+	// needs no RabbitMQ, for local development and integration tests
+	MessagingDriver string
+	// EmbeddedWorker runs the consumers, the outbox relay and the digests inside the API process
+	// Turn it off when cmd/worker is deployed, so that scaling the API does not multiply them
+	EmbeddedWorker bool
+	// WorkerConcurrency is the number of messages, or batches of product events, each consumer handles at once
+	WorkerConcurrency int
+	// WorkerPrefetch is the number of messages RabbitMQ delivers to each consumer ahead of their acknowledgement
+	WorkerPrefetch int
+	// WorkerHealthAddr is the address of the standalone worker's health check endpoint
+	WorkerHealthAddr string
+	// ShutdownTimeout bounds the wait for requests in flight and running jobs on SIGINT or SIGTERM
+	ShutdownTimeout time.Duration
+	// DailyDigestHour is the hour of the day at which daily notification digests are sent, in each user's
+	// time zone or DefaultTimeZone
+	DailyDigestHour int
+	// UnsubscribeURL is the page that receives the unsubscribe token as a "token" query parameter
+	// It defaults to the API's own GET /api/notifications/unsubscribe endpoint when API_URL is set, and is
+	// required, since every notification email carries an unsubscribe link
+	UnsubscribeURL string
+	// EmailTemplateDir holds templates and locale bundles overriding the built-in notification emails
+	EmailTemplateDir string
+	// DefaultTimeZone is the IANA time zone of the dates in emails to users who did not choose one
+	DefaultTimeZone string
+	// NotificationRoutes maps each product event, or "*" for the others, to the channels receiving it
+	// (email, inbox, slack, teams), e.g. "product_deleted=email|slack,*=email|inbox"
+	NotificationRoutes map[string][]string
+	// SlackWebhookURL and TeamsWebhookURL are incoming webhooks of the slack and teams channels
+	SlackWebhookURL string
+	TeamsWebhookURL string
+	// OIDCIssuerURL is the corporate identity provider used for single sign-on; OIDC is disabled when empty
+	OIDCIssuerURL string
+	// OIDCClientID and OIDCClientSecret identify this application at the identity provider
+	OIDCClientID     string
+	OIDCClientSecret string
+	// OIDCRedirectURL is this API's callback endpoint registered at the identity provider
+	OIDCRedirectURL string
+	// OIDCScopes are requested in addition to "openid"
+	OIDCScopes []string
+	// OIDCGroupsClaim is the ID token claim listing the user's IdP groups
+	OIDCGroupsClaim string
+	// OIDCRoleMapping maps IdP groups to local roles, e.g. "erp-admins=admin,catalog=editor"
+	OIDCRoleMapping map[string]string
+	// OIDCDefaultRole is given to provisioned users whose groups match no mapping
+	OIDCDefaultRole string
+	// OIDCAutoProvision creates local accounts for unknown IdP users; otherwise an existing account is required
+	OIDCAutoProvision bool
 }
 
 // New loads the environment variables from a .env file,
@@ -48,7 +171,8 @@ func New() (*Configs, error) {
 	cfg.DbPassword, errorList = getRequiredEnv("DB_PASSWORD", errorList)
 	cfg.AppEnv, errorList = getRequiredEnv("APP_ENV", errorList)
 	cfg.JWTKey, errorList = getRequiredEnv("JWT_SECRET_KEY", errorList)
-	cfg.RabbitMQURL, errorList = getRequiredEnv("RABBITMQ_URL", errorList)
+	// RABBITMQ_URL is only required by the rabbitmq messaging driver, checked below
+	cfg.RabbitMQURL = os.Getenv("RABBITMQ_URL")
 	cfg.SMTPFrom, errorList = getRequiredEnv("SMTP_FROM", errorList)
 	cfg.SMTPUser, errorList = getRequiredEnv("SMTP_USER", errorList)
 	cfg.SMTPPassword, errorList = getRequiredEnv("SMTP_PASSWORD", errorList)
@@ -56,6 +180,139 @@ func New() (*Configs, error) {
 	cfg.SMTPPort, errorList = getRequiredEnv("SMTP_PORT", errorList)
 	cfg.JWTSecret, errorList = getRequiredEnv("JWT_SECRET_KEY", errorList)
 
+	// Optional variables fall back to sensible defaults when not set
+	cfg.AccessTokenTTL, errorList = getDurationEnv("ACCESS_TOKEN_TTL", 15*time.Minute, errorList)
+	cfg.RefreshTokenTTL, errorList = getDurationEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour, errorList)
+	cfg.JWTSigningKeyFile = os.Getenv("JWT_SIGNING_KEY_FILE")
+	cfg.JWTRetiredKeyFiles = getListEnv("JWT_RETIRED_KEY_FILES")
+	if cfg.JWTSigningKeyFile == "" && len(cfg.JWTRetiredKeyFiles) > 0 {
+		errorList = append(errorList, errors.New("JWT_RETIRED_KEY_FILES requires JWT_SIGNING_KEY_FILE"))
+	}
+	cfg.AdminEmails = getListEnv("ADMIN_EMAILS")
+	cfg.PasswordResetTTL, errorList = getDurationEnv("PASSWORD_RESET_TTL", time.Hour, errorList)
+	cfg.PasswordResetURL = os.Getenv("PASSWORD_RESET_URL")
+	cfg.EmailVerificationTTL, errorList = getDurationEnv("EMAIL_VERIFICATION_TTL", 24*time.Hour, errorList)
+	cfg.EmailVerificationURL = os.Getenv("EMAIL_VERIFICATION_URL")
+	if cfg.EmailVerificationURL == "" && os.Getenv("API_URL") != "" {
+		cfg.EmailVerificationURL = strings.TrimSuffix(os.Getenv("API_URL"), "/") + "/api/email/verify"
+	}
+	cfg.EmailVerificationResendInterval, errorList = getDurationEnv("EMAIL_VERIFICATION_RESEND_INTERVAL", 2*time.Minute, errorList)
+	cfg.AllowUnverifiedLogin, errorList = getBoolEnv("ALLOW_UNVERIFIED_LOGIN", false, errorList)
+	cfg.LoginMaxFailures, errorList = getIntEnv("LOGIN_MAX_FAILURES", 5, errorList)
+	cfg.LoginIPMaxFailures, errorList = getIntEnv("LOGIN_IP_MAX_FAILURES", 50, errorList)
+	cfg.LoginFailureWindow, errorList = getDurationEnv("LOGIN_FAILURE_WINDOW", 15*time.Minute, errorList)
+	cfg.LoginLockoutDuration, errorList = getDurationEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute, errorList)
+	cfg.MFAChallengeTTL, errorList = getDurationEnv("MFA_CHALLENGE_TTL", 5*time.Minute, errorList)
+	cfg.MFAIssuer = os.Getenv("MFA_ISSUER")
+	if cfg.MFAIssuer == "" {
+		cfg.MFAIssuer = "Products CRUD"
+	}
+	cfg.LocalLoginEnabled, errorList = getBoolEnv("LOCAL_LOGIN_ENABLED", true, errorList)
+	cfg.OpenRegistration, errorList = getBoolEnv("OPEN_REGISTRATION", cfg.AppEnv != "production", errorList)
+	cfg.InvitationTTL, errorList = getDurationEnv("INVITATION_TTL", 7*24*time.Hour, errorList)
+	cfg.InvitationURL = os.Getenv("INVITATION_URL")
+	cfg.OutboxPollInterval, errorList = getDurationEnv("OUTBOX_POLL_INTERVAL", time.Second, errorList)
+	cfg.OutboxBatchSize, errorList = getIntEnv("OUTBOX_BATCH_SIZE", 100, errorList)
+	cfg.OutboxMaxBackoff, errorList = getDurationEnv("OUTBOX_MAX_BACKOFF", 5*time.Minute, errorList)
+	cfg.OutboxRetention, errorList = getDurationEnv("OUTBOX_RETENTION", 7*24*time.Hour, errorList)
+	cfg.CloudEventsMode = os.Getenv("CLOUDEVENTS_MODE")
+	if cfg.CloudEventsMode == "" {
+		cfg.CloudEventsMode = "structured"
+	} else if cfg.CloudEventsMode != "structured" && cfg.CloudEventsMode != "binary" {
+		errorList = append(errorList, fmt.Errorf("environment variable \"CLOUDEVENTS_MODE\" must be structured or binary, got %q", cfg.CloudEventsMode))
+	}
+	cfg.ConsumerMaxAttempts, errorList = getIntEnv("CONSUMER_MAX_ATTEMPTS", 5, errorList)
+	cfg.ConsumerRetryDelay, errorList = getDurationEnv("CONSUMER_RETRY_DELAY", 30*time.Second, errorList)
+	cfg.ProcessedMessageRetention, errorList = getDurationEnv("PROCESSED_MESSAGE_RETENTION", 7*24*time.Hour, errorList)
+	cfg.EmailLogRedactAfter, errorList = getDurationEnv("EMAIL_LOG_REDACT_AFTER", 30*24*time.Hour, errorList)
+	cfg.EmailLogRetention, errorList = getDurationEnv("EMAIL_LOG_RETENTION", 90*24*time.Hour, errorList)
+	cfg.EmbeddedWorker, errorList = getBoolEnv("EMBEDDED_WORKER", true, errorList)
+	cfg.WorkerConcurrency, errorList = getIntEnv("WORKER_CONCURRENCY", 1, errorList)
+	cfg.WorkerPrefetch, errorList = getIntEnv("WORKER_PREFETCH", 100, errorList)
+	cfg.WorkerHealthAddr = os.Getenv("WORKER_HEALTH_ADDR")
+	if cfg.WorkerHealthAddr == "" {
+		cfg.WorkerHealthAddr = ":8989"
+	}
+	cfg.ShutdownTimeout, errorList = getDurationEnv("SHUTDOWN_TIMEOUT", 30*time.Second, errorList)
+	cfg.MessagingDriver = os.Getenv("MESSAGING_DRIVER")
+	if cfg.MessagingDriver == "" {
+		cfg.MessagingDriver = "rabbitmq"
+	}
+	switch cfg.MessagingDriver {
+	case "rabbitmq":
+		if cfg.RabbitMQURL == "" {
+			errorList = append(errorList, errors.New("RABBITMQ_URL is required when MESSAGING_DRIVER is rabbitmq"))
+		}
+	case "memory":
+		// The in-process broker only reaches consumers running in the same process
+		if !cfg.EmbeddedWorker {
+			errorList = append(errorList, errors.New("MESSAGING_DRIVER memory requires EMBEDDED_WORKER"))
+		}
+	default:
+		errorList = append(errorList, fmt.Errorf("environment variable \"MESSAGING_DRIVER\" must be rabbitmq or memory, got %q", cfg.MessagingDriver))
+	}
+	cfg.DailyDigestHour = 8
+	if value := os.Getenv("DAILY_DIGEST_HOUR"); value != "" {
+		hour, err := strconv.Atoi(value)
+		if err != nil || hour < 0 || hour > 23 {
+			errorList = append(errorList, fmt.Errorf("environment variable \"DAILY_DIGEST_HOUR\" must be an hour between 0 and 23, got %q", value))
+		} else {
+			cfg.DailyDigestHour = hour
+		}
+	}
+	cfg.UnsubscribeURL = os.Getenv("UNSUBSCRIBE_URL")
+	if cfg.UnsubscribeURL == "" && os.Getenv("API_URL") != "" {
+		cfg.UnsubscribeURL = strings.TrimSuffix(os.Getenv("API_URL"), "/") + "/api/notifications/unsubscribe"
+	}
+	if cfg.UnsubscribeURL == "" {
+		errorList = append(errorList, errors.New("UNSUBSCRIBE_URL or API_URL is required for the unsubscribe links of notification emails"))
+	}
+	cfg.EmailTemplateDir = os.Getenv("EMAIL_TEMPLATE_DIR")
+	cfg.DefaultTimeZone = os.Getenv("DEFAULT_TIME_ZONE")
+	if cfg.DefaultTimeZone == "" {
+		cfg.DefaultTimeZone = "America/Sao_Paulo"
+	}
+	cfg.NotificationRoutes, errorList = getNotificationRoutesEnv("NOTIFICATION_ROUTES", errorList)
+	cfg.SlackWebhookURL = os.Getenv("SLACK_WEBHOOK_URL")
+	cfg.TeamsWebhookURL = os.Getenv("TEAMS_WEBHOOK_URL")
+	for _, channels := range cfg.NotificationRoutes {
+		for _, channel := range channels {
+			if channel == "slack" && cfg.SlackWebhookURL == "" || channel == "teams" && cfg.TeamsWebhookURL == "" {
+				errorList = append(errorList, fmt.Errorf("NOTIFICATION_ROUTES uses the %s channel, which requires %s_WEBHOOK_URL", channel, strings.ToUpper(channel)))
+			}
+		}
+	}
+	cfg.OIDCIssuerURL = os.Getenv("OIDC_ISSUER_URL")
+	cfg.OIDCClientID = os.Getenv("OIDC_CLIENT_ID")
+	cfg.OIDCClientSecret = os.Getenv("OIDC_CLIENT_SECRET")
+	cfg.OIDCRedirectURL = os.Getenv("OIDC_REDIRECT_URL")
+	scopes := getListEnv("OIDC_SCOPES")
+	if len(scopes) == 0 {
+		scopes = []string{"email", "profile"}
+	}
+	cfg.OIDCScopes = append([]string{"openid"}, scopes...)
+	cfg.OIDCGroupsClaim = os.Getenv("OIDC_GROUPS_CLAIM")
+	if cfg.OIDCGroupsClaim == "" {
+		cfg.OIDCGroupsClaim = "groups"
+	}
+	cfg.OIDCRoleMapping, errorList = getRoleMappingEnv("OIDC_ROLE_MAPPING", errorList)
+	cfg.OIDCDefaultRole = os.Getenv("OIDC_DEFAULT_ROLE")
+	if cfg.OIDCDefaultRole == "" {
+		cfg.OIDCDefaultRole = "viewer"
+	} else if !isRole(cfg.OIDCDefaultRole) {
+		errorList = append(errorList, fmt.Errorf("environment variable \"OIDC_DEFAULT_ROLE\" must be admin, editor or viewer, got %q", cfg.OIDCDefaultRole))
+	}
+	cfg.OIDCAutoProvision, errorList = getBoolEnv("OIDC_AUTO_PROVISION", true, errorList)
+	if cfg.OIDCIssuerURL != "" && (cfg.OIDCClientID == "" || cfg.OIDCRedirectURL == "") {
+		errorList = append(errorList, errors.New("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required when OIDC_ISSUER_URL is set"))
+	}
+	if !cfg.LocalLoginEnabled && cfg.OIDCIssuerURL == "" {
+		errorList = append(errorList, errors.New("LOCAL_LOGIN_ENABLED=false requires OIDC_ISSUER_URL"))
+	}
+	if cfg.AllowUnverifiedLogin && cfg.AppEnv == "production" {
+		errorList = append(errorList, errors.New("ALLOW_UNVERIFIED_LOGIN cannot be enabled in production"))
+	}
+
 	if len(errorList) > 0 {
 		return nil, errors.Join(errorList...)
 	}
@@ -72,3 +329,125 @@ func getRequiredEnv(key string, errs []error) (string, []error) {
 	}
 	return value, errs
 }
+
+// getDurationEnv retrieves an optional duration environment variable (e.g. "15m", "720h")
+// If the variable is not set, the default value is returned; if it cannot be parsed, an error is appended
+func getDurationEnv(key string, defaultValue time.Duration, errs []error) (time.Duration, []error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, errs
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("environment variable \"%s\" is not a valid duration: %w", key, err))
+		return defaultValue, errs
+	}
+	return duration, errs
+}
+
+// getIntEnv retrieves an optional positive integer environment variable
+// If the variable is not set, the default value is returned; if it is not a positive integer, an error is appended
+func getIntEnv(key string, defaultValue int, errs []error) (int, []error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, errs
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		errs = append(errs, fmt.Errorf("environment variable \"%s\" must be a positive integer, got %q", key, value))
+		return defaultValue, errs
+	}
+	return parsed, errs
+}
+
+// getBoolEnv retrieves an optional boolean environment variable (e.g. "true", "false", "1", "0")
+// If the variable is not set, the default value is returned; if it cannot be parsed, an error is appended
+func getBoolEnv(key string, defaultValue bool, errs []error) (bool, []error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, errs
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("environment variable \"%s\" is not a valid boolean: %w", key, err))
+		return defaultValue, errs
+	}
+	return parsed, errs
+}
+
+// getRoleMappingEnv retrieves an optional comma-separated list of group=role pairs as a map
+// If a pair is malformed or names an unknown role, an error is appended
+func getRoleMappingEnv(key string, errs []error) (map[string]string, []error) {
+	mapping := make(map[string]string)
+	for _, pair := range getListEnv(key) {
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" || !isRole(role) {
+			errs = append(errs, fmt.Errorf("environment variable \"%s\" has an invalid group=role pair %q", key, pair))
+			continue
+		}
+		mapping[group] = role
+	}
+	return mapping, errs
+}
+
+// getNotificationRoutesEnv retrieves optional comma-separated event=channel|channel rules as a map
+// When the variable is not set, every event goes to the email and inbox channels; if a rule names an
+// unknown event or channel, an error is appended
+func getNotificationRoutesEnv(key string, errs []error) (map[string][]string, []error) {
+	rules := getListEnv(key)
+	if len(rules) == 0 {
+		return map[string][]string{"*": {"email", "inbox"}}, errs
+	}
+
+	routes := make(map[string][]string)
+	for _, rule := range rules {
+		event, channelList, ok := strings.Cut(rule, "=")
+		event = strings.TrimSpace(event)
+		if !ok || !isNotificationEvent(event) {
+			errs = append(errs, fmt.Errorf("environment variable \"%s\" has an invalid event=channels rule %q", key, rule))
+			continue
+		}
+		channels := []string{}
+		for _, channel := range strings.Split(channelList, "|") {
+			channel = strings.TrimSpace(channel)
+			if channel == "" {
+				continue
+			}
+			if !isNotificationChannel(channel) {
+				errs = append(errs, fmt.Errorf("environment variable \"%s\" names an unknown channel %q", key, channel))
+				continue
+			}
+			channels = append(channels, channel)
+		}
+		routes[event] = channels
+	}
+	return routes, errs
+}
+
+// isNotificationEvent reports whether value is a product event, or "*" for any other event
+func isNotificationEvent(value string) bool {
+	return value == "*" || value == "product_created" || value == "product_updated" || value == "product_deleted"
+}
+
+// isNotificationChannel reports whether value is one of the notification channels
+func isNotificationChannel(value string) bool {
+	return value == "email" || value == "inbox" || value == "slack" || value == "teams"
+}
+
+// isRole reports whether value is one of the user roles
+func isRole(value string) bool {
+	return value == "admin" || value == "editor" || value == "viewer"
+}
+
+// getListEnv retrieves an optional comma-separated environment variable as a slice
+// Blank entries are dropped, and an unset variable yields an empty slice
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
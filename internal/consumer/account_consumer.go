@@ -0,0 +1,198 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"sync"
+	"time"
+
+	domainmessaging "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	"github.com/Amandasilvbr/products-crud/internal/infrastructure/mail"
+
+	"github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// AccountEvent defines the structure for account security events received from the message queue
+type AccountEvent struct {
+	Event       string    `json:"event"`
+	UserID      uint      `json:"user_id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	IP          string    `json:"ip"`
+	LockedUntil time.Time `json:"locked_until"`
+	// MessageID is the ID of the message carrying the event, recorded with the alert in the delivery log
+	MessageID string `json:"-"`
+}
+
+// AccountConsumer processes account security events and emails the affected users right away
+// Unlike product notifications, security alerts are never batched
+type AccountConsumer struct {
+	logger  *zap.Logger
+	broker  domainmessaging.Broker
+	mailer  domainmessaging.Mailer
+	dedup   usecase.MessageDeduplicatorInterface
+	options domainmessaging.ConsumerOptions
+}
+
+// NewAccountConsumer creates and initializes a consumer for the account events queue
+// Alerts that cannot be sent are retried according to the retry policy, then dead-lettered
+// Alerts already sent, according to the deduplicator, are not sent again when redelivered
+func NewAccountConsumer(logger *zap.Logger, dial domainmessaging.Dialer, mailer domainmessaging.Mailer, dedup usecase.MessageDeduplicatorInterface, retry domainmessaging.RetryPolicy, options domainmessaging.ConsumerOptions) (*AccountConsumer, error) {
+
+	broker, err := dial(retry)
+	if err != nil {
+		logger.Error("Failed to connect to the message broker", zap.Error(err))
+		return nil, fmt.Errorf("failed to connect to the message broker: %w", err)
+	}
+
+	if err := broker.DeclareQueue(domainmessaging.AccountEventsQueue); err != nil {
+		logger.Error("Failed to declare queue", zap.String("queue", domainmessaging.AccountEventsQueue), zap.Error(err))
+		broker.Close()
+		return nil, fmt.Errorf("failed to declare queue %s: %w", domainmessaging.AccountEventsQueue, err)
+	}
+
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+	if options.Prefetch > 0 {
+		if err := broker.SetPrefetch(options.Prefetch); err != nil {
+			logger.Error("Failed to set prefetch", zap.Int("prefetch", options.Prefetch), zap.Error(err))
+			broker.Close()
+			return nil, fmt.Errorf("failed to set prefetch: %w", err)
+		}
+	}
+
+	return &AccountConsumer{
+		logger:  logger,
+		broker:  broker,
+		mailer:  mailer,
+		dedup:   dedup,
+		options: options,
+	}, nil
+}
+
+// Start consumes account events until the context is cancelled or the channel closes, handling as many
+// events in parallel as the options' concurrency
+func (c *AccountConsumer) Start(ctx context.Context) error {
+	msgs, err := c.broker.Consume(domainmessaging.AccountEventsQueue, "", false, false, false, false, nil)
+	if err != nil {
+		c.logger.Error("Failed to consume messages", zap.String("queue", domainmessaging.AccountEventsQueue), zap.Error(err))
+		return fmt.Errorf("failed to consume messages: %w", err)
+	}
+
+	c.logger.Info("Account events consumer started", zap.Int("concurrency", c.options.Concurrency))
+	errs := make(chan error, c.options.Concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < c.options.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.run(ctx, msgs)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run handles account events one at a time until the context is cancelled or the channel closes
+func (c *AccountConsumer) run(ctx context.Context, msgs <-chan amqp091.Delivery) error {
+	for {
+		select {
+		case <-ctx.Done():
+			c.logger.Info("Account events consumer interrupted due to context cancellation")
+			return ctx.Err()
+
+		case msg, ok := <-msgs:
+			if !ok {
+				c.logger.Error("Account events channel closed")
+				return fmt.Errorf("message channel closed")
+			}
+
+			var event AccountEvent
+			if err := json.Unmarshal(msg.Body, &event); err != nil {
+				// A malformed message will never parse, so it goes straight to the dead-letter queue
+				c.logger.Error("Failed to deserialize account event", zap.String("body", string(msg.Body)), zap.Error(err))
+				c.broker.Reject(ctx, domainmessaging.AccountEventsQueue, msg, err, true)
+				continue
+			}
+			event.MessageID = msg.MessageId
+
+			processed, err := c.dedup.Processed(ctx, domainmessaging.AccountEventsQueue, []string{msg.MessageId})
+			if err != nil {
+				c.logger.Error("Failed to look up processed messages", zap.String("message_id", msg.MessageId), zap.Error(err))
+				c.broker.Reject(ctx, domainmessaging.AccountEventsQueue, msg, err, false)
+				continue
+			}
+			if processed[msg.MessageId] {
+				c.logger.Info("Skipping account event already processed", zap.String("message_id", msg.MessageId), zap.String("event", event.Event))
+				msg.Ack(false)
+				continue
+			}
+
+			if err := c.handle(ctx, event); err != nil {
+				c.logger.Error("Failed to handle account event", zap.String("event", event.Event), zap.Uint("user_id", event.UserID), zap.Error(err))
+				c.broker.Reject(ctx, domainmessaging.AccountEventsQueue, msg, err, mail.IsPermanent(err))
+				continue
+			}
+			// The alert is acknowledged only once recorded, so that a redelivery does not send it twice
+			if err := c.dedup.Record(context.WithoutCancel(ctx), domainmessaging.AccountEventsQueue, []string{msg.MessageId}); err != nil {
+				c.logger.Error("Failed to record processed message", zap.String("message_id", msg.MessageId), zap.Error(err))
+				c.broker.Reject(ctx, domainmessaging.AccountEventsQueue, msg, err, false)
+				continue
+			}
+			msg.Ack(false)
+		}
+	}
+}
+
+// handle dispatches an account event by type; unknown types are acknowledged and ignored
+func (c *AccountConsumer) handle(ctx context.Context, event AccountEvent) error {
+	switch event.Event {
+	case "account_locked":
+		return c.sendLockoutEmail(ctx, event)
+	default:
+		c.logger.Warn("Ignoring unknown account event", zap.String("event", event.Event))
+		return nil
+	}
+}
+
+// sendLockoutEmail warns the account owner that repeated failed logins locked their account
+func (c *AccountConsumer) sendLockoutEmail(ctx context.Context, event AccountEvent) error {
+	lockedUntil := event.LockedUntil.Local().Format("02/01/2006 15:04:05")
+	textBody := fmt.Sprintf("Olá, %s,\n\nSua conta foi bloqueada temporariamente após várias tentativas de login malsucedidas (IP de origem: %s).\n\nO bloqueio termina em %s. Se não foi você, recomendamos redefinir sua senha assim que possível.\n\nAtenciosamente,\nEquipe de Produtos",
+		event.Name, event.IP, lockedUntil)
+	htmlBody := fmt.Sprintf("<p>Olá, %s,</p><p>Sua conta foi bloqueada temporariamente após várias tentativas de login malsucedidas (IP de origem: %s).</p><p>O bloqueio termina em <strong>%s</strong>. Se não foi você, recomendamos redefinir sua senha assim que possível.</p><p>Atenciosamente,<br>Equipe de Produtos</p>",
+		html.EscapeString(event.Name), html.EscapeString(event.IP), lockedUntil)
+
+	return c.mailer.Send(ctx, &domainmessaging.Email{
+		To:       []string{event.Email},
+		Subject:  "Alerta de segurança: conta bloqueada temporariamente",
+		Text:     textBody,
+		HTML:     htmlBody,
+		Kind:     domainmessaging.EmailKindAccountLocked,
+		EventIDs: []string{event.MessageID},
+	})
+}
+
+// State reports the state of the consumer's RabbitMQ connection for health checks
+func (c *AccountConsumer) State() string {
+	return c.broker.State()
+}
+
+// Close closes the connection to RabbitMQ
+func (c *AccountConsumer) Close() {
+	if c.broker != nil {
+		c.broker.Close()
+		c.logger.Info("Account events connection to RabbitMQ closed")
+	}
+}
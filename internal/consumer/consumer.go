@@ -0,0 +1,389 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	domainmessaging "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// ProductEvent defines the structure for product-related events received from the message queue
+type ProductEvent struct {
+	Event            string `json:"event"`
+	SKU              int    `json:"sku"`
+	Name             string `json:"name"`
+	ResponsibleEmail string `json:"responsible_email"`
+	OrgID            uint   `json:"org_id"`
+	OrgName          string `json:"org_name"`
+}
+
+// decodeProductEvent reads a product event published as a CloudEvent, in structured or binary mode,
+// or in the legacy format, which is still accepted while publishers migrate
+func decodeProductEvent(msg amqp091.Delivery) (ProductEvent, error) {
+	var event ProductEvent
+	cloudEvent, err := domainmessaging.DecodeCloudEvent(msg)
+	if err != nil {
+		return event, err
+	}
+	if cloudEvent == nil {
+		err := json.Unmarshal(msg.Body, &event)
+		return event, err
+	}
+
+	name, err := domainmessaging.ProductEventName(cloudEvent.Type)
+	if err != nil {
+		return event, err
+	}
+	var data domainmessaging.ProductEventData
+	if err := json.Unmarshal(cloudEvent.Data, &data); err != nil {
+		return event, fmt.Errorf("invalid data of %s event %s: %w", cloudEvent.Type, cloudEvent.ID, err)
+	}
+	return ProductEvent{
+		Event:            name,
+		SKU:              data.Product.SKU,
+		Name:             data.Product.Name,
+		ResponsibleEmail: data.ResponsibleEmail,
+		OrgID:            data.OrgID,
+		OrgName:          data.OrgName,
+	}, nil
+}
+
+// batchItem holds both the deserialized event and the original message,
+// which is necessary for acknowledging or rejecting the message after processing
+type batchItem struct {
+	event ProductEvent
+	msg   amqp091.Delivery
+}
+
+// Consumer represents a RabbitMQ consumer that processes product events
+type Consumer struct {
+	logger     *zap.Logger
+	broker     domainmessaging.Broker
+	queueName  string
+	dispatcher usecase.NotificationDispatcherInterface
+	dedup      usecase.MessageDeduplicatorInterface
+	options    domainmessaging.ConsumerOptions
+}
+
+// NewConsumer creates and initializes a new RabbitMQ consumer
+// Events that cannot be notified are retried according to the retry policy, then dead-lettered
+// Each event is delivered to the channels the dispatcher routes it to, by as many batches in parallel
+// as the options' concurrency
+// The consumer declares its own queue, bound to the subscription's routing keys on the topic exchange
+// Messages already processed, according to the deduplicator, are acknowledged without notifying anyone
+func NewConsumer(logger *zap.Logger, dial domainmessaging.Dialer, subscription domainmessaging.Subscription, retry domainmessaging.RetryPolicy, options domainmessaging.ConsumerOptions, dispatcher usecase.NotificationDispatcherInterface, dedup usecase.MessageDeduplicatorInterface) (*Consumer, error) {
+	queueName := subscription.Queue
+	if queueName == "" {
+		logger.Error("Queue name is missing")
+		return nil, fmt.Errorf("queue name is missing")
+	}
+
+	logger.Info("Initializing RabbitMQ consumer")
+
+	// Connect to the message broker
+	broker, err := dial(retry)
+	if err != nil {
+		logger.Error("Failed to connect to the message broker", zap.Error(err))
+		return nil, fmt.Errorf("failed to connect to the message broker: %w", err)
+	}
+	logger.Info("Connected to the message broker successfully")
+
+	// Declare the queue and bind it to the exchange
+	err = broker.DeclareTopology(subscription.Topology())
+	if err != nil {
+		logger.Error("Failed to declare queue", zap.String("queue", queueName), zap.String("exchange", subscription.Exchange), zap.Error(err))
+		broker.Close()
+		return nil, fmt.Errorf("failed to declare queue %s: %w", queueName, err)
+	}
+	logger.Info("Queue declared successfully", zap.String("queue", queueName), zap.String("exchange", subscription.Exchange), zap.Strings("routing_keys", subscription.RoutingKeys))
+
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+	if options.Prefetch > 0 {
+		if err := broker.SetPrefetch(options.Prefetch); err != nil {
+			logger.Error("Failed to set prefetch", zap.Int("prefetch", options.Prefetch), zap.Error(err))
+			broker.Close()
+			return nil, fmt.Errorf("failed to set prefetch: %w", err)
+		}
+	}
+
+	return &Consumer{
+		logger:     logger,
+		broker:     broker,
+		queueName:  queueName,
+		dispatcher: dispatcher,
+		dedup:      dedup,
+		options:    options,
+	}, nil
+}
+
+// Batches hold at most maxBatchSize messages and wait at most batchTimeout before being processed
+const (
+	maxBatchSize = 100
+	batchTimeout = 5 * time.Second
+)
+
+// Start begins the message consumption loops and returns once all of them have stopped
+// It processes messages in batches, notifying each recipient when a batch is full or a timeout occurs
+func (c *Consumer) Start(ctx context.Context) error {
+	c.logger.Info("Starting consumer for queue", zap.String("queue", c.queueName), zap.Int("concurrency", c.options.Concurrency))
+
+	msgs, err := c.broker.Consume(c.queueName, "", false, false, false, false, nil)
+	if err != nil {
+		c.logger.Error("Failed to consume messages", zap.String("queue", c.queueName), zap.Error(err))
+		return fmt.Errorf("failed to consume messages: %w", err)
+	}
+
+	c.logger.Info("Consumer started, waiting for messages...")
+
+	// The prefetched messages are shared by the loops, so a batch cannot grow beyond each loop's share
+	batchSize := maxBatchSize
+	if c.options.Prefetch > 0 && c.options.Prefetch/c.options.Concurrency < batchSize {
+		batchSize = max(1, c.options.Prefetch/c.options.Concurrency)
+	}
+
+	errs := make(chan error, c.options.Concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < c.options.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.run(ctx, msgs, batchSize)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run gathers messages into batches of up to batchSize and processes them until the context is cancelled
+// or the message channel closes
+func (c *Consumer) run(ctx context.Context, msgs <-chan amqp091.Delivery, batchSize int) error {
+	var batch []batchItem // Slice to accumulate messages
+	batchTimer := time.NewTimer(batchTimeout)
+	defer batchTimer.Stop()
+
+	// Main processing loop that listens for messages, context cancellation, or timer events
+	for {
+		select {
+		// Handle shutdown signal
+		case <-ctx.Done():
+			c.logger.Info("Consumer interrupted due to context cancellation")
+			// Process any pending messages in the batch before shutting down
+			if len(batch) > 0 {
+				c.flush(ctx, batch, true)
+			}
+			return ctx.Err()
+
+		// Handle incoming messages from the queue
+		case msg, ok := <-msgs:
+			if !ok {
+				c.logger.Error("Message channel closed")
+				// If the channel closes, process any pending batch
+				if len(batch) > 0 {
+					c.flush(ctx, batch, true)
+				}
+				return fmt.Errorf("message channel closed")
+			}
+
+			c.logger.Info("Message received from RabbitMQ", zap.String("body", string(msg.Body)))
+
+			// Deserialize the message, a CloudEvent or a legacy JSON body, into a ProductEvent struct
+			event, err := decodeProductEvent(msg)
+			if err != nil {
+				c.logger.Error("Failed to deserialize message", zap.String("body", string(msg.Body)), zap.Error(err))
+				// A malformed message will never parse, so it goes straight to the dead-letter queue
+				c.broker.Reject(ctx, c.queueName, msg, err, true)
+				continue
+			}
+
+			c.logger.Info("Processing event",
+				zap.String("event", event.Event),
+				zap.Int("sku", event.SKU),
+				zap.Uint("org_id", event.OrgID),
+				zap.String("responsible_email", event.ResponsibleEmail))
+
+			// Add the successfully processed event to the current batch
+			batch = append(batch, batchItem{event: event, msg: msg})
+
+			// If the batch reaches its maximum size, process it immediately
+			if len(batch) >= batchSize {
+				c.flush(ctx, batch, false)
+				batch = nil
+				batchTimer.Reset(batchTimeout)
+			}
+
+		// Handle the batch timeout
+		case <-batchTimer.C:
+			// If there are any messages in the batch when the timer fires, process them
+			if len(batch) > 0 {
+				c.flush(ctx, batch, false)
+				batch = nil
+			}
+			// Restart the timer
+			batchTimer.Reset(batchTimeout)
+		}
+	}
+}
+
+// recipientBatch holds the messages of a batch addressed to the same person
+type recipientBatch struct {
+	email string
+	items []batchItem
+}
+
+// groupByRecipient partitions a batch by the responsible email of its events, keeping the order
+// in which recipients and their events were received; addresses are compared case-insensitively
+func groupByRecipient(batch []batchItem) []recipientBatch {
+	var groups []recipientBatch
+	index := make(map[string]int)
+	for _, item := range batch {
+		key := strings.ToLower(strings.TrimSpace(item.event.ResponsibleEmail))
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, recipientBatch{email: strings.TrimSpace(item.event.ResponsibleEmail)})
+		}
+		groups[i].items = append(groups[i].items, item)
+	}
+	return groups
+}
+
+// flush delivers the events of each recipient to the notification channels chosen by the routing rules
+// Each message is settled according to its own recipient: a failed delivery only affects that recipient's
+// messages, which are retried later, or dead-lettered if every failed channel failed permanently. While
+// shutting down they are requeued as they are, since nothing can be republished
+// Delivered messages are acknowledged only once recorded as processed, so that a redelivery is skipped; a
+// redelivery after a partial failure only reaches the channels that failed, since the dispatcher records the others
+func (c *Consumer) flush(ctx context.Context, batch []batchItem, shuttingDown bool) {
+	batch, err := c.skipProcessed(ctx, batch)
+	if err != nil {
+		c.settleFailed(ctx, batch, err, shuttingDown)
+		return
+	}
+
+	for _, group := range groupByRecipient(batch) {
+		if group.email == "" {
+			c.logger.Warn("No ResponsibleEmail provided for events", zap.Int("event_count", len(group.items)))
+			ackAll(group.items)
+			continue
+		}
+
+		notifications := make([]model.ProductNotification, len(group.items))
+		for i, item := range group.items {
+			notifications[i] = model.ProductNotification{
+				Event:   item.event.Event,
+				SKU:     item.event.SKU,
+				Name:    item.event.Name,
+				OrgID:   item.event.OrgID,
+				OrgName: item.event.OrgName,
+				At:      item.msg.Timestamp,
+				EventID: item.msg.MessageId,
+			}
+		}
+		if err := c.dispatcher.Dispatch(ctx, group.email, notifications); err != nil {
+			c.logger.Error("Failed to notify recipient", zap.String("to", group.email), zap.Int("event_count", len(group.items)), zap.Error(err))
+			c.settleFailed(ctx, group.items, err, shuttingDown)
+			continue
+		}
+		c.complete(ctx, group.items, shuttingDown)
+	}
+}
+
+// skipProcessed acknowledges the messages of a batch already processed, by an earlier delivery or earlier
+// in the same batch, and returns the others; messages published without an ID are never skipped
+// The deduplicator is not bound to the consumer's context, so a batch flushed while shutting down is still checked
+func (c *Consumer) skipProcessed(ctx context.Context, batch []batchItem) ([]batchItem, error) {
+	processed, err := c.dedup.Processed(context.WithoutCancel(ctx), c.queueName, messageIDs(batch))
+	if err != nil {
+		c.logger.Error("Failed to look up processed messages", zap.String("queue", c.queueName), zap.Int("event_count", len(batch)), zap.Error(err))
+		return batch, err
+	}
+
+	pending := make([]batchItem, 0, len(batch))
+	for _, item := range batch {
+		id := item.msg.MessageId
+		if id != "" && processed[id] {
+			c.logger.Info("Skipping message already processed", zap.String("message_id", id), zap.String("event", item.event.Event), zap.Int("sku", item.event.SKU))
+			item.msg.Ack(false)
+			continue
+		}
+		if id != "" {
+			processed[id] = true
+		}
+		pending = append(pending, item)
+	}
+	return pending, nil
+}
+
+// complete records the messages of a notified recipient as processed, then acknowledges them
+// When the record cannot be stored they are retried instead: a duplicate notification is preferred to a lost one
+func (c *Consumer) complete(ctx context.Context, items []batchItem, shuttingDown bool) {
+	if err := c.dedup.Record(context.WithoutCancel(ctx), c.queueName, messageIDs(items)); err != nil {
+		c.logger.Error("Failed to record processed messages", zap.String("queue", c.queueName), zap.Int("event_count", len(items)), zap.Error(err))
+		c.settleFailed(ctx, items, err, shuttingDown)
+		return
+	}
+	ackAll(items)
+}
+
+// messageIDs returns the message IDs of batch items
+func messageIDs(items []batchItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.msg.MessageId
+	}
+	return ids
+}
+
+// settleFailed settles the messages whose notification failed
+// Permanent failures, such as a rejected address or webhook, are dead-lettered without retrying
+func (c *Consumer) settleFailed(ctx context.Context, items []batchItem, err error, shuttingDown bool) {
+	if shuttingDown {
+		for _, item := range items {
+			item.msg.Nack(false, true)
+		}
+		return
+	}
+	permanent := errors.Is(err, domainmessaging.ErrPermanentDelivery)
+	for _, item := range items {
+		c.broker.Reject(ctx, c.queueName, item.msg, err, permanent)
+	}
+}
+
+// ackAll acknowledges every message of a batch
+func ackAll(items []batchItem) {
+	for _, item := range items {
+		item.msg.Ack(false)
+	}
+}
+
+// State reports the state of the consumer's RabbitMQ connection for health checks
+func (c *Consumer) State() string {
+	return c.broker.State()
+}
+
+// closes the connection to RabbitMQ
+func (c *Consumer) Close() {
+	if c.broker != nil {
+		c.broker.Close()
+		c.logger.Info("Connection to RabbitMQ closed")
+	}
+}
@@ -0,0 +1,100 @@
+package consumer
+
+import (
+	"errors"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	domainmessaging "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	"github.com/Amandasilvbr/products-crud/internal/infrastructure/mail"
+	"github.com/Amandasilvbr/products-crud/internal/infrastructure/webhook"
+	"github.com/Amandasilvbr/products-crud/internal/server"
+	usecaseimpl "github.com/Amandasilvbr/products-crud/internal/usecase"
+
+	"go.uber.org/zap"
+)
+
+// ErrWorkerMemoryDriver is returned for a standalone worker using the memory messaging driver, whose
+// in-process broker only reaches consumers running inside the API
+var ErrWorkerMemoryDriver = errors.New("the worker cannot use the memory messaging driver; run the API with EMBEDDED_WORKER instead")
+
+// Worker bundles the background work of the application: the product and account event consumers,
+// the outbox relay, the notification digests and the retention of the processed messages and email deliveries
+// It runs inside the API when EMBEDDED_WORKER is on, or alone through cmd/worker
+type Worker struct {
+	products      *Consumer
+	accounts      *AccountConsumer
+	relay         usecase.OutboxRelayInterface
+	notifications usecase.NotificationUsecaseInterface
+	dedup         usecase.MessageDeduplicatorInterface
+	deliveries    usecase.EmailDeliveryUsecaseInterface
+}
+
+// NewWorker connects the consumers, with the retry policy, concurrency and prefetch from the configuration
+// Both consumers skip the messages recorded as processed by the deduplicator, and account alerts are sent
+// through the email delivery log
+func NewWorker(cfg *config.Configs, dial domainmessaging.Dialer, relay usecase.OutboxRelayInterface, notifications usecase.NotificationUsecaseInterface, dispatcher usecase.NotificationDispatcherInterface, dedup usecase.MessageDeduplicatorInterface, deliveries usecase.EmailDeliveryUsecaseInterface, logger *zap.Logger) (*Worker, error) {
+	retry := domainmessaging.RetryPolicy{MaxAttempts: cfg.ConsumerMaxAttempts, Delay: cfg.ConsumerRetryDelay}
+	options := domainmessaging.ConsumerOptions{Concurrency: cfg.WorkerConcurrency, Prefetch: cfg.WorkerPrefetch}
+
+	accounts, err := NewAccountConsumer(logger, dial, deliveries, dedup, retry, options)
+	if err != nil {
+		return nil, err
+	}
+	products, err := NewConsumer(logger, dial, domainmessaging.ProductNotificationsSubscription, retry, options, dispatcher, dedup)
+	if err != nil {
+		accounts.Close()
+		return nil, err
+	}
+
+	return &Worker{
+		products:      products,
+		accounts:      accounts,
+		relay:         relay,
+		notifications: notifications,
+		dedup:         dedup,
+		deliveries:    deliveries,
+	}, nil
+}
+
+// CheckStandalone validates the configuration of a worker running apart from the API, through cmd/worker
+func CheckStandalone(cfg *config.Configs) error {
+	if cfg.MessagingDriver == domainmessaging.DriverMemory {
+		return ErrWorkerMemoryDriver
+	}
+	return nil
+}
+
+// NewDispatcher builds the notification dispatcher with the channels enabled in the configuration
+// The deduplicator records what each channel delivered, so that a retried event is not delivered twice to the same channel
+func NewDispatcher(cfg *config.Configs, notifications usecase.NotificationUsecaseInterface, inbox usecase.InboxUsecaseInterface, mailer domainmessaging.Mailer, dedup usecase.MessageDeduplicatorInterface, logger *zap.Logger) usecase.NotificationDispatcherInterface {
+	notifiers := []domainmessaging.Notifier{mail.NewEmailNotifier(notifications, mailer, logger), inbox}
+	if cfg.SlackWebhookURL != "" {
+		notifiers = append(notifiers, webhook.NewSlackNotifier(cfg.SlackWebhookURL, logger))
+	}
+	if cfg.TeamsWebhookURL != "" {
+		notifiers = append(notifiers, webhook.NewTeamsNotifier(cfg.TeamsWebhookURL, logger))
+	}
+	return usecaseimpl.NewNotificationDispatcher(cfg.NotificationRoutes, notifiers, dedup, logger)
+}
+
+// Start runs the worker's tasks in the group until it shuts down
+func (w *Worker) Start(group *server.Group) {
+	group.Go("product events consumer", w.products.Start)
+	group.Go("account events consumer", w.accounts.Start)
+	group.Go("outbox relay", w.relay.Run)
+	group.Go("notification digests", w.notifications.RunDigests)
+	group.Go("processed messages cleanup", w.dedup.RunCleanup)
+	group.Go("email delivery retention", w.deliveries.RunRetention)
+}
+
+// Monitors returns the consumers' connections, for the health check
+func (w *Worker) Monitors() []domainmessaging.ConnectionMonitor {
+	return []domainmessaging.ConnectionMonitor{w.products, w.accounts}
+}
+
+// Close closes the consumers' connections
+func (w *Worker) Close() {
+	w.products.Close()
+	w.accounts.Close()
+}
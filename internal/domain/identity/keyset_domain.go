@@ -0,0 +1,30 @@
+package identity
+
+// JSONWebKey is the public part of a signing key, as published in the JWKS document (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// N and E are the RSA modulus and exponent
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Crv and X are the curve and public key of an OKP (Ed25519) key
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// KeySet signs the application's access tokens and verifies them against the active and retired keys
+type KeySet interface {
+	// Sign returns a JWT for the claims, signed with the active key and carrying its kid header
+	Sign(claims map[string]interface{}) (string, error)
+	// Verify checks the signature and expiry of a JWT and returns its claims
+	Verify(rawToken string) (map[string]interface{}, error)
+	// JWKS returns the public keys downstream services use to verify tokens; symmetric keys are never published
+	JWKS() JSONWebKeySet
+}
@@ -0,0 +1,21 @@
+package identity
+
+import "context"
+
+// Claims holds the verified identity returned by an external identity provider
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// Provider performs the OpenID Connect authorization code flow with PKCE against an identity provider
+type Provider interface {
+	// AuthCodeURL returns the provider URL the user is redirected to for signing in
+	AuthCodeURL(state, nonce, codeChallenge string) string
+	// Exchange redeems the authorization code and returns the claims of the verified ID token
+	// The ID token must carry the nonce sent in AuthCodeURL
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error)
+}
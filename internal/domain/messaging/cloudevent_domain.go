@@ -0,0 +1,166 @@
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// CloudEventsSpecVersion is the version of the CloudEvents specification the events follow
+const CloudEventsSpecVersion = "1.0"
+
+// Content modes of CloudEvents over AMQP
+// Structured events carry the whole envelope as JSON in the body; binary events carry the attributes
+// as "cloudEvents:" application properties and only the data in the body
+const (
+	CloudEventsStructured = "structured"
+	CloudEventsBinary     = "binary"
+)
+
+// Content types of published messages
+const (
+	ContentTypeCloudEvents = "application/cloudevents+json"
+	ContentTypeJSON        = "application/json"
+)
+
+// cloudEventsHeaderPrefix prefixes the attributes of binary events, as in the CloudEvents AMQP binding
+const cloudEventsHeaderPrefix = "cloudEvents:"
+
+// ErrInvalidCloudEvent is returned for messages that claim to be CloudEvents but miss required attributes
+var ErrInvalidCloudEvent = errors.New("invalid CloudEvent")
+
+// CloudEvent is a CloudEvents 1.0 envelope with JSON data
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            time.Time       `json:"time"`
+	Subject         string          `json:"subject,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	DataSchema      string          `json:"dataschema,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// NewCloudEvent builds an event whose data is marshalled to JSON
+func NewCloudEvent(id, source, eventType, subject, dataSchema string, at time.Time, data interface{}) (*CloudEvent, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              id,
+		Source:          source,
+		Type:            eventType,
+		Time:            at.UTC(),
+		Subject:         subject,
+		DataContentType: ContentTypeJSON,
+		DataSchema:      dataSchema,
+		Data:            body,
+	}, nil
+}
+
+// ParseCloudEvent reads an event stored in structured mode
+// It returns nil without error when the JSON is not a CloudEvent, such as a legacy message
+func ParseCloudEvent(body []byte) (*CloudEvent, error) {
+	var event CloudEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+	if event.SpecVersion == "" {
+		return nil, nil
+	}
+	return &event, event.validate()
+}
+
+// validate checks the attributes required by the specification
+func (e *CloudEvent) validate() error {
+	if e.SpecVersion != CloudEventsSpecVersion {
+		return fmt.Errorf("%w: unsupported specversion %q", ErrInvalidCloudEvent, e.SpecVersion)
+	}
+	if e.ID == "" || e.Source == "" || e.Type == "" {
+		return fmt.Errorf("%w: id, source and type are required", ErrInvalidCloudEvent)
+	}
+	return nil
+}
+
+// Publishing encodes the event as an AMQP message in the given content mode
+func (e *CloudEvent) Publishing(mode string) (amqp091.Publishing, error) {
+	msg := amqp091.Publishing{
+		DeliveryMode: amqp091.Persistent,
+		MessageId:    e.ID,
+		Timestamp:    e.Time,
+	}
+
+	if mode == CloudEventsBinary {
+		headers := amqp091.Table{
+			cloudEventsHeaderPrefix + "specversion": e.SpecVersion,
+			cloudEventsHeaderPrefix + "id":          e.ID,
+			cloudEventsHeaderPrefix + "source":      e.Source,
+			cloudEventsHeaderPrefix + "type":        e.Type,
+			cloudEventsHeaderPrefix + "time":        e.Time.Format(time.RFC3339Nano),
+		}
+		if e.Subject != "" {
+			headers[cloudEventsHeaderPrefix+"subject"] = e.Subject
+		}
+		if e.DataSchema != "" {
+			headers[cloudEventsHeaderPrefix+"dataschema"] = e.DataSchema
+		}
+		msg.Headers = headers
+		msg.ContentType = e.DataContentType
+		msg.Body = e.Data
+		return msg, nil
+	}
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		return msg, err
+	}
+	msg.ContentType = ContentTypeCloudEvents
+	msg.Body = body
+	return msg, nil
+}
+
+// DecodeCloudEvent reads the event carried by a message in either content mode
+// It returns nil without error when the message is not a CloudEvent, such as a legacy message
+func DecodeCloudEvent(msg amqp091.Delivery) (*CloudEvent, error) {
+	if strings.HasPrefix(msg.ContentType, ContentTypeCloudEvents) {
+		event, err := ParseCloudEvent(msg.Body)
+		if err == nil && event == nil {
+			err = fmt.Errorf("%w: specversion is missing", ErrInvalidCloudEvent)
+		}
+		return event, err
+	}
+
+	header := func(name string) string {
+		value, _ := msg.Headers[cloudEventsHeaderPrefix+name].(string)
+		return value
+	}
+	if header("specversion") == "" {
+		return nil, nil
+	}
+
+	event := &CloudEvent{
+		SpecVersion:     header("specversion"),
+		ID:              header("id"),
+		Source:          header("source"),
+		Type:            header("type"),
+		Subject:         header("subject"),
+		DataContentType: msg.ContentType,
+		DataSchema:      header("dataschema"),
+		Data:            msg.Body,
+	}
+	if at := header("time"); at != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, at)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid time: %v", ErrInvalidCloudEvent, err)
+		}
+		event.Time = parsed
+	}
+	return event, event.validate()
+}
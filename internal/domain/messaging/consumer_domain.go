@@ -1,7 +1,43 @@
 package messaging
 
-import "context"
+import (
+	"context"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// Messaging drivers selected by MESSAGING_DRIVER
+const (
+	DriverRabbitMQ = "rabbitmq"
+	// DriverMemory runs an in-process broker, for local development and tests; messages are lost on exit
+	DriverMemory = "memory"
+)
 
 type Consumer interface {
 	Start(ctx context.Context) error
 }
+
+// ConsumerOptions sets how much work a consumer takes on at once
+type ConsumerOptions struct {
+	// Concurrency is the number of messages, or batches, handled in parallel
+	Concurrency int
+	// Prefetch is the number of messages the broker delivers ahead of their acknowledgement
+	Prefetch int
+}
+
+// Broker is a connection to the message broker, with what publishers, consumers and the
+// dead-letter administration need
+type Broker interface {
+	Publisher
+	ConnectionMonitor
+	DeadLetterQueues
+	// DeclareQueue declares a work queue with its retry and dead-letter queues
+	DeclareQueue(queueName string) error
+	// SetPrefetch limits the deliveries the connection's consumers receive ahead of their acknowledgements
+	SetPrefetch(count int) error
+	// Reject settles a message whose processing failed, retrying it or sending it to the dead-letter queue
+	Reject(ctx context.Context, queueName string, msg amqp091.Delivery, cause error, permanent bool)
+}
+
+// Dialer opens a new connection to the broker; the retry policy applies to the messages its consumers reject
+type Dialer func(retry RetryPolicy) (Broker, error)
@@ -0,0 +1,69 @@
+package messaging
+
+import (
+	"context"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// Suffixes of the queues and exchange declared around every work queue
+// Failed messages wait in "<queue>.retry" before returning to the queue; messages that keep failing,
+// or can never succeed, are routed through the "<queue>.dlx" exchange to the "<queue>.dlq" queue
+const (
+	RetrySuffix              = ".retry"
+	DeadLetterSuffix         = ".dlq"
+	DeadLetterExchangeSuffix = ".dlx"
+)
+
+// Headers carried by retried and dead-lettered messages
+const (
+	AttemptsHeader  = "x-attempts"
+	LastErrorHeader = "x-last-error"
+)
+
+// RetryPolicy controls how messages whose processing failed are retried before being dead-lettered
+type RetryPolicy struct {
+	// MaxAttempts is the number of deliveries after which a failing message goes to the dead-letter queue
+	MaxAttempts int
+	// Delay is how long a failed message waits before being delivered again
+	Delay time.Duration
+}
+
+// ShouldDeadLetter reports whether a message that failed for the given attempt, counting from 1,
+// must be dead-lettered instead of retried; permanent failures are never retried
+func (p RetryPolicy) ShouldDeadLetter(attempt int, permanent bool) bool {
+	return permanent || attempt >= p.MaxAttempts
+}
+
+// Attempts returns how many times a message was already delivered and failed
+func Attempts(msg amqp091.Delivery) int {
+	switch n := msg.Headers[AttemptsHeader].(type) {
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
+
+// DeadLetter is a message held in a dead-letter queue
+type DeadLetter struct {
+	MessageID string    `json:"message_id"`
+	Body      string    `json:"body"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DeadLetterQueues inspects and drains the dead-letter queue of a work queue
+type DeadLetterQueues interface {
+	// Peek returns up to limit messages of the dead-letter queue without removing them
+	Peek(ctx context.Context, queueName string, limit int) ([]DeadLetter, error)
+	// Requeue moves up to limit messages back to the work queue with a fresh attempt count
+	Requeue(ctx context.Context, queueName string, limit int) (int, error)
+	// Purge removes every message of the dead-letter queue
+	Purge(ctx context.Context, queueName string) (int, error)
+}
@@ -0,0 +1,26 @@
+package messaging
+
+import (
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// ProductDigest is a summary of product notifications addressed to one person
+type ProductDigest struct {
+	// Frequency is immediate for the consumer's short batches, or hourly or daily for scheduled digests
+	Frequency     string
+	RecipientName string
+	// Locale and TimeZone select the language and the clock of the email; empty values use the defaults
+	Locale         string
+	TimeZone       string
+	Notifications  []model.ProductNotification
+	UnsubscribeURL string
+	SentAt         time.Time
+}
+
+// EmailRenderer builds the subject and bodies of notification emails from templates
+type EmailRenderer interface {
+	// RenderProductDigest returns the email of a digest, without recipients
+	RenderProductDigest(digest *ProductDigest) (*Email, error)
+}
@@ -0,0 +1,27 @@
+package messaging
+
+import "context"
+
+// Kinds of the notification emails recorded in the email delivery log
+const (
+	EmailKindProductNotification = "product_notification"
+	EmailKindProductDigest       = "product_digest"
+	EmailKindAccountLocked       = "account_locked"
+)
+
+// Email describes a message to be delivered by a Mailer
+type Email struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+	// Kind and EventIDs describe notification emails in the delivery log
+	// Emails without a kind, such as password resets whose content holds secrets, are not recorded
+	Kind     string
+	EventIDs []string
+}
+
+// Mailer sends transactional emails such as password resets
+type Mailer interface {
+	Send(ctx context.Context, email *Email) error
+}
@@ -0,0 +1,28 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// Notification channels product notifications can be routed to
+const (
+	ChannelEmail = "email"
+	ChannelInbox = "inbox"
+	ChannelSlack = "slack"
+	ChannelTeams = "teams"
+)
+
+// ErrPermanentDelivery marks delivery failures that can never succeed on retry, such as a rejected
+// address or webhook; messages failing this way are dead-lettered right away
+var ErrPermanentDelivery = errors.New("permanent delivery failure")
+
+// Notifier delivers product notifications through one channel
+type Notifier interface {
+	// Channel returns the name of the channel in the routing rules
+	Channel() string
+	// Notify delivers the notifications of the person who made the changes
+	Notify(ctx context.Context, recipient string, notifications []model.ProductNotification) error
+}
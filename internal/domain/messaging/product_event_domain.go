@@ -0,0 +1,154 @@
+package messaging
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// Product events are typed "com.products.product.<action>.v<version>"; the version changes with the
+// data schema, so that consumers can keep reading the versions they know
+const (
+	productEventTypePrefix = "com.products.product."
+	ProductEventVersion    = "v1"
+	// ProductEventSchema identifies the schema of ProductEventData
+	ProductEventSchema = "urn:products-crud:schema:product-event:v1"
+)
+
+// Product events are published to the ProductEventsExchange topic exchange with the routing key
+// "product.<action>.<category>"; subscribers bind their own queue to the events they need
+const (
+	ProductEventsExchange = "products"
+	// AllProductEvents is the binding pattern matching every product event
+	AllProductEvents = "product.#"
+	// uncategorized stands for the category of products without one in routing keys
+	uncategorized = "uncategorized"
+)
+
+// ProductEventsQueue is the queue of the product notifications, the first subscriber of the product events
+// It received the events through the default exchange before the topic exchange, and stays bound to every event
+const ProductEventsQueue = "product_events"
+
+// ProductNotificationsSubscription subscribes the notifications consumer to every product event
+var ProductNotificationsSubscription = Subscription{
+	Queue:       ProductEventsQueue,
+	Exchange:    ProductEventsExchange,
+	RoutingKeys: []string{AllProductEvents},
+}
+
+// ProductEventData is the data of product events, version 1
+// Updates also list the fields that changed
+type ProductEventData struct {
+	Product          ProductSnapshot `json:"product"`
+	Changes          []FieldChange   `json:"changes,omitempty"`
+	ResponsibleEmail string          `json:"responsible_email"`
+	OrgID            uint            `json:"org_id"`
+	OrgName          string          `json:"org_name"`
+}
+
+// ProductSnapshot is the state of a product after the change, or before its deletion
+type ProductSnapshot struct {
+	SKU          int       `json:"sku"`
+	OrgID        uint      `json:"org_id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Price        float64   `json:"price"`
+	Category     string    `json:"category"`
+	Link         string    `json:"link"`
+	ImageLink    string    `json:"image_link"`
+	Availability string    `json:"availability"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// FieldChange is a product field changed by an update, named as in ProductSnapshot
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// NewProductSnapshot copies the fields of a product carried by its events
+func NewProductSnapshot(product *model.Product) ProductSnapshot {
+	return ProductSnapshot{
+		SKU:          product.SKU,
+		OrgID:        product.OrgID,
+		Name:         product.Name,
+		Description:  product.Description,
+		Price:        product.Price,
+		Category:     product.Category,
+		Link:         product.Link,
+		ImageLink:    product.ImageLink,
+		Availability: product.Availability,
+		CreatedBy:    product.CreatedBy,
+		CreatedAt:    product.CreatedAt,
+		UpdatedAt:    product.UpdatedAt,
+	}
+}
+
+// DiffProducts lists the editable fields that differ between two versions of a product
+func DiffProducts(before, after *model.Product) []FieldChange {
+	var changes []FieldChange
+	add := func(field string, from, to interface{}) {
+		if from != to {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	add("name", before.Name, after.Name)
+	add("description", before.Description, after.Description)
+	add("price", before.Price, after.Price)
+	add("category", before.Category, after.Category)
+	add("link", before.Link, after.Link)
+	add("image_link", before.ImageLink, after.ImageLink)
+	add("availability", before.Availability, after.Availability)
+	return changes
+}
+
+// ProductEventType returns the CloudEvents type of a product event, e.g. product_updated gives
+// com.products.product.updated.v1
+func ProductEventType(event string) string {
+	return productEventTypePrefix + strings.TrimPrefix(event, "product_") + "." + ProductEventVersion
+}
+
+// ProductEventName returns the event name, e.g. product_updated, of a product event type of a supported version
+func ProductEventName(eventType string) (string, error) {
+	action, ok := strings.CutPrefix(eventType, productEventTypePrefix)
+	if ok {
+		action, ok = strings.CutSuffix(action, "."+ProductEventVersion)
+	}
+	if !ok || action == "" || strings.Contains(action, ".") {
+		return "", fmt.Errorf("unsupported product event type %q", eventType)
+	}
+	return "product_" + action, nil
+}
+
+// ProductRoutingKey returns the routing key of a product event, e.g. product_updated of a product in
+// "Home & Garden" gives product.updated.home-garden
+// Categories are lowercased and reduced to letters, digits and dashes, since dots separate the words of keys
+func ProductRoutingKey(event, category string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(category) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			slug.WriteRune(r)
+			dash = false
+		} else if !dash && slug.Len() > 0 {
+			slug.WriteByte('-')
+			dash = true
+		}
+	}
+	key := strings.TrimSuffix(slug.String(), "-")
+	if key == "" {
+		key = uncategorized
+	}
+	return "product." + strings.TrimPrefix(event, "product_") + "." + key
+}
+
+// ProductEventSource returns the CloudEvents source of the product events of an organization
+func ProductEventSource(orgID uint) string {
+	return fmt.Sprintf("/organizations/%d/products", orgID)
+}
@@ -6,8 +6,89 @@ import (
 	"github.com/rabbitmq/amqp091-go"
 )
 
+// AccountEventsQueue carries account security events such as lockouts to the consumer
+const AccountEventsQueue = "account_events"
+
+// Connection states reported by a broker client
+const (
+	StateConnected    = "connected"
+	StateReconnecting = "reconnecting"
+	StateClosed       = "closed"
+)
+
+// Publisher sends messages to RabbitMQ and consumes its queues
 type Publisher interface {
+	// Publish sends a message to a queue through the default exchange
+	// The message ID is the one carried by the context (WithMessageID), or a random one
 	Publish(ctx context.Context, queueName, body string) error
+	// PublishTo sends a message to an exchange with a routing key, with the message ID carried by the context
+	PublishTo(ctx context.Context, exchange, routingKey, body string) error
+	// PublishEvent publishes a CloudEvent in the given content mode, CloudEventsStructured or CloudEventsBinary
+	PublishEvent(ctx context.Context, exchange, routingKey string, event *CloudEvent, mode string) error
+	// DeclareTopology declares the exchanges, queues and bindings the publisher or its subscribers rely on
+	DeclareTopology(topology Topology) error
 	Consume(queueName, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp091.Table) (<-chan amqp091.Delivery, error)
 	Close()
 }
+
+// messageIDKey is the context key holding the ID of the message to publish
+type messageIDKey struct{}
+
+// WithMessageID returns a copy of the context publishing its message with the given ID
+// Publishing the same message again with the same ID lets consumers recognize it as a duplicate
+func WithMessageID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, messageIDKey{}, id)
+}
+
+// MessageID returns the ID the context publishes its message with
+func MessageID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(messageIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// ConnectionMonitor reports the state of a broker connection, for health checks
+type ConnectionMonitor interface {
+	State() string
+}
+
+// Exchange is an exchange declared by DeclareTopology; exchanges are durable
+type Exchange struct {
+	Name string
+	// Kind is the exchange type, such as amqp091.ExchangeTopic
+	Kind string
+}
+
+// Binding routes the messages of an exchange whose routing key matches a pattern to a queue
+type Binding struct {
+	Queue      string
+	Exchange   string
+	RoutingKey string
+}
+
+// Topology is a set of exchanges, work queues and bindings
+// Work queues are declared with their retry and dead-letter queues
+type Topology struct {
+	Exchanges []Exchange
+	Queues    []string
+	Bindings  []Binding
+}
+
+// Subscription is the queue a subscriber consumes and the routing keys bound to it on an exchange
+// Each subscriber has its own queue, so that every subscriber receives its own copy of the messages
+type Subscription struct {
+	Queue       string
+	Exchange    string
+	RoutingKeys []string
+}
+
+// Topology returns the topic exchange, the queue and the bindings of the subscription
+func (s Subscription) Topology() Topology {
+	topology := Topology{
+		Exchanges: []Exchange{{Name: s.Exchange, Kind: amqp091.ExchangeTopic}},
+		Queues:    []string{s.Queue},
+	}
+	for _, key := range s.RoutingKeys {
+		topology.Bindings = append(topology.Bindings, Binding{Queue: s.Queue, Exchange: s.Exchange, RoutingKey: key})
+	}
+	return topology
+}
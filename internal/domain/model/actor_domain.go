@@ -0,0 +1,38 @@
+package model
+
+// Actor identifies the authenticated user performing an operation
+// It is built from the JWT claims and passed down to the use cases
+type Actor struct {
+	ID    uint
+	Name  string
+	Email string
+	// Role is the platform-wide role
+	Role string
+	// OrgID, OrgName and OrgRole describe the active organization; OrgID is 0 when there is none
+	OrgID   uint
+	OrgName string
+	OrgRole string
+}
+
+// IsAdmin reports whether the actor holds the admin role
+func (a *Actor) IsAdmin() bool {
+	return a.Role == RoleAdmin
+}
+
+// CanModify reports whether the actor may update or delete the given product
+// Within the product's organization, admins may modify any product, editors only the products they
+// created and viewers none
+// Ownership is decided by user ID, since names are not unique across accounts
+func (a *Actor) CanModify(product *Product) bool {
+	if product.OrgID != a.OrgID {
+		return false
+	}
+	switch a.OrgRole {
+	case RoleAdmin:
+		return true
+	case RoleEditor:
+		return product.CreatedByID != 0 && product.CreatedByID == a.ID
+	default:
+		return false
+	}
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Scopes that can be granted to an API key
+const (
+	ScopeProductsRead  = "products:read"
+	ScopeProductsWrite = "products:write"
+)
+
+// APIKey represents a credential used by other services to call the API
+// Requests made with the key act on behalf of its owner within one organization, restricted to the granted scopes
+// Only the SHA-256 hash of the key is persisted; the raw value is shown once on creation
+type APIKey struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Name        string     `gorm:"not null" json:"name"`
+	Prefix      string     `gorm:"index;not null" json:"prefix"`
+	KeyHash     string     `gorm:"uniqueIndex;not null" json:"-"`
+	Scopes      string     `gorm:"not null" json:"scopes"`
+	OwnerID     uint       `gorm:"index;not null" json:"ownerId"`
+	OrgID       uint       `gorm:"index;not null" json:"orgId"`
+	CreatedByID uint       `json:"createdById"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+	RevokedAt   *time.Time `json:"revokedAt"`
+	LastUsedAt  *time.Time `json:"lastUsedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// IsValidScope reports whether scope is one of the known API key scopes
+func IsValidScope(scope string) bool {
+	return scope == ScopeProductsRead || scope == ScopeProductsWrite
+}
+
+// ScopeList returns the scopes granted to the key
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return nil
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// IsActive reports whether the key is neither revoked nor expired at the given instant
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
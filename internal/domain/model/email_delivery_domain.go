@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// Statuses of an email delivery after its last attempt
+const (
+	EmailDeliverySent   = "sent"
+	EmailDeliveryFailed = "failed"
+)
+
+// EmailDelivery records the attempts to send a notification email, so that support can tell whether
+// and when someone was emailed
+// Every attempt to send the same email, with the same kind, recipients and events, updates the same record.
+// Recipients, subject, content and the server's response may hold personal data, such as names and addresses,
+// and are cleared once the last attempt is older than the redaction delay; kind, events, status and code are kept
+type EmailDelivery struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+	// DeliveryKey identifies the email across its attempts
+	DeliveryKey string `gorm:"uniqueIndex;not null" json:"-"`
+	Kind        string `gorm:"index;not null" json:"kind"`
+	// Recipients and EventIDs are comma-separated lists
+	Recipients string `gorm:"type:text" json:"recipients"`
+	Subject    string `json:"subject"`
+	EventIDs   string `gorm:"type:text" json:"eventIds"`
+	Text       string `gorm:"type:text" json:"-"`
+	HTML       string `gorm:"type:text" json:"-"`
+	Status     string `gorm:"index;not null" json:"status"`
+	// ResponseCode and Response describe a failed last attempt: the code and message of the server's refusal,
+	// or 0 and the error when it could not be reached. They are empty when the email was accepted
+	ResponseCode   int       `json:"responseCode"`
+	Response       string    `gorm:"type:text" json:"response"`
+	Attempts       int       `gorm:"not null;default:0" json:"attempts"`
+	FirstAttemptAt time.Time `gorm:"index;not null" json:"firstAttemptAt"`
+	LastAttemptAt  time.Time `gorm:"index;not null" json:"lastAttemptAt"`
+	// SentAt is when the email was first accepted by the SMTP server, or nil while it was not
+	SentAt *time.Time `json:"sentAt"`
+	// ResendOf is the delivery an administrator resent
+	ResendOf *uint `json:"resendOf"`
+	// RedactedAt is when the recipients and content were cleared, or nil while they are kept
+	RedactedAt *time.Time `json:"redactedAt"`
+}
+
+// EmailDeliveryFilter narrows a search of the email delivery log; empty fields match every delivery
+type EmailDeliveryFilter struct {
+	// Recipient matches the deliveries to an address, case-insensitively
+	Recipient string
+	EventID   string
+	Kind      string
+	Status    string
+	// From and To bound the first attempt of the deliveries
+	From *time.Time
+	To   *time.Time
+}
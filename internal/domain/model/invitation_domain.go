@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// Invitation statuses reported by Invitation.Status
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+// Invitation lets a person create an account that joins an organization with the given role
+// Only the SHA-256 hash of the token emailed to the invitee is persisted; resending replaces it
+type Invitation struct {
+	ID           uint          `gorm:"primaryKey" json:"id"`
+	OrgID        uint          `gorm:"index;not null" json:"orgId"`
+	Email        string        `gorm:"index;not null" json:"email"`
+	Role         string        `gorm:"not null" json:"role"`
+	TokenHash    string        `gorm:"uniqueIndex;not null" json:"-"`
+	InvitedByID  uint          `json:"invitedById"`
+	ExpiresAt    time.Time     `json:"expiresAt"`
+	SentAt       time.Time     `json:"sentAt"`
+	AcceptedAt   *time.Time    `json:"acceptedAt"`
+	RevokedAt    *time.Time    `json:"revokedAt"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
+	Organization *Organization `gorm:"foreignKey:OrgID;constraint:OnDelete:CASCADE" json:"organization,omitempty"`
+}
+
+// Status reports whether the invitation is pending, accepted, revoked or expired at the given instant
+func (i *Invitation) Status(now time.Time) string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case !now.Before(i.ExpiresAt):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// Outcomes recorded in the authentication audit log
+const (
+	AuthOutcomeSuccess            = "success"
+	AuthOutcomeInvalidCredentials = "invalid_credentials"
+	AuthOutcomeNotVerified        = "email_not_verified"
+	AuthOutcomeDisabled           = "account_disabled"
+	AuthOutcomeMFARequired        = "mfa_required"
+	AuthOutcomeInvalidMFACode     = "invalid_mfa_code"
+	AuthOutcomeOIDC               = "oidc"
+	AuthOutcomeThrottled          = "throttled"
+	AuthOutcomeLocked             = "locked"
+	AuthOutcomeUnlocked           = "unlocked"
+)
+
+// LoginThrottle tracks recent failed logins for one account email or one client IP
+// Keys are prefixed with their kind, e.g. "account:ana@example.com" or "ip:203.0.113.7",
+// so unknown emails are throttled exactly like existing ones
+type LoginThrottle struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Key           string     `gorm:"uniqueIndex;not null" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `json:"lastFailureAt"`
+	LockedUntil   *time.Time `json:"lockedUntil"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// IsLocked reports whether the key is locked out at the given instant
+func (t *LoginThrottle) IsLocked(now time.Time) bool {
+	return t.LockedUntil != nil && now.Before(*t.LockedUntil)
+}
+
+// AuthAuditEntry records a single authentication attempt or lockout change
+type AuthAuditEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    *uint     `gorm:"index" json:"userId"`
+	Email     string    `gorm:"index" json:"email"`
+	IP        string    `gorm:"index" json:"ip"`
+	Success   bool      `json:"success"`
+	Outcome   string    `gorm:"not null" json:"outcome"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// MFAPolicy records whether users with a role must use two-factor authentication
+// Roles without a stored policy do not require it
+type MFAPolicy struct {
+	Role      string    `gorm:"primaryKey" json:"role"`
+	Required  bool      `gorm:"not null;default:false" json:"required"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// MFARecoveryCode is a one-time code that replaces a TOTP code when the authenticator is unavailable
+// Only the SHA-256 hash of the code is persisted, never the raw value
+type MFARecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"userId"`
+	CodeHash  string     `gorm:"index;not null" json:"-"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// MFAEnrollment holds the TOTP secret shown to the user while enrolling an authenticator app
+// URI is the otpauth:// payload to render as a QR code
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// LoginResult is the outcome of a login step: either the session tokens or a pending MFA challenge
+type LoginResult struct {
+	Tokens *TokenPair
+	// MFAToken is the short-lived challenge to complete with a code when a second factor is needed
+	MFAToken string
+	// MFAEnrollmentRequired is set when the role requires 2FA and the user must enroll before logging in
+	MFAEnrollmentRequired bool
+	// RecoveryCodes are returned once, when enrollment is completed during login
+	RecoveryCodes []string
+}
+
+// MFARequired reports whether the login is waiting for a second factor
+func (r *LoginResult) MFARequired() bool {
+	return r.MFAToken != ""
+}
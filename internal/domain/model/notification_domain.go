@@ -0,0 +1,130 @@
+package model
+
+import "time"
+
+// Notification frequencies a user can choose for each product event
+// Immediate events are emailed in the consumer's short batches; hourly and daily events are gathered
+// in a digest sent on schedule; off events are not emailed
+const (
+	FrequencyImmediate = "immediate"
+	FrequencyHourly    = "hourly"
+	FrequencyDaily     = "daily"
+	FrequencyOff       = "off"
+)
+
+// Product events users can be notified about
+const (
+	EventProductCreated = "product_created"
+	EventProductUpdated = "product_updated"
+	EventProductDeleted = "product_deleted"
+)
+
+// NotificationEvents lists the product events whose notifications can be configured
+var NotificationEvents = []string{EventProductCreated, EventProductUpdated, EventProductDeleted}
+
+// IsValidFrequency reports whether the frequency is one of the supported values
+func IsValidFrequency(frequency string) bool {
+	switch frequency {
+	case FrequencyImmediate, FrequencyHourly, FrequencyDaily, FrequencyOff:
+		return true
+	}
+	return false
+}
+
+// IsNotificationEvent reports whether the event's notifications can be configured
+func IsNotificationEvent(event string) bool {
+	for _, known := range NotificationEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationPreference is how often a user is emailed about one product event
+// Events without a stored preference are notified immediately
+type NotificationPreference struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_notification_preference" json:"userId"`
+	Event     string    `gorm:"not null;uniqueIndex:idx_notification_preference" json:"event"`
+	Frequency string    `gorm:"not null" json:"frequency"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// UnsubscribedEmail is an address without an account whose owner turned off product notifications from an
+// email link; people without an account cannot choose per event, so every notification to the address stops
+type UnsubscribedEmail struct {
+	// Email is stored in lower case
+	Email     string    `gorm:"primaryKey" json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Locales notification emails can be written in
+const (
+	LocalePortuguese = "pt-BR"
+	LocaleEnglish    = "en"
+	LocaleSpanish    = "es"
+	// DefaultLocale is used for users who never chose a locale and for people without an account
+	DefaultLocale = LocalePortuguese
+)
+
+// IsSupportedLocale reports whether notification emails can be written in the locale
+func IsSupportedLocale(locale string) bool {
+	switch locale {
+	case LocalePortuguese, LocaleEnglish, LocaleSpanish:
+		return true
+	}
+	return false
+}
+
+// NotificationSettings gathers how often a user is emailed about each event and how the emails are written
+type NotificationSettings struct {
+	// Preferences maps each product event to its frequency
+	Preferences map[string]string
+	Locale      string
+	// TimeZone is an IANA time zone name, such as "America/Sao_Paulo", used for the dates of the emails
+	TimeZone string
+}
+
+// ProductNotification describes a product change to be emailed to the person who made it
+type ProductNotification struct {
+	Event   string
+	SKU     int
+	Name    string
+	OrgID   uint
+	OrgName string
+	// At is when the change happened; it is shown in digests, which gather changes over hours
+	At time.Time
+	// EventID is the ID of the event message, recorded with the emails in the delivery log
+	EventID string
+}
+
+// DigestEntry is a product notification waiting for the user's next hourly or daily digest
+// An event is stored once per user, so that a redelivered event does not appear twice in the digest
+type DigestEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index;not null;uniqueIndex:idx_digest_entries_user_event,priority:1,where:event_id <> ''" json:"userId"`
+	Frequency string    `gorm:"not null" json:"frequency"`
+	Event     string    `gorm:"not null" json:"event"`
+	SKU       int       `json:"sku"`
+	Name      string    `json:"name"`
+	OrgID     uint      `json:"orgId"`
+	OrgName   string    `json:"orgName"`
+	EventID   string    `gorm:"uniqueIndex:idx_digest_entries_user_event,priority:2,where:event_id <> ''" json:"eventId"`
+	DueAt     time.Time `gorm:"index;not null" json:"dueAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// InboxNotification is a product notification shown in the in-app inbox of a user
+type InboxNotification struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	UserID  uint   `gorm:"index:idx_inbox_user;not null" json:"userId"`
+	Event   string `gorm:"not null" json:"event"`
+	SKU     int    `json:"sku"`
+	Name    string `json:"name"`
+	OrgID   uint   `json:"orgId"`
+	OrgName string `json:"orgName"`
+	// ReadAt is when the user marked the notification as read, or nil while it is unread
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `gorm:"index:idx_inbox_user" json:"createdAt"`
+}
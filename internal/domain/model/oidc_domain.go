@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// OIDCLoginState holds an OIDC login started by this API until the identity provider redirects back
+// The state is stored hashed; the PKCE verifier and nonce never leave the server
+type OIDCLoginState struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	StateHash    string    `gorm:"uniqueIndex;not null" json:"-"`
+	CodeVerifier string    `gorm:"not null" json:"-"`
+	Nonce        string    `gorm:"not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"index" json:"expiresAt"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
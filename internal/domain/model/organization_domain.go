@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Organization is a tenant owning its own catalog; SKUs are unique within an organization
+type Organization struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Slug      string    `gorm:"uniqueIndex;not null" json:"slug"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Membership grants a user a role within one organization
+// Users may belong to several organizations, with a different role in each
+type Membership struct {
+	UserID       uint          `gorm:"primaryKey;autoIncrement:false" json:"userId"`
+	OrgID        uint          `gorm:"primaryKey;autoIncrement:false;index" json:"orgId"`
+	Role         string        `gorm:"not null" json:"role"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
+	User         *User         `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+	Organization *Organization `gorm:"foreignKey:OrgID;constraint:OnDelete:CASCADE" json:"organization,omitempty"`
+}
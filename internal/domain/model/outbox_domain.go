@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// OutboxEvent is a message waiting to be published to RabbitMQ
+// It is written in the same transaction as the change it describes, so the event is stored exactly
+// when the change is committed; a relay publishes it afterwards and records the delivery
+type OutboxEvent struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+	// Queue is the queue of events published through the default exchange, before the topic exchange
+	Queue string `gorm:"not null" json:"queue"`
+	// Exchange and RoutingKey address the events published to an exchange
+	Exchange    string     `json:"exchange"`
+	RoutingKey  string     `json:"routingKey"`
+	Event       string     `gorm:"not null" json:"event"`
+	Payload     string     `gorm:"type:text;not null" json:"payload"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	LastError   string     `json:"lastError"`
+	AvailableAt time.Time  `gorm:"index;not null" json:"availableAt"`
+	SentAt      *time.Time `gorm:"index" json:"sentAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// PasswordResetToken represents a single-use token emailed to a user who forgot their password
+// Only the SHA-256 hash of the token is persisted
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"userId"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
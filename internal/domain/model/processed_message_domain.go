@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// ProcessedMessage records a message whose side effects a consumer completed
+// Consumers look up the IDs of the messages they receive, so that a redelivered message is acknowledged
+// without notifying its recipient twice
+type ProcessedMessage struct {
+	Consumer    string    `gorm:"primaryKey" json:"consumer"`
+	MessageID   string    `gorm:"primaryKey" json:"messageId"`
+	ProcessedAt time.Time `gorm:"index;not null" json:"processedAt"`
+}
@@ -3,8 +3,10 @@ package model
 import "time"
 
 // Product represents the data model for a product in the database
+// SKUs are unique per organization, so the primary key combines both
 type Product struct {
-	SKU int `gorm:"primaryKey" json:"sku" validate:"required,gt=0"`
+	OrgID uint `gorm:"primaryKey;autoIncrement:false" json:"orgId"`
+	SKU int `gorm:"primaryKey;autoIncrement:false" json:"sku" validate:"required,gt=0"`
 	Name string `json:"name" validate:"required,min=3,max=100"`
 	Description string `json:"description"`
 	Price float64 `json:"price" validate:"required,gt=0"`
@@ -15,4 +17,6 @@ type Product struct {
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 	CreatedBy string `json:"createdBy"`
+	// CreatedByID is the ID of the user who created the product and decides ownership; CreatedBy is only the display name
+	CreatedByID uint `gorm:"index" json:"createdById"`
 }
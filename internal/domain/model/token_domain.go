@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// RefreshToken represents a rotating refresh token stored in the database
+// Only the SHA-256 hash of the token is persisted, never the raw value
+// OrgID keeps the active organization across refreshes; 0 means no organization
+type RefreshToken struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"index;not null" json:"userId"`
+	TokenHash    string     `gorm:"uniqueIndex;not null" json:"-"`
+	FamilyID     string     `gorm:"index;not null" json:"familyId"`
+	OrgID        uint       `gorm:"not null;default:0" json:"orgId"`
+	ExpiresAt    time.Time  `json:"expiresAt"`
+	RevokedAt    *time.Time `json:"revokedAt"`
+	ReplacedByID *uint      `json:"replacedById"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// RevokedToken represents an access token whose JTI was explicitly revoked before expiring
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey" json:"jti"`
+	ExpiresAt time.Time `gorm:"index" json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TokenPair holds the credentials returned to a client after a successful authentication
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
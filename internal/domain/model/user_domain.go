@@ -1,13 +1,67 @@
 package model
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// Roles that can be assigned to a user
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
 // User represents the data model for a user in the database
 type User struct {
 	gorm.Model
-	Name string `gorm:"primaryKey" json:"name" validate:"required,min=3,max=100"`
-	Email string `json:"email" validate:"required,email"`
+	Name string `gorm:"not null" json:"name" validate:"required,min=3,max=100"`
+	// Email is unique among users that have not been deleted
+	Email string `gorm:"uniqueIndex:idx_users_email,where:deleted_at IS NULL;not null" json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
+	// Role is the platform-wide role; product access within an organization follows the Membership role
+	Role string `gorm:"not null;default:editor" json:"role" validate:"omitempty,oneof=admin editor viewer"`
+	// SessionsRevokedAt invalidates every access token issued before this instant
+	SessionsRevokedAt *time.Time `json:"-"`
+	// VerifiedAt is set once the user confirms ownership of the email address
+	VerifiedAt *time.Time `json:"verified_at"`
+	// VerificationSentAt records the last verification email, used to throttle resends
+	VerificationSentAt *time.Time `json:"-"`
+	// DisabledAt is set while an administrator has disabled the account
+	DisabledAt *time.Time `json:"disabled_at"`
+	// Locale is the language of the notification emails; empty means the default locale
+	Locale string `json:"locale"`
+	// TimeZone is the IANA time zone of the dates in notification emails; empty means the default time zone
+	TimeZone string `json:"time_zone"`
+	// MFASecret is the base32 TOTP secret, set when enrollment starts
+	MFASecret string `json:"-"`
+	// MFAEnabledAt is set once the user confirms the authenticator with a valid code
+	MFAEnabledAt *time.Time `json:"mfa_enabled_at"`
+	// MFALastStep is the last accepted TOTP time step, so a code cannot be replayed
+	MFALastStep int64 `json:"-"`
+	// OIDCSubject links the account to its identity at the corporate identity provider
+	OIDCSubject *string `gorm:"uniqueIndex" json:"-"`
+	// ActiveOrgID is the organization last switched to, used for the next login
+	ActiveOrgID *uint `json:"active_org_id"`
+}
+
+// IsVerified reports whether the user has confirmed their email address
+func (u *User) IsVerified() bool {
+	return u.VerifiedAt != nil
+}
+
+// IsDisabled reports whether an administrator has disabled the account
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// MFAEnabled reports whether the user completed two-factor enrollment
+func (u *User) MFAEnabled() bool {
+	return u.MFAEnabledAt != nil
+}
+
+// IsValidRole reports whether role is one of the known roles
+func IsValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleEditor || role == RoleViewer
 }
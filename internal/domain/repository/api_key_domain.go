@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// APIKeyRepositoryInterface defines the interface for API key data access operations
+type APIKeyRepositoryInterface interface {
+	Create(key *model.APIKey) error
+	FindByHash(keyHash string) (*model.APIKey, error)
+	FindByID(id uint) (*model.APIKey, error)
+	List() ([]*model.APIKey, error)
+	Revoke(id uint) error
+	TouchLastUsed(id uint, usedAt time.Time) error
+}
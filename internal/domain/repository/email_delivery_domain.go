@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// EmailDeliveryRepositoryInterface defines the data access operations for the email delivery log
+type EmailDeliveryRepositoryInterface interface {
+	// RecordAttempt stores the outcome of an attempt, counting it in the delivery with the same key when there is one,
+	// and returns the delivery as stored
+	RecordAttempt(ctx context.Context, attempt *model.EmailDelivery) (*model.EmailDelivery, error)
+	// Search returns a page of the deliveries matching the filter, newest first, with the total number of matches
+	Search(filter model.EmailDeliveryFilter, offset, limit int) ([]*model.EmailDelivery, int64, error)
+	// FindByID returns nil when there is no delivery with the ID
+	FindByID(id uint) (*model.EmailDelivery, error)
+	// RedactBefore clears the recipients and content of the deliveries last attempted before the given instant
+	RedactBefore(ctx context.Context, before time.Time) (int64, error)
+	// DeleteBefore removes the deliveries last attempted before the given instant
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// InboxRepositoryInterface defines the data access operations for the in-app notification inbox
+type InboxRepositoryInterface interface {
+	Add(ctx context.Context, notifications []*model.InboxNotification) error
+	// List returns a page of the user's notifications, newest first, with the total number of matches
+	List(userID uint, unreadOnly bool, offset, limit int) ([]*model.InboxNotification, int64, error)
+	CountUnread(userID uint) (int64, error)
+	// SetReadAt marks one notification of the user as read at the given time, or unread when it is nil;
+	// it returns nil when the user has no such notification
+	SetReadAt(userID, id uint, readAt *time.Time) (*model.InboxNotification, error)
+	// MarkAllRead marks every unread notification of the user as read and returns how many were changed
+	MarkAllRead(userID uint, readAt time.Time) (int64, error)
+}
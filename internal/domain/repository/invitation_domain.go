@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// InvitationRepositoryInterface defines the interface for invitation data access operations
+type InvitationRepositoryInterface interface {
+	Create(invitation *model.Invitation) error
+	FindByID(id uint) (*model.Invitation, error)
+	FindByHash(tokenHash string) (*model.Invitation, error)
+	ListByOrg(orgID uint) ([]*model.Invitation, error)
+	Update(invitation *model.Invitation) error
+	MarkAccepted(id uint) (bool, error)
+	Revoke(id uint) (bool, error)
+	RevokePending(orgID uint, email string) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// LoginAttemptRepositoryInterface defines the interface for login throttling and authentication audit data access
+type LoginAttemptRepositoryInterface interface {
+	FindThrottle(key string) (*model.LoginThrottle, error)
+	// RegisterFailure atomically counts a failure for the key and returns its failures, starting over at one
+	// when the previous failures fell out of the window or their lockout elapsed
+	RegisterFailure(key string, now time.Time, window time.Duration) (int, error)
+	// LockThrottle locks the key until the given time unless it is already locked, reporting whether it did
+	LockThrottle(key string, until, now time.Time) (bool, error)
+	DeleteThrottle(key string) error
+	RecordAudit(entry *model.AuthAuditEntry) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// MFARepositoryInterface defines the interface for recovery codes and two-factor policies
+type MFARepositoryInterface interface {
+	ReplaceRecoveryCodes(userID uint, codes []*model.MFARecoveryCode) error
+	UseRecoveryCode(userID uint, codeHash string) (bool, error)
+	DeleteRecoveryCodes(userID uint) error
+	FindPolicy(role string) (*model.MFAPolicy, error)
+	ListPolicies() ([]*model.MFAPolicy, error)
+	SavePolicy(policy *model.MFAPolicy) error
+}
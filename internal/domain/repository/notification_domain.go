@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// NotificationRepositoryInterface defines the data access operations for notification preferences and digests
+type NotificationRepositoryInterface interface {
+	FindPreferences(userID uint) ([]*model.NotificationPreference, error)
+	// SavePreferences creates or updates the user's preference for each given event
+	SavePreferences(userID uint, preferences []*model.NotificationPreference) error
+	// IsEmailUnsubscribed reports whether an address without an account turned off its notifications
+	IsEmailUnsubscribed(email string) (bool, error)
+	// UnsubscribeEmail turns off the notifications of an address without an account; it is idempotent
+	UnsubscribeEmail(email string) error
+	// AddDigestEntries stores notifications for their digest, skipping events already stored for the user
+	AddDigestEntries(ctx context.Context, entries []*model.DigestEntry) error
+	// ClaimDueDigestEntries returns up to limit due entries, hiding them from other workers for the lease
+	ClaimDueDigestEntries(ctx context.Context, limit int, lease time.Duration) ([]*model.DigestEntry, error)
+	DeleteDigestEntries(ctx context.Context, ids []uint) error
+}
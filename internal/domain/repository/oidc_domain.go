@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// OIDCStateRepositoryInterface defines the interface for pending OIDC login states
+type OIDCStateRepositoryInterface interface {
+	Create(state *model.OIDCLoginState) error
+	Consume(stateHash string) (*model.OIDCLoginState, error)
+}
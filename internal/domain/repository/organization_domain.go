@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// OrganizationRepositoryInterface defines the interface for organizations and their memberships
+type OrganizationRepositoryInterface interface {
+	Create(org *model.Organization) error
+	FindByID(id uint) (*model.Organization, error)
+	FindBySlug(slug string) (*model.Organization, error)
+	List() ([]*model.Organization, error)
+	FindMembership(userID, orgID uint) (*model.Membership, error)
+	ListMemberships(userID uint) ([]*model.Membership, error)
+	ListMembers(orgID uint) ([]*model.Membership, error)
+	CountAdmins(orgID uint) (int64, error)
+	SaveMembership(membership *model.Membership) error
+	DeleteMembership(userID, orgID uint) (bool, error)
+}
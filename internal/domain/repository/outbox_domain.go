@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// OutboxRepositoryInterface defines the data access operations of the relay publishing outbox events
+// Events are added by the repositories whose changes they describe, inside the same transaction
+type OutboxRepositoryInterface interface {
+	// ClaimPending returns up to limit unsent events that are due, hiding them from other relays for the lease
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEvent, error)
+	MarkSent(ctx context.Context, id uint) error
+	// MarkFailed records a failed publication and schedules the next attempt
+	MarkFailed(ctx context.Context, id uint, lastError string, retryAt time.Time) error
+	DeleteSentBefore(ctx context.Context, before time.Time) (int64, error)
+}
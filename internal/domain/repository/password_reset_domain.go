@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// PasswordResetRepositoryInterface defines the interface for password reset token data access operations
+type PasswordResetRepositoryInterface interface {
+	Create(token *model.PasswordResetToken) error
+	FindByHash(tokenHash string) (*model.PasswordResetToken, error)
+	MarkUsed(id uint) (bool, error)
+	InvalidateForUser(userID uint) error
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// ProcessedMessageRepositoryInterface defines the data access operations of the store of processed messages,
+// keyed by consumer and message ID
+type ProcessedMessageRepositoryInterface interface {
+	// Processed returns the IDs among ids that the consumer already processed
+	Processed(ctx context.Context, consumer string, ids []string) (map[string]bool, error)
+	// MarkProcessed records messages as processed; those already recorded are left unchanged
+	MarkProcessed(ctx context.Context, messages []*model.ProcessedMessage) error
+	DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error)
+}
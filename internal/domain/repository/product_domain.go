@@ -7,10 +7,12 @@ import (
 )
 
 // UserRepository defines the interface for user data access operations
+// Create, Update and Delete store the event of each product, keyed by SKU, in the outbox
+// within the same transaction as the change
 type ProductRepositoryInterface interface {
-	Create(ctx context.Context, products []*model.Product) map[int]string
+	Create(ctx context.Context, products []*model.Product, events map[int]*model.OutboxEvent) map[int]string
 	GetAll(ctx context.Context) ([]*model.Product, error)
 	GetBySKU(ctx context.Context, sku int) (*model.Product, error)
-	Update(ctx context.Context, products []*model.Product) map[int]string
-	Delete(ctx context.Context, skus []int) map[int]string
+	Update(ctx context.Context, products []*model.Product, events map[int]*model.OutboxEvent) map[int]string
+	Delete(ctx context.Context, skus []int, events map[int]*model.OutboxEvent) map[int]string
 }
@@ -4,8 +4,14 @@ import (
 	"github.com/Amandasilvbr/products-crud/internal/domain/model"
 )
 
-// UserRepository defines the interface for user data access operations
+// UserRepositoryInterface defines the interface for user data access operations
 type UserRepositoryInterface interface {
 	FindByEmail(email string) (*model.User, error)
+	FindByID(id uint) (*model.User, error)
+	FindByOIDCSubject(subject string) (*model.User, error)
 	Create(user *model.User) error
+	Update(user *model.User) error
+	List(search string, offset, limit int) ([]*model.User, int64, error)
+	NameTaken(name string, exceptID uint) (bool, error)
+	Delete(id uint) error
 }
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// TokenRepositoryInterface defines the interface for refresh token and revocation data access operations
+type TokenRepositoryInterface interface {
+	CreateRefreshToken(token *model.RefreshToken) error
+	FindRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error)
+	// RotateRefreshToken returns false when the old token was revoked concurrently, leaving the replacement unsaved
+	RotateRefreshToken(oldID uint, newToken *model.RefreshToken) (bool, error)
+	RevokeRefreshToken(id uint) error
+	RevokeFamily(familyID string) error
+	RevokeAllForUser(userID uint) error
+	RevokeJTI(jti string, expiresAt time.Time) error
+	IsJTIRevoked(jti string) (bool, error)
+}
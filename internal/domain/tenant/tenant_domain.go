@@ -0,0 +1,24 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoOrganization is returned by tenant-scoped repositories when the context carries no organization
+var ErrNoOrganization = errors.New("no active organization")
+
+// orgKey is the context key holding the active organization ID
+type orgKey struct{}
+
+// WithOrg returns a copy of the context scoped to the given organization
+// The JWT middleware sets it on every request made within an active organization
+func WithOrg(ctx context.Context, orgID uint) context.Context {
+	return context.WithValue(ctx, orgKey{}, orgID)
+}
+
+// OrgID returns the organization the context is scoped to
+func OrgID(ctx context.Context) (uint, bool) {
+	orgID, ok := ctx.Value(orgKey{}).(uint)
+	return orgID, ok && orgID != 0
+}
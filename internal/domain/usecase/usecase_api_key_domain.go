@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// APIKeyUsecaseInterface defines the interface for API key management and authentication
+type APIKeyUsecaseInterface interface {
+	CreateKey(name string, scopes []string, expiresAt *time.Time, ownerID, orgID, createdByID uint) (*model.APIKey, string, error)
+	ListKeys() ([]*model.APIKey, error)
+	RevokeKey(id uint) error
+	Authenticate(rawKey string) (*model.Actor, *model.APIKey, error)
+}
@@ -1,7 +1,21 @@
 package usecase
 
+import (
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
 // AuthUsecaseInterface defines the interface for authentication-related use cases
 type AuthUsecaseInterface interface {
-	Login(email, password string) (string, error)
+	Login(email, password, ip string) (*model.LoginResult, error)
+	CompleteMFA(mfaToken, code, ip string) (*model.LoginResult, error)
+	BeginMFAEnrollment(mfaToken string) (*model.MFAEnrollment, error)
+	StartSession(user *model.User) (*model.TokenPair, error)
 	CreateUser(name, email, password string) error
+	RefreshToken(refreshToken string) (*model.TokenPair, error)
+	SwitchOrganization(userID, orgID uint) (*model.TokenPair, error)
+	Logout(userID uint, refreshToken, jti string, accessExpiresAt time.Time) error
+	RevokeAllSessions(userID uint) error
+	ValidateAccessToken(jti string, userID uint, issuedAt time.Time) error
 }
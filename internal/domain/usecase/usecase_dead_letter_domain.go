@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+)
+
+// DeadLetterUsecaseInterface defines the administration of the dead-letter queues of the consumers
+type DeadLetterUsecaseInterface interface {
+	// Queues returns the work queues whose dead-letter queue can be administered
+	Queues() []string
+	Peek(ctx context.Context, queueName string, limit int) ([]messaging.DeadLetter, error)
+	// Requeue moves dead-lettered messages back to their work queue; a limit of zero moves all of them
+	Requeue(ctx context.Context, queueName string, limit int) (int, error)
+	Purge(ctx context.Context, queueName string) (int, error)
+}
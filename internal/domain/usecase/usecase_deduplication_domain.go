@@ -0,0 +1,14 @@
+package usecase
+
+import "context"
+
+// MessageDeduplicatorInterface defines how consumers recognize the messages they already processed
+// Each consumer is a separate scope, so that the same message processed by two subscribers is not a duplicate
+type MessageDeduplicatorInterface interface {
+	// Processed returns the IDs among ids that the consumer already processed
+	Processed(ctx context.Context, consumer string, ids []string) (map[string]bool, error)
+	// Record durably marks messages as processed; consumers call it after the side effects and before acknowledging
+	Record(ctx context.Context, consumer string, ids []string) error
+	// RunCleanup forgets the messages processed longer than the retention ago, until the context is cancelled
+	RunCleanup(ctx context.Context) error
+}
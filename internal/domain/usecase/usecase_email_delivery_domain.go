@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// EmailDeliveryUsecaseInterface defines the email delivery log
+// It is the Mailer of notification emails: each attempt to send one is recorded with its SMTP reply
+type EmailDeliveryUsecaseInterface interface {
+	messaging.Mailer
+	// Search returns one page of the deliveries matching the filter, with the total number of matches
+	Search(filter model.EmailDeliveryFilter, page, pageSize int) ([]*model.EmailDelivery, int64, error)
+	Get(id uint) (*model.EmailDelivery, error)
+	// Resend sends the recorded content of a delivery again, to the same recipients, as a new delivery
+	Resend(ctx context.Context, id uint) (*model.EmailDelivery, error)
+	// RunRetention redacts and removes old deliveries every hour until the context is cancelled
+	RunRetention(ctx context.Context) error
+}
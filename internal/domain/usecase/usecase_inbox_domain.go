@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// InboxUsecaseInterface defines the in-app notification inbox, which is also a notification channel
+type InboxUsecaseInterface interface {
+	messaging.Notifier
+	// List returns one page of the user's notifications, with the total number of matches and of unread ones
+	List(userID uint, unreadOnly bool, page, pageSize int) ([]*model.InboxNotification, int64, int64, error)
+	// MarkRead marks one notification of the user as read, or unread when read is false
+	MarkRead(userID, id uint, read bool) (*model.InboxNotification, error)
+	// MarkAllRead marks every notification of the user as read and returns how many were unread
+	MarkAllRead(userID uint) (int64, error)
+}
@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// InvitationUsecaseInterface defines the interface for invitation-based onboarding
+type InvitationUsecaseInterface interface {
+	CreateInvitation(actor *model.Actor, orgID uint, email, role string, expiresAt *time.Time) (*model.Invitation, error)
+	ListInvitations(actor *model.Actor, orgID uint) ([]*model.Invitation, error)
+	ResendInvitation(actor *model.Actor, orgID, invitationID uint) (*model.Invitation, error)
+	RevokeInvitation(actor *model.Actor, orgID, invitationID uint) error
+	AcceptInvitation(rawToken, name, password string) (*model.User, error)
+}
@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// LockoutUsecaseInterface defines the interface for login brute-force protection use cases
+type LockoutUsecaseInterface interface {
+	Check(email, ip string) error
+	RecordFailure(email, ip string, user *model.User, outcome string)
+	RecordSuccess(email, ip string, user *model.User, outcome string)
+	Unlock(userID uint, adminID uint) error
+}
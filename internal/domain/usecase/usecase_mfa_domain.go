@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// MFAUsecaseInterface defines the interface for two-factor authentication use cases
+type MFAUsecaseInterface interface {
+	Challenge(user *model.User) (*model.LoginResult, error)
+	ParseChallenge(token string) (userID uint, enrollment bool, err error)
+	Verify(user *model.User, code string) error
+	BeginEnrollment(userID uint) (*model.MFAEnrollment, error)
+	ConfirmEnrollment(userID uint, code string) ([]string, error)
+	RegenerateRecoveryCodes(userID uint, code string) ([]string, error)
+	Disable(userID uint, code string) error
+	Reset(userID uint, adminID uint) error
+	ListPolicies() ([]*model.MFAPolicy, error)
+	SetPolicy(role string, required bool) error
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// NotificationUsecaseInterface defines the notification preferences of users and the delivery of digests
+type NotificationUsecaseInterface interface {
+	// GetPreferences returns the frequency of every configurable event, including the defaults, with
+	// the locale and time zone of the emails
+	GetPreferences(userID uint) (*model.NotificationSettings, error)
+	// UpdatePreferences changes the given frequencies, and the locale and time zone when they are not empty
+	UpdatePreferences(userID uint, settings model.NotificationSettings) (*model.NotificationSettings, error)
+	// Unsubscribe turns off the notifications of the user identified by the token of an email link;
+	// an empty event turns off every event
+	Unsubscribe(token, event string) error
+	// Route stores the notifications the recipient wants in a digest and returns, for each notification,
+	// the frequency it was routed to, with the email of the immediate ones, or nil when there are none
+	Route(ctx context.Context, recipient string, notifications []model.ProductNotification) ([]string, *messaging.Email, error)
+	// PreviewDigest renders a sample digest of the given frequency in a locale and time zone
+	PreviewDigest(frequency, locale, timeZone string) (*messaging.Email, error)
+	// RunDigests sends the due digests every minute until the context is cancelled
+	RunDigests(ctx context.Context) error
+	// FlushDigests sends the due digests once and returns how many emails were sent
+	FlushDigests(ctx context.Context) (int, error)
+}
+
+// NotificationDispatcherInterface delivers product notifications to the channels chosen by the routing rules
+type NotificationDispatcherInterface interface {
+	// Dispatch sends each notification to the channels its event is routed to
+	// The error wraps messaging.ErrPermanentDelivery only when every failed channel failed permanently
+	Dispatch(ctx context.Context, recipient string, notifications []model.ProductNotification) error
+}
@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// OIDCUsecaseInterface defines the interface for single sign-on through an OpenID Connect identity provider
+type OIDCUsecaseInterface interface {
+	BeginLogin() (string, error)
+	CompleteLogin(ctx context.Context, state, code, ip string) (*model.TokenPair, error)
+}
@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// OrganizationUsecaseInterface defines the interface for organizations and their memberships
+type OrganizationUsecaseInterface interface {
+	CreateOrganization(name, slug string) (*model.Organization, error)
+	ListOrganizations() ([]*model.Organization, error)
+	ListMemberships(userID uint) ([]*model.Membership, error)
+	ListMembers(actor *model.Actor, orgID uint) ([]*model.Membership, error)
+	AddMember(actor *model.Actor, orgID uint, email, role string) (*model.Membership, error)
+	UpdateMember(actor *model.Actor, orgID, userID uint, role string) (*model.Membership, error)
+	RemoveMember(actor *model.Actor, orgID, userID uint) error
+	ResolveMembership(userID uint, platformRole string, orgID uint) (*model.Membership, error)
+	DefaultMembership(user *model.User) (*model.Membership, error)
+	AuthorizeAdmin(actor *model.Actor, orgID uint) (*model.Membership, error)
+}
@@ -0,0 +1,11 @@
+package usecase
+
+import "context"
+
+// OutboxRelayInterface defines the relay publishing the events stored in the outbox to RabbitMQ
+type OutboxRelayInterface interface {
+	// Run publishes due events at every poll interval until the context is cancelled
+	Run(ctx context.Context) error
+	// Flush publishes one batch of due events and returns how many were sent
+	Flush(ctx context.Context) (int, error)
+}
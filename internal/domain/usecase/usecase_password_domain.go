@@ -0,0 +1,8 @@
+package usecase
+
+// PasswordUsecaseInterface defines the interface for password recovery and change use cases
+type PasswordUsecaseInterface interface {
+	ForgotPassword(email string) error
+	ResetPassword(token, newPassword string) error
+	ChangePassword(userID uint, currentPassword, newPassword string) error
+}
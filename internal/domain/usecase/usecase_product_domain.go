@@ -8,9 +8,9 @@ import (
 
 // ProductUseCaseInterface defines the interface for product-related use cases
 type ProductUseCaseInterface interface {
-	Create(context.Context, []*model.Product, string) (map[int]string, map[int]string)
+	Create(context.Context, []*model.Product, *model.Actor) map[int]string
 	GetAll(ctx context.Context) ([]*model.Product, error)
 	GetBySKU(ctx context.Context, sku int) (*model.Product, error)
-	Update(ctx context.Context, products []*model.Product, userEmail string) map[int]string
-	Delete(ctx context.Context, skus []int, userEmail string) map[int]string
+	Update(ctx context.Context, products []*model.Product, actor *model.Actor) map[int]string
+	Delete(ctx context.Context, skus []int, actor *model.Actor) map[int]string
 }
@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+)
+
+// UserUsecaseInterface defines the interface for user administration use cases
+type UserUsecaseInterface interface {
+	AssignRole(userID uint, role string) error
+	EnsureAdmins(emails []string) error
+	ListUsers(search string, page, pageSize int) ([]*model.User, int64, error)
+	GetUser(userID uint) (*model.User, error)
+	UpdateUser(userID uint, name, email string) (*model.User, error)
+	SetDisabled(userID uint, disabled bool, actorID uint) error
+	DeleteUser(userID uint, actorID uint) error
+}
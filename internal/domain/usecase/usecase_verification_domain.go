@@ -0,0 +1,8 @@
+package usecase
+
+// VerificationUsecaseInterface defines the interface for email verification use cases
+type VerificationUsecaseInterface interface {
+	SendVerification(email string) error
+	VerifyEmail(token string) error
+	ResendVerification(email string) error
+}
@@ -0,0 +1,32 @@
+package dtos
+
+import "time"
+
+// CreateAPIKeyDTO represents the data transfer object for creating an API key
+type CreateAPIKeyDTO struct {
+	Name      string     `json:"name" validate:"required,min=3,max=100" example:"erp-integration"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=products:read products:write" example:"products:read,products:write"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty"`
+	OwnerID   uint       `json:"owner_id" validate:"omitempty" example:"7"`
+	OrgID     uint       `json:"org_id" validate:"omitempty" example:"3"`
+}
+
+// APIKeyResponseDTO represents the data transfer object for returning API key information
+type APIKeyResponseDTO struct {
+	ID         uint       `json:"id" example:"1"`
+	Name       string     `json:"name" example:"erp-integration"`
+	Prefix     string     `json:"prefix" example:"pck_Ab12Cd34"`
+	Scopes     []string   `json:"scopes"`
+	OwnerID    uint       `json:"owner_id" example:"7"`
+	OrgID      uint       `json:"org_id" example:"3"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponseDTO represents the response to an API key creation, the only one carrying the raw key
+type CreateAPIKeyResponseDTO struct {
+	APIKeyResponseDTO
+	Key string `json:"key" example:"pck_Ab12Cd34..."`
+}
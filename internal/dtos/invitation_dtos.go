@@ -0,0 +1,32 @@
+package dtos
+
+import "time"
+
+// CreateInvitationDTO represents the data transfer object for inviting someone to an organization
+type CreateInvitationDTO struct {
+	Email     string     `json:"email" validate:"required,email" example:"maria@example.com"`
+	Role      string     `json:"role" validate:"required,oneof=admin editor viewer" example:"editor"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty"`
+}
+
+// AcceptInvitationDTO represents the data transfer object for creating an account from an invitation
+type AcceptInvitationDTO struct {
+	Name     string `json:"name" validate:"required,min=3,max=100" example:"Maria Silva"`
+	Password string `json:"password" validate:"required,min=6" example:"s3nh4-f0rt3"`
+}
+
+// InvitationResponseDTO represents the data transfer object for returning an invitation
+// The token is never returned; it is only sent to the invitee by email
+type InvitationResponseDTO struct {
+	ID          uint       `json:"id" example:"12"`
+	OrgID       uint       `json:"org_id" example:"3"`
+	Email       string     `json:"email" example:"maria@example.com"`
+	Role        string     `json:"role" example:"editor"`
+	Status      string     `json:"status" example:"pending"`
+	InvitedByID uint       `json:"invited_by_id" example:"1"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	SentAt      time.Time  `json:"sent_at"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,56 @@
+package dtos
+
+// MFACodeDTO represents the data transfer object carrying a TOTP or recovery code
+type MFACodeDTO struct {
+	Code string `json:"code" validate:"required" example:"123456"`
+}
+
+// LoginMFADTO represents the data transfer object for completing a login with a second factor
+type LoginMFADTO struct {
+	MFAToken string `json:"mfa_token" validate:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Code     string `json:"code" validate:"required" example:"123456"`
+}
+
+// MFATokenDTO represents the data transfer object for starting enrollment during login
+type MFATokenDTO struct {
+	MFAToken string `json:"mfa_token" validate:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+}
+
+// SetMFAPolicyDTO represents the data transfer object for changing the two-factor policy of a role
+type SetMFAPolicyDTO struct {
+	Required *bool `json:"required" validate:"required" example:"true"`
+}
+
+// MFAChallengeResponse is returned by the login step when a second factor is needed
+type MFAChallengeResponse struct {
+	MFARequired        bool   `json:"mfa_required" example:"true"`
+	MFAToken           string `json:"mfa_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	EnrollmentRequired bool   `json:"enrollment_required" example:"false"`
+}
+
+// MFALoginResponse is returned when a login is completed with a second factor
+// Recovery codes are only present when the login also completed enrollment
+type MFALoginResponse struct {
+	Token         string   `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken  string   `json:"refresh_token" example:"q8Zk3v2m9YbT0xWc..."`
+	ExpiresIn     int64    `json:"expires_in" example:"900"`
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// MFAEnrollmentResponse carries the TOTP secret to add to an authenticator app
+// The otpauth URI is the payload to render as a QR code
+type MFAEnrollmentResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	OTPAuthURI string `json:"otpauth_uri" example:"otpauth://totp/Products%20CRUD:amanda@example.com?algorithm=SHA1&digits=6&issuer=Products+CRUD&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+}
+
+// RecoveryCodesResponse carries one-time recovery codes, shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k3f9q-2mx7d,p8w2z-n4c6v"`
+}
+
+// MFAPolicyDTO represents the two-factor policy of a role
+type MFAPolicyDTO struct {
+	Role     string `json:"role" example:"editor"`
+	Required bool   `json:"required" example:"true"`
+}
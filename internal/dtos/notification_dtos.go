@@ -0,0 +1,81 @@
+package dtos
+
+import "time"
+
+// NotificationPreferencesDTO represents the notification frequency of each product event and the email locale
+// Frequencies are immediate, hourly, daily or off; events left out of an update keep their frequency, and empty
+// locale or time zone keep the current ones
+type NotificationPreferencesDTO struct {
+	Preferences map[string]string `json:"preferences" example:"product_created:immediate,product_updated:daily,product_deleted:off"`
+	Locale      string            `json:"locale,omitempty" example:"pt-BR"`
+	TimeZone    string            `json:"time_zone,omitempty" example:"America/Sao_Paulo"`
+}
+
+// EmailPreviewResponse represents a rendered notification email
+type EmailPreviewResponse struct {
+	Subject string `json:"subject" example:"Resumo de Notificações de Produtos: 2 Produtos foram criados"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// InboxNotificationDTO represents a notification of the in-app inbox
+type InboxNotificationDTO struct {
+	ID        uint       `json:"id" example:"1"`
+	Event     string     `json:"event" example:"product_created"`
+	SKU       int        `json:"sku" example:"1001"`
+	Name      string     `json:"name" example:"Caneta"`
+	OrgID     uint       `json:"org_id" example:"1"`
+	OrgName   string     `json:"org_name" example:"Acme"`
+	Read      bool       `json:"read" example:"false"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// InboxListResponseDTO represents one page of the inbox, with the number of unread notifications
+type InboxListResponseDTO struct {
+	Items    []InboxNotificationDTO `json:"items"`
+	Page     int                    `json:"page" example:"1"`
+	PageSize int                    `json:"page_size" example:"20"`
+	Total    int64                  `json:"total" example:"42"`
+	Unread   int64                  `json:"unread" example:"3"`
+}
+
+// InboxReadAllResponseDTO represents how many notifications were marked as read
+type InboxReadAllResponseDTO struct {
+	Updated int64 `json:"updated" example:"3"`
+}
+
+// EmailDeliveryDTO represents an entry of the email delivery log
+// Recipients are empty once the delivery is redacted
+type EmailDeliveryDTO struct {
+	ID             uint       `json:"id" example:"1"`
+	Kind           string     `json:"kind" example:"product_notification"`
+	Recipients     []string   `json:"recipients" example:"joao@example.com"`
+	Subject        string     `json:"subject" example:"Resumo de Notificações de Produtos: 2 Produtos foram criados"`
+	EventIDs       []string   `json:"event_ids" example:"0b6f3c1e-9a4d-4f8e-8a57-2d1f0c9e7b31"`
+	Status         string     `json:"status" example:"sent"`
+	ResponseCode   int        `json:"response_code,omitempty" example:"550"`
+	Response       string     `json:"response,omitempty" example:"mailbox unavailable"`
+	Attempts       int        `json:"attempts" example:"1"`
+	FirstAttemptAt time.Time  `json:"first_attempt_at"`
+	LastAttemptAt  time.Time  `json:"last_attempt_at"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+	ResendOf       *uint      `json:"resend_of,omitempty"`
+	Redacted       bool       `json:"redacted" example:"false"`
+	RedactedAt     *time.Time `json:"redacted_at,omitempty"`
+}
+
+// EmailDeliveryDetailDTO represents an entry of the email delivery log with the content that was sent
+type EmailDeliveryDetailDTO struct {
+	EmailDeliveryDTO
+	Text string `json:"text"`
+	HTML string `json:"html"`
+}
+
+// EmailDeliveryListResponseDTO represents one page of the email delivery log
+type EmailDeliveryListResponseDTO struct {
+	Items    []EmailDeliveryDTO `json:"items"`
+	Page     int                `json:"page" example:"1"`
+	PageSize int                `json:"page_size" example:"20"`
+	Total    int64              `json:"total" example:"42"`
+}
@@ -0,0 +1,38 @@
+package dtos
+
+import "time"
+
+// CreateOrganizationDTO represents the data transfer object for creating an organization
+type CreateOrganizationDTO struct {
+	Name string `json:"name" validate:"required,min=2,max=100" example:"Acme Store"`
+	Slug string `json:"slug" validate:"required,min=2,max=63" example:"acme-store"`
+}
+
+// AddMemberDTO represents the data transfer object for adding a user to an organization
+type AddMemberDTO struct {
+	Email string `json:"email" validate:"required,email" example:"maria@example.com"`
+	Role  string `json:"role" validate:"required,oneof=admin editor viewer" example:"editor"`
+}
+
+// OrganizationResponseDTO represents the data transfer object for returning an organization
+type OrganizationResponseDTO struct {
+	ID        uint      `json:"id" example:"3"`
+	Name      string    `json:"name" example:"Acme Store"`
+	Slug      string    `json:"slug" example:"acme-store"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MembershipResponseDTO represents one of the current user's organizations and their role in it
+type MembershipResponseDTO struct {
+	OrganizationResponseDTO
+	Role   string `json:"role" example:"editor"`
+	Active bool   `json:"active" example:"true"`
+}
+
+// MemberResponseDTO represents a member of an organization
+type MemberResponseDTO struct {
+	UserID uint   `json:"user_id" example:"7"`
+	Name   string `json:"name" example:"Maria Silva"`
+	Email  string `json:"email" example:"maria@example.com"`
+	Role   string `json:"role" example:"editor"`
+}
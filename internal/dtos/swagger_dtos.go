@@ -1,8 +1,17 @@
 package dtos
 
-// LoginResponse defines the structure for a successful login response.
+import "time"
+
+// LoginResponse defines the structure for a successful login or token refresh response.
 type LoginResponse struct {
-	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Token        string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string `json:"refresh_token" example:"q8Zk3v2m9YbT0xWc..."`
+	ExpiresIn    int64  `json:"expires_in" example:"900"`
+}
+
+// MessageResponse defines the structure for a generic successful response carrying a message.
+type MessageResponse struct {
+	Message string `json:"message" example:"Logged out successfully"`
 }
 
 // CreateUserResponse defines the structure for a successful user creation response.
@@ -34,4 +43,32 @@ type UpdateProductResponse struct {
 type DeleteProductResponse struct {
 	Message string        `json:"message" example:"Product(s) deleted successfully"`
 	Results []BatchResult `json:"results"`
-}
\ No newline at end of file
+}
+
+// HealthResponse defines the structure for the health check response.
+type HealthResponse struct {
+	Status   string `json:"status" example:"ok"`
+	RabbitMQ string `json:"rabbitmq" example:"connected"`
+}
+
+// DeadLetterResponse defines the structure of a message held in a dead-letter queue.
+type DeadLetterResponse struct {
+	MessageID string    `json:"message_id" example:"5f2b6c1e9a7d4e3f8b0c1d2e3f4a5b6c"`
+	Body      string    `json:"body" example:"{\"event\":\"product_created\"}"`
+	Attempts  int       `json:"attempts" example:"5"`
+	LastError string    `json:"last_error" example:"dial tcp: connection refused"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DeadLetterListResponse defines the structure for the listing of a dead-letter queue.
+type DeadLetterListResponse struct {
+	Queue    string               `json:"queue" example:"product_events"`
+	Messages []DeadLetterResponse `json:"messages"`
+}
+
+// DeadLetterCountResponse defines the structure for a requeue or purge of a dead-letter queue.
+type DeadLetterCountResponse struct {
+	Message string `json:"message" example:"Dead-lettered messages requeued"`
+	Queue   string `json:"queue" example:"product_events"`
+	Count   int    `json:"count" example:"3"`
+}
@@ -1,5 +1,7 @@
 package dtos
 
+import "time"
+
 // UserLoginDTO represents the data transfer object for user login
 type UserLoginDTO struct {
     Email    string `json:"email" validate:"required,email"`
@@ -11,4 +13,63 @@ type UserCreateDTO struct {
     Name     string `json:"name" validate:"required,min=3,max=100"`
     Email    string `json:"email" validate:"required,email"`
     Password string `json:"password" validate:"required,min=6"`
-}
\ No newline at end of file
+}
+// RefreshTokenDTO represents the data transfer object for refreshing or revoking a session
+type RefreshTokenDTO struct {
+    RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// AssignRoleDTO represents the data transfer object for changing a user's role
+type AssignRoleDTO struct {
+    Role string `json:"role" validate:"required,oneof=admin editor viewer"`
+}
+
+// ForgotPasswordDTO represents the data transfer object for requesting a password reset email
+type ForgotPasswordDTO struct {
+    Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordDTO represents the data transfer object for setting a new password with a reset token
+type ResetPasswordDTO struct {
+    Token       string `json:"token" validate:"required"`
+    NewPassword string `json:"new_password" validate:"required,min=6"`
+}
+
+// ChangePasswordDTO represents the data transfer object for changing the password of a logged-in user
+type ChangePasswordDTO struct {
+    CurrentPassword string `json:"current_password" validate:"required"`
+    NewPassword     string `json:"new_password" validate:"required,min=6"`
+}
+
+// ResendVerificationDTO represents the data transfer object for requesting a new verification email
+type ResendVerificationDTO struct {
+    Email string `json:"email" validate:"required,email"`
+}
+
+// UpdateUserDTO represents the data transfer object for editing a user's profile
+// Empty fields are left unchanged
+type UpdateUserDTO struct {
+    Name  string `json:"name" validate:"omitempty,min=3,max=100" example:"Amanda Silva"`
+    Email string `json:"email" validate:"omitempty,email" example:"amanda@example.com"`
+}
+
+// UserResponseDTO represents the data transfer object for returning user information
+type UserResponseDTO struct {
+    ID         uint       `json:"id" example:"7"`
+    Name       string     `json:"name" example:"Amanda Silva"`
+    Email      string     `json:"email" example:"amanda@example.com"`
+    Role       string     `json:"role" example:"editor"`
+    VerifiedAt *time.Time `json:"verified_at"`
+    DisabledAt *time.Time `json:"disabled_at"`
+    MFAEnabled bool       `json:"mfa_enabled" example:"true"`
+    CreatedAt  time.Time  `json:"created_at"`
+    UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// UserListResponseDTO represents one page of users
+type UserListResponseDTO struct {
+    Items    []UserResponseDTO `json:"items"`
+    Page     int               `json:"page" example:"1"`
+    PageSize int               `json:"page_size" example:"20"`
+    Total    int64             `json:"total" example:"42"`
+}
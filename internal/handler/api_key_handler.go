@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	"github.com/Amandasilvbr/products-crud/internal/dtos"
+	"github.com/Amandasilvbr/products-crud/internal/handler/validator"
+	uc "github.com/Amandasilvbr/products-crud/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// APIKeyHandler handles HTTP requests for managing API keys
+type APIKeyHandler struct {
+	apiKeyUsecase usecase.APIKeyUsecaseInterface
+	validator     *validator.APIKeyValidator
+	logger        *zap.Logger
+}
+
+// NewAPIKeyHandler creates and returns a new instance of APIKeyHandler
+func NewAPIKeyHandler(apiKeyUsecase usecase.APIKeyUsecaseInterface, logger *zap.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUsecase: apiKeyUsecase,
+		validator:     validator.NewAPIKeyValidator(),
+		logger:        logger,
+	}
+}
+
+// Create godoc
+//
+//	@Summary		Cria uma chave de API
+//	@Description	Cria uma chave de API com escopos (products:read, products:write) e expiração opcional. A chave atua em nome do usuário dono (por padrão, o administrador que a criou) dentro de uma organização (por padrão, a organização ativa do administrador) e é exibida apenas nesta resposta.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			key	body		dtos.CreateAPIKeyDTO			true	"API key data"
+//	@Success		201	{object}	dtos.CreateAPIKeyResponseDTO	"API key created successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/api-keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var input dtos.CreateAPIKeyDTO
+	// Bind the incoming JSON payload to the CreateAPIKeyDTO struct
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Debug("Invalid API key request body", zap.Error(err), zap.String("operation", "create_api_key"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body format",
+		})
+		return
+	}
+
+	// Validate the input data using the API key validator
+	if errors := h.validator.ValidateCreateAPIKey(&input); len(errors) > 0 {
+		h.logger.Warn("Validation failed for API key creation", zap.Any("errors", errors), zap.String("operation", "create_api_key"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": errors,
+		})
+		return
+	}
+
+	actor, ok := actorFromContext(c)
+	if !ok {
+		h.logger.Error("User not found in context", zap.String("operation", "create_api_key"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	// Keys act on behalf of the creating admin unless another owner is given
+	ownerID := input.OwnerID
+	if ownerID == 0 {
+		ownerID = actor.ID
+	}
+
+	// Keys are bound to the admin's active organization unless another one is given
+	orgID := input.OrgID
+	if orgID == 0 {
+		orgID = actor.OrgID
+	}
+
+	key, rawKey, err := h.apiKeyUsecase.CreateKey(input.Name, input.Scopes, input.ExpiresAt, ownerID, orgID, actor.ID)
+	if err != nil {
+		if errors.Is(err, uc.ErrInvalidKeyOwner) || errors.Is(err, uc.ErrInvalidScope) || errors.Is(err, uc.ErrOrgNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to create API key", zap.Error(err), zap.String("operation", "create_api_key"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	h.logger.Info("API key created", zap.Uint("key_id", key.ID), zap.String("admin_email", actor.Email), zap.String("operation", "create_api_key"))
+	c.JSON(http.StatusCreated, dtos.CreateAPIKeyResponseDTO{
+		APIKeyResponseDTO: apiKeyResponse(key),
+		Key:               rawKey,
+	})
+}
+
+// List godoc
+//
+//	@Summary		Lista as chaves de API
+//	@Description	Lista todas as chaves de API com escopos, expiração, revogação e último uso. Os valores das chaves nunca são retornados.
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		200	{array}	dtos.APIKeyResponseDTO	"API keys retrieved successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/api-keys [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.apiKeyUsecase.ListKeys()
+	if err != nil {
+		h.logger.Error("Failed to list API keys", zap.Error(err), zap.String("operation", "list_api_keys"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list API keys"})
+		return
+	}
+
+	response := make([]dtos.APIKeyResponseDTO, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, apiKeyResponse(key))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Revoke godoc
+//
+//	@Summary		Revoga uma chave de API
+//	@Description	Revoga permanentemente uma chave de API; requisições futuras com ela são rejeitadas.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int						true	"API key ID"
+//	@Success		200	{object}	dtos.MessageResponse	"API key revoked"
+//	@Security		bearerAuth
+//	@Router			/admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Debug("Invalid API key ID", zap.Error(err), zap.String("operation", "revoke_api_key"))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	if err := h.apiKeyUsecase.RevokeKey(uint(id)); err != nil {
+		if errors.Is(err, uc.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		h.logger.Error("Failed to revoke API key", zap.Error(err), zap.String("operation", "revoke_api_key"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+
+	h.logger.Info("API key revoked", zap.Uint64("key_id", id), zap.String("admin_email", c.GetString("userEmail")), zap.String("operation", "revoke_api_key"))
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
+
+// apiKeyResponse maps an API key model to its response DTO
+func apiKeyResponse(key *model.APIKey) dtos.APIKeyResponseDTO {
+	return dtos.APIKeyResponseDTO{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		OwnerID:    key.OwnerID,
+		OrgID:      key.OrgID,
+		ExpiresAt:  key.ExpiresAt,
+		RevokedAt:  key.RevokedAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
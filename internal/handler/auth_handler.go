@@ -1,11 +1,17 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
 	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
 	"github.com/Amandasilvbr/products-crud/internal/dtos"
 	"github.com/Amandasilvbr/products-crud/internal/handler/validator"
+	uc "github.com/Amandasilvbr/products-crud/internal/usecase"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -13,29 +19,33 @@ import (
 
 // AuthHandler handles authentication-related HTTP requests
 type AuthHandler struct {
-	authUsecase usecase.AuthUsecaseInterface
-	validator   *validator.UserValidator
-	logger      *zap.Logger
+	authUsecase         usecase.AuthUsecaseInterface
+	verificationUsecase usecase.VerificationUsecaseInterface
+	validator           *validator.UserValidator
+	logger              *zap.Logger
 }
 
 // NewAuthHandler creates and returns a new instance of AuthHandler
-func NewAuthHandler(authUsecase usecase.AuthUsecaseInterface, logger *zap.Logger) *AuthHandler {
+func NewAuthHandler(authUsecase usecase.AuthUsecaseInterface, verificationUsecase usecase.VerificationUsecaseInterface, logger *zap.Logger) *AuthHandler {
 	return &AuthHandler{
-		authUsecase: authUsecase,
-		validator:   validator.NewUserValidator(),
-		logger:      logger,
+		authUsecase:         authUsecase,
+		verificationUsecase: verificationUsecase,
+		validator:           validator.NewUserValidator(),
+		logger:              logger,
 	}
 }
 
 // Login godoc
 //
 //	@Summary		Autentica um usuário
-//	@Description	Autentica um usuário com base em e-mail e senha, retornando um token JWT válido para endpoints protegidos.
+//	@Description	Autentica um usuário com base em e-mail e senha, retornando um token JWT de curta duração e um refresh token rotativo. Contas com e-mail não verificado recebem 403 com o código "email_not_verified". Falhas repetidas por conta ou IP impõem atrasos progressivos e bloqueio temporário (429 com Retry-After); e-mail inexistente e senha incorreta recebem a mesma resposta. Usuários com autenticação em dois fatores (ou cujo papel a exige) recebem, em vez dos tokens, um desafio (dtos.MFAChallengeResponse) a ser concluído em /login/mfa.
 //	@Tags			Authentication
 //	@Accept			json
 //	@Produce		json
 //	@Param			credentials	body		dtos.UserLoginDTO	true	"User credentials (email and password)"
 //	@Success		200			{object}	dtos.LoginResponse	"Successful authentication with JWT token"
+//	@Failure		401			{object}	map[string]string	"Invalid credentials"
+//	@Failure		429			{object}	map[string]string	"Too many failed attempts"
 //	@Router			/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var input dtos.UserLoginDTO
@@ -59,22 +69,318 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Call the use case to perform the login logic
-	token, err := h.authUsecase.Login(input.Email, input.Password)
+	result, err := h.authUsecase.Login(input.Email, input.Password, c.ClientIP())
 	if err != nil {
+		if h.tooManyAttempts(c, err) {
+			return
+		}
+		if errors.Is(err, uc.ErrUserDisabled) {
+			h.logger.Warn("Login refused for disabled account", zap.String("email", input.Email), zap.String("operation", "login"))
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Account disabled",
+				"code":  "account_disabled",
+			})
+			return
+		}
+		if errors.Is(err, uc.ErrEmailNotVerified) {
+			h.logger.Warn("Login refused for unverified email", zap.String("email", input.Email), zap.String("operation", "login"))
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Email address not verified",
+				"code":  "email_not_verified",
+			})
+			return
+		}
+		if !errors.Is(err, uc.ErrInvalidCredentials) {
+			h.logger.Error("Login failed", zap.String("email", input.Email), zap.Error(err), zap.String("operation", "login"))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+			return
+		}
 		h.logger.Warn("Login failed", zap.String("email", input.Email), zap.Error(err), zap.String("operation", "login"))
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	// Return the JWT token on successful authentication
+	// A second factor is needed before the session tokens are issued
+	if result.MFARequired() {
+		h.logger.Info("MFA challenge issued", zap.String("email", input.Email), zap.String("operation", "login"))
+		c.JSON(http.StatusOK, dtos.MFAChallengeResponse{
+			MFARequired:        true,
+			MFAToken:           result.MFAToken,
+			EnrollmentRequired: result.MFAEnrollmentRequired,
+		})
+		return
+	}
+
+	// Return the JWT token and refresh token on successful authentication
 	h.logger.Info("User authenticated", zap.String("email", input.Email), zap.String("operation", "login"))
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, tokenResponse(result.Tokens))
+}
+
+// LoginMFA godoc
+//
+//	@Summary		Conclui o login com o segundo fator
+//	@Description	Conclui o login iniciado em /login com o token de desafio e um código TOTP do aplicativo autenticador ou um código de recuperação. Quando o desafio é de cadastro obrigatório, o código confirma o autenticador configurado em /login/mfa/enroll e os códigos de recuperação são retornados uma única vez. Códigos incorretos contam como tentativas de login malsucedidas.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			mfa	body		dtos.LoginMFADTO		true	"MFA challenge token and code"
+//	@Success		200	{object}	dtos.MFALoginResponse	"Successful authentication with JWT token"
+//	@Failure		401	{object}	map[string]string		"Invalid MFA token or code"
+//	@Failure		429	{object}	map[string]string		"Too many failed attempts"
+//	@Router			/login/mfa [post]
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var input dtos.LoginMFADTO
+	if !h.bindMFA(c, &input, "login_mfa") {
+		return
+	}
+
+	result, err := h.authUsecase.CompleteMFA(input.MFAToken, input.Code, c.ClientIP())
+	if err != nil {
+		if h.tooManyAttempts(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, uc.ErrInvalidMFAToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token"})
+		case errors.Is(err, uc.ErrInvalidMFACode):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid MFA code"})
+		case errors.Is(err, uc.ErrMFAEnrollmentNotStarted):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor enrollment has not been started"})
+		case errors.Is(err, uc.ErrUserDisabled):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account disabled", "code": "account_disabled"})
+		default:
+			h.logger.Error("MFA login failed", zap.Error(err), zap.String("operation", "login_mfa"))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		}
+		return
+	}
+
+	h.logger.Info("User authenticated with MFA", zap.String("operation", "login_mfa"))
+	c.JSON(http.StatusOK, dtos.MFALoginResponse{
+		Token:         result.Tokens.AccessToken,
+		RefreshToken:  result.Tokens.RefreshToken,
+		ExpiresIn:     result.Tokens.ExpiresIn,
+		RecoveryCodes: result.RecoveryCodes,
+	})
+}
+
+// LoginMFAEnroll godoc
+//
+//	@Summary		Inicia o cadastro obrigatório do segundo fator
+//	@Description	Gera o segredo TOTP de um usuário cujo papel exige autenticação em dois fatores e que ainda não a configurou. Usa o token de desafio retornado por /login com "enrollment_required"; o otpauth_uri deve ser exibido como QR code. O cadastro é concluído em /login/mfa.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			mfa	body		dtos.MFATokenDTO			true	"MFA challenge token"
+//	@Success		200	{object}	dtos.MFAEnrollmentResponse	"TOTP secret and otpauth URI"
+//	@Router			/login/mfa/enroll [post]
+func (h *AuthHandler) LoginMFAEnroll(c *gin.Context) {
+	var input dtos.MFATokenDTO
+	if !h.bindMFA(c, &input, "login_mfa_enroll") {
+		return
+	}
+
+	enrollment, err := h.authUsecase.BeginMFAEnrollment(input.MFAToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, uc.ErrInvalidMFAToken), errors.Is(err, uc.ErrUserNotFound):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token"})
+		case errors.Is(err, uc.ErrMFAAlreadyEnabled):
+			c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		default:
+			h.logger.Error("Failed to start MFA enrollment", zap.Error(err), zap.String("operation", "login_mfa_enroll"))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor enrollment"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollmentResponse(enrollment))
+}
+
+// bindMFA binds the JSON body into input and validates it, writing the error response on failure
+func (h *AuthHandler) bindMFA(c *gin.Context, input interface{}, operation string) bool {
+	if err := c.ShouldBindJSON(input); err != nil {
+		h.logger.Debug("Invalid request body", zap.Error(err), zap.String("operation", operation))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body format",
+		})
+		return false
+	}
+
+	if errors := h.validator.ValidateMFADTO(input); len(errors) > 0 {
+		h.logger.Warn("Validation failed", zap.Any("errors", errors), zap.String("operation", operation))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": errors,
+		})
+		return false
+	}
+	return true
+}
+
+// tooManyAttempts writes a 429 response with Retry-After when err is a throttling error
+func (h *AuthHandler) tooManyAttempts(c *gin.Context, err error) bool {
+	var attemptsErr *uc.AttemptsError
+	if !errors.As(err, &attemptsErr) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(attemptsErr.RetryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
+	return true
+}
+
+// RefreshToken godoc
+//
+//	@Summary		Renova o token de acesso
+//	@Description	Troca um refresh token válido por um novo token JWT e um novo refresh token. O refresh token antigo é invalidado; reutilizá-lo revoga toda a sessão.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			refresh	body		dtos.RefreshTokenDTO	true	"Refresh token"
+//	@Success		200		{object}	dtos.LoginResponse		"New access and refresh tokens"
+//	@Router			/token/refresh [post]
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var input dtos.RefreshTokenDTO
+	// Bind the incoming JSON payload to the RefreshTokenDTO struct
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Debug("Invalid refresh request body", zap.Error(err), zap.String("operation", "refresh"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body format",
+		})
+		return
+	}
+
+	// Validate the input data using the user validator
+	if errors := h.validator.ValidateRefreshToken(&input); len(errors) > 0 {
+		h.logger.Warn("Validation failed for refresh", zap.Any("errors", errors), zap.String("operation", "refresh"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": errors,
+		})
+		return
+	}
+
+	// Call the use case to rotate the refresh token
+	tokens, err := h.authUsecase.RefreshToken(input.RefreshToken)
+	if err != nil {
+		if errors.Is(err, uc.ErrInvalidRefreshToken) || errors.Is(err, uc.ErrRefreshTokenReused) {
+			h.logger.Warn("Refresh rejected", zap.Error(err), zap.String("operation", "refresh"))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+			return
+		}
+		if errors.Is(err, uc.ErrUserDisabled) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account disabled", "code": "account_disabled"})
+			return
+		}
+		h.logger.Error("Failed to refresh token", zap.Error(err), zap.String("operation", "refresh"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	h.logger.Info("Token refreshed", zap.String("operation", "refresh"))
+	c.JSON(http.StatusOK, tokenResponse(tokens))
+}
+
+// Logout godoc
+//
+//	@Summary		Encerra a sessão atual
+//	@Description	Revoga o token JWT usado na requisição e, se informado, o refresh token da sessão, que precisa pertencer ao usuário autenticado.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			refresh	body		dtos.RefreshTokenDTO	false	"Refresh token of the session"
+//	@Success		200		{object}	dtos.MessageResponse	"Logged out successfully"
+//	@Failure		400		{object}	map[string]string		"Refresh token of another user"
+//	@Security		bearerAuth
+//	@Router			/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	// The refresh token is optional, so only bind a body when one was sent
+	var input dtos.RefreshTokenDTO
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			h.logger.Debug("Invalid logout request body", zap.Error(err), zap.String("operation", "logout"))
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request body format",
+			})
+			return
+		}
+	}
+
+	// Retrieve the current access token identity set by the JWT middleware
+	jti := c.GetString("tokenJTI")
+	expiresAt := c.GetTime("tokenExpiresAt")
+	if expiresAt.IsZero() {
+		expiresAt = time.Now()
+	}
+
+	if err := h.authUsecase.Logout(c.GetUint("userID"), input.RefreshToken, jti, expiresAt); err != nil {
+		if errors.Is(err, uc.ErrInvalidRefreshToken) {
+			h.logger.Warn("Logout rejected", zap.Error(err), zap.String("operation", "logout"))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid refresh token"})
+			return
+		}
+		h.logger.Error("Failed to log out", zap.Error(err), zap.String("operation", "logout"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	h.logger.Info("User logged out", zap.String("email", c.GetString("userEmail")), zap.String("operation", "logout"))
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// RevokeSessions godoc
+//
+//	@Summary		Revoga todas as sessões do usuário
+//	@Description	Invalida todos os refresh tokens e tokens JWT emitidos para o usuário autenticado, encerrando todas as sessões ativas.
+//	@Tags			Authentication
+//	@Produce		json
+//	@Success		200	{object}	dtos.MessageResponse	"All sessions revoked"
+//	@Failure		404	{object}	map[string]string		"User not found"
+//	@Security		bearerAuth
+//	@Router			/sessions/revoke [post]
+func (h *AuthHandler) RevokeSessions(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Error("User ID not found in context", zap.String("operation", "revoke_sessions"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.authUsecase.RevokeAllSessions(userID.(uint)); err != nil {
+		if errors.Is(err, uc.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		h.logger.Error("Failed to revoke sessions", zap.Error(err), zap.String("operation", "revoke_sessions"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	h.logger.Info("Sessions revoked", zap.Uint("user_id", userID.(uint)), zap.String("operation", "revoke_sessions"))
+	c.JSON(http.StatusOK, gin.H{"message": "All sessions revoked"})
+}
+
+// tokenResponse maps a token pair to the JSON body returned by login and refresh
+func tokenResponse(tokens *model.TokenPair) dtos.LoginResponse {
+	return dtos.LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}
+}
+
+// enrollmentResponse maps a TOTP enrollment to the JSON body shown to the user
+func enrollmentResponse(enrollment *model.MFAEnrollment) dtos.MFAEnrollmentResponse {
+	return dtos.MFAEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	}
 }
 
 // CreateUser godoc
 //
 //	@Summary		Cria um usuário
-//	@Description	Registra um novo usuário com nome, e-mail e senha. O e-mail deve ser único e a senha deve atender aos critérios de validação.
+//	@Description	Registra um novo usuário com nome, e-mail e senha. O e-mail deve ser único e a senha deve atender aos critérios de validação. A conta recebe o papel viewer, somente leitura, até que um administrador o altere. Um link de verificação é enviado para o e-mail informado. Disponível apenas com OPEN_REGISTRATION habilitado (padrão desabilitado em produção); caso contrário, contas são criadas por convite.
 //	@Tags			Authentication
 //	@Accept			json
 //	@Produce		json
@@ -122,9 +428,86 @@ func (h *AuthHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
+	// The account exists even if the email cannot be sent; the user can ask for a resend
+	if err := h.verificationUsecase.SendVerification(input.Email); err != nil {
+		h.logger.Error("Failed to send verification email", zap.String("email", input.Email), zap.Error(err), zap.String("operation", "create_user"))
+	}
+
 	// Return a success message upon user creation
 	h.logger.Info("User created", zap.String("email", input.Email), zap.String("operation", "create_user"))
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "User created successfully",
+		"message": "User created successfully. Check your email to verify your account",
+	})
+}
+
+// VerifyEmail godoc
+//
+//	@Summary		Confirma o e-mail do usuário
+//	@Description	Valida o token assinado enviado por e-mail após o cadastro e marca a conta como verificada.
+//	@Tags			Authentication
+//	@Produce		json
+//	@Param			token	query		string					true	"Verification token"
+//	@Success		200		{object}	dtos.MessageResponse	"Email verified successfully"
+//	@Router			/email/verify [get]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": map[string]string{"token": "The token query parameter is required"},
+		})
+		return
+	}
+
+	if err := h.verificationUsecase.VerifyEmail(token); err != nil {
+		if errors.Is(err, uc.ErrInvalidVerificationToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+			return
+		}
+		h.logger.Error("Failed to verify email", zap.Error(err), zap.String("operation", "verify_email"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// ResendVerification godoc
+//
+//	@Summary		Reenvia o e-mail de verificação
+//	@Description	Envia um novo link de verificação. A resposta é a mesma para e-mails desconhecidos, já verificados ou pedidos dentro do intervalo; novos envios para a mesma conta são limitados por intervalo.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dtos.ResendVerificationDTO	true	"Account email"
+//	@Success		202		{object}	dtos.MessageResponse		"Request accepted"
+//	@Router			/email/verify/resend [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var input dtos.ResendVerificationDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Debug("Invalid resend request body", zap.Error(err), zap.String("operation", "resend_verification"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body format",
+		})
+		return
+	}
+
+	if errors := h.validator.ValidatePasswordDTO(&input); len(errors) > 0 {
+		h.logger.Warn("Validation failed for resend", zap.Any("errors", errors), zap.String("operation", "resend_verification"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": errors,
+		})
+		return
+	}
+
+	if err := h.verificationUsecase.ResendVerification(input.Email); err != nil {
+		h.logger.Error("Failed to resend verification email", zap.Error(err), zap.String("operation", "resend_verification"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process request"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If an unverified account exists for this email, a verification message has been sent",
 	})
 }
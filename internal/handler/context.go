@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// actorFromContext builds the authenticated actor from the values set by the JWT middleware
+// It returns false when the request did not go through the middleware
+func actorFromContext(c *gin.Context) (*model.Actor, bool) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		return nil, false
+	}
+	id, ok := userID.(uint)
+	if !ok {
+		return nil, false
+	}
+	email := c.GetString("userEmail")
+	if email == "" {
+		return nil, false
+	}
+	return &model.Actor{
+		ID:      id,
+		Name:    c.GetString("userName"),
+		Email:   email,
+		Role:    c.GetString("userRole"),
+		OrgID:   c.GetUint("orgID"),
+		OrgName: c.GetString("orgName"),
+		OrgRole: c.GetString("orgRole"),
+	}, true
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	"github.com/Amandasilvbr/products-crud/internal/dtos"
+	uc "github.com/Amandasilvbr/products-crud/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DeadLetterHandler handles HTTP requests for inspecting and draining the dead-letter queues
+type DeadLetterHandler struct {
+	deadLetterUsecase usecase.DeadLetterUsecaseInterface
+	logger            *zap.Logger
+}
+
+// NewDeadLetterHandler creates and returns a new instance of DeadLetterHandler
+func NewDeadLetterHandler(deadLetterUsecase usecase.DeadLetterUsecaseInterface, logger *zap.Logger) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		deadLetterUsecase: deadLetterUsecase,
+		logger:            logger,
+	}
+}
+
+// List godoc
+//
+//	@Summary		Lista as mensagens de uma fila de mensagens mortas
+//	@Description	Retorna as mensagens mais antigas da fila de mensagens mortas (dead-letter) de uma fila de trabalho (product_events ou account_events), com o número de tentativas e o último erro. As mensagens permanecem na fila.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			queue	path		string						true	"Work queue name"
+//	@Param			limit	query		int							false	"Maximum number of messages (default 20, max 100)"
+//	@Success		200		{object}	dtos.DeadLetterListResponse	"Dead-lettered messages retrieved successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/dead-letters/{queue} [get]
+func (h *DeadLetterHandler) List(c *gin.Context) {
+	queueName := c.Param("queue")
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(uc.DefaultDeadLetterPeekLimit)))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	if limit > uc.MaxDeadLetterPeekLimit {
+		limit = uc.MaxDeadLetterPeekLimit
+	}
+
+	letters, err := h.deadLetterUsecase.Peek(c.Request.Context(), queueName, limit)
+	if err != nil {
+		if errors.Is(err, uc.ErrUnknownQueue) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Queue not found"})
+			return
+		}
+		h.logger.Error("Failed to list dead-lettered messages", zap.String("queue", queueName), zap.Error(err), zap.String("operation", "peek_dead_letters"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list dead-lettered messages"})
+		return
+	}
+
+	messages := make([]dtos.DeadLetterResponse, 0, len(letters))
+	for _, letter := range letters {
+		messages = append(messages, dtos.DeadLetterResponse{
+			MessageID: letter.MessageID,
+			Body:      letter.Body,
+			Attempts:  letter.Attempts,
+			LastError: letter.LastError,
+			Timestamp: letter.Timestamp,
+		})
+	}
+	c.JSON(http.StatusOK, dtos.DeadLetterListResponse{Queue: queueName, Messages: messages})
+}
+
+// Requeue godoc
+//
+//	@Summary		Reenvia as mensagens mortas para a fila de trabalho
+//	@Description	Move as mensagens da fila de mensagens mortas de volta para a fila de trabalho, com a contagem de tentativas zerada. Sem limite, move todas as mensagens presentes no início da operação. Use depois de corrigir a causa das falhas.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			queue	path		string							true	"Work queue name"
+//	@Param			limit	query		int								false	"Maximum number of messages (default all)"
+//	@Success		200		{object}	dtos.DeadLetterCountResponse	"Dead-lettered messages requeued"
+//	@Security		bearerAuth
+//	@Router			/admin/dead-letters/{queue}/requeue [post]
+func (h *DeadLetterHandler) Requeue(c *gin.Context) {
+	queueName := c.Param("queue")
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	moved, err := h.deadLetterUsecase.Requeue(c.Request.Context(), queueName, limit)
+	if err != nil {
+		if errors.Is(err, uc.ErrUnknownQueue) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Queue not found"})
+			return
+		}
+		h.logger.Error("Failed to requeue dead-lettered messages", zap.String("queue", queueName), zap.Int("moved", moved), zap.Error(err), zap.String("operation", "requeue_dead_letters"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to requeue dead-lettered messages"})
+		return
+	}
+
+	h.logger.Info("Dead-lettered messages requeued", zap.String("queue", queueName), zap.Int("count", moved), zap.String("admin_email", c.GetString("userEmail")), zap.String("operation", "requeue_dead_letters"))
+	c.JSON(http.StatusOK, dtos.DeadLetterCountResponse{Message: "Dead-lettered messages requeued", Queue: queueName, Count: moved})
+}
+
+// Purge godoc
+//
+//	@Summary		Descarta as mensagens mortas de uma fila
+//	@Description	Remove definitivamente todas as mensagens da fila de mensagens mortas de uma fila de trabalho.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			queue	path		string							true	"Work queue name"
+//	@Success		200		{object}	dtos.DeadLetterCountResponse	"Dead-letter queue purged"
+//	@Security		bearerAuth
+//	@Router			/admin/dead-letters/{queue} [delete]
+func (h *DeadLetterHandler) Purge(c *gin.Context) {
+	queueName := c.Param("queue")
+	purged, err := h.deadLetterUsecase.Purge(c.Request.Context(), queueName)
+	if err != nil {
+		if errors.Is(err, uc.ErrUnknownQueue) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Queue not found"})
+			return
+		}
+		h.logger.Error("Failed to purge dead-letter queue", zap.String("queue", queueName), zap.Error(err), zap.String("operation", "purge_dead_letters"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge dead-letter queue"})
+		return
+	}
+
+	h.logger.Info("Dead-letter queue purged", zap.String("queue", queueName), zap.Int("count", purged), zap.String("admin_email", c.GetString("userEmail")), zap.String("operation", "purge_dead_letters"))
+	c.JSON(http.StatusOK, dtos.DeadLetterCountResponse{Message: "Dead-letter queue purged", Queue: queueName, Count: purged})
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	"github.com/Amandasilvbr/products-crud/internal/dtos"
+	uc "github.com/Amandasilvbr/products-crud/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// EmailDeliveryHandler handles HTTP requests for the email delivery log
+type EmailDeliveryHandler struct {
+	emailDeliveryUsecase usecase.EmailDeliveryUsecaseInterface
+	logger               *zap.Logger
+}
+
+// NewEmailDeliveryHandler creates and returns a new instance of EmailDeliveryHandler
+func NewEmailDeliveryHandler(emailDeliveryUsecase usecase.EmailDeliveryUsecaseInterface, logger *zap.Logger) *EmailDeliveryHandler {
+	return &EmailDeliveryHandler{
+		emailDeliveryUsecase: emailDeliveryUsecase,
+		logger:               logger,
+	}
+}
+
+// List godoc
+//
+//	@Summary		Pesquisa o registro de envios de e-mail
+//	@Description	Lista os e-mails de notificação enviados ou tentados, dos mais recentes para os mais antigos, com a resposta do servidor SMTP e o número de tentativas. Filtra por destinatário, ID de evento, tipo, status e período da primeira tentativa.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			recipient	query		string								false	"Recipient address, or part of it"
+//	@Param			event_id	query		string								false	"ID of an event included in the email"
+//	@Param			kind		query		string								false	"product_notification, product_digest or account_locked"
+//	@Param			status		query		string								false	"sent or failed"
+//	@Param			from		query		string								false	"First attempt at or after, RFC 3339"
+//	@Param			to			query		string								false	"First attempt before, RFC 3339"
+//	@Param			page		query		int									false	"Page number (default 1)"
+//	@Param			page_size	query		int									false	"Page size (default 20, max 100)"
+//	@Success		200			{object}	dtos.EmailDeliveryListResponseDTO	"Email deliveries retrieved successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/email-deliveries [get]
+func (h *EmailDeliveryHandler) List(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(uc.DefaultEmailDeliveryPageSize)))
+	if err != nil || pageSize < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page size"})
+		return
+	}
+	if pageSize > uc.MaxEmailDeliveryPageSize {
+		pageSize = uc.MaxEmailDeliveryPageSize
+	}
+
+	filter := model.EmailDeliveryFilter{
+		Recipient: strings.TrimSpace(c.Query("recipient")),
+		EventID:   strings.TrimSpace(c.Query("event_id")),
+		Kind:      c.Query("kind"),
+		Status:    c.Query("status"),
+	}
+	if filter.From, err = timeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected RFC 3339"})
+		return
+	}
+	if filter.To, err = timeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected RFC 3339"})
+		return
+	}
+
+	deliveries, total, err := h.emailDeliveryUsecase.Search(filter, page, pageSize)
+	if err != nil {
+		if errors.Is(err, uc.ErrInvalidEmailDeliveryStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to search email deliveries", zap.Error(err), zap.String("operation", "email_delivery_search"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search email deliveries"})
+		return
+	}
+
+	items := make([]dtos.EmailDeliveryDTO, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		items = append(items, emailDeliveryResponse(delivery))
+	}
+	c.JSON(http.StatusOK, dtos.EmailDeliveryListResponseDTO{
+		Items:    items,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// Get godoc
+//
+//	@Summary		Exibe um envio de e-mail
+//	@Description	Retorna um envio do registro com o conteúdo renderizado (texto e HTML) que foi enviado. Com format=html, retorna apenas o HTML do e-mail. O conteúdo não está mais disponível depois da anonimização.
+//	@Tags			Admin
+//	@Produce		json
+//	@Produce		html
+//	@Param			id		path		int							true	"Delivery ID"
+//	@Param			format	query		string						false	"json or html"	default(json)
+//	@Success		200		{object}	dtos.EmailDeliveryDetailDTO	"Email delivery retrieved successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/email-deliveries/{id} [get]
+func (h *EmailDeliveryHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	delivery, err := h.emailDeliveryUsecase.Get(uint(id))
+	if err != nil {
+		if errors.Is(err, uc.ErrEmailDeliveryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Email delivery not found"})
+			return
+		}
+		h.logger.Error("Failed to get email delivery", zap.Uint64("delivery_id", id), zap.Error(err), zap.String("operation", "email_delivery_get"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get email delivery"})
+		return
+	}
+
+	if c.Query("format") == "html" {
+		if delivery.RedactedAt != nil {
+			c.JSON(http.StatusGone, gin.H{"error": "Email content was redacted"})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(delivery.HTML))
+		return
+	}
+	c.JSON(http.StatusOK, dtos.EmailDeliveryDetailDTO{
+		EmailDeliveryDTO: emailDeliveryResponse(delivery),
+		Text:             delivery.Text,
+		HTML:             delivery.HTML,
+	})
+}
+
+// Resend godoc
+//
+//	@Summary		Reenvia um e-mail
+//	@Description	Envia de novo o conteúdo registrado de um envio aos mesmos destinatários. O reenvio é registrado como um novo envio, ligado ao original por resend_of. Envios anonimizados não podem ser reenviados.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int						true	"Delivery ID"
+//	@Success		201	{object}	dtos.EmailDeliveryDTO	"Email resent"
+//	@Security		bearerAuth
+//	@Router			/admin/email-deliveries/{id}/resend [post]
+func (h *EmailDeliveryHandler) Resend(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	delivery, err := h.emailDeliveryUsecase.Resend(c.Request.Context(), uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, uc.ErrEmailDeliveryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Email delivery not found"})
+		case errors.Is(err, uc.ErrEmailDeliveryRedacted):
+			c.JSON(http.StatusGone, gin.H{"error": "Email content was redacted"})
+		case delivery != nil:
+			// The SMTP server refused the email or could not be reached; the attempt is in the log
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to resend email", "delivery": emailDeliveryResponse(delivery)})
+		default:
+			h.logger.Error("Failed to resend email", zap.Uint64("delivery_id", id), zap.Error(err), zap.String("operation", "email_resend"))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resend email"})
+		}
+		return
+	}
+
+	h.logger.Info("Email delivery resent", zap.Uint64("delivery_id", id), zap.Uint("resent_id", delivery.ID), zap.String("admin_email", c.GetString("userEmail")), zap.String("operation", "email_resend"))
+	c.JSON(http.StatusCreated, emailDeliveryResponse(delivery))
+}
+
+// emailDeliveryResponse converts a delivery of the log to its response body, without its content
+func emailDeliveryResponse(delivery *model.EmailDelivery) dtos.EmailDeliveryDTO {
+	return dtos.EmailDeliveryDTO{
+		ID:             delivery.ID,
+		Kind:           delivery.Kind,
+		Recipients:     splitList(delivery.Recipients),
+		Subject:        delivery.Subject,
+		EventIDs:       splitList(delivery.EventIDs),
+		Status:         delivery.Status,
+		ResponseCode:   delivery.ResponseCode,
+		Response:       delivery.Response,
+		Attempts:       delivery.Attempts,
+		FirstAttemptAt: delivery.FirstAttemptAt,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		SentAt:         delivery.SentAt,
+		ResendOf:       delivery.ResendOf,
+		Redacted:       delivery.RedactedAt != nil,
+		RedactedAt:     delivery.RedactedAt,
+	}
+}
+
+// timeQuery parses an optional RFC 3339 query parameter, giving nil when it is absent
+func timeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// splitList splits a comma-separated list, giving an empty list for an empty string
+func splitList(list string) []string {
+	if list == "" {
+		return []string{}
+	}
+	return strings.Split(list, ",")
+}
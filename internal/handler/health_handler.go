@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/dtos"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// HealthHandler reports the state of the process's connections for health checks
+// It serves both the API and the standalone worker
+type HealthHandler struct {
+	brokers []messaging.ConnectionMonitor
+	logger  *zap.Logger
+}
+
+// NewHealthHandler creates and returns a new instance of HealthHandler watching the given broker connections
+func NewHealthHandler(brokers []messaging.ConnectionMonitor, logger *zap.Logger) *HealthHandler {
+	return &HealthHandler{
+		brokers: brokers,
+		logger:  logger,
+	}
+}
+
+// Health godoc
+//
+//	@Summary		Verifica a saúde da API
+//	@Description	Informa o estado das conexões com o RabbitMQ (connected, reconnecting ou closed), incluindo as dos consumidores quando o worker roda embutido na API. Retorna 503 enquanto alguma conexão não está disponível; as escritas de produtos continuam funcionando e seus eventos aguardam no outbox.
+//	@Tags			Health
+//	@Produce		json
+//	@Success		200	{object}	dtos.HealthResponse	"All connections are available"
+//	@Failure		503	{object}	dtos.HealthResponse	"RabbitMQ is unavailable"
+//	@Router			/health [get]
+func (h *HealthHandler) Health(c *gin.Context) {
+	state := h.state()
+	if state != messaging.StateConnected {
+		h.logger.Warn("Health check failed: RabbitMQ unavailable", zap.String("rabbitmq", state), zap.String("operation", "health"))
+		c.JSON(http.StatusServiceUnavailable, dtos.HealthResponse{Status: "degraded", RabbitMQ: state})
+		return
+	}
+	c.JSON(http.StatusOK, dtos.HealthResponse{Status: "ok", RabbitMQ: state})
+}
+
+// state returns the worst state among the broker connections: closed, then reconnecting, then connected
+func (h *HealthHandler) state() string {
+	state := messaging.StateConnected
+	for _, broker := range h.brokers {
+		switch broker.State() {
+		case messaging.StateClosed:
+			return messaging.StateClosed
+		case messaging.StateReconnecting:
+			state = messaging.StateReconnecting
+		}
+	}
+	return state
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	"github.com/Amandasilvbr/products-crud/internal/dtos"
+	uc "github.com/Amandasilvbr/products-crud/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// InboxHandler handles HTTP requests for the in-app notification inbox
+type InboxHandler struct {
+	inboxUsecase usecase.InboxUsecaseInterface
+	logger       *zap.Logger
+}
+
+// NewInboxHandler creates and returns a new instance of InboxHandler
+func NewInboxHandler(inboxUsecase usecase.InboxUsecaseInterface, logger *zap.Logger) *InboxHandler {
+	return &InboxHandler{
+		inboxUsecase: inboxUsecase,
+		logger:       logger,
+	}
+}
+
+// List godoc
+//
+//	@Summary		Lista as notificações do usuário
+//	@Description	Lista as notificações de produtos da caixa de entrada do usuário autenticado, das mais recentes para as mais antigas, com o total de não lidas.
+//	@Tags			Profile
+//	@Produce		json
+//	@Param			unread		query		bool						false	"Only unread notifications"
+//	@Param			page		query		int							false	"Page number (default 1)"
+//	@Param			page_size	query		int							false	"Page size (default 20, max 100)"
+//	@Success		200			{object}	dtos.InboxListResponseDTO	"Notifications retrieved successfully"
+//	@Security		bearerAuth
+//	@Router			/notifications [get]
+func (h *InboxHandler) List(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(uc.DefaultInboxPageSize)))
+	if err != nil || pageSize < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page size"})
+		return
+	}
+	if pageSize > uc.MaxInboxPageSize {
+		pageSize = uc.MaxInboxPageSize
+	}
+	unreadOnly, err := strconv.ParseBool(c.DefaultQuery("unread", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unread filter"})
+		return
+	}
+
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	notifications, total, unread, err := h.inboxUsecase.List(actor.ID, unreadOnly, page, pageSize)
+	if err != nil {
+		h.logger.Error("Failed to list notifications", zap.Error(err), zap.String("operation", "inbox_list"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list notifications"})
+		return
+	}
+
+	items := make([]dtos.InboxNotificationDTO, 0, len(notifications))
+	for _, notification := range notifications {
+		items = append(items, inboxNotificationResponse(notification))
+	}
+	c.JSON(http.StatusOK, dtos.InboxListResponseDTO{
+		Items:    items,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+		Unread:   unread,
+	})
+}
+
+// MarkRead godoc
+//
+//	@Summary		Marca uma notificação como lida
+//	@Description	Marca uma notificação da caixa de entrada do usuário autenticado como lida.
+//	@Tags			Profile
+//	@Produce		json
+//	@Param			id	path		int							true	"Notification ID"
+//	@Success		200	{object}	dtos.InboxNotificationDTO	"Notification marked as read"
+//	@Security		bearerAuth
+//	@Router			/notifications/{id}/read [post]
+func (h *InboxHandler) MarkRead(c *gin.Context) {
+	h.mark(c, true)
+}
+
+// MarkUnread godoc
+//
+//	@Summary		Marca uma notificação como não lida
+//	@Description	Volta uma notificação da caixa de entrada do usuário autenticado para não lida.
+//	@Tags			Profile
+//	@Produce		json
+//	@Param			id	path		int							true	"Notification ID"
+//	@Success		200	{object}	dtos.InboxNotificationDTO	"Notification marked as unread"
+//	@Security		bearerAuth
+//	@Router			/notifications/{id}/unread [post]
+func (h *InboxHandler) MarkUnread(c *gin.Context) {
+	h.mark(c, false)
+}
+
+// mark changes the read state of the notification in the URL
+func (h *InboxHandler) mark(c *gin.Context, read bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	notification, err := h.inboxUsecase.MarkRead(actor.ID, uint(id), read)
+	if err != nil {
+		if errors.Is(err, uc.ErrInboxNotificationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		h.logger.Error("Failed to mark notification", zap.Error(err), zap.String("operation", "inbox_mark"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+		return
+	}
+	c.JSON(http.StatusOK, inboxNotificationResponse(notification))
+}
+
+// MarkAllRead godoc
+//
+//	@Summary		Marca todas as notificações como lidas
+//	@Description	Marca como lidas todas as notificações não lidas da caixa de entrada do usuário autenticado.
+//	@Tags			Profile
+//	@Produce		json
+//	@Success		200	{object}	dtos.InboxReadAllResponseDTO	"Notifications marked as read"
+//	@Security		bearerAuth
+//	@Router			/notifications/read-all [post]
+func (h *InboxHandler) MarkAllRead(c *gin.Context) {
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	updated, err := h.inboxUsecase.MarkAllRead(actor.ID)
+	if err != nil {
+		h.logger.Error("Failed to mark notifications as read", zap.Error(err), zap.String("operation", "inbox_mark_all"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+	c.JSON(http.StatusOK, dtos.InboxReadAllResponseDTO{Updated: updated})
+}
+
+// inboxNotificationResponse converts an inbox notification to its response body
+func inboxNotificationResponse(notification *model.InboxNotification) dtos.InboxNotificationDTO {
+	return dtos.InboxNotificationDTO{
+		ID:        notification.ID,
+		Event:     notification.Event,
+		SKU:       notification.SKU,
+		Name:      notification.Name,
+		OrgID:     notification.OrgID,
+		OrgName:   notification.OrgName,
+		Read:      notification.ReadAt != nil,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
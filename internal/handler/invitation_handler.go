@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	"github.com/Amandasilvbr/products-crud/internal/dtos"
+	"github.com/Amandasilvbr/products-crud/internal/handler/validator"
+	uc "github.com/Amandasilvbr/products-crud/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// InvitationHandler handles HTTP requests for inviting people to organizations and accepting invitations
+type InvitationHandler struct {
+	invitationUsecase usecase.InvitationUsecaseInterface
+	validator         *validator.OrganizationValidator
+	logger            *zap.Logger
+}
+
+// NewInvitationHandler creates and returns a new instance of InvitationHandler
+func NewInvitationHandler(invitationUsecase usecase.InvitationUsecaseInterface, logger *zap.Logger) *InvitationHandler {
+	return &InvitationHandler{
+		invitationUsecase: invitationUsecase,
+		validator:         validator.NewOrganizationValidator(),
+		logger:            logger,
+	}
+}
+
+// Create godoc
+//
+//	@Summary		Convida uma pessoa para a organização
+//	@Description	Cria um convite com papel e expiração opcional (padrão INVITATION_TTL) e o envia por e-mail. Um novo convite para o mesmo e-mail substitui o pendente. Restrito aos administradores da organização.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int							true	"Organization ID"
+//	@Param			invitation	body		dtos.CreateInvitationDTO	true	"Invitation data"
+//	@Success		201			{object}	dtos.InvitationResponseDTO	"Invitation created successfully"
+//	@Security		bearerAuth
+//	@Router			/orgs/{id}/invitations [post]
+func (h *InvitationHandler) Create(c *gin.Context) {
+	orgID, ok := h.parseID(c, "id", "Invalid organization ID", "create_invitation")
+	if !ok {
+		return
+	}
+
+	var input dtos.CreateInvitationDTO
+	// Bind the incoming JSON payload to the CreateInvitationDTO struct
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Debug("Invalid invitation request body", zap.Error(err), zap.String("operation", "create_invitation"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body format",
+		})
+		return
+	}
+
+	if errors := h.validator.ValidateOrganizationDTO(&input); len(errors) > 0 {
+		h.logger.Warn("Validation failed for invitation creation", zap.Any("errors", errors), zap.String("operation", "create_invitation"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": errors,
+		})
+		return
+	}
+
+	actor, ok := actorFromContext(c)
+	if !ok {
+		h.logger.Error("User not found in context", zap.String("operation", "create_invitation"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	invitation, err := h.invitationUsecase.CreateInvitation(actor, orgID, input.Email, input.Role, input.ExpiresAt)
+	if err != nil {
+		h.respondError(c, err, "create_invitation", "Failed to create invitation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitationResponse(invitation))
+}
+
+// List godoc
+//
+//	@Summary		Lista os convites da organização
+//	@Description	Lista os convites da organização com seu status (pending, accepted, revoked ou expired). Restrito aos administradores da organização.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			id	path	int	true	"Organization ID"
+//	@Success		200	{array}	dtos.InvitationResponseDTO	"Invitations retrieved successfully"
+//	@Security		bearerAuth
+//	@Router			/orgs/{id}/invitations [get]
+func (h *InvitationHandler) List(c *gin.Context) {
+	orgID, ok := h.parseID(c, "id", "Invalid organization ID", "list_invitations")
+	if !ok {
+		return
+	}
+	actor, ok := actorFromContext(c)
+	if !ok {
+		h.logger.Error("User not found in context", zap.String("operation", "list_invitations"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	invitations, err := h.invitationUsecase.ListInvitations(actor, orgID)
+	if err != nil {
+		h.respondError(c, err, "list_invitations", "Failed to list invitations")
+		return
+	}
+
+	response := make([]dtos.InvitationResponseDTO, 0, len(invitations))
+	for _, invitation := range invitations {
+		response = append(response, invitationResponse(invitation))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Resend godoc
+//
+//	@Summary		Reenvia um convite
+//	@Description	Envia novamente um convite pendente ou expirado com um novo código, renovando sua validade; o código anterior deixa de funcionar. Restrito aos administradores da organização.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			id				path		int							true	"Organization ID"
+//	@Param			invitationId	path		int							true	"Invitation ID"
+//	@Success		200				{object}	dtos.InvitationResponseDTO	"Invitation resent successfully"
+//	@Security		bearerAuth
+//	@Router			/orgs/{id}/invitations/{invitationId}/resend [post]
+func (h *InvitationHandler) Resend(c *gin.Context) {
+	orgID, ok := h.parseID(c, "id", "Invalid organization ID", "resend_invitation")
+	if !ok {
+		return
+	}
+	invitationID, ok := h.parseID(c, "invitationId", "Invalid invitation ID", "resend_invitation")
+	if !ok {
+		return
+	}
+	actor, ok := actorFromContext(c)
+	if !ok {
+		h.logger.Error("User not found in context", zap.String("operation", "resend_invitation"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	invitation, err := h.invitationUsecase.ResendInvitation(actor, orgID, invitationID)
+	if err != nil {
+		h.respondError(c, err, "resend_invitation", "Failed to resend invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, invitationResponse(invitation))
+}
+
+// Revoke godoc
+//
+//	@Summary		Revoga um convite
+//	@Description	Cancela um convite pendente; seu código deixa de ser aceito. Restrito aos administradores da organização.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			id				path		int						true	"Organization ID"
+//	@Param			invitationId	path		int						true	"Invitation ID"
+//	@Success		200				{object}	dtos.MessageResponse	"Invitation revoked"
+//	@Security		bearerAuth
+//	@Router			/orgs/{id}/invitations/{invitationId} [delete]
+func (h *InvitationHandler) Revoke(c *gin.Context) {
+	orgID, ok := h.parseID(c, "id", "Invalid organization ID", "revoke_invitation")
+	if !ok {
+		return
+	}
+	invitationID, ok := h.parseID(c, "invitationId", "Invalid invitation ID", "revoke_invitation")
+	if !ok {
+		return
+	}
+	actor, ok := actorFromContext(c)
+	if !ok {
+		h.logger.Error("User not found in context", zap.String("operation", "revoke_invitation"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.invitationUsecase.RevokeInvitation(actor, orgID, invitationID); err != nil {
+		h.respondError(c, err, "revoke_invitation", "Failed to revoke invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
+}
+
+// Accept godoc
+//
+//	@Summary		Aceita um convite
+//	@Description	Cria a conta do convidado com o e-mail do convite, o nome e a senha informados, já verificada e vinculada à organização com o papel do convite. O código pode ser usado apenas uma vez.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			token	path		string						true	"Invitation token"
+//	@Param			account	body		dtos.AcceptInvitationDTO	true	"Account data"
+//	@Success		201		{object}	dtos.MessageResponse		"Account created successfully"
+//	@Router			/invitations/{token}/accept [post]
+func (h *InvitationHandler) Accept(c *gin.Context) {
+	var input dtos.AcceptInvitationDTO
+	// Bind the incoming JSON payload to the AcceptInvitationDTO struct
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Debug("Invalid accept invitation request body", zap.Error(err), zap.String("operation", "accept_invitation"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body format",
+		})
+		return
+	}
+
+	if errors := h.validator.ValidateOrganizationDTO(&input); len(errors) > 0 {
+		h.logger.Warn("Validation failed for accept invitation", zap.Any("errors", errors), zap.String("operation", "accept_invitation"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": errors,
+		})
+		return
+	}
+
+	user, err := h.invitationUsecase.AcceptInvitation(c.Param("token"), input.Name, input.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, uc.ErrInvalidInvitation):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "invalid_invitation"})
+		case errors.Is(err, uc.ErrEmailTaken), errors.Is(err, uc.ErrNameTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("Failed to accept invitation", zap.Error(err), zap.String("operation", "accept_invitation"))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		}
+		return
+	}
+
+	h.logger.Info("Invitation accepted", zap.Uint("user_id", user.ID), zap.String("operation", "accept_invitation"))
+	c.JSON(http.StatusCreated, gin.H{"message": "Account created successfully"})
+}
+
+// parseID reads a numeric path parameter, answering 400 when it is malformed
+func (h *InvitationHandler) parseID(c *gin.Context, param, message, operation string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 64)
+	if err != nil {
+		h.logger.Debug(message, zap.Error(err), zap.String("operation", operation))
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondError maps the invitation use case errors to HTTP responses
+// Organizations the caller cannot access are answered with 404, like ones that do not exist
+func (h *InvitationHandler) respondError(c *gin.Context, err error, operation, message string) {
+	switch {
+	case errors.Is(err, uc.ErrOrgNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+	case errors.Is(err, uc.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, uc.ErrOrgForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+	case errors.Is(err, uc.ErrInviteeAlreadyUser):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "user_exists"})
+	case errors.Is(err, uc.ErrInvitationNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, uc.ErrInvalidRole), errors.Is(err, uc.ErrInvalidInvitationExpiry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, zap.Error(err), zap.String("operation", operation))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// invitationResponse maps an invitation model to its response DTO
+func invitationResponse(invitation *model.Invitation) dtos.InvitationResponseDTO {
+	return dtos.InvitationResponseDTO{
+		ID:          invitation.ID,
+		OrgID:       invitation.OrgID,
+		Email:       invitation.Email,
+		Role:        invitation.Role,
+		Status:      invitation.Status(time.Now()),
+		InvitedByID: invitation.InvitedByID,
+		ExpiresAt:   invitation.ExpiresAt,
+		SentAt:      invitation.SentAt,
+		AcceptedAt:  invitation.AcceptedAt,
+		RevokedAt:   invitation.RevokedAt,
+		CreatedAt:   invitation.CreatedAt,
+	}
+}
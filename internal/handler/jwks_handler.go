@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/identity"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// JWKSHandler publishes the public keys that verify the API's access tokens
+type JWKSHandler struct {
+	keys   identity.KeySet
+	logger *zap.Logger
+}
+
+// NewJWKSHandler creates and returns a new instance of JWKSHandler
+func NewJWKSHandler(keys identity.KeySet, logger *zap.Logger) *JWKSHandler {
+	return &JWKSHandler{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+// JWKS serves GET /.well-known/jwks.json, outside the /api prefix as downstream JWT libraries expect
+// Retired keys stay listed while tokens they signed may still be valid, and clients may cache the document briefly
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
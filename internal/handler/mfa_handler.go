@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	"github.com/Amandasilvbr/products-crud/internal/dtos"
+	"github.com/Amandasilvbr/products-crud/internal/handler/validator"
+	uc "github.com/Amandasilvbr/products-crud/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// MFAHandler handles two-factor enrollment, recovery codes and the administrative policy
+type MFAHandler struct {
+	mfaUsecase usecase.MFAUsecaseInterface
+	validator  *validator.UserValidator
+	logger     *zap.Logger
+}
+
+// NewMFAHandler creates and returns a new instance of MFAHandler
+func NewMFAHandler(mfaUsecase usecase.MFAUsecaseInterface, logger *zap.Logger) *MFAHandler {
+	return &MFAHandler{
+		mfaUsecase: mfaUsecase,
+		validator:  validator.NewUserValidator(),
+		logger:     logger,
+	}
+}
+
+// Enroll godoc
+//
+//	@Summary		Inicia a autenticação em dois fatores
+//	@Description	Gera um novo segredo TOTP para o usuário autenticado. O otpauth_uri deve ser exibido como QR code para o aplicativo autenticador; a autenticação em dois fatores só é ativada após a confirmação com um código válido.
+//	@Tags			MFA
+//	@Produce		json
+//	@Success		200	{object}	dtos.MFAEnrollmentResponse	"TOTP secret and otpauth URI"
+//	@Security		bearerAuth
+//	@Router			/mfa/enroll [post]
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	enrollment, err := h.mfaUsecase.BeginEnrollment(actor.ID)
+	if err != nil {
+		h.respondError(c, err, "mfa_enroll", "Failed to start two-factor enrollment")
+		return
+	}
+	c.JSON(http.StatusOK, enrollmentResponse(enrollment))
+}
+
+// Confirm godoc
+//
+//	@Summary		Ativa a autenticação em dois fatores
+//	@Description	Confirma o aplicativo autenticador com um código TOTP válido e ativa a autenticação em dois fatores. Os códigos de recuperação de uso único são exibidos apenas nesta resposta.
+//	@Tags			MFA
+//	@Accept			json
+//	@Produce		json
+//	@Param			code	body		dtos.MFACodeDTO				true	"TOTP code"
+//	@Success		200		{object}	dtos.RecoveryCodesResponse	"Two-factor authentication enabled"
+//	@Security		bearerAuth
+//	@Router			/mfa/confirm [post]
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	var input dtos.MFACodeDTO
+	actor, ok := h.bindWithActor(c, &input, "mfa_confirm")
+	if !ok {
+		return
+	}
+
+	codes, err := h.mfaUsecase.ConfirmEnrollment(actor.ID, input.Code)
+	if err != nil {
+		h.respondError(c, err, "mfa_confirm", "Failed to enable two-factor authentication")
+		return
+	}
+
+	h.logger.Info("MFA enabled", zap.Uint("user_id", actor.ID), zap.String("operation", "mfa_confirm"))
+	c.JSON(http.StatusOK, dtos.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodes godoc
+//
+//	@Summary		Gera novos códigos de recuperação
+//	@Description	Substitui todos os códigos de recuperação do usuário autenticado após validar um código atual. Os códigos anteriores deixam de funcionar.
+//	@Tags			MFA
+//	@Accept			json
+//	@Produce		json
+//	@Param			code	body		dtos.MFACodeDTO				true	"TOTP or recovery code"
+//	@Success		200		{object}	dtos.RecoveryCodesResponse	"New recovery codes"
+//	@Security		bearerAuth
+//	@Router			/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var input dtos.MFACodeDTO
+	actor, ok := h.bindWithActor(c, &input, "mfa_recovery_codes")
+	if !ok {
+		return
+	}
+
+	codes, err := h.mfaUsecase.RegenerateRecoveryCodes(actor.ID, input.Code)
+	if err != nil {
+		h.respondError(c, err, "mfa_recovery_codes", "Failed to generate recovery codes")
+		return
+	}
+	c.JSON(http.StatusOK, dtos.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable godoc
+//
+//	@Summary		Desativa a autenticação em dois fatores
+//	@Description	Desativa a autenticação em dois fatores do usuário autenticado após validar um código atual. Não é permitido quando o papel do usuário a exige.
+//	@Tags			MFA
+//	@Accept			json
+//	@Produce		json
+//	@Param			code	body		dtos.MFACodeDTO			true	"TOTP or recovery code"
+//	@Success		200		{object}	dtos.MessageResponse	"Two-factor authentication disabled"
+//	@Security		bearerAuth
+//	@Router			/mfa/disable [post]
+func (h *MFAHandler) Disable(c *gin.Context) {
+	var input dtos.MFACodeDTO
+	actor, ok := h.bindWithActor(c, &input, "mfa_disable")
+	if !ok {
+		return
+	}
+
+	if err := h.mfaUsecase.Disable(actor.ID, input.Code); err != nil {
+		h.respondError(c, err, "mfa_disable", "Failed to disable two-factor authentication")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// Reset godoc
+//
+//	@Summary		Redefine a autenticação em dois fatores de um usuário
+//	@Description	Remove o segredo TOTP e os códigos de recuperação de um usuário que perdeu o acesso ao autenticador. Se o papel exigir, o usuário fará um novo cadastro no próximo login. Restrito a administradores.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int						true	"User ID"
+//	@Success		200	{object}	dtos.MessageResponse	"Two-factor authentication reset"
+//	@Security		bearerAuth
+//	@Router			/admin/users/{id}/mfa [delete]
+func (h *MFAHandler) Reset(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Debug("Invalid user ID", zap.Error(err), zap.String("operation", "mfa_reset"))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.mfaUsecase.Reset(uint(userID), c.GetUint("userID")); err != nil {
+		h.respondError(c, err, "mfa_reset", "Failed to reset two-factor authentication")
+		return
+	}
+
+	h.logger.Info("MFA reset",
+		zap.Uint64("user_id", userID),
+		zap.String("admin_email", c.GetString("userEmail")),
+		zap.String("operation", "mfa_reset"))
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset"})
+}
+
+// ListPolicies godoc
+//
+//	@Summary		Lista as políticas de autenticação em dois fatores
+//	@Description	Informa, para cada papel, se a autenticação em dois fatores é obrigatória. Restrito a administradores.
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		200	{array}	dtos.MFAPolicyDTO	"Policies by role"
+//	@Security		bearerAuth
+//	@Router			/admin/mfa/policies [get]
+func (h *MFAHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.mfaUsecase.ListPolicies()
+	if err != nil {
+		h.logger.Error("Failed to list MFA policies", zap.Error(err), zap.String("operation", "mfa_policy"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list policies"})
+		return
+	}
+
+	response := make([]dtos.MFAPolicyDTO, 0, len(policies))
+	for _, policy := range policies {
+		response = append(response, dtos.MFAPolicyDTO{Role: policy.Role, Required: policy.Required})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SetPolicy godoc
+//
+//	@Summary		Define a política de autenticação em dois fatores de um papel
+//	@Description	Torna a autenticação em dois fatores obrigatória (ou opcional) para um papel. Usuários do papel sem cadastro precisarão configurá-la no próximo login. Restrito a administradores.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			role	path		string					true	"Role (admin, editor or viewer)"
+//	@Param			policy	body		dtos.SetMFAPolicyDTO	true	"Whether 2FA is required"
+//	@Success		200		{object}	dtos.MFAPolicyDTO		"Policy updated"
+//	@Security		bearerAuth
+//	@Router			/admin/mfa/policies/{role} [put]
+func (h *MFAHandler) SetPolicy(c *gin.Context) {
+	var input dtos.SetMFAPolicyDTO
+	if !h.bind(c, &input, "mfa_policy") {
+		return
+	}
+
+	role := c.Param("role")
+	if err := h.mfaUsecase.SetPolicy(role, *input.Required); err != nil {
+		if errors.Is(err, uc.ErrInvalidRole) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+			return
+		}
+		h.logger.Error("Failed to set MFA policy", zap.Error(err), zap.String("operation", "mfa_policy"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set policy"})
+		return
+	}
+
+	h.logger.Info("MFA policy changed",
+		zap.String("role", role),
+		zap.Bool("required", *input.Required),
+		zap.String("admin_email", c.GetString("userEmail")),
+		zap.String("operation", "mfa_policy"))
+	c.JSON(http.StatusOK, dtos.MFAPolicyDTO{Role: role, Required: *input.Required})
+}
+
+// bindWithActor binds and validates the body and returns the authenticated user, writing the error response on failure
+func (h *MFAHandler) bindWithActor(c *gin.Context, input interface{}, operation string) (*model.Actor, bool) {
+	if !h.bind(c, input, operation) {
+		return nil, false
+	}
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+	return actor, true
+}
+
+// bind binds the JSON body into input and validates it, writing the error response on failure
+func (h *MFAHandler) bind(c *gin.Context, input interface{}, operation string) bool {
+	if err := c.ShouldBindJSON(input); err != nil {
+		h.logger.Debug("Invalid request body", zap.Error(err), zap.String("operation", operation))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body format",
+		})
+		return false
+	}
+
+	if errors := h.validator.ValidateMFADTO(input); len(errors) > 0 {
+		h.logger.Warn("Validation failed", zap.Any("errors", errors), zap.String("operation", operation))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": errors,
+		})
+		return false
+	}
+	return true
+}
+
+// respondError maps two-factor use case errors to HTTP responses
+func (h *MFAHandler) respondError(c *gin.Context, err error, operation, fallback string) {
+	switch {
+	case errors.Is(err, uc.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, uc.ErrInvalidMFACode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid MFA code"})
+	case errors.Is(err, uc.ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+	case errors.Is(err, uc.ErrMFANotEnabled):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+	case errors.Is(err, uc.ErrMFAEnrollmentNotStarted):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor enrollment has not been started"})
+	case errors.Is(err, uc.ErrMFARequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for your role"})
+	default:
+		h.logger.Error(fallback, zap.Error(err), zap.String("operation", operation))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RequireRoles creates a Gin middleware that only lets through users holding one of the given roles
+// It must run after JWTMiddleware, which stores the user's role in the Gin context
+func RequireRoles(zapLogger *zap.Logger, roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		allowed[role] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		role := c.GetString("userRole")
+		if _, ok := allowed[role]; !ok {
+			zapLogger.Warn("Access denied for role",
+				zap.String("role", role),
+				zap.String("email", c.GetString("userEmail")),
+				zap.String("path", c.FullPath()))
+			c.JSON(403, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
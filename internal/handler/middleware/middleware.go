@@ -1,18 +1,24 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
+	"time"
 
-	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/identity"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	uc "github.com/Amandasilvbr/products-crud/internal/usecase"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 // JWTMiddleware creates a Gin middleware for handling JWT authentication
-// It extracts, parses, and validates the token from the Authorization header
-func JWTMiddleware(zapLogger *zap.Logger) gin.HandlerFunc {
+// It extracts the token from the Authorization header, verifies it against the key set loaded at startup,
+// then asks the auth use case whether the token has been revoked
+// Requests using the "ApiKey <key>" scheme are authenticated through the API key use case instead
+// The active organization carried by the token is checked against the user's current memberships
+func JWTMiddleware(keys identity.KeySet, authUsecase usecase.AuthUsecaseInterface, apiKeyUsecase usecase.APIKeyUsecaseInterface, orgUsecase usecase.OrganizationUsecaseInterface, zapLogger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Retrieve the Authorization header from the request
 		authHeader := c.GetHeader("Authorization")
@@ -24,53 +30,34 @@ func JWTMiddleware(zapLogger *zap.Logger) gin.HandlerFunc {
 			return
 		}
 
-		// Check if the token is in the "Bearer <token>" format
+		// Service-to-service calls authenticate with an API key instead of a JWT
 		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			zapLogger.Debug("Invalid token format", zap.String("header", authHeader))
-			c.JSON(401, gin.H{"error": "Invalid token format"})
-			c.Abort()
-			return
-		}
-
-		// Load application configuration to get the JWT secret key
-		cfg, err := config.New()
-		if err != nil {
-			zapLogger.Error("Failed to load config", zap.Error(err))
-			c.JSON(500, gin.H{"error": "Internal server error"})
-			c.Abort()
+		if len(parts) == 2 && parts[0] == "ApiKey" {
+			authenticateAPIKey(c, apiKeyUsecase, parts[1], zapLogger)
 			return
 		}
 
-		// Ensure the JWT secret key is configured
-		secretKey := cfg.JWTSecret
-		if secretKey == "" {
-			zapLogger.Error("JWT secret key not configured")
-			c.JSON(500, gin.H{"error": "Internal server error"})
+		// Check if the token is in the "Bearer <token>" format
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			zapLogger.Debug("Invalid token format")
+			c.JSON(401, gin.H{"error": "Invalid token format"})
 			c.Abort()
 			return
 		}
 
-		// Parse and validate the JWT token using the secret key
-		token, err := jwt.Parse(parts[1], func(token *jwt.Token) (interface{}, error) {
-			// Verify that the signing method is HMAC
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(secretKey), nil
-		})
-		if err != nil || token == nil || !token.Valid {
+		// Verify the signature and expiry; the kid header selects the active or a retired key
+		claims, err := keys.Verify(parts[1])
+		if err != nil {
 			zapLogger.Warn("Invalid JWT token", zap.Error(err))
 			c.JSON(401, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
-		// Extract claims from the validated token
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			zapLogger.Warn("Invalid JWT claims")
-			c.JSON(401, gin.H{"error": "Invalid claims"})
+		// Purpose-bound tokens (email verification, MFA challenges) are never access tokens
+		if _, ok := claims["purpose"]; ok {
+			zapLogger.Warn("Rejected purpose-bound token used as access token")
+			c.JSON(401, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
@@ -102,10 +89,68 @@ func JWTMiddleware(zapLogger *zap.Logger) gin.HandlerFunc {
 			return
 		}
 
+		// Validate and extract user's role from claims
+		userRole, ok := claims["role"].(string)
+		if !ok || userRole == "" {
+			zapLogger.Warn("Missing or invalid role in JWT claims")
+			c.JSON(401, gin.H{"error": "Invalid token claims"})
+			c.Abort()
+			return
+		}
+
+		// Validate and extract the token identifier and timestamps used for revocation
+		jti, ok := claims["jti"].(string)
+		if !ok || jti == "" {
+			zapLogger.Warn("Missing or invalid jti in JWT claims")
+			c.JSON(401, gin.H{"error": "Invalid token claims"})
+			c.Abort()
+			return
+		}
+		issuedAt, _ := claims["iat"].(float64)
+		expiresAt, _ := claims["exp"].(float64)
+
+		// Reject tokens that were revoked by logout or by revoking all sessions
+		if err := authUsecase.ValidateAccessToken(jti, uint(userID), time.Unix(int64(issuedAt), 0)); err != nil {
+			if errors.Is(err, uc.ErrUserDisabled) {
+				zapLogger.Warn("Rejected JWT token of disabled user", zap.Float64("user_id", userID))
+				c.JSON(403, gin.H{"error": "Account disabled", "code": "account_disabled"})
+				c.Abort()
+				return
+			}
+			zapLogger.Warn("Rejected revoked JWT token", zap.String("jti", jti), zap.Error(err))
+			c.JSON(401, gin.H{"error": "Invalid token"})
+			c.Abort()
+			return
+		}
+
 		// Set user information in the Gin context
 		c.Set("userName", userName)
 		c.Set("userID", uint(userID))
 		c.Set("userEmail", userEmail)
+		c.Set("userRole", userRole)
+		c.Set("authMethod", AuthMethodJWT)
+		c.Set("tokenJTI", jti)
+		c.Set("tokenExpiresAt", time.Unix(int64(expiresAt), 0))
+
+		// A membership removed after the token was issued leaves the request without an organization
+		if orgID, _ := claims["org_id"].(float64); orgID > 0 {
+			membership, err := orgUsecase.ResolveMembership(uint(userID), userRole, uint(orgID))
+			switch {
+			case err == nil:
+				orgName := ""
+				if membership.Organization != nil {
+					orgName = membership.Organization.Name
+				}
+				setOrganization(c, membership.OrgID, orgName, membership.Role)
+			case errors.Is(err, uc.ErrOrgNotFound):
+				zapLogger.Warn("Token organization no longer accessible", zap.Float64("user_id", userID), zap.Float64("org_id", orgID))
+			default:
+				zapLogger.Error("Failed to resolve organization", zap.Float64("org_id", orgID), zap.Error(err))
+				c.JSON(500, gin.H{"error": "Failed to resolve organization"})
+				c.Abort()
+				return
+			}
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	"github.com/Amandasilvbr/products-crud/internal/dtos"
+	uc "github.com/Amandasilvbr/products-crud/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// NotificationHandler handles HTTP requests for the notification preferences of users
+type NotificationHandler struct {
+	notificationUsecase usecase.NotificationUsecaseInterface
+	logger              *zap.Logger
+}
+
+// NewNotificationHandler creates and returns a new instance of NotificationHandler
+func NewNotificationHandler(notificationUsecase usecase.NotificationUsecaseInterface, logger *zap.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notificationUsecase: notificationUsecase,
+		logger:              logger,
+	}
+}
+
+// GetPreferences godoc
+//
+//	@Summary		Retorna as preferências de notificação
+//	@Description	Retorna, para cada evento de produto (product_created, product_updated, product_deleted), a frequência dos e-mails do usuário autenticado: immediate, hourly, daily ou off. Retorna também o idioma (pt-BR, en ou es) e o fuso horário usados nos e-mails.
+//	@Tags			Profile
+//	@Produce		json
+//	@Success		200	{object}	dtos.NotificationPreferencesDTO	"Preferences retrieved successfully"
+//	@Security		bearerAuth
+//	@Router			/me/notifications [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	settings, err := h.notificationUsecase.GetPreferences(actor.ID)
+	if err != nil {
+		h.logger.Error("Failed to get notification preferences", zap.Error(err), zap.String("operation", "get_notification_preferences"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, toNotificationPreferencesDTO(settings))
+}
+
+// UpdatePreferences godoc
+//
+//	@Summary		Altera as preferências de notificação
+//	@Description	Define a frequência dos e-mails de cada evento de produto: immediate (resumo a cada poucos segundos), hourly (resumo por hora), daily (resumo diário) ou off. Eventos omitidos mantêm a frequência atual. Os campos opcionais locale (pt-BR, en ou es) e time_zone (ex.: America/Sao_Paulo) definem o idioma e o fuso horário dos e-mails.
+//	@Tags			Profile
+//	@Accept			json
+//	@Produce		json
+//	@Param			preferences	body		dtos.NotificationPreferencesDTO	true	"Frequency per event"
+//	@Success		200			{object}	dtos.NotificationPreferencesDTO	"Preferences updated successfully"
+//	@Security		bearerAuth
+//	@Router			/me/notifications [put]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var input dtos.NotificationPreferencesDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body format"})
+		return
+	}
+
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	settings, err := h.notificationUsecase.UpdatePreferences(actor.ID, model.NotificationSettings{
+		Preferences: input.Preferences,
+		Locale:      input.Locale,
+		TimeZone:    input.TimeZone,
+	})
+	if err != nil {
+		if errors.Is(err, uc.ErrInvalidNotificationEvent) || errors.Is(err, uc.ErrInvalidFrequency) ||
+			errors.Is(err, uc.ErrUnsupportedLocale) || errors.Is(err, uc.ErrInvalidTimeZone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to update notification preferences", zap.Error(err), zap.String("operation", "update_notification_preferences"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, toNotificationPreferencesDTO(settings))
+}
+
+// Unsubscribe godoc
+//
+//	@Summary		Cancela o recebimento de notificações
+//	@Description	Desativa os e-mails de produtos do usuário identificado pelo token do link de cancelamento presente em cada e-mail. Sem o parâmetro event, desativa todos os eventos. Links enviados a endereços sem conta desativam sempre todos os eventos.
+//	@Tags			Profile
+//	@Produce		json
+//	@Param			token	query		string					true	"Unsubscribe token"
+//	@Param			event	query		string					false	"Single event to turn off"
+//	@Success		200		{object}	dtos.MessageResponse	"Unsubscribed successfully"
+//	@Router			/notifications/unsubscribe [get]
+func (h *NotificationHandler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": map[string]string{"token": "The token query parameter is required"},
+		})
+		return
+	}
+
+	if err := h.notificationUsecase.Unsubscribe(token, c.Query("event")); err != nil {
+		if errors.Is(err, uc.ErrInvalidUnsubscribeToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unsubscribe token"})
+			return
+		}
+		if errors.Is(err, uc.ErrInvalidNotificationEvent) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to unsubscribe", zap.Error(err), zap.String("operation", "unsubscribe"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsubscribe"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unsubscribed successfully"})
+}
+
+// Preview godoc
+//
+//	@Summary		Pré-visualiza o e-mail de notificação
+//	@Description	Renderiza um e-mail de produtos com notificações de exemplo, como seria enviado para a frequência, o idioma e o fuso horário informados. Com format=html, retorna apenas o HTML do e-mail.
+//	@Tags			Admin
+//	@Produce		json
+//	@Produce		html
+//	@Param			frequency	query		string						false	"immediate, hourly or daily"	default(immediate)
+//	@Param			locale		query		string						false	"pt-BR, en or es"
+//	@Param			time_zone	query		string						false	"IANA time zone, e.g. America/Sao_Paulo"
+//	@Param			format		query		string						false	"json or html"	default(json)
+//	@Success		200			{object}	dtos.EmailPreviewResponse	"Email rendered successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/email-preview [get]
+func (h *NotificationHandler) Preview(c *gin.Context) {
+	frequency := c.DefaultQuery("frequency", model.FrequencyImmediate)
+	email, err := h.notificationUsecase.PreviewDigest(frequency, c.Query("locale"), c.Query("time_zone"))
+	if err != nil {
+		if errors.Is(err, uc.ErrInvalidFrequency) || errors.Is(err, uc.ErrUnsupportedLocale) || errors.Is(err, uc.ErrInvalidTimeZone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to render email preview", zap.Error(err), zap.String("operation", "email_preview"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render email preview"})
+		return
+	}
+
+	if c.Query("format") == "html" {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(email.HTML))
+		return
+	}
+	c.JSON(http.StatusOK, dtos.EmailPreviewResponse{Subject: email.Subject, Text: email.Text, HTML: email.HTML})
+}
+
+// toNotificationPreferencesDTO converts notification settings to their response body
+func toNotificationPreferencesDTO(settings *model.NotificationSettings) dtos.NotificationPreferencesDTO {
+	return dtos.NotificationPreferencesDTO{
+		Preferences: settings.Preferences,
+		Locale:      settings.Locale,
+		TimeZone:    settings.TimeZone,
+	}
+}
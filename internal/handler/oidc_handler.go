@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	uc "github.com/Amandasilvbr/products-crud/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// OIDCHandler handles single sign-on through the configured OpenID Connect identity provider
+type OIDCHandler struct {
+	oidcUsecase usecase.OIDCUsecaseInterface
+	logger      *zap.Logger
+}
+
+// NewOIDCHandler creates and returns a new instance of OIDCHandler
+func NewOIDCHandler(oidcUsecase usecase.OIDCUsecaseInterface, logger *zap.Logger) *OIDCHandler {
+	return &OIDCHandler{
+		oidcUsecase: oidcUsecase,
+		logger:      logger,
+	}
+}
+
+// Login godoc
+//
+//	@Summary		Inicia o login pelo provedor de identidade
+//	@Description	Redireciona o navegador para o provedor OpenID Connect configurado usando o fluxo authorization code com PKCE. O provedor retorna para /oidc/callback.
+//	@Tags			Authentication
+//	@Success		302	"Redirect to the identity provider"
+//	@Router			/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	url, err := h.oidcUsecase.BeginLogin()
+	if err != nil {
+		h.logger.Error("Failed to start OIDC login", zap.Error(err), zap.String("operation", "oidc_login"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start single sign-on"})
+		return
+	}
+	c.Redirect(http.StatusFound, url)
+}
+
+// Callback godoc
+//
+//	@Summary		Conclui o login pelo provedor de identidade
+//	@Description	Troca o código de autorização, valida o ID token pelas chaves JWKS do provedor e emite os tokens da própria API. Usuários são vinculados pelo e-mail verificado ou criados automaticamente, e os grupos do provedor definem o papel conforme OIDC_ROLE_MAPPING.
+//	@Tags			Authentication
+//	@Produce		json
+//	@Param			code	query		string				true	"Authorization code"
+//	@Param			state	query		string				true	"State returned by the identity provider"
+//	@Success		200		{object}	dtos.LoginResponse	"Successful authentication with JWT token"
+//	@Failure		400		{object}	map[string]string	"Invalid or expired state"
+//	@Failure		401		{object}	map[string]string	"Identity provider login failed"
+//	@Failure		403		{object}	map[string]string	"Account disabled or not provisioned"
+//	@Router			/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if idpErr := c.Query("error"); idpErr != "" {
+		h.logger.Warn("Identity provider returned an error", zap.String("error", idpErr), zap.String("description", c.Query("error_description")), zap.String("operation", "oidc_callback"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Identity provider login failed", "code": idpErr})
+		return
+	}
+
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The code and state query parameters are required"})
+		return
+	}
+
+	tokens, err := h.oidcUsecase.CompleteLogin(c.Request.Context(), state, code, c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, uc.ErrInvalidOIDCState):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired login state"})
+		case errors.Is(err, uc.ErrOIDCLoginFailed):
+			h.logger.Warn("OIDC login failed", zap.Error(err), zap.String("operation", "oidc_callback"))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Identity provider login failed"})
+		case errors.Is(err, uc.ErrOIDCEmailNotVerified):
+			c.JSON(http.StatusForbidden, gin.H{"error": "The identity provider has not verified this email address", "code": "email_not_verified"})
+		case errors.Is(err, uc.ErrOIDCAccountNotFound):
+			c.JSON(http.StatusForbidden, gin.H{"error": "No account is provisioned for this identity", "code": "account_not_provisioned"})
+		case errors.Is(err, uc.ErrUserDisabled):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account disabled", "code": "account_disabled"})
+		default:
+			h.logger.Error("Failed to complete OIDC login", zap.Error(err), zap.String("operation", "oidc_callback"))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete single sign-on"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse(tokens))
+}
@@ -0,0 +1,407 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	"github.com/Amandasilvbr/products-crud/internal/dtos"
+	"github.com/Amandasilvbr/products-crud/internal/handler/validator"
+	uc "github.com/Amandasilvbr/products-crud/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// OrganizationHandler handles HTTP requests for organizations, their members and the active organization
+type OrganizationHandler struct {
+	orgUsecase  usecase.OrganizationUsecaseInterface
+	authUsecase usecase.AuthUsecaseInterface
+	validator   *validator.OrganizationValidator
+	logger      *zap.Logger
+}
+
+// NewOrganizationHandler creates and returns a new instance of OrganizationHandler
+func NewOrganizationHandler(orgUsecase usecase.OrganizationUsecaseInterface, authUsecase usecase.AuthUsecaseInterface, logger *zap.Logger) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgUsecase:  orgUsecase,
+		authUsecase: authUsecase,
+		validator:   validator.NewOrganizationValidator(),
+		logger:      logger,
+	}
+}
+
+// Create godoc
+//
+//	@Summary		Cria uma organização
+//	@Description	Cria uma organização vazia com nome e slug únicos (letras minúsculas, números e hífens). Os membros são adicionados depois. Restrito a administradores da plataforma.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization	body		dtos.CreateOrganizationDTO		true	"Organization data"
+//	@Success		201				{object}	dtos.OrganizationResponseDTO	"Organization created successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/orgs [post]
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	var input dtos.CreateOrganizationDTO
+	// Bind the incoming JSON payload to the CreateOrganizationDTO struct
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Debug("Invalid organization request body", zap.Error(err), zap.String("operation", "create_org"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body format",
+		})
+		return
+	}
+
+	if errors := h.validator.ValidateOrganizationDTO(&input); len(errors) > 0 {
+		h.logger.Warn("Validation failed for organization creation", zap.Any("errors", errors), zap.String("operation", "create_org"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": errors,
+		})
+		return
+	}
+
+	org, err := h.orgUsecase.CreateOrganization(input.Name, input.Slug)
+	if err != nil {
+		h.respondError(c, err, "create_org", "Failed to create organization")
+		return
+	}
+
+	h.logger.Info("Organization created", zap.Uint("org_id", org.ID), zap.String("admin_email", c.GetString("userEmail")), zap.String("operation", "create_org"))
+	c.JSON(http.StatusCreated, organizationResponse(org))
+}
+
+// List godoc
+//
+//	@Summary		Lista as organizações
+//	@Description	Lista todas as organizações da plataforma. Restrito a administradores da plataforma.
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		200	{array}	dtos.OrganizationResponseDTO	"Organizations retrieved successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/orgs [get]
+func (h *OrganizationHandler) List(c *gin.Context) {
+	orgs, err := h.orgUsecase.ListOrganizations()
+	if err != nil {
+		h.logger.Error("Failed to list organizations", zap.Error(err), zap.String("operation", "list_orgs"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list organizations"})
+		return
+	}
+
+	response := make([]dtos.OrganizationResponseDTO, 0, len(orgs))
+	for _, org := range orgs {
+		response = append(response, organizationResponse(org))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ListMine godoc
+//
+//	@Summary		Lista minhas organizações
+//	@Description	Lista as organizações das quais o usuário autenticado é membro, com seu papel em cada uma e qual está ativa no token atual.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Success		200	{array}	dtos.MembershipResponseDTO	"Organizations retrieved successfully"
+//	@Security		bearerAuth
+//	@Router			/orgs [get]
+func (h *OrganizationHandler) ListMine(c *gin.Context) {
+	actor, ok := actorFromContext(c)
+	if !ok {
+		h.logger.Error("User not found in context", zap.String("operation", "list_memberships"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	memberships, err := h.orgUsecase.ListMemberships(actor.ID)
+	if err != nil {
+		h.logger.Error("Failed to list memberships", zap.Error(err), zap.String("operation", "list_memberships"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list organizations"})
+		return
+	}
+
+	response := make([]dtos.MembershipResponseDTO, 0, len(memberships))
+	for _, membership := range memberships {
+		if membership.Organization == nil {
+			continue
+		}
+		response = append(response, dtos.MembershipResponseDTO{
+			OrganizationResponseDTO: organizationResponse(membership.Organization),
+			Role:                    membership.Role,
+			Active:                  membership.OrgID == actor.OrgID,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Switch godoc
+//
+//	@Summary		Troca a organização ativa
+//	@Description	Emite novos tokens com a organização informada como ativa. A escolha é lembrada nos próximos logins. Organizações às quais o usuário não tem acesso retornam 404.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			id	path		int					true	"Organization ID"
+//	@Success		200	{object}	dtos.LoginResponse	"Organization switched successfully"
+//	@Security		bearerAuth
+//	@Router			/orgs/{id}/switch [post]
+func (h *OrganizationHandler) Switch(c *gin.Context) {
+	orgID, ok := h.parseID(c, "id", "Invalid organization ID", "switch_org")
+	if !ok {
+		return
+	}
+
+	tokens, err := h.authUsecase.SwitchOrganization(c.GetUint("userID"), orgID)
+	if err != nil {
+		h.respondError(c, err, "switch_org", "Failed to switch organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse(tokens))
+}
+
+// ListMembers godoc
+//
+//	@Summary		Lista os membros de uma organização
+//	@Description	Lista os membros da organização com seus papéis. Restrito aos administradores da organização.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			id	path	int	true	"Organization ID"
+//	@Success		200	{array}	dtos.MemberResponseDTO	"Members retrieved successfully"
+//	@Security		bearerAuth
+//	@Router			/orgs/{id}/members [get]
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	orgID, ok := h.parseID(c, "id", "Invalid organization ID", "list_members")
+	if !ok {
+		return
+	}
+	actor, ok := actorFromContext(c)
+	if !ok {
+		h.logger.Error("User not found in context", zap.String("operation", "list_members"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	members, err := h.orgUsecase.ListMembers(actor, orgID)
+	if err != nil {
+		h.respondError(c, err, "list_members", "Failed to list members")
+		return
+	}
+
+	response := make([]dtos.MemberResponseDTO, 0, len(members))
+	for _, member := range members {
+		response = append(response, memberResponse(member))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// AddMember godoc
+//
+//	@Summary		Adiciona um membro à organização
+//	@Description	Adiciona um usuário existente, identificado pelo email, à organização com o papel informado; se já for membro, seu papel é alterado. Restrito aos administradores da organização.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Organization ID"
+//	@Param			member	body		dtos.AddMemberDTO		true	"Member data"
+//	@Success		201		{object}	dtos.MemberResponseDTO	"Member added successfully"
+//	@Security		bearerAuth
+//	@Router			/orgs/{id}/members [post]
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	orgID, ok := h.parseID(c, "id", "Invalid organization ID", "add_member")
+	if !ok {
+		return
+	}
+
+	var input dtos.AddMemberDTO
+	// Bind the incoming JSON payload to the AddMemberDTO struct
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Debug("Invalid add member request body", zap.Error(err), zap.String("operation", "add_member"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body format",
+		})
+		return
+	}
+
+	if errors := h.validator.ValidateOrganizationDTO(&input); len(errors) > 0 {
+		h.logger.Warn("Validation failed for add member", zap.Any("errors", errors), zap.String("operation", "add_member"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": errors,
+		})
+		return
+	}
+
+	actor, ok := actorFromContext(c)
+	if !ok {
+		h.logger.Error("User not found in context", zap.String("operation", "add_member"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	membership, err := h.orgUsecase.AddMember(actor, orgID, input.Email, input.Role)
+	if err != nil {
+		h.respondError(c, err, "add_member", "Failed to add member")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.MemberResponseDTO{
+		UserID: membership.UserID,
+		Email:  input.Email,
+		Role:   membership.Role,
+	})
+}
+
+// UpdateMember godoc
+//
+//	@Summary		Altera o papel de um membro
+//	@Description	Define o papel (admin, editor ou viewer) de um membro na organização. A organização deve manter ao menos um administrador. Restrito aos administradores da organização.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Organization ID"
+//	@Param			userId	path		int						true	"User ID"
+//	@Param			role	body		dtos.AssignRoleDTO		true	"New role"
+//	@Success		200		{object}	dtos.MessageResponse	"Member updated successfully"
+//	@Security		bearerAuth
+//	@Router			/orgs/{id}/members/{userId} [put]
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	orgID, ok := h.parseID(c, "id", "Invalid organization ID", "update_member")
+	if !ok {
+		return
+	}
+	userID, ok := h.parseID(c, "userId", "Invalid user ID", "update_member")
+	if !ok {
+		return
+	}
+
+	var input dtos.AssignRoleDTO
+	// Bind the incoming JSON payload to the AssignRoleDTO struct
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Debug("Invalid update member request body", zap.Error(err), zap.String("operation", "update_member"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body format",
+		})
+		return
+	}
+
+	if errors := h.validator.ValidateOrganizationDTO(&input); len(errors) > 0 {
+		h.logger.Warn("Validation failed for update member", zap.Any("errors", errors), zap.String("operation", "update_member"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": errors,
+		})
+		return
+	}
+
+	actor, ok := actorFromContext(c)
+	if !ok {
+		h.logger.Error("User not found in context", zap.String("operation", "update_member"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if _, err := h.orgUsecase.UpdateMember(actor, orgID, userID, input.Role); err != nil {
+		h.respondError(c, err, "update_member", "Failed to update member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member updated successfully"})
+}
+
+// RemoveMember godoc
+//
+//	@Summary		Remove um membro da organização
+//	@Description	Remove o usuário da organização; o acesso termina imediatamente, mesmo com tokens já emitidos. A organização deve manter ao menos um administrador. Restrito aos administradores da organização.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			id		path		int						true	"Organization ID"
+//	@Param			userId	path		int						true	"User ID"
+//	@Success		200		{object}	dtos.MessageResponse	"Member removed successfully"
+//	@Security		bearerAuth
+//	@Router			/orgs/{id}/members/{userId} [delete]
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	orgID, ok := h.parseID(c, "id", "Invalid organization ID", "remove_member")
+	if !ok {
+		return
+	}
+	userID, ok := h.parseID(c, "userId", "Invalid user ID", "remove_member")
+	if !ok {
+		return
+	}
+
+	actor, ok := actorFromContext(c)
+	if !ok {
+		h.logger.Error("User not found in context", zap.String("operation", "remove_member"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.orgUsecase.RemoveMember(actor, orgID, userID); err != nil {
+		h.respondError(c, err, "remove_member", "Failed to remove member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// parseID reads a numeric path parameter, answering 400 when it is malformed
+func (h *OrganizationHandler) parseID(c *gin.Context, param, message, operation string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 64)
+	if err != nil {
+		h.logger.Debug(message, zap.Error(err), zap.String("operation", operation))
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondError maps the organization use case errors to HTTP responses
+// Organizations the caller cannot access are answered with 404, like ones that do not exist
+func (h *OrganizationHandler) respondError(c *gin.Context, err error, operation, message string) {
+	switch {
+	case errors.Is(err, uc.ErrOrgNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+	case errors.Is(err, uc.ErrMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, uc.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, uc.ErrOrgForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+	case errors.Is(err, uc.ErrUserDisabled):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account disabled", "code": "account_disabled"})
+	case errors.Is(err, uc.ErrOrgSlugTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "slug_taken"})
+	case errors.Is(err, uc.ErrLastOrgAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "last_org_admin"})
+	case errors.Is(err, uc.ErrInvalidOrgSlug), errors.Is(err, uc.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, zap.Error(err), zap.String("operation", operation))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// organizationResponse maps an organization model to its response DTO
+func organizationResponse(org *model.Organization) dtos.OrganizationResponseDTO {
+	return dtos.OrganizationResponseDTO{
+		ID:        org.ID,
+		Name:      org.Name,
+		Slug:      org.Slug,
+		CreatedAt: org.CreatedAt,
+	}
+}
+
+// memberResponse maps a membership, with its user loaded, to its response DTO
+func memberResponse(membership *model.Membership) dtos.MemberResponseDTO {
+	response := dtos.MemberResponseDTO{
+		UserID: membership.UserID,
+		Role:   membership.Role,
+	}
+	if membership.User != nil {
+		response.Name = membership.User.Name
+		response.Email = membership.User.Email
+	}
+	return response
+}
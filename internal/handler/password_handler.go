@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	"github.com/Amandasilvbr/products-crud/internal/dtos"
+	"github.com/Amandasilvbr/products-crud/internal/handler/validator"
+	uc "github.com/Amandasilvbr/products-crud/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PasswordHandler handles password recovery and change HTTP requests
+type PasswordHandler struct {
+	passwordUsecase usecase.PasswordUsecaseInterface
+	validator       *validator.UserValidator
+	logger          *zap.Logger
+}
+
+// NewPasswordHandler creates and returns a new instance of PasswordHandler
+func NewPasswordHandler(passwordUsecase usecase.PasswordUsecaseInterface, logger *zap.Logger) *PasswordHandler {
+	return &PasswordHandler{
+		passwordUsecase: passwordUsecase,
+		validator:       validator.NewUserValidator(),
+		logger:          logger,
+	}
+}
+
+// Forgot godoc
+//
+//	@Summary		Solicita a redefinição de senha
+//	@Description	Envia por e-mail um código de uso único e tempo limitado para redefinir a senha. A resposta é sempre a mesma, exista ou não uma conta com o e-mail informado.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dtos.ForgotPasswordDTO	true	"Account email"
+//	@Success		202		{object}	dtos.MessageResponse	"Request accepted"
+//	@Router			/password/forgot [post]
+func (h *PasswordHandler) Forgot(c *gin.Context) {
+	var input dtos.ForgotPasswordDTO
+	if !h.bindAndValidate(c, &input, "forgot_password") {
+		return
+	}
+
+	if err := h.passwordUsecase.ForgotPassword(input.Email); err != nil {
+		h.logger.Error("Failed to process password reset request", zap.Error(err), zap.String("operation", "forgot_password"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process request"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If an account exists for this email, a password reset message has been sent",
+	})
+}
+
+// Reset godoc
+//
+//	@Summary		Redefine a senha
+//	@Description	Define uma nova senha usando o código recebido por e-mail. O código só pode ser usado uma vez e todas as sessões existentes são revogadas.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dtos.ResetPasswordDTO	true	"Reset token and new password"
+//	@Success		200		{object}	dtos.MessageResponse	"Password reset successfully"
+//	@Router			/password/reset [post]
+func (h *PasswordHandler) Reset(c *gin.Context) {
+	var input dtos.ResetPasswordDTO
+	if !h.bindAndValidate(c, &input, "reset_password") {
+		return
+	}
+
+	if err := h.passwordUsecase.ResetPassword(input.Token, input.NewPassword); err != nil {
+		if errors.Is(err, uc.ErrInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+		h.logger.Error("Failed to reset password", zap.Error(err), zap.String("operation", "reset_password"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+// Change godoc
+//
+//	@Summary		Altera a senha
+//	@Description	Altera a senha do usuário autenticado após confirmar a senha atual.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dtos.ChangePasswordDTO	true	"Current and new password"
+//	@Success		200		{object}	dtos.MessageResponse	"Password changed successfully"
+//	@Security		bearerAuth
+//	@Router			/password/change [post]
+func (h *PasswordHandler) Change(c *gin.Context) {
+	var input dtos.ChangePasswordDTO
+	if !h.bindAndValidate(c, &input, "change_password") {
+		return
+	}
+
+	actor, ok := actorFromContext(c)
+	if !ok {
+		h.logger.Error("User not found in context", zap.String("operation", "change_password"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.passwordUsecase.ChangePassword(actor.ID, input.CurrentPassword, input.NewPassword); err != nil {
+		if errors.Is(err, uc.ErrIncorrectPassword) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Current password is incorrect"})
+			return
+		}
+		h.logger.Error("Failed to change password", zap.Error(err), zap.String("operation", "change_password"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+// bindAndValidate binds the JSON body into input and validates it, writing the error response on failure
+func (h *PasswordHandler) bindAndValidate(c *gin.Context, input interface{}, operation string) bool {
+	if err := c.ShouldBindJSON(input); err != nil {
+		h.logger.Debug("Invalid request body", zap.Error(err), zap.String("operation", operation))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body format",
+		})
+		return false
+	}
+
+	if errors := h.validator.ValidatePasswordDTO(input); len(errors) > 0 {
+		h.logger.Warn("Validation failed", zap.Any("errors", errors), zap.String("operation", operation))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": errors,
+		})
+		return false
+	}
+	return true
+}
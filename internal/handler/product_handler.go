@@ -48,6 +48,7 @@ type batchResult struct {
 //	@Param			products	body		[]dtos.CreateProductDTO		true	"Product data to create"
 //	@Success		200			{object}	dtos.CreateProductResponse	"Product(s) created successfully"
 //	@Security		bearerAuth
+//	@Security		apiKeyAuth
 //	@Router			/products [post]
 func (h *ProductHandler) Create(c *gin.Context) {
 	// Lê o corpo da requisição
@@ -74,21 +75,12 @@ func (h *ProductHandler) Create(c *gin.Context) {
 	var products []*model.Product
 
 	// Obtém informações do usuário
-	userNameVal, ok := c.Get("userName")
+	actor, ok := actorFromContext(c)
 	if !ok {
 		h.logger.Error("User not found in context")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
-	userName, _ := userNameVal.(string)
-
-	userEmailVal, ok := c.Get("userEmail")
-	if !ok {
-		h.logger.Error("User email not found in context")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "User email not found"})
-		return
-	}
-	userEmail, _ := userEmailVal.(string)
 
 	// Validate and prepare products
 	for i, input := range inputs {
@@ -101,7 +93,8 @@ func (h *ProductHandler) Create(c *gin.Context) {
 			Link:         input.Link,
 			ImageLink:    input.ImageLink,
 			Availability: input.Availability,
-			CreatedBy:    userName,
+			CreatedBy:    actor.Name,
+			CreatedByID:  actor.ID,
 		}
 
 		if errs := h.validator.ValidateProduct(product); errs != nil {
@@ -121,9 +114,9 @@ func (h *ProductHandler) Create(c *gin.Context) {
 	}
 
 	// Create products
-	var createErrors, publishErrors map[int]string
+	var createErrors map[int]string
 	if len(products) > 0 {
-		createErrors, publishErrors = h.productUseCase.Create(c.Request.Context(), products, userEmail)
+		createErrors = h.productUseCase.Create(c.Request.Context(), products, actor)
 	}
 
 	// Refresh results
@@ -150,13 +143,6 @@ func (h *ProductHandler) Create(c *gin.Context) {
 			}
 			results[resultIndex].Errors["creation_error"] = errMsg
 			h.logger.Error("Failed to create product", zap.Int("sku", product.SKU), zap.String("error", errMsg))
-		} else if errMsg, exists := publishErrors[product.SKU]; exists {
-			results[resultIndex].Status = "error"
-			if results[resultIndex].Errors == nil {
-				results[resultIndex].Errors = make(map[string]string)
-			}
-			results[resultIndex].Errors["publish_error"] = errMsg
-			h.logger.Error("Failed to publish product event", zap.Int("sku", product.SKU), zap.String("error", errMsg))
 		} else {
 			results[resultIndex].Status = "ok"
 		}
@@ -180,6 +166,7 @@ func (h *ProductHandler) Create(c *gin.Context) {
 //	@Produce		json
 //	@Success		200	{array}	dtos.ProductResponseDTO	"Products retrieved successfully"
 //	@Security		bearerAuth
+//	@Security		apiKeyAuth
 //	@Router			/products [get]
 func (h *ProductHandler) GetAll(c *gin.Context) {
 	// Call the use case to retrieve all products
@@ -222,6 +209,7 @@ func (h *ProductHandler) GetAll(c *gin.Context) {
 //	@Param			sku	path		int						true	"Product SKU"
 //	@Success		200	{object}	dtos.ProductResponseDTO	"Product retrieved successfully"
 //	@Security		bearerAuth
+//	@Security		apiKeyAuth
 //	@Router			/products/{sku} [get]
 func (h *ProductHandler) GetBySKU(c *gin.Context) {
 	// Parse the SKU from the URL parameter
@@ -270,6 +258,7 @@ func (h *ProductHandler) GetBySKU(c *gin.Context) {
 //	@Param			products	body		[]dtos.UpdateProductDTO		true	"Product data to update"
 //	@Success		200			{object}	dtos.UpdateProductResponse	"Product(s) updated successfully"
 //	@Security		bearerAuth
+//	@Security		apiKeyAuth
 //	@Router			/products [put]
 func (h *ProductHandler) Update(c *gin.Context) {
 	// Read the raw request body to handle both single and multiple updates
@@ -296,17 +285,11 @@ func (h *ProductHandler) Update(c *gin.Context) {
 		inputs = []dtos.UpdateProductDTO{singleInput}
 	}
 
-	// Retrieve user email from the context
-	userEmailVal, exists := c.Get("userEmail")
-	if !exists {
-		h.logger.Error("User email not found in context")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "User email not found"})
-		return
-	}
-	userEmail, ok := userEmailVal.(string)
+	// Retrieve the authenticated user from the context
+	actor, ok := actorFromContext(c)
 	if !ok {
-		h.logger.Error("Invalid user email format in context")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user email data"})
+		h.logger.Error("User not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
@@ -347,12 +330,12 @@ func (h *ProductHandler) Update(c *gin.Context) {
 	}
 
 	// Call the use case to perform the actual update in the database
-	updateErrors := h.productUseCase.Update(c.Request.Context(), products, userEmail)
+	updateErrors := h.productUseCase.Update(c.Request.Context(), products, actor)
 
 	// If the use case returned errors, update the results accordingly
 	for i, product := range products {
 		if errMsg, exists := updateErrors[product.SKU]; exists {
-			results[i].Status = "error"
+			results[i].Status = errorStatus(errMsg)
 			if results[i].Errors == nil {
 				results[i].Errors = make(map[string]string)
 			}
@@ -382,6 +365,7 @@ func (h *ProductHandler) Update(c *gin.Context) {
 //	@Param			skus	body		[]int						true	"SKUs of products to delete"
 //	@Success		200		{object}	dtos.DeleteProductResponse	"Product(s) deleted successfully"
 //	@Security		bearerAuth
+//	@Security		apiKeyAuth
 //	@Router			/products [delete]
 func (h *ProductHandler) Delete(c *gin.Context) {
 	// Read the raw request body to handle both single and multiple SKUs
@@ -408,17 +392,11 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 
 	var results []batchResult
 
-	// Get user email from context
-	userEmailVal, exists := c.Get("userEmail")
-	if !exists {
-		h.logger.Error("User email not found in context", zap.String("operation", "delete"))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "User email not found"})
-		return
-	}
-	userEmail, ok := userEmailVal.(string)
+	// Get the authenticated user from context
+	actor, ok := actorFromContext(c)
 	if !ok {
-		h.logger.Error("Invalid user email format in context", zap.String("operation", "delete"))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user email data"})
+		h.logger.Error("User not found in context", zap.String("operation", "delete"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
@@ -432,10 +410,10 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 	}
 
 	// Call the use case to perform the deletion
-	deleteErrors := h.productUseCase.Delete(c.Request.Context(), skus, userEmail)
+	deleteErrors := h.productUseCase.Delete(c.Request.Context(), skus, actor)
 	for i, sku := range skus {
 		if errMsg, exists := deleteErrors[sku]; exists {
-			results[i].Status = "error"
+			results[i].Status = errorStatus(errMsg)
 			if results[i].Errors == nil {
 				results[i].Errors = make(map[string]string)
 			}
@@ -464,9 +442,18 @@ func (h *ProductHandler) readRequestBody(c *gin.Context) ([]byte, error) {
 	return body, nil
 }
 
+// errorStatus maps a use case error message to the per-item status reported in batch responses
+func errorStatus(errMsg string) string {
+	if strings.HasPrefix(errMsg, "Forbidden") {
+		return "forbidden"
+	}
+	return "error"
+}
+
 func determineHTTPStatus(results []batchResult) int {
 	allOk := true
 	allConflicts := true
+	allForbidden := true
 	for _, r := range results {
 		if r.Status != "ok" {
 			allOk = false
@@ -474,12 +461,17 @@ func determineHTTPStatus(results []batchResult) int {
 		if r.Status != "conflict" {
 			allConflicts = false
 		}
+		if r.Status != "forbidden" {
+			allForbidden = false
+		}
 	}
 	switch {
 	case allOk:
 		return http.StatusCreated // 201
 	case allConflicts:
 		return http.StatusConflict // 409
+	case allForbidden:
+		return http.StatusForbidden // 403
 	default:
 		return http.StatusMultiStatus // 207
 	}
@@ -0,0 +1,410 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+	"github.com/Amandasilvbr/products-crud/internal/dtos"
+	"github.com/Amandasilvbr/products-crud/internal/handler/validator"
+	uc "github.com/Amandasilvbr/products-crud/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// UserHandler handles user administration HTTP requests
+type UserHandler struct {
+	userUsecase    usecase.UserUsecaseInterface
+	lockoutUsecase usecase.LockoutUsecaseInterface
+	validator      *validator.UserValidator
+	logger         *zap.Logger
+}
+
+// NewUserHandler creates and returns a new instance of UserHandler
+func NewUserHandler(userUsecase usecase.UserUsecaseInterface, lockoutUsecase usecase.LockoutUsecaseInterface, logger *zap.Logger) *UserHandler {
+	return &UserHandler{
+		userUsecase:    userUsecase,
+		lockoutUsecase: lockoutUsecase,
+		validator:      validator.NewUserValidator(),
+		logger:         logger,
+	}
+}
+
+// AssignRole godoc
+//
+//	@Summary		Altera o papel de um usuário
+//	@Description	Define o papel (admin, editor ou viewer) de um usuário. O novo papel passa a valer no próximo login ou renovação de token. Restrito a administradores.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"User ID"
+//	@Param			role	body		dtos.AssignRoleDTO		true	"New role"
+//	@Success		200		{object}	dtos.MessageResponse	"Role assigned successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/users/{id}/role [put]
+func (h *UserHandler) AssignRole(c *gin.Context) {
+	// Parse the user ID from the URL parameter
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Debug("Invalid user ID", zap.Error(err), zap.String("operation", "assign_role"))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input dtos.AssignRoleDTO
+	// Bind the incoming JSON payload to the AssignRoleDTO struct
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Debug("Invalid assign role request body", zap.Error(err), zap.String("operation", "assign_role"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body format",
+		})
+		return
+	}
+
+	// Validate the input data using the user validator
+	if errors := h.validator.ValidateAssignRole(&input); len(errors) > 0 {
+		h.logger.Warn("Validation failed for assign role", zap.Any("errors", errors), zap.String("operation", "assign_role"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": errors,
+		})
+		return
+	}
+
+	// Call the use case to change the role
+	if err := h.userUsecase.AssignRole(uint(userID), input.Role); err != nil {
+		if errors.Is(err, uc.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		h.logger.Error("Failed to assign role", zap.Error(err), zap.String("operation", "assign_role"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign role"})
+		return
+	}
+
+	h.logger.Info("Role assigned",
+		zap.Uint64("user_id", userID),
+		zap.String("role", input.Role),
+		zap.String("admin_email", c.GetString("userEmail")),
+		zap.String("operation", "assign_role"))
+	c.JSON(http.StatusOK, gin.H{"message": "Role assigned successfully"})
+}
+
+// Unlock godoc
+//
+//	@Summary		Desbloqueia um usuário
+//	@Description	Remove o bloqueio temporário por tentativas de login malsucedidas e zera o contador de falhas da conta. Restrito a administradores.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int						true	"User ID"
+//	@Success		200	{object}	dtos.MessageResponse	"User unlocked successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/users/{id}/unlock [post]
+func (h *UserHandler) Unlock(c *gin.Context) {
+	// Parse the user ID from the URL parameter
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Debug("Invalid user ID", zap.Error(err), zap.String("operation", "unlock_user"))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.lockoutUsecase.Unlock(uint(userID), c.GetUint("userID")); err != nil {
+		if errors.Is(err, uc.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		h.logger.Error("Failed to unlock user", zap.Error(err), zap.String("operation", "unlock_user"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+
+	h.logger.Info("User unlocked",
+		zap.Uint64("user_id", userID),
+		zap.String("admin_email", c.GetString("userEmail")),
+		zap.String("operation", "unlock_user"))
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
+
+// List godoc
+//
+//	@Summary		Lista usuários
+//	@Description	Lista os usuários de forma paginada, com busca opcional por nome ou e-mail. Restrito a administradores.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			search		query		string						false	"Search by name or email"
+//	@Param			page		query		int							false	"Page number (default 1)"
+//	@Param			page_size	query		int							false	"Page size (default 20, max 100)"
+//	@Success		200			{object}	dtos.UserListResponseDTO	"Users retrieved successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/users [get]
+func (h *UserHandler) List(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(uc.DefaultUserPageSize)))
+	if err != nil || pageSize < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page size"})
+		return
+	}
+	if pageSize > uc.MaxUserPageSize {
+		pageSize = uc.MaxUserPageSize
+	}
+
+	users, total, err := h.userUsecase.ListUsers(c.Query("search"), page, pageSize)
+	if err != nil {
+		h.logger.Error("Failed to list users", zap.Error(err), zap.String("operation", "list_users"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+		return
+	}
+
+	items := make([]dtos.UserResponseDTO, 0, len(users))
+	for _, user := range users {
+		items = append(items, userResponse(user))
+	}
+	c.JSON(http.StatusOK, dtos.UserListResponseDTO{
+		Items:    items,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// Get godoc
+//
+//	@Summary		Busca um usuário
+//	@Description	Retorna os dados de um usuário pelo ID. Restrito a administradores.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int						true	"User ID"
+//	@Success		200	{object}	dtos.UserResponseDTO	"User retrieved successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/users/{id} [get]
+func (h *UserHandler) Get(c *gin.Context) {
+	userID, ok := h.parseUserID(c, "get_user")
+	if !ok {
+		return
+	}
+
+	user, err := h.userUsecase.GetUser(userID)
+	if err != nil {
+		h.respondError(c, err, "get_user", "Failed to get user")
+		return
+	}
+	c.JSON(http.StatusOK, userResponse(user))
+}
+
+// Update godoc
+//
+//	@Summary		Edita um usuário
+//	@Description	Altera o nome e/ou o e-mail de um usuário. Um novo e-mail precisa ser verificado novamente; os produtos criados passam para o novo nome. Restrito a administradores.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"User ID"
+//	@Param			user	body		dtos.UpdateUserDTO		true	"New name and/or email"
+//	@Success		200		{object}	dtos.UserResponseDTO	"User updated successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/users/{id} [put]
+func (h *UserHandler) Update(c *gin.Context) {
+	userID, ok := h.parseUserID(c, "update_user")
+	if !ok {
+		return
+	}
+	h.update(c, userID)
+}
+
+// Disable godoc
+//
+//	@Summary		Desativa um usuário
+//	@Description	Desativa a conta: o usuário não consegue mais fazer login, renovar tokens nem usar os tokens e chaves de API existentes. Restrito a administradores.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int						true	"User ID"
+//	@Success		200	{object}	dtos.MessageResponse	"User disabled successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/users/{id}/disable [post]
+func (h *UserHandler) Disable(c *gin.Context) {
+	h.setDisabled(c, true)
+}
+
+// Enable godoc
+//
+//	@Summary		Reativa um usuário
+//	@Description	Reativa uma conta desativada. Restrito a administradores.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int						true	"User ID"
+//	@Success		200	{object}	dtos.MessageResponse	"User enabled successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/users/{id}/enable [post]
+func (h *UserHandler) Enable(c *gin.Context) {
+	h.setDisabled(c, false)
+}
+
+// Delete godoc
+//
+//	@Summary		Exclui um usuário
+//	@Description	Exclui a conta; seus tokens deixam de ser aceitos e o e-mail pode ser usado em um novo cadastro. Restrito a administradores.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int						true	"User ID"
+//	@Success		200	{object}	dtos.MessageResponse	"User deleted successfully"
+//	@Security		bearerAuth
+//	@Router			/admin/users/{id} [delete]
+func (h *UserHandler) Delete(c *gin.Context) {
+	userID, ok := h.parseUserID(c, "delete_user")
+	if !ok {
+		return
+	}
+
+	if err := h.userUsecase.DeleteUser(userID, c.GetUint("userID")); err != nil {
+		h.respondError(c, err, "delete_user", "Failed to delete user")
+		return
+	}
+
+	h.logger.Info("User deleted", zap.Uint("user_id", userID), zap.String("admin_email", c.GetString("userEmail")), zap.String("operation", "delete_user"))
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
+
+// GetMe godoc
+//
+//	@Summary		Retorna o perfil do usuário autenticado
+//	@Description	Retorna os dados da conta do usuário autenticado.
+//	@Tags			Profile
+//	@Produce		json
+//	@Success		200	{object}	dtos.UserResponseDTO	"Profile retrieved successfully"
+//	@Security		bearerAuth
+//	@Router			/me [get]
+func (h *UserHandler) GetMe(c *gin.Context) {
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	user, err := h.userUsecase.GetUser(actor.ID)
+	if err != nil {
+		h.respondError(c, err, "get_me", "Failed to get profile")
+		return
+	}
+	c.JSON(http.StatusOK, userResponse(user))
+}
+
+// UpdateMe godoc
+//
+//	@Summary		Edita o perfil do usuário autenticado
+//	@Description	Altera o nome e/ou o e-mail do usuário autenticado. Um novo e-mail precisa ser verificado antes do próximo login.
+//	@Tags			Profile
+//	@Accept			json
+//	@Produce		json
+//	@Param			user	body		dtos.UpdateUserDTO		true	"New name and/or email"
+//	@Success		200		{object}	dtos.UserResponseDTO	"Profile updated successfully"
+//	@Security		bearerAuth
+//	@Router			/me [put]
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	h.update(c, actor.ID)
+}
+
+// update binds, validates and applies a profile change for the given user
+func (h *UserHandler) update(c *gin.Context, userID uint) {
+	var input dtos.UpdateUserDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Debug("Invalid update user request body", zap.Error(err), zap.String("operation", "update_user"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body format",
+		})
+		return
+	}
+
+	if errors := h.validator.ValidateUpdateUser(&input); len(errors) > 0 {
+		h.logger.Warn("Validation failed for update user", zap.Any("errors", errors), zap.String("operation", "update_user"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": errors,
+		})
+		return
+	}
+
+	user, err := h.userUsecase.UpdateUser(userID, input.Name, input.Email)
+	if err != nil {
+		h.respondError(c, err, "update_user", "Failed to update user")
+		return
+	}
+
+	h.logger.Info("User updated", zap.Uint("user_id", userID), zap.String("by", c.GetString("userEmail")), zap.String("operation", "update_user"))
+	c.JSON(http.StatusOK, userResponse(user))
+}
+
+// setDisabled disables or re-enables the user identified by the path parameter
+func (h *UserHandler) setDisabled(c *gin.Context, disabled bool) {
+	userID, ok := h.parseUserID(c, "set_disabled")
+	if !ok {
+		return
+	}
+
+	if err := h.userUsecase.SetDisabled(userID, disabled, c.GetUint("userID")); err != nil {
+		h.respondError(c, err, "set_disabled", "Failed to change account status")
+		return
+	}
+
+	message := "User enabled successfully"
+	if disabled {
+		message = "User disabled successfully"
+	}
+	h.logger.Info("Account status changed", zap.Uint("user_id", userID), zap.Bool("disabled", disabled), zap.String("admin_email", c.GetString("userEmail")), zap.String("operation", "set_disabled"))
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// parseUserID reads the user ID path parameter, writing a 400 response when it is invalid
+func (h *UserHandler) parseUserID(c *gin.Context, operation string) (uint, bool) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Debug("Invalid user ID", zap.Error(err), zap.String("operation", operation))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, false
+	}
+	return uint(userID), true
+}
+
+// respondError maps user use case errors to HTTP responses
+func (h *UserHandler) respondError(c *gin.Context, err error, operation, fallback string) {
+	switch {
+	case errors.Is(err, uc.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, uc.ErrEmailTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already in use"})
+	case errors.Is(err, uc.ErrNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "Name already in use"})
+	case errors.Is(err, uc.ErrCannotModifySelf):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Administrators cannot disable or delete their own account"})
+	default:
+		h.logger.Error(fallback, zap.Error(err), zap.String("operation", operation))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// userResponse maps a user model to its response DTO, leaving out credentials
+func userResponse(user *model.User) dtos.UserResponseDTO {
+	return dtos.UserResponseDTO{
+		ID:         user.ID,
+		Name:       user.Name,
+		Email:      user.Email,
+		Role:       user.Role,
+		VerifiedAt: user.VerifiedAt,
+		DisabledAt: user.DisabledAt,
+		MFAEnabled: user.MFAEnabled(),
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
+	}
+}
@@ -0,0 +1,53 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/Amandasilvbr/products-crud/internal/dtos"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// APIKeyValidator wraps the go-playground/validator instance
+// It provides methods for validating API key data transfer objects (DTOs)
+type APIKeyValidator struct {
+	validate *validator.Validate
+}
+
+// NewAPIKeyValidator creates and returns a new instance of APIKeyValidator
+func NewAPIKeyValidator() *APIKeyValidator {
+	v := validator.New()
+	return &APIKeyValidator{validate: v}
+}
+
+// ValidateCreateAPIKey checks a CreateAPIKeyDTO against a set of validation rules
+// It returns a map of validation errors for the API key fields
+func (v *APIKeyValidator) ValidateCreateAPIKey(dto *dtos.CreateAPIKeyDTO) map[string]string {
+	err := v.validate.Struct(dto)
+	if err == nil {
+		return nil // Return nil if validation passes
+	}
+
+	// Create a map to hold custom error messages
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		field := err.Field()
+		tag := err.Tag()
+		value := err.Value()
+
+		// Generate user-friendly error messages based on the field and validation rule
+		switch tag {
+		case "required":
+			errors[field] = fmt.Sprintf("The %s field is required and cannot be empty", field)
+		case "min":
+			errors[field] = fmt.Sprintf("The %s field is too short", field)
+		case "max":
+			errors[field] = fmt.Sprintf("The %s field is too long", field)
+		case "oneof":
+			errors[field] = fmt.Sprintf("The scope must be one of 'products:read' or 'products:write', got '%v'", value)
+		default:
+			errors[field] = fmt.Sprintf("Validation failed for field %s with rule %s, got value '%v'", field, tag, value)
+		}
+	}
+	return errors
+}
@@ -0,0 +1,52 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// OrganizationValidator wraps the go-playground/validator instance
+// It provides methods for validating organization, membership and invitation data transfer objects (DTOs)
+type OrganizationValidator struct {
+	validate *validator.Validate
+}
+
+// NewOrganizationValidator creates and returns a new instance of OrganizationValidator
+func NewOrganizationValidator() *OrganizationValidator {
+	v := validator.New()
+	return &OrganizationValidator{validate: v}
+}
+
+// ValidateOrganizationDTO checks one of the organization or invitation DTOs against its validation rules
+// It returns a map of validation errors for the name, slug, email, role and password fields
+func (v *OrganizationValidator) ValidateOrganizationDTO(dto interface{}) map[string]string {
+	err := v.validate.Struct(dto)
+	if err == nil {
+		return nil // Return nil if validation passes
+	}
+
+	// Create a map to hold custom error messages
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		field := err.Field()
+		tag := err.Tag()
+		value := err.Value()
+
+		switch tag {
+		case "required":
+			errors[field] = fmt.Sprintf("The %s field is required and cannot be empty", field)
+		case "min":
+			errors[field] = fmt.Sprintf("The %s field is too short", field)
+		case "max":
+			errors[field] = fmt.Sprintf("The %s field is too long", field)
+		case "email":
+			errors[field] = "The email must be a valid email address"
+		case "oneof":
+			errors[field] = fmt.Sprintf("The role must be one of 'admin', 'editor' or 'viewer', got '%v'", value)
+		default:
+			errors[field] = fmt.Sprintf("Validation failed for field %s with rule %s, got value '%v'", field, tag, value)
+		}
+	}
+	return errors
+}
@@ -91,3 +91,147 @@ func (v *UserValidator) ValidateUserCreate(dto *dtos.UserCreateDTO) map[string]s
 	}
 	return errors
 }
+
+// ValidateRefreshToken checks a RefreshTokenDTO against a set of validation rules
+// It returns a map of validation errors for the refresh token field
+func (v *UserValidator) ValidateRefreshToken(dto *dtos.RefreshTokenDTO) map[string]string {
+	err := v.validate.Struct(dto)
+	if err == nil {
+		return nil // Return nil if validation passes
+	}
+
+	// Create a map to hold custom error messages
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		field := err.Field()
+		tag := err.Tag()
+		value := err.Value()
+
+		switch field + "|" + tag {
+		case "RefreshToken|required":
+			errors[field] = "The refresh token field is required and cannot be empty"
+		default:
+			errors[field] = fmt.Sprintf("Validation failed for field %s with rule %s, got value '%v'", field, tag, value)
+		}
+	}
+	return errors
+}
+
+// ValidateAssignRole checks an AssignRoleDTO against a set of validation rules
+// It returns a map of validation errors for the role field
+func (v *UserValidator) ValidateAssignRole(dto *dtos.AssignRoleDTO) map[string]string {
+	err := v.validate.Struct(dto)
+	if err == nil {
+		return nil // Return nil if validation passes
+	}
+
+	// Create a map to hold custom error messages
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		field := err.Field()
+		tag := err.Tag()
+		value := err.Value()
+
+		switch field + "|" + tag {
+		case "Role|required":
+			errors[field] = "The role field is required and cannot be empty"
+		case "Role|oneof":
+			errors[field] = fmt.Sprintf("The role must be one of 'admin', 'editor' or 'viewer', got '%v'", value)
+		default:
+			errors[field] = fmt.Sprintf("Validation failed for field %s with rule %s, got value '%v'", field, tag, value)
+		}
+	}
+	return errors
+}
+
+// ValidatePasswordDTO checks one of the password or verification DTOs against its validation rules
+// It returns a map of validation errors for the email, token and password fields
+func (v *UserValidator) ValidatePasswordDTO(dto interface{}) map[string]string {
+	err := v.validate.Struct(dto)
+	if err == nil {
+		return nil // Return nil if validation passes
+	}
+
+	// Create a map to hold custom error messages
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		field := err.Field()
+		tag := err.Tag()
+		value := err.Value()
+
+		switch field + "|" + tag {
+		case "Email|required":
+			errors[field] = "The email field is required and cannot be empty"
+		case "Email|email":
+			errors[field] = fmt.Sprintf("The email must be a valid email address, got '%v'", value)
+		case "Token|required":
+			errors[field] = "The token field is required and cannot be empty"
+		case "CurrentPassword|required":
+			errors[field] = "The current password field is required and cannot be empty"
+		case "NewPassword|required":
+			errors[field] = "The new password field is required and cannot be empty"
+		case "NewPassword|min":
+			errors[field] = fmt.Sprintf("The new password must be at least 6 characters long, got %d characters", len(value.(string)))
+		default:
+			errors[field] = fmt.Sprintf("Validation failed for field %s with rule %s", field, tag)
+		}
+	}
+	return errors
+}
+
+// ValidateUpdateUser checks an UpdateUserDTO against a set of validation rules
+// It returns a map of validation errors for the profile fields
+func (v *UserValidator) ValidateUpdateUser(dto *dtos.UpdateUserDTO) map[string]string {
+	err := v.validate.Struct(dto)
+	if err == nil {
+		return nil // Return nil if validation passes
+	}
+
+	// Create a map to hold custom error messages
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		field := err.Field()
+		tag := err.Tag()
+		value := err.Value()
+
+		switch field + "|" + tag {
+		case "Name|min":
+			errors[field] = fmt.Sprintf("The name must be at least 3 characters long, got %d characters", len(value.(string)))
+		case "Name|max":
+			errors[field] = fmt.Sprintf("The name cannot exceed 100 characters, got %d characters", len(value.(string)))
+		case "Email|email":
+			errors[field] = fmt.Sprintf("The email must be a valid email address, got '%v'", value)
+		default:
+			errors[field] = fmt.Sprintf("Validation failed for field %s with rule %s, got value '%v'", field, tag, value)
+		}
+	}
+	return errors
+}
+
+// ValidateMFADTO checks one of the two-factor authentication DTOs against its validation rules
+// It returns a map of validation errors for the MFA token, code and policy fields
+func (v *UserValidator) ValidateMFADTO(dto interface{}) map[string]string {
+	err := v.validate.Struct(dto)
+	if err == nil {
+		return nil // Return nil if validation passes
+	}
+
+	// Create a map to hold custom error messages
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		field := err.Field()
+		tag := err.Tag()
+
+		switch field + "|" + tag {
+		case "MFAToken|required":
+			errors[field] = "The MFA token field is required and cannot be empty"
+		case "Code|required":
+			errors[field] = "The code field is required and cannot be empty"
+		case "Required|required":
+			errors[field] = "The required field must be true or false"
+		default:
+			errors[field] = fmt.Sprintf("Validation failed for field %s with rule %s", field, tag)
+		}
+	}
+	return errors
+}
@@ -59,10 +59,59 @@ func ConnectDB(cfg *config.Configs, zapLogger *zap.Logger) (*gorm.DB, error) {
 
 // RunMigrations applies auto-migrations for the specified GORM models
 func RunMigrations(db *gorm.DB, zapLogger *zap.Logger) error {
-	// AutoMigrate will create or update tables for the Product and User models
-	err := db.AutoMigrate(
+	if err := migrateUserPrimaryKey(db, zapLogger); err != nil {
+		zapLogger.Error("Failed to migrate users primary key", zap.Error(err))
+		panic("failed to run migrations: " + err.Error())
+	}
+
+	// Catalogs created before organizations existed are moved into a default organization
+	defaultOrgID, err := migrateOrganizations(db, zapLogger)
+	if err != nil {
+		zapLogger.Error("Failed to migrate products into organizations", zap.Error(err))
+		panic("failed to run migrations: " + err.Error())
+	}
+
+	// Accounts created before email verification existed are trusted as verified
+	backfillVerified := db.Migrator().HasTable(&model.User{}) && !db.Migrator().HasColumn(&model.User{}, "VerifiedAt")
+
+	// Products created before ownership was recorded by ID are matched to their creator by name
+	backfillCreators := db.Migrator().HasTable(&model.Product{}) && !db.Migrator().HasColumn(&model.Product{}, "CreatedByID")
+
+	// Events stored twice for the same user's digest are removed before they are made unique
+	if db.Migrator().HasTable(&model.DigestEntry{}) && !db.Migrator().HasIndex(&model.DigestEntry{}, "idx_digest_entries_user_event") {
+		result := db.Exec(`DELETE FROM digest_entries AS duplicate USING digest_entries AS kept
+			WHERE duplicate.event_id <> '' AND duplicate.user_id = kept.user_id
+			AND duplicate.event_id = kept.event_id AND duplicate.id > kept.id`)
+		if result.Error != nil {
+			zapLogger.Error("Failed to remove duplicate digest entries", zap.Error(result.Error))
+			panic("failed to run migrations: " + result.Error.Error())
+		}
+		zapLogger.Info("Removed duplicate digest entries", zap.Int64("count", result.RowsAffected))
+	}
+
+	// AutoMigrate will create or update tables for the application models
+	err = db.AutoMigrate(
+		&model.Organization{},
 		&model.Product{},
 		&model.User{},
+		&model.RefreshToken{},
+		&model.RevokedToken{},
+		&model.APIKey{},
+		&model.PasswordResetToken{},
+		&model.LoginThrottle{},
+		&model.AuthAuditEntry{},
+		&model.MFARecoveryCode{},
+		&model.MFAPolicy{},
+		&model.OIDCLoginState{},
+		&model.Membership{},
+		&model.Invitation{},
+		&model.OutboxEvent{},
+		&model.NotificationPreference{},
+		&model.UnsubscribedEmail{},
+		&model.DigestEntry{},
+		&model.InboxNotification{},
+		&model.ProcessedMessage{},
+		&model.EmailDelivery{},
 	)
 	// Handle migration errors by logging and terminating the application
 	if err != nil {
@@ -70,7 +119,126 @@ func RunMigrations(db *gorm.DB, zapLogger *zap.Logger) error {
 		panic("failed to run migrations: " + err.Error())
 	}
 
+	if backfillVerified {
+		result := db.Model(&model.User{}).Where("verified_at IS NULL").Update("verified_at", gorm.Expr("created_at"))
+		if result.Error != nil {
+			zapLogger.Error("Failed to mark existing users as verified", zap.Error(result.Error))
+			panic("failed to run migrations: " + result.Error.Error())
+		}
+		zapLogger.Info("Marked existing users as verified", zap.Int64("count", result.RowsAffected))
+	}
+
+	if backfillCreators {
+		// Names shared by several accounts are ambiguous; those products are left to organization admins
+		result := db.Exec(`UPDATE products SET created_by_id = users.id FROM users
+			WHERE products.created_by = users.name AND users.deleted_at IS NULL
+			AND (SELECT COUNT(*) FROM users AS namesakes WHERE namesakes.name = users.name AND namesakes.deleted_at IS NULL) = 1`)
+		if result.Error != nil {
+			zapLogger.Error("Failed to record existing product creators", zap.Error(result.Error))
+			panic("failed to run migrations: " + result.Error.Error())
+		}
+		zapLogger.Info("Recorded existing product creators", zap.Int64("count", result.RowsAffected))
+	}
+
+	// Existing users join the default organization with the role they held globally
+	if defaultOrgID != 0 {
+		result := db.Exec(`INSERT INTO memberships (user_id, org_id, role, created_at, updated_at)
+			SELECT id, ?, role, NOW(), NOW() FROM users WHERE deleted_at IS NULL
+			ON CONFLICT DO NOTHING`, defaultOrgID)
+		if result.Error != nil {
+			zapLogger.Error("Failed to add existing users to the default organization", zap.Error(result.Error))
+			panic("failed to run migrations: " + result.Error.Error())
+		}
+		zapLogger.Info("Added existing users to the default organization", zap.Int64("count", result.RowsAffected))
+	}
+
 	// Log successful migration
 	zapLogger.Info("Migration completed successfully")
 	return nil
 }
+
+// migrateUserPrimaryKey drops the name column from the users primary key
+// Earlier versions tagged User.Name as a primary key, which GORM combined with the ID;
+// AutoMigrate never changes primary keys, so the constraint is rebuilt on the ID alone
+func migrateUserPrimaryKey(db *gorm.DB, zapLogger *zap.Logger) error {
+	if !db.Migrator().HasTable(&model.User{}) {
+		return nil
+	}
+
+	var constraint string
+	err := db.Raw(`SELECT tc.constraint_name
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+			ON kcu.constraint_name = tc.constraint_name AND kcu.table_name = tc.table_name
+		WHERE tc.table_name = 'users' AND tc.constraint_type = 'PRIMARY KEY' AND kcu.column_name = 'name'`).
+		Scan(&constraint).Error
+	if err != nil || constraint == "" {
+		return err
+	}
+
+	err = db.Exec(fmt.Sprintf(`ALTER TABLE users DROP CONSTRAINT %q, ADD PRIMARY KEY (id)`, constraint)).Error
+	if err != nil {
+		return err
+	}
+	zapLogger.Info("Rebuilt users primary key on id", zap.String("dropped_constraint", constraint))
+	return nil
+}
+
+// migrateOrganizations moves a catalog created before organizations existed into a "default" organization
+// The products primary key becomes (org_id, sku) so that SKUs are only unique within an organization,
+// and existing API keys are bound to the same organization
+// It returns the ID of the default organization when one was created, or 0 when there was nothing to migrate
+func migrateOrganizations(db *gorm.DB, zapLogger *zap.Logger) (uint, error) {
+	if !db.Migrator().HasTable(&model.Product{}) || db.Migrator().HasColumn(&model.Product{}, "OrgID") {
+		return 0, nil
+	}
+
+	var orgID uint
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(&model.Organization{}); err != nil {
+			return err
+		}
+		org := &model.Organization{Name: "Default", Slug: "default"}
+		if err := tx.Where("slug = ?", org.Slug).FirstOrCreate(org).Error; err != nil {
+			return err
+		}
+		orgID = org.ID
+
+		if err := addOrgColumn(tx, "products", orgID); err != nil {
+			return err
+		}
+		var constraint string
+		err := tx.Raw(`SELECT constraint_name FROM information_schema.table_constraints
+			WHERE table_name = 'products' AND constraint_type = 'PRIMARY KEY'`).Scan(&constraint).Error
+		if err != nil {
+			return err
+		}
+		if constraint != "" {
+			if err := tx.Exec(fmt.Sprintf(`ALTER TABLE products DROP CONSTRAINT %q`, constraint)).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec(`ALTER TABLE products ADD PRIMARY KEY (org_id, sku)`).Error; err != nil {
+			return err
+		}
+
+		if tx.Migrator().HasTable(&model.APIKey{}) && !tx.Migrator().HasColumn(&model.APIKey{}, "OrgID") {
+			return addOrgColumn(tx, "api_keys", orgID)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	zapLogger.Info("Moved existing products into the default organization", zap.Uint("org_id", orgID))
+	return orgID, nil
+}
+
+// addOrgColumn adds a required org_id column to a table, filling existing rows with the given organization
+func addOrgColumn(tx *gorm.DB, table string, orgID uint) error {
+	if err := tx.Exec(fmt.Sprintf(`ALTER TABLE %q ADD COLUMN org_id bigint NOT NULL DEFAULT %d`, table, orgID)).Error; err != nil {
+		return err
+	}
+	return tx.Exec(fmt.Sprintf(`ALTER TABLE %q ALTER COLUMN org_id DROP DEFAULT`, table)).Error
+}
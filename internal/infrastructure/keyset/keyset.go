@@ -0,0 +1,258 @@
+package keyset
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/identity"
+
+	"github.com/dgrijalva/jwt-go"
+	"go.uber.org/zap"
+)
+
+// minRSABits is the smallest RSA modulus accepted for signing or verification
+const minRSABits = 2048
+
+// Ensure KeySet implements the domain interface at compile time
+var _ identity.KeySet = (*KeySet)(nil)
+
+// KeySet holds the active signing key and the retired keys still accepted for verification
+// Without an asymmetric key it falls back to HS256 with the shared secret, and publishes no keys
+type KeySet struct {
+	active *signingKey
+	keys   map[string]*signingKey
+	secret []byte
+	logger *zap.Logger
+}
+
+// signingKey is one asymmetric key, identified by its RFC 7638 thumbprint
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+	jwk     identity.JSONWebKey
+}
+
+// Load reads the signing and retired keys configured as PEM files
+func Load(cfg *config.Configs, logger *zap.Logger) (*KeySet, error) {
+	var active crypto.PrivateKey
+	if cfg.JWTSigningKeyFile != "" {
+		block, err := readPEM(cfg.JWTSigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if active, err = parsePrivateKey(block); err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.JWTSigningKeyFile, err)
+		}
+	}
+
+	var retired []crypto.PublicKey
+	for _, path := range cfg.JWTRetiredKeyFiles {
+		block, err := readPEM(path)
+		if err != nil {
+			return nil, err
+		}
+		public, err := parsePublicKey(block)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		retired = append(retired, public)
+	}
+
+	return New(cfg.JWTSecret, active, retired, logger)
+}
+
+// New creates a key set signing with the given private key, or with HS256 and the secret when it is nil
+// Retired public keys only verify tokens, so rotation keeps earlier tokens valid until they expire
+func New(secret string, privateKey crypto.PrivateKey, retiredKeys []crypto.PublicKey, logger *zap.Logger) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*signingKey), logger: logger}
+
+	if privateKey == nil {
+		if secret == "" {
+			return nil, errors.New("a JWT signing key or secret is required")
+		}
+		ks.secret = []byte(secret)
+		logger.Info("Signing access tokens with HS256", zap.String("operation", "load_keys"))
+		return ks, nil
+	}
+
+	active, err := newSigningKey(privateKey, publicKeyOf(privateKey))
+	if err != nil {
+		return nil, err
+	}
+	ks.active = active
+	ks.keys[active.kid] = active
+
+	for _, public := range retiredKeys {
+		key, err := newSigningKey(nil, public)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := ks.keys[key.kid]; !exists {
+			ks.keys[key.kid] = key
+		}
+	}
+
+	logger.Info("Signing access tokens with asymmetric key",
+		zap.String("kid", active.kid),
+		zap.String("alg", active.method.Alg()),
+		zap.Int("retired_keys", len(ks.keys)-1),
+		zap.String("operation", "load_keys"))
+	return ks, nil
+}
+
+// Sign returns a JWT for the claims, signed with the active key and carrying its kid header
+func (ks *KeySet) Sign(claims map[string]interface{}) (string, error) {
+	if ks.active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims(claims)).SignedString(ks.secret)
+	}
+	token := jwt.NewWithClaims(ks.active.method, jwt.MapClaims(claims))
+	token.Header["kid"] = ks.active.kid
+	return token.SignedString(ks.active.private)
+}
+
+// Verify checks the signature and expiry of a JWT and returns its claims
+// With asymmetric keys, the kid header selects the key and its algorithm must match; HS256 tokens are refused
+func (ks *KeySet) Verify(rawToken string) (map[string]interface{}, error) {
+	token, err := jwt.Parse(rawToken, func(token *jwt.Token) (interface{}, error) {
+		if ks.active == nil {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return ks.secret, nil
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return key.public, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
+
+// JWKS returns the public part of the active and retired keys
+func (ks *KeySet) JWKS() identity.JSONWebKeySet {
+	set := identity.JSONWebKeySet{Keys: []identity.JSONWebKey{}}
+	if ks.active == nil {
+		return set
+	}
+	// The active key comes first, so clients that pick the first key get the current one
+	set.Keys = append(set.Keys, ks.active.jwk)
+	for kid, key := range ks.keys {
+		if kid != ks.active.kid {
+			set.Keys = append(set.Keys, key.jwk)
+		}
+	}
+	return set
+}
+
+// newSigningKey describes an RSA or Ed25519 key, with its algorithm, JWK and thumbprint kid
+func newSigningKey(private crypto.PrivateKey, public crypto.PublicKey) (*signingKey, error) {
+	key := &signingKey{private: private, public: public}
+
+	var thumbprintInput string
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits, got %d", minRSABits, pub.N.BitLen())
+		}
+		key.method = jwt.SigningMethodRS256
+		key.jwk = identity.JSONWebKey{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}
+		thumbprintInput = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, key.jwk.E, key.jwk.N)
+	case ed25519.PublicKey:
+		key.method = SigningMethodEdDSA
+		key.jwk = identity.JSONWebKey{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}
+		thumbprintInput = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, key.jwk.X)
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, expected RSA or Ed25519", public)
+	}
+
+	thumbprint := sha256.Sum256([]byte(thumbprintInput))
+	key.kid = base64.RawURLEncoding.EncodeToString(thumbprint[:])
+	key.jwk.Kid = key.kid
+	key.jwk.Use = "sig"
+	key.jwk.Alg = key.method.Alg()
+	return key, nil
+}
+
+// publicKeyOf returns the public half of an RSA or Ed25519 private key
+func publicKeyOf(private crypto.PrivateKey) crypto.PublicKey {
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		return &key.PublicKey
+	case ed25519.PrivateKey:
+		return key.Public()
+	}
+	return nil
+}
+
+// readPEM reads the first PEM block of a key file
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+	return block, nil
+}
+
+// parsePrivateKey parses a PKCS#8 or PKCS#1 private key
+func parsePrivateKey(block *pem.Block) (crypto.PrivateKey, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("unsupported PEM block %q, expected a private key", block.Type)
+}
+
+// parsePublicKey parses a public key, or takes the public half of a private key
+func parsePublicKey(block *pem.Block) (crypto.PublicKey, error) {
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	private, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, err
+	}
+	return publicKeyOf(private), nil
+}
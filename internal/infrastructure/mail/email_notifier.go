@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+)
+
+// Ensure EmailNotifier implements the Notifier interface at compile time
+var _ messaging.Notifier = (*EmailNotifier)(nil)
+
+// EmailNotifier emails product notifications according to the recipient's notification preferences
+type EmailNotifier struct {
+	notifications usecase.NotificationUsecaseInterface
+	mailer        messaging.Mailer
+	logger        *zap.Logger
+}
+
+// NewEmailNotifier creates an EmailNotifier sending the emails with the given mailer
+func NewEmailNotifier(notifications usecase.NotificationUsecaseInterface, mailer messaging.Mailer, logger *zap.Logger) *EmailNotifier {
+	return &EmailNotifier{
+		notifications: notifications,
+		mailer:        mailer,
+		logger:        logger,
+	}
+}
+
+// Channel returns the name of the email channel in the routing rules
+func (n *EmailNotifier) Channel() string {
+	return messaging.ChannelEmail
+}
+
+// Notify sends one email with the notifications the recipient wants now; the others are stored for
+// their digest or dropped by the preferences
+func (n *EmailNotifier) Notify(ctx context.Context, recipient string, notifications []model.ProductNotification) error {
+	_, email, err := n.notifications.Route(ctx, recipient, notifications)
+	if err != nil {
+		return err
+	}
+	if email == nil {
+		return nil
+	}
+
+	n.logger.Info("Trying to send batch email", zap.String("to", recipient), zap.String("subject", email.Subject), zap.Int("event_count", len(notifications)))
+	if err := n.mailer.Send(ctx, email); err != nil {
+		if IsPermanent(err) {
+			return fmt.Errorf("%w: %w", messaging.ErrPermanentDelivery, err)
+		}
+		return err
+	}
+	n.logger.Info("Batch email sent successfully", zap.String("to", recipient))
+	return nil
+}
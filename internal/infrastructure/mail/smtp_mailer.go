@@ -0,0 +1,78 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/smtp"
+	"net/textproto"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+
+	"github.com/jordan-wright/email"
+	"go.uber.org/zap"
+)
+
+// Ensure SMTPMailer implements the Mailer interface at compile time
+var _ messaging.Mailer = (*SMTPMailer)(nil)
+
+// SMTPMailer sends emails through the SMTP server configured in config.Configs
+type SMTPMailer struct {
+	host     string
+	port     string
+	user     string
+	password string
+	from     string
+	logger   *zap.Logger
+}
+
+// NewSMTPMailer creates a new SMTPMailer from the application configuration
+func NewSMTPMailer(cfg *config.Configs, logger *zap.Logger) *SMTPMailer {
+	return &SMTPMailer{
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		user:     cfg.SMTPUser,
+		password: cfg.SMTPPassword,
+		from:     cfg.SMTPFrom,
+		logger:   logger,
+	}
+}
+
+// Send delivers the email using PLAIN authentication against the configured SMTP server
+func (m *SMTPMailer) Send(ctx context.Context, msg *messaging.Email) error {
+	// Validate that required SMTP configuration is present
+	if m.host == "" || m.port == "" || m.from == "" {
+		err := fmt.Errorf("missing SMTP configuration: host=%s, port=%s, from=%s", m.host, m.port, m.from)
+		m.logger.Error("Invalid SMTP configuration", zap.Error(err))
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	e := email.NewEmail()
+	e.From = m.from
+	e.To = msg.To
+	e.Subject = msg.Subject
+	e.Text = []byte(msg.Text)
+	if msg.HTML != "" {
+		e.HTML = []byte(msg.HTML)
+	}
+
+	smtpAddr := fmt.Sprintf("%s:%s", m.host, m.port)
+	if err := e.Send(smtpAddr, smtp.PlainAuth("", m.user, m.password, m.host)); err != nil {
+		m.logger.Error("Failed to send email", zap.Strings("to", msg.To), zap.String("subject", msg.Subject), zap.Error(err))
+		return err
+	}
+
+	m.logger.Info("Email sent successfully", zap.Strings("to", msg.To), zap.String("subject", msg.Subject))
+	return nil
+}
+
+// IsPermanent reports whether a sending error can never succeed on retry, such as a rejected
+// recipient address; SMTP servers signal these failures with 5xx replies
+func IsPermanent(err error) bool {
+	var reply *textproto.Error
+	return errors.As(err, &reply) && reply.Code >= 500
+}
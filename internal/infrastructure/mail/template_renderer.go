@@ -0,0 +1,267 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	// Embed the time zone database so that user time zones resolve on hosts without it
+	_ "time/tzdata"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+
+	"go.uber.org/zap"
+)
+
+// Ensure TemplateRenderer implements the EmailRenderer interface at compile time
+var _ messaging.EmailRenderer = (*TemplateRenderer)(nil)
+
+// defaultTemplates holds the built-in templates and locale bundles
+//
+//go:embed templates
+var defaultTemplates embed.FS
+
+// Names of the product digest templates, relative to the template directory
+const (
+	productDigestHTML = "product_digest.html.tmpl"
+	productDigestText = "product_digest.txt.tmpl"
+)
+
+// TemplateRenderer renders notification emails from html/template and text/template files
+// Built-in templates and locale bundles can be overridden by files with the same name in a directory;
+// a bundle there only needs the keys it changes
+type TemplateRenderer struct {
+	html            *htmltemplate.Template
+	text            *texttemplate.Template
+	bundles         map[string]map[string]string
+	defaultTimeZone *time.Location
+	logger          *zap.Logger
+}
+
+// NewTemplateRenderer loads the templates and locale bundles, preferring the files found in dir
+// An empty dir uses the built-in files only
+func NewTemplateRenderer(dir, defaultTimeZone string, logger *zap.Logger) (*TemplateRenderer, error) {
+	zone, err := time.LoadLocation(defaultTimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default time zone %q: %w", defaultTimeZone, err)
+	}
+
+	htmlSource, err := readTemplate(dir, productDigestHTML)
+	if err != nil {
+		return nil, err
+	}
+	htmlTemplate, err := htmltemplate.New(productDigestHTML).Parse(htmlSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", productDigestHTML, err)
+	}
+	textSource, err := readTemplate(dir, productDigestText)
+	if err != nil {
+		return nil, err
+	}
+	textTemplate, err := texttemplate.New(productDigestText).Parse(textSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", productDigestText, err)
+	}
+
+	bundles := make(map[string]map[string]string)
+	for _, locale := range []string{model.LocalePortuguese, model.LocaleEnglish, model.LocaleSpanish} {
+		bundle, err := readBundle(dir, locale)
+		if err != nil {
+			return nil, err
+		}
+		bundles[locale] = bundle
+	}
+
+	if dir != "" {
+		logger.Info("Email templates loaded", zap.String("template_dir", dir))
+	}
+	return &TemplateRenderer{
+		html:            htmlTemplate,
+		text:            textTemplate,
+		bundles:         bundles,
+		defaultTimeZone: zone,
+		logger:          logger,
+	}, nil
+}
+
+// readTemplate returns the template from dir when it exists there, or the built-in one
+func readTemplate(dir, name string) (string, error) {
+	if dir != "" {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return string(content), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("failed to read template %s: %w", name, err)
+		}
+	}
+	content, err := defaultTemplates.ReadFile(path.Join("templates", name))
+	if err != nil {
+		return "", fmt.Errorf("failed to read built-in template %s: %w", name, err)
+	}
+	return string(content), nil
+}
+
+// readBundle loads the built-in bundle of a locale and applies the keys of dir/locales/<locale>.json
+func readBundle(dir, locale string) (map[string]string, error) {
+	bundle := make(map[string]string)
+	content, err := defaultTemplates.ReadFile(path.Join("templates", "locales", locale+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read built-in locale %s: %w", locale, err)
+	}
+	if err := json.Unmarshal(content, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse built-in locale %s: %w", locale, err)
+	}
+
+	if dir == "" {
+		return bundle, nil
+	}
+	content, err = os.ReadFile(filepath.Join(dir, "locales", locale+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return bundle, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read locale %s: %w", locale, err)
+	}
+	overrides := make(map[string]string)
+	if err := json.Unmarshal(content, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse locale %s: %w", locale, err)
+	}
+	for key, value := range overrides {
+		bundle[key] = value
+	}
+	return bundle, nil
+}
+
+// digestView is the data given to the product digest templates
+// Its strings are plain text; html/template escapes them, product names included
+type digestView struct {
+	bundle         map[string]string
+	Title          string
+	Greeting       string
+	Intro          string
+	Items          []digestItem
+	Date           string
+	UnsubscribeURL string
+}
+
+// digestItem is one product change of a digest
+type digestItem struct {
+	Name    string
+	SKU     int
+	Action  string
+	OrgName string
+	At      string
+}
+
+// T returns the localized text of a key, formatted with the arguments
+// Missing keys are returned as they are, so that a broken bundle is visible without failing the email
+func (v *digestView) T(key string, args ...interface{}) string {
+	text, ok := v.bundle[key]
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// RenderProductDigest renders the subject, text and HTML of a digest in the recipient's locale and time zone
+// Unsupported locales and unknown time zones fall back to the defaults
+func (r *TemplateRenderer) RenderProductDigest(digest *messaging.ProductDigest) (*messaging.Email, error) {
+	bundle, ok := r.bundles[digest.Locale]
+	if !ok {
+		bundle = r.bundles[model.DefaultLocale]
+	}
+	zone := r.defaultTimeZone
+	if digest.TimeZone != "" {
+		if loaded, err := time.LoadLocation(digest.TimeZone); err == nil {
+			zone = loaded
+		} else {
+			r.logger.Warn("Unknown time zone, using the default", zap.String("time_zone", digest.TimeZone))
+		}
+	}
+	frequency := digest.Frequency
+	if frequency == "" {
+		frequency = model.FrequencyImmediate
+	}
+	sentAt := digest.SentAt
+	if sentAt.IsZero() {
+		sentAt = time.Now()
+	}
+
+	view := &digestView{bundle: bundle, UnsubscribeURL: digest.UnsubscribeURL}
+	layout := view.T("datetime_layout")
+	view.Title = view.T("title." + frequency)
+	view.Date = sentAt.In(zone).Format(layout)
+	if digest.RecipientName != "" {
+		view.Greeting = view.T("greeting", digest.RecipientName)
+	} else {
+		view.Greeting = view.T("greeting.anonymous")
+	}
+	if len(digest.Notifications) == 1 {
+		view.Intro = view.T("intro.one")
+	} else {
+		view.Intro = view.T("intro.other")
+	}
+
+	// Count each event, in the order events first appear, for the subject
+	var events []string
+	counts := make(map[string]int)
+	for _, notification := range digest.Notifications {
+		if counts[notification.Event] == 0 {
+			events = append(events, notification.Event)
+		}
+		counts[notification.Event]++
+
+		action, ok := bundle["action."+notification.Event]
+		if !ok {
+			action = notification.Event
+		}
+		item := digestItem{Name: notification.Name, SKU: notification.SKU, Action: action, OrgName: notification.OrgName}
+		// Immediate emails go out seconds after the changes; only digests show when each one happened
+		if frequency != model.FrequencyImmediate && !notification.At.IsZero() {
+			item.At = notification.At.In(zone).Format(layout)
+		}
+		view.Items = append(view.Items, item)
+	}
+	summary := make([]string, 0, len(events))
+	for _, event := range events {
+		plural := "other"
+		if counts[event] == 1 {
+			plural = "one"
+		}
+		if _, ok := bundle["summary."+event+"."+plural]; ok {
+			summary = append(summary, view.T("summary."+event+"."+plural, counts[event]))
+		} else {
+			summary = append(summary, view.T("summary.unknown", counts[event], event))
+		}
+	}
+
+	var htmlBody, textBody bytes.Buffer
+	if err := r.html.Execute(&htmlBody, view); err != nil {
+		r.logger.Error("Failed to render HTML email", zap.Error(err))
+		return nil, err
+	}
+	if err := r.text.Execute(&textBody, view); err != nil {
+		r.logger.Error("Failed to render text email", zap.Error(err))
+		return nil, err
+	}
+
+	return &messaging.Email{
+		Subject: view.T("subject."+frequency, strings.Join(summary, ", ")),
+		Text:    textBody.String(),
+		HTML:    htmlBody.String(),
+	}, nil
+}
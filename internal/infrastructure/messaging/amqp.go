@@ -0,0 +1,85 @@
+package messaging
+
+import (
+	"context"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// AMQPDial opens a connection to the AMQP broker at the URL
+// The RabbitMQ client dials through it on start and on every reconnection, which lets tests replace the broker
+type AMQPDial func(url string) (AMQPConnection, error)
+
+// AMQPConnection is the part of an AMQP connection the RabbitMQ client uses
+type AMQPConnection interface {
+	Channel() (AMQPChannel, error)
+	NotifyClose(receiver chan *amqp091.Error) chan *amqp091.Error
+	Close() error
+}
+
+// AMQPChannel is the part of an AMQP channel the RabbitMQ client uses
+type AMQPChannel interface {
+	Confirm(noWait bool) error
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	NotifyClose(receiver chan *amqp091.Error) chan *amqp091.Error
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp091.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp091.Table) (amqp091.Queue, error)
+	QueueDeclarePassive(name string, durable, autoDelete, exclusive, noWait bool, args amqp091.Table) (amqp091.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp091.Table) error
+	QueueUnbind(name, key, exchange string, args amqp091.Table) error
+	QueueDelete(name string, ifUnused, ifEmpty, noWait bool) (int, error)
+	QueuePurge(name string, noWait bool) (int, error)
+	Get(queue string, autoAck bool) (amqp091.Delivery, bool, error)
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp091.Table) (<-chan amqp091.Delivery, error)
+	// PublishConfirmed publishes a message on a channel in confirm mode and returns its pending confirmation
+	PublishConfirmed(ctx context.Context, exchange, key string, msg amqp091.Publishing) (Confirmation, error)
+	Close() error
+}
+
+// Confirmation is the broker's pending answer to a published message
+type Confirmation interface {
+	// WaitContext blocks until the broker acknowledges (true) or refuses (false) the message, or the context ends
+	WaitContext(ctx context.Context) (bool, error)
+}
+
+// DialAMQP connects to a RabbitMQ server
+func DialAMQP(url string) (AMQPConnection, error) {
+	conn, err := amqp091.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return amqpConnection{conn}, nil
+}
+
+// amqpConnection adapts an amqp091 connection to AMQPConnection
+type amqpConnection struct {
+	*amqp091.Connection
+}
+
+func (c amqpConnection) Channel() (AMQPChannel, error) {
+	ch, err := c.Connection.Channel()
+	if err != nil {
+		return nil, err
+	}
+	return amqpChannel{ch}, nil
+}
+
+// amqpChannel adapts an amqp091 channel to AMQPChannel
+type amqpChannel struct {
+	*amqp091.Channel
+}
+
+func (c amqpChannel) PublishConfirmed(ctx context.Context, exchange, key string, msg amqp091.Publishing) (Confirmation, error) {
+	confirmation, err := c.PublishWithDeferredConfirmWithContext(
+		ctx,
+		exchange, // Exchange
+		key,      // Routing key
+		false,    // Mandatory
+		false,    // Immediate
+		msg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return confirmation, nil
+}
@@ -0,0 +1,172 @@
+package messaging
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+
+	"github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// Ensure RabbitMQClient implements the DeadLetterQueues interface at compile time
+var _ messaging.DeadLetterQueues = (*RabbitMQClient)(nil)
+
+// Reject settles a message whose processing failed, instead of requeueing it forever
+// Permanent failures and messages out of attempts are sent to the dead-letter queue; the others wait
+// in the retry queue for the policy's delay before being delivered again, with their attempt counted
+func (c *RabbitMQClient) Reject(ctx context.Context, queueName string, msg amqp091.Delivery, cause error, permanent bool) {
+	attempt := messaging.Attempts(msg) + 1
+	deadLetter := c.retry.ShouldDeadLetter(attempt, permanent)
+
+	headers := amqp091.Table{}
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	headers[messaging.AttemptsHeader] = int32(attempt)
+	headers[messaging.LastErrorHeader] = cause.Error()
+	republished := amqp091.Publishing{
+		Headers:      headers,
+		ContentType:  msg.ContentType,
+		DeliveryMode: amqp091.Persistent,
+		MessageId:    msg.MessageId,
+		Timestamp:    msg.Timestamp,
+		Body:         msg.Body,
+	}
+
+	exchange, key := "", queueName+messaging.RetrySuffix
+	if deadLetter {
+		exchange, key = queueName+messaging.DeadLetterExchangeSuffix, ""
+	} else {
+		// The message expires from the retry queue back into the work queue
+		republished.Expiration = strconv.FormatInt(c.retry.Delay.Milliseconds(), 10)
+	}
+
+	ch, err := c.channel()
+	if err == nil {
+		err = publish(ctx, ch, exchange, key, republished)
+	}
+	if err != nil {
+		// Without the copy, let the broker dead-letter the message or deliver it again
+		c.logger.Error("Failed to republish rejected message", zap.String("queue", queueName), zap.String("message_id", msg.MessageId), zap.Bool("dead_letter", deadLetter), zap.Error(err))
+		msg.Nack(false, !deadLetter)
+		return
+	}
+	msg.Ack(false)
+
+	if deadLetter {
+		c.logger.Error("Message sent to the dead-letter queue", zap.String("queue", queueName), zap.String("message_id", msg.MessageId), zap.Int("attempts", attempt), zap.Bool("permanent", permanent), zap.NamedError("cause", cause))
+		return
+	}
+	c.logger.Warn("Message scheduled for retry", zap.String("queue", queueName), zap.String("message_id", msg.MessageId), zap.Int("attempts", attempt), zap.Duration("delay", c.retry.Delay), zap.NamedError("cause", cause))
+}
+
+// Peek returns up to limit messages of a dead-letter queue and puts them back in place
+func (c *RabbitMQClient) Peek(ctx context.Context, queueName string, limit int) ([]messaging.DeadLetter, error) {
+	ch, err := c.channel()
+	if err != nil {
+		return nil, err
+	}
+
+	var taken []amqp091.Delivery
+	defer func() {
+		for _, msg := range taken {
+			msg.Nack(false, true)
+		}
+	}()
+
+	letters := []messaging.DeadLetter{}
+	for len(letters) < limit {
+		msg, ok, err := ch.Get(queueName+messaging.DeadLetterSuffix, false)
+		if err != nil {
+			c.logger.Error("Failed to read dead-letter queue", zap.String("queue", queueName), zap.Error(err))
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		taken = append(taken, msg)
+		lastError, _ := msg.Headers[messaging.LastErrorHeader].(string)
+		letters = append(letters, messaging.DeadLetter{
+			MessageID: msg.MessageId,
+			Body:      string(msg.Body),
+			Attempts:  messaging.Attempts(msg),
+			LastError: lastError,
+			Timestamp: msg.Timestamp,
+		})
+	}
+	return letters, nil
+}
+
+// Requeue moves up to limit messages of a dead-letter queue back to its work queue
+// A limit of zero moves the messages present when the call starts
+func (c *RabbitMQClient) Requeue(ctx context.Context, queueName string, limit int) (int, error) {
+	ch, err := c.channel()
+	if err != nil {
+		return 0, err
+	}
+
+	dlq := queueName + messaging.DeadLetterSuffix
+	if limit <= 0 {
+		queue, err := ch.QueueDeclarePassive(dlq, true, false, false, false, nil)
+		if err != nil {
+			c.logger.Error("Failed to inspect dead-letter queue", zap.String("queue", queueName), zap.Error(err))
+			return 0, err
+		}
+		limit = queue.Messages
+	}
+
+	moved := 0
+	for moved < limit {
+		msg, ok, err := ch.Get(dlq, false)
+		if err != nil {
+			c.logger.Error("Failed to read dead-letter queue", zap.String("queue", queueName), zap.Error(err))
+			return moved, err
+		}
+		if !ok {
+			break
+		}
+
+		// The message starts over with a fresh attempt count
+		headers := amqp091.Table{}
+		for key, value := range msg.Headers {
+			if key != messaging.AttemptsHeader && key != messaging.LastErrorHeader && key != "x-death" {
+				headers[key] = value
+			}
+		}
+		err = publish(ctx, ch, "", queueName, amqp091.Publishing{
+			Headers:      headers,
+			ContentType:  msg.ContentType,
+			DeliveryMode: amqp091.Persistent,
+			MessageId:    msg.MessageId,
+			Timestamp:    msg.Timestamp,
+			Body:         msg.Body,
+		})
+		if err != nil {
+			msg.Nack(false, true)
+			c.logger.Error("Failed to requeue dead-lettered message", zap.String("queue", queueName), zap.String("message_id", msg.MessageId), zap.Error(err))
+			return moved, err
+		}
+		msg.Ack(false)
+		moved++
+	}
+
+	c.logger.Info("Requeued dead-lettered messages", zap.String("queue", queueName), zap.Int("count", moved))
+	return moved, nil
+}
+
+// Purge removes every message of a dead-letter queue
+func (c *RabbitMQClient) Purge(ctx context.Context, queueName string) (int, error) {
+	ch, err := c.channel()
+	if err != nil {
+		return 0, err
+	}
+	purged, err := ch.QueuePurge(queueName+messaging.DeadLetterSuffix, false)
+	if err != nil {
+		c.logger.Error("Failed to purge dead-letter queue", zap.String("queue", queueName), zap.Error(err))
+		return 0, err
+	}
+	c.logger.Info("Purged dead-letter queue", zap.String("queue", queueName), zap.Int("count", purged))
+	return purged, nil
+}
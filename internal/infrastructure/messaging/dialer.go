@@ -0,0 +1,25 @@
+package messaging
+
+import (
+	"fmt"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+)
+
+// NewDialer returns the dialer of the configured messaging driver
+// With the memory driver, every connection of the process shares the same in-process broker
+func NewDialer(driver, amqpURL string) (messaging.Dialer, error) {
+	switch driver {
+	case messaging.DriverMemory:
+		return NewMemoryBroker().Dial, nil
+	case messaging.DriverRabbitMQ:
+		return func(retry messaging.RetryPolicy) (messaging.Broker, error) {
+			client, err := NewRabbitMQClient(amqpURL, retry)
+			if err != nil {
+				return nil, err
+			}
+			return client, nil
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown messaging driver %q", driver)
+}
@@ -0,0 +1,537 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+
+	"github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// Ensure MemoryClient implements the Broker interface at compile time
+var _ messaging.Broker = (*MemoryClient)(nil)
+
+// ErrExchangeNotFound is returned when publishing to an exchange that was never declared
+var ErrExchangeNotFound = errors.New("exchange not found")
+
+// MemoryBroker is an in-process message broker with the semantics the application relies on from
+// RabbitMQ: direct, fanout and topic exchanges, durable-looking work queues with retry and dead-letter
+// queues, prefetch, and ack, nack and requeue of deliveries
+// Messages only live in memory, so it suits local development and tests, with the consumers running
+// in the same process as the publishers
+type MemoryBroker struct {
+	logger *zap.Logger
+
+	mu        sync.Mutex
+	changed   *sync.Cond
+	exchanges map[string]string
+	bindings  map[string][]messaging.Binding
+	queues    map[string]*memoryQueue
+}
+
+// memoryQueue holds the messages waiting for a consumer
+type memoryQueue struct {
+	name     string
+	messages []memoryMessage
+}
+
+// memoryMessage is a published message and how it was routed
+type memoryMessage struct {
+	msg         amqp091.Publishing
+	exchange    string
+	routingKey  string
+	redelivered bool
+}
+
+// NewMemoryBroker creates an empty in-process broker
+func NewMemoryBroker() *MemoryBroker {
+	b := &MemoryBroker{
+		logger:    zap.L(),
+		exchanges: make(map[string]string),
+		bindings:  make(map[string][]messaging.Binding),
+		queues:    make(map[string]*memoryQueue),
+	}
+	b.changed = sync.NewCond(&b.mu)
+	return b
+}
+
+// Dial opens a client of the broker; each client has its own prefetch and unacknowledged deliveries
+func (b *MemoryBroker) Dial(retry messaging.RetryPolicy) (messaging.Broker, error) {
+	return &MemoryClient{
+		broker:  b,
+		retry:   retry,
+		logger:  b.logger,
+		unacked: make(map[uint64]memoryDelivery),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// declareQueue creates a queue unless it exists; the caller holds the lock
+func (b *MemoryBroker) declareQueue(name string) {
+	if _, ok := b.queues[name]; !ok {
+		b.queues[name] = &memoryQueue{name: name}
+	}
+}
+
+// enqueue appends messages to a queue and wakes up its consumers; messages for unknown queues are dropped,
+// as RabbitMQ drops unroutable messages. The caller holds the lock
+func (b *MemoryBroker) enqueue(queueName string, messages ...memoryMessage) {
+	queue, ok := b.queues[queueName]
+	if !ok {
+		return
+	}
+	queue.messages = append(queue.messages, messages...)
+	b.changed.Broadcast()
+}
+
+// route delivers a message to the queues bound to the exchange with a matching routing key
+func (b *MemoryBroker) route(exchange, routingKey string, msg amqp091.Publishing) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	message := memoryMessage{msg: msg, exchange: exchange, routingKey: routingKey}
+	if exchange == "" {
+		b.enqueue(routingKey, message)
+		return nil
+	}
+
+	kind, ok := b.exchanges[exchange]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrExchangeNotFound, exchange)
+	}
+	routed := make(map[string]bool)
+	for _, binding := range b.bindings[exchange] {
+		if routed[binding.Queue] || !bindingMatches(kind, binding.RoutingKey, routingKey) {
+			continue
+		}
+		routed[binding.Queue] = true
+		b.enqueue(binding.Queue, message)
+	}
+	return nil
+}
+
+// bindingMatches reports whether a routing key matches a binding of an exchange of the given kind
+func bindingMatches(kind, pattern, routingKey string) bool {
+	switch kind {
+	case amqp091.ExchangeFanout:
+		return true
+	case amqp091.ExchangeTopic:
+		return topicMatches(strings.Split(pattern, "."), strings.Split(routingKey, "."))
+	default:
+		return pattern == routingKey
+	}
+}
+
+// topicMatches matches the words of a routing key against a topic pattern, where "*" stands for
+// exactly one word and "#" for zero or more words
+func topicMatches(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(words); i++ {
+			if topicMatches(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(words) > 0 && topicMatches(pattern[1:], words[1:])
+	default:
+		return len(words) > 0 && pattern[0] == words[0] && topicMatches(pattern[1:], words[1:])
+	}
+}
+
+// MemoryClient is a connection to a MemoryBroker
+// Deliveries it hands out stay unacknowledged until settled through the delivery, and go back to
+// their queue when the client is closed
+type MemoryClient struct {
+	broker *MemoryBroker
+	retry  messaging.RetryPolicy
+	logger *zap.Logger
+
+	// The fields below are guarded by the broker's lock
+	prefetch int
+	nextTag  uint64
+	unacked  map[uint64]memoryDelivery
+	closed   bool
+	done     chan struct{}
+}
+
+// memoryDelivery is a message handed to a consumer and waiting for its acknowledgement
+type memoryDelivery struct {
+	queue   string
+	message memoryMessage
+}
+
+// State reports whether the client is connected or closed
+func (c *MemoryClient) State() string {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	if c.closed {
+		return messaging.StateClosed
+	}
+	return messaging.StateConnected
+}
+
+// SetPrefetch limits the deliveries the client's consumers receive ahead of their acknowledgements
+func (c *MemoryClient) SetPrefetch(count int) error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	if c.closed {
+		return ErrNotConnected
+	}
+	c.prefetch = count
+	c.broker.changed.Broadcast()
+	return nil
+}
+
+// DeclareQueue declares a work queue and its dead-letter queue
+// Retries wait in a timer rather than in a retry queue
+func (c *MemoryClient) DeclareQueue(queueName string) error {
+	return c.DeclareTopology(messaging.Topology{Queues: []string{queueName}})
+}
+
+// DeclareTopology declares exchanges, work queues with their dead-letter queues, and bindings
+func (c *MemoryClient) DeclareTopology(topology messaging.Topology) error {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c.closed {
+		return ErrNotConnected
+	}
+
+	for _, exchange := range topology.Exchanges {
+		if kind, ok := b.exchanges[exchange.Name]; ok && kind != exchange.Kind {
+			return fmt.Errorf("failed to declare exchange %s: already declared as %s", exchange.Name, kind)
+		}
+		b.exchanges[exchange.Name] = exchange.Kind
+	}
+	for _, queueName := range topology.Queues {
+		b.declareQueue(queueName)
+		b.declareQueue(queueName + messaging.DeadLetterSuffix)
+	}
+	for _, binding := range topology.Bindings {
+		if _, ok := b.exchanges[binding.Exchange]; !ok {
+			return fmt.Errorf("failed to bind queue %s: %w: %s", binding.Queue, ErrExchangeNotFound, binding.Exchange)
+		}
+		if _, ok := b.queues[binding.Queue]; !ok {
+			return fmt.Errorf("failed to bind queue %s: queue not found", binding.Queue)
+		}
+		duplicate := false
+		for _, existing := range b.bindings[binding.Exchange] {
+			duplicate = duplicate || existing == binding
+		}
+		if !duplicate {
+			b.bindings[binding.Exchange] = append(b.bindings[binding.Exchange], binding)
+		}
+	}
+	return nil
+}
+
+// Publish sends a message to the specified queue, through the default exchange
+func (c *MemoryClient) Publish(ctx context.Context, queueName, body string) error {
+	return c.PublishTo(ctx, "", queueName, body)
+}
+
+// PublishTo sends a message to an exchange with a routing key
+func (c *MemoryClient) PublishTo(ctx context.Context, exchange, routingKey, body string) error {
+	return c.send(exchange, routingKey, amqp091.Publishing{
+		ContentType:  messaging.ContentTypeJSON,
+		DeliveryMode: amqp091.Persistent,
+		MessageId:    messageID(ctx),
+		Timestamp:    time.Now(),
+		Body:         []byte(body),
+	})
+}
+
+// PublishEvent publishes a CloudEvent to an exchange with a routing key
+func (c *MemoryClient) PublishEvent(ctx context.Context, exchange, routingKey string, event *messaging.CloudEvent, mode string) error {
+	msg, err := event.Publishing(mode)
+	if err != nil {
+		return err
+	}
+	return c.send(exchange, routingKey, msg)
+}
+
+// send routes a message through the broker
+func (c *MemoryClient) send(exchange, routingKey string, msg amqp091.Publishing) error {
+	if c.State() != messaging.StateConnected {
+		return ErrNotConnected
+	}
+	if err := c.broker.route(exchange, routingKey, msg); err != nil {
+		c.logger.Error("Failed to publish message to the memory broker", zap.String("exchange", exchange), zap.String("routing_key", routingKey), zap.Error(err))
+		return err
+	}
+	c.logger.Debug("Published message to the memory broker", zap.String("exchange", exchange), zap.String("routing_key", routingKey), zap.String("message_id", msg.MessageId))
+	return nil
+}
+
+// Consume starts consuming messages from a queue
+// Consumers of the same queue compete for its messages; the channel closes with the client
+func (c *MemoryClient) Consume(queueName, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp091.Table) (<-chan amqp091.Delivery, error) {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c.closed {
+		return nil, ErrNotConnected
+	}
+	if _, ok := b.queues[queueName]; !ok {
+		return nil, fmt.Errorf("queue %s not found", queueName)
+	}
+
+	out := make(chan amqp091.Delivery)
+	go func() {
+		defer close(out)
+		for {
+			delivery, ok := c.next(queueName, consumer, autoAck)
+			if !ok {
+				return
+			}
+			// A delivery not taken before the client closes was already given back to its queue
+			select {
+			case out <- delivery:
+			case <-c.done:
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// next waits for a message of the queue while the client has room under its prefetch
+// It reports false once the client is closed
+func (c *MemoryClient) next(queueName, consumer string, autoAck bool) (amqp091.Delivery, bool) {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for {
+		if c.closed {
+			return amqp091.Delivery{}, false
+		}
+		queue := b.queues[queueName]
+		if len(queue.messages) > 0 && (c.prefetch <= 0 || len(c.unacked) < c.prefetch) {
+			break
+		}
+		b.changed.Wait()
+	}
+
+	queue := b.queues[queueName]
+	message := queue.messages[0]
+	queue.messages = queue.messages[1:]
+	c.nextTag++
+	if !autoAck {
+		c.unacked[c.nextTag] = memoryDelivery{queue: queueName, message: message}
+	}
+
+	msg := message.msg
+	return amqp091.Delivery{
+		Acknowledger: memoryAcknowledger{client: c},
+		Headers:      msg.Headers,
+		ContentType:  msg.ContentType,
+		DeliveryMode: msg.DeliveryMode,
+		Expiration:   msg.Expiration,
+		MessageId:    msg.MessageId,
+		Timestamp:    msg.Timestamp,
+		ConsumerTag:  consumer,
+		DeliveryTag:  c.nextTag,
+		Redelivered:  message.redelivered,
+		Exchange:     message.exchange,
+		RoutingKey:   message.routingKey,
+		Body:         msg.Body,
+	}, true
+}
+
+// settle acknowledges deliveries, or gives them back to their queue or to the dead-letter queue
+// The caller holds the lock
+func (c *MemoryClient) settle(tag uint64, multiple, ack, requeue bool) error {
+	tags := []uint64{tag}
+	if multiple {
+		tags = tags[:0]
+		for pending := range c.unacked {
+			if pending <= tag {
+				tags = append(tags, pending)
+			}
+		}
+	}
+
+	for _, pending := range tags {
+		delivery, ok := c.unacked[pending]
+		if !ok {
+			return fmt.Errorf("unknown delivery tag %d", pending)
+		}
+		delete(c.unacked, pending)
+		if ack {
+			continue
+		}
+		if requeue {
+			delivery.message.redelivered = true
+			queue := c.broker.queues[delivery.queue]
+			queue.messages = append([]memoryMessage{delivery.message}, queue.messages...)
+		} else {
+			c.broker.enqueue(delivery.queue+messaging.DeadLetterSuffix, delivery.message)
+		}
+	}
+	c.broker.changed.Broadcast()
+	return nil
+}
+
+// memoryAcknowledger settles the deliveries of a MemoryClient, as amqp091.Delivery expects
+type memoryAcknowledger struct {
+	client *MemoryClient
+}
+
+func (a memoryAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.client.broker.mu.Lock()
+	defer a.client.broker.mu.Unlock()
+	return a.client.settle(tag, multiple, true, false)
+}
+
+func (a memoryAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.client.broker.mu.Lock()
+	defer a.client.broker.mu.Unlock()
+	return a.client.settle(tag, multiple, false, requeue)
+}
+
+func (a memoryAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+// Reject settles a message whose processing failed, as RabbitMQClient.Reject does
+// Messages to retry wait for the policy's delay in a timer before returning to their queue
+func (c *MemoryClient) Reject(ctx context.Context, queueName string, msg amqp091.Delivery, cause error, permanent bool) {
+	attempt := messaging.Attempts(msg) + 1
+	deadLetter := c.retry.ShouldDeadLetter(attempt, permanent)
+
+	headers := amqp091.Table{}
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	headers[messaging.AttemptsHeader] = int32(attempt)
+	headers[messaging.LastErrorHeader] = cause.Error()
+	republished := memoryMessage{
+		msg: amqp091.Publishing{
+			Headers:      headers,
+			ContentType:  msg.ContentType,
+			DeliveryMode: amqp091.Persistent,
+			MessageId:    msg.MessageId,
+			Timestamp:    msg.Timestamp,
+			Body:         msg.Body,
+		},
+		exchange:   msg.Exchange,
+		routingKey: msg.RoutingKey,
+	}
+
+	b := c.broker
+	if deadLetter {
+		b.mu.Lock()
+		b.enqueue(queueName+messaging.DeadLetterSuffix, republished)
+		b.mu.Unlock()
+		c.logger.Error("Message sent to the dead-letter queue", zap.String("queue", queueName), zap.String("message_id", msg.MessageId), zap.Int("attempts", attempt), zap.Bool("permanent", permanent), zap.NamedError("cause", cause))
+	} else {
+		time.AfterFunc(c.retry.Delay, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.enqueue(queueName, republished)
+		})
+		c.logger.Warn("Message scheduled for retry", zap.String("queue", queueName), zap.String("message_id", msg.MessageId), zap.Int("attempts", attempt), zap.Duration("delay", c.retry.Delay), zap.NamedError("cause", cause))
+	}
+	msg.Ack(false)
+}
+
+// Peek returns up to limit messages of a dead-letter queue without removing them
+func (c *MemoryClient) Peek(ctx context.Context, queueName string, limit int) ([]messaging.DeadLetter, error) {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	letters := []messaging.DeadLetter{}
+	queue, ok := b.queues[queueName+messaging.DeadLetterSuffix]
+	if !ok {
+		return letters, nil
+	}
+	for _, message := range queue.messages {
+		if len(letters) >= limit {
+			break
+		}
+		lastError, _ := message.msg.Headers[messaging.LastErrorHeader].(string)
+		letters = append(letters, messaging.DeadLetter{
+			MessageID: message.msg.MessageId,
+			Body:      string(message.msg.Body),
+			Attempts:  messaging.Attempts(amqp091.Delivery{Headers: message.msg.Headers}),
+			LastError: lastError,
+			Timestamp: message.msg.Timestamp,
+		})
+	}
+	return letters, nil
+}
+
+// Requeue moves up to limit messages of a dead-letter queue back to its work queue with a fresh
+// attempt count; a limit of zero moves them all
+func (c *MemoryClient) Requeue(ctx context.Context, queueName string, limit int) (int, error) {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	dlq, ok := b.queues[queueName+messaging.DeadLetterSuffix]
+	if !ok {
+		return 0, nil
+	}
+	moved := len(dlq.messages)
+	if limit > 0 && limit < moved {
+		moved = limit
+	}
+	for _, message := range dlq.messages[:moved] {
+		headers := amqp091.Table{}
+		for key, value := range message.msg.Headers {
+			if key != messaging.AttemptsHeader && key != messaging.LastErrorHeader {
+				headers[key] = value
+			}
+		}
+		message.msg.Headers = headers
+		b.enqueue(queueName, message)
+	}
+	dlq.messages = dlq.messages[moved:]
+	c.logger.Info("Requeued dead-lettered messages", zap.String("queue", queueName), zap.Int("count", moved))
+	return moved, nil
+}
+
+// Purge removes every message of a dead-letter queue
+func (c *MemoryClient) Purge(ctx context.Context, queueName string) (int, error) {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	dlq, ok := b.queues[queueName+messaging.DeadLetterSuffix]
+	if !ok {
+		return 0, nil
+	}
+	purged := len(dlq.messages)
+	dlq.messages = nil
+	c.logger.Info("Purged dead-letter queue", zap.String("queue", queueName), zap.Int("count", purged))
+	return purged, nil
+}
+
+// Close stops the client's consumers and gives their unacknowledged deliveries back to the queues
+func (c *MemoryClient) Close() {
+	b := c.broker
+	b.mu.Lock()
+	if c.closed {
+		b.mu.Unlock()
+		return
+	}
+	c.closed = true
+	close(c.done)
+	for tag := range c.unacked {
+		c.settle(tag, false, false, true)
+	}
+	b.changed.Broadcast()
+	b.mu.Unlock()
+}
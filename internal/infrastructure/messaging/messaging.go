@@ -2,7 +2,13 @@ package messaging
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"reflect"
+	"sync"
+	"time"
 
 	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
 
@@ -12,99 +18,486 @@ import (
 
 // Ensure RabbitMQClient implements the Publisher and Consumer interfaces at compile time
 var _ messaging.Publisher = (*RabbitMQClient)(nil)
+var _ messaging.ConnectionMonitor = (*RabbitMQClient)(nil)
+var _ messaging.Broker = (*RabbitMQClient)(nil)
+
+// Reconnection attempts wait from minReconnectDelay, doubling up to maxReconnectDelay
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+	// confirmTimeout bounds the wait for the broker to confirm a message when the context has no deadline
+	confirmTimeout = 5 * time.Second
+)
+
+// ErrNotConnected is returned by Publish while the client is reconnecting or closed
+var ErrNotConnected = errors.New("not connected to RabbitMQ")
+
+// ErrPublishNacked is returned by Publish when the broker refuses to take responsibility for a message
+var ErrPublishNacked = errors.New("message not confirmed by RabbitMQ")
 
 // RabbitMQClient wraps the RabbitMQ connection and channel
+// It supervises the connection and reconnects with backoff when it drops, re-creating the channel,
+// re-declaring the queues and resuming the consumers; the channel runs in confirm mode so that
+// Publish only succeeds once the broker has accepted the message
 type RabbitMQClient struct {
-	conn *amqp091.Connection
-	ch   *amqp091.Channel
+	url    string
+	dial   AMQPDial
+	retry  messaging.RetryPolicy
+	logger *zap.Logger
+
+	mu    sync.RWMutex
+	conn  AMQPConnection
+	ch    AMQPChannel
+	state string
+	// topologies are declared again after every reconnection
+	topologies []messaging.Topology
+	// prefetch is the number of unacknowledged deliveries consumers of the client receive; zero is unlimited
+	prefetch int
+	// connected is closed, and replaced, whenever a connection is established
+	connected chan struct{}
+	done      chan struct{}
 }
 
 // NewRabbitMQClient creates and initializes a new RabbitMQ client
-func NewRabbitMQClient(amqpURL string) (*RabbitMQClient, error) {
+// The first connection must succeed; later disconnections are recovered in the background
+// The retry policy applies to the messages consumers reject
+func NewRabbitMQClient(amqpURL string, retry messaging.RetryPolicy) (*RabbitMQClient, error) {
+	return NewRabbitMQClientWithDial(amqpURL, retry, DialAMQP)
+}
+
+// NewRabbitMQClientWithDial creates a RabbitMQ client that connects, and reconnects, through dial
+func NewRabbitMQClientWithDial(amqpURL string, retry messaging.RetryPolicy, dial AMQPDial) (*RabbitMQClient, error) {
 	zapLogger := zap.L()
 
 	// Log the connection attempt (mask sensitive parts of the URL)
 	zapLogger.Info("Trying to connect to RabbitMQ")
 
+	c := &RabbitMQClient{
+		url:       amqpURL,
+		dial:      dial,
+		retry:     retry,
+		logger:    zapLogger,
+		state:     messaging.StateReconnecting,
+		connected: make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// connect dials the broker, opens a channel in confirm mode and re-declares the known topology
+// On success it starts watching the new connection
+func (c *RabbitMQClient) connect() error {
 	// Establish a connection to the RabbitMQ server
-	conn, err := amqp091.Dial(amqpURL)
+	conn, err := c.dial(c.url)
 	if err != nil {
-		zapLogger.Error("Failed to connect to RabbitMQ", zap.Error(err))
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+		c.logger.Error("Failed to connect to RabbitMQ", zap.Error(err))
+		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
 
-	// Log a successful connection
-	zapLogger.Info("Successfully connected to RabbitMQ")
-
 	// Open a channel on the established connection
 	ch, err := conn.Channel()
 	if err != nil {
-		zapLogger.Error("Failed to create RabbitMQ channel", zap.Error(err))
+		c.logger.Error("Failed to create RabbitMQ channel", zap.Error(err))
 		conn.Close()
-		return nil, fmt.Errorf("failed to create channel: %w", err)
+		return fmt.Errorf("failed to create channel: %w", err)
+	}
+
+	// Publisher confirms let Publish wait for the broker to accept each message
+	if err := ch.Confirm(false); err != nil {
+		c.logger.Error("Failed to enable publisher confirms", zap.Error(err))
+		conn.Close()
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.done:
+		conn.Close()
+		return ErrNotConnected
+	default:
+	}
+	if c.prefetch > 0 {
+		if err := ch.Qos(c.prefetch, 0, false); err != nil {
+			c.logger.Error("Failed to set RabbitMQ prefetch", zap.Int("prefetch", c.prefetch), zap.Error(err))
+			conn.Close()
+			return fmt.Errorf("failed to set prefetch: %w", err)
+		}
+	}
+	for _, topology := range c.topologies {
+		if err := c.declareTopology(conn, ch, topology); err != nil {
+			c.logger.Error("Failed to re-declare RabbitMQ topology", zap.Error(err))
+			conn.Close()
+			return err
+		}
+	}
+	c.conn = conn
+	c.ch = ch
+	c.state = messaging.StateConnected
+	close(c.connected)
+
+	// Log a successful connection
+	c.logger.Info("Successfully connected to RabbitMQ")
+
+	go c.supervise(conn.NotifyClose(make(chan *amqp091.Error, 1)), ch.NotifyClose(make(chan *amqp091.Error, 1)))
+	return nil
+}
+
+// supervise waits for the connection or its channel to close and reconnects, unless the client was closed
+func (c *RabbitMQClient) supervise(connClosed, chClosed chan *amqp091.Error) {
+	var reason *amqp091.Error
+	select {
+	case <-c.done:
+		return
+	case reason = <-connClosed:
+	case reason = <-chClosed:
+	}
+
+	c.mu.Lock()
+	select {
+	case <-c.done:
+		c.mu.Unlock()
+		return
+	default:
+	}
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	c.state = messaging.StateReconnecting
+	c.connected = make(chan struct{})
+	c.mu.Unlock()
+
+	if reason != nil {
+		c.logger.Warn("RabbitMQ connection lost, reconnecting", zap.String("reason", reason.Reason), zap.Int("code", reason.Code))
+	} else {
+		c.logger.Warn("RabbitMQ connection closed, reconnecting")
 	}
 
-	// Log successful channel creation
-	zapLogger.Info("Successfully created RabbitMQ channel")
+	delay := minReconnectDelay
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-time.After(delay):
+		}
+		if err := c.connect(); err == nil {
+			return
+		}
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
 
-	return &RabbitMQClient{conn: conn, ch: ch}, nil
+// waitConnected blocks until a connection is available, reporting false once the client is closed
+func (c *RabbitMQClient) waitConnected() bool {
+	c.mu.RLock()
+	connected := c.connected
+	c.mu.RUnlock()
+	select {
+	case <-c.done:
+		return false
+	case <-connected:
+		return true
+	}
 }
 
-// DeclareQueue declares a queue on the RabbitMQ server
+// channel returns the current channel, or ErrNotConnected while reconnecting
+func (c *RabbitMQClient) channel() (AMQPChannel, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.state != messaging.StateConnected {
+		return nil, ErrNotConnected
+	}
+	return c.ch, nil
+}
+
+// State reports whether the client is connected, reconnecting or closed
+func (c *RabbitMQClient) State() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state
+}
+
+// SetPrefetch limits the deliveries consumers receive ahead of their acknowledgements
+// It applies to the consumers started afterwards and is set again after every reconnection
+func (c *RabbitMQClient) SetPrefetch(count int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state != messaging.StateConnected {
+		return ErrNotConnected
+	}
+	if err := c.ch.Qos(count, 0, false); err != nil {
+		return err
+	}
+	c.prefetch = count
+	return nil
+}
+
+// DeclareQueue declares a work queue on the RabbitMQ server, with its retry queue and its
+// dead-letter exchange and queue. Everything is declared again after every reconnection
+// A queue declared by a previous version without the dead-letter arguments is migrated with its messages
 func (c *RabbitMQClient) DeclareQueue(queueName string) error {
-	_, err := c.ch.QueueDeclare(
-		queueName, // Name of the queue
-		true,      // Durable
-		false,     // Auto-delete
-		false,     // Exclusive
-		false,     // No-wait
-		nil,       // Arguments
+	return c.DeclareTopology(messaging.Topology{Queues: []string{queueName}})
+}
+
+// DeclareTopology declares durable exchanges, work queues with their retry and dead-letter queues,
+// and the bindings between them. Everything is declared again after every reconnection
+func (c *RabbitMQClient) DeclareTopology(topology messaging.Topology) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state != messaging.StateConnected {
+		return ErrNotConnected
+	}
+	if err := c.declareTopology(c.conn, c.ch, topology); err != nil {
+		c.logger.Error("Failed to declare RabbitMQ topology", zap.Error(err))
+		return err
+	}
+	// A topology declared again, by each consumer of a queue for instance, is only kept once
+	for _, declared := range c.topologies {
+		if reflect.DeepEqual(declared, topology) {
+			return nil
+		}
+	}
+	c.topologies = append(c.topologies, topology)
+	return nil
+}
+
+// declareTopology declares the exchanges first, then the queues and finally their bindings
+// Queues left by a previous version are migrated, through their own channels, before being declared
+func (c *RabbitMQClient) declareTopology(conn AMQPConnection, ch AMQPChannel, topology messaging.Topology) error {
+	for _, exchange := range topology.Exchanges {
+		if err := ch.ExchangeDeclare(exchange.Name, exchange.Kind, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare exchange %s: %w", exchange.Name, err)
+		}
+	}
+	for _, queueName := range topology.Queues {
+		var bindings []messaging.Binding
+		for _, binding := range topology.Bindings {
+			if binding.Queue == queueName {
+				bindings = append(bindings, binding)
+			}
+		}
+		if err := c.migrateQueue(conn, queueName, bindings); err != nil {
+			return fmt.Errorf("failed to migrate queue %s: %w", queueName, err)
+		}
+		if err := declareQueue(ch, queueName); err != nil {
+			return fmt.Errorf("failed to declare queue %s: %w", queueName, err)
+		}
+	}
+	for _, binding := range topology.Bindings {
+		if err := ch.QueueBind(binding.Queue, binding.RoutingKey, binding.Exchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind queue %s to %s with %q: %w", binding.Queue, binding.Exchange, binding.RoutingKey, err)
+		}
+	}
+	return nil
+}
+
+// declareQueue declares a durable work queue and its dead-letter topology on the given channel
+// Messages rejected without requeueing go to the dead-letter queue; messages expiring in the
+// retry queue go back to the work queue
+func declareQueue(ch AMQPChannel, queueName string) error {
+	dlx := queueName + messaging.DeadLetterExchangeSuffix
+	dlq := queueName + messaging.DeadLetterSuffix
+	if err := ch.ExchangeDeclare(dlx, amqp091.ExchangeFanout, true, false, false, false, nil); err != nil {
+		return err
+	}
+	if _, err := ch.QueueDeclare(dlq, true, false, false, false, nil); err != nil {
+		return err
+	}
+	if err := ch.QueueBind(dlq, "", dlx, false, nil); err != nil {
+		return err
+	}
+	_, err := ch.QueueDeclare(queueName+messaging.RetrySuffix, true, false, false, false, amqp091.Table{
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": queueName,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = ch.QueueDeclare(
+		queueName,                // Name of the queue
+		true,                     // Durable
+		false,                    // Auto-delete
+		false,                    // Exclusive
+		false,                    // No-wait
+		workQueueArgs(queueName), // Arguments
 	)
 	return err
 }
 
-// Publish sends a message to the specified queue
+// workQueueArgs returns the arguments of a work queue, whose rejected messages go to its dead-letter exchange
+func workQueueArgs(queueName string) amqp091.Table {
+	return amqp091.Table{"x-dead-letter-exchange": queueName + messaging.DeadLetterExchangeSuffix}
+}
+
+// Publish sends a persistent message to the specified queue, through the default exchange
 func (c *RabbitMQClient) Publish(ctx context.Context, queueName, body string) error {
-	zapLogger := zap.L()
-	err := c.ch.PublishWithContext(
-		ctx,
-		"",        // Exchange
-		queueName, // Routing key (queue name)
-		false,     // Mandatory
-		false,     // Immediate
-		amqp091.Publishing{
-			ContentType: "text/plain",
-			Body:        []byte(body),
-		},
-	)
+	return c.PublishTo(ctx, "", queueName, body)
+}
+
+// PublishTo sends a persistent message to an exchange with a routing key and waits for the broker to confirm it
+// Without a deadline on the context, the confirmation is awaited for at most confirmTimeout
+func (c *RabbitMQClient) PublishTo(ctx context.Context, exchange, routingKey, body string) error {
+	return c.send(ctx, exchange, routingKey, amqp091.Publishing{
+		ContentType:  messaging.ContentTypeJSON,
+		DeliveryMode: amqp091.Persistent,
+		MessageId:    messageID(ctx),
+		Timestamp:    time.Now(),
+		Body:         []byte(body),
+	}, zap.String("body", body))
+}
+
+// PublishEvent publishes a CloudEvent to an exchange with a routing key and waits for the broker to confirm it
+// The event ID is the message ID, so that every publication of the same event can be recognized
+func (c *RabbitMQClient) PublishEvent(ctx context.Context, exchange, routingKey string, event *messaging.CloudEvent, mode string) error {
+	msg, err := event.Publishing(mode)
+	if err != nil {
+		c.logger.Error("Failed to encode CloudEvent", zap.String("exchange", exchange), zap.String("event_id", event.ID), zap.Error(err))
+		return err
+	}
+	return c.send(ctx, exchange, routingKey, msg, zap.String("type", event.Type), zap.String("subject", event.Subject), zap.String("mode", mode))
+}
+
+// send publishes a message on the current channel, bounding the wait for its confirmation
+func (c *RabbitMQClient) send(ctx context.Context, exchange, routingKey string, msg amqp091.Publishing, fields ...zap.Field) error {
+	fields = append([]zap.Field{
+		zap.String("exchange", exchange),
+		zap.String("routing_key", routingKey),
+		zap.String("message_id", msg.MessageId),
+	}, fields...)
+
+	ch, err := c.channel()
+	if err != nil {
+		c.logger.Warn("Cannot publish message while RabbitMQ is unavailable", fields...)
+		return err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, confirmTimeout)
+		defer cancel()
+	}
+
+	if err := publish(ctx, ch, exchange, routingKey, msg); err != nil {
+		c.logger.Error("Failed to publish message to RabbitMQ", append(fields, zap.Error(err))...)
+		return err
+	}
+	c.logger.Info("Successfully published message to RabbitMQ", fields...)
+	return nil
+}
+
+// publish sends a message and waits for the broker to confirm it
+func publish(ctx context.Context, ch AMQPChannel, exchange, key string, msg amqp091.Publishing) error {
+	confirmation, err := ch.PublishConfirmed(ctx, exchange, key, msg)
+	if err != nil {
+		return err
+	}
+	acked, err := confirmation.WaitContext(ctx)
 	if err != nil {
-		zapLogger.Error("Failed to publish message to RabbitMQ",
-			zap.String("queue", queueName),
-			zap.String("body", body),
-			zap.Error(err))
 		return err
 	}
-	zapLogger.Info("Successfully published message to RabbitMQ",
-		zap.String("queue", queueName),
-		zap.String("body", body))
+	if !acked {
+		return ErrPublishNacked
+	}
 	return nil
 }
 
+// messageID returns the message ID carried by the context, or a random one when it carries none
+func messageID(ctx context.Context) string {
+	if id, ok := messaging.MessageID(ctx); ok {
+		return id
+	}
+	return newMessageID()
+}
+
+// newMessageID returns a random identifier for a published message
+func newMessageID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
 // Consume starts consuming messages from a specified queue
+// The returned channel survives reconnections: consumption resumes on each new channel and the
+// channel is only closed with the client. Messages not acknowledged before a disconnection are redelivered
 func (c *RabbitMQClient) Consume(queueName, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp091.Table) (<-chan amqp091.Delivery, error) {
-	return c.ch.Consume(
-		queueName, // Name of the queue
-		consumer,  // Consumer tag
-		autoAck,   // Auto-acknowledgment
-		exclusive, // Exclusive
-		noLocal,   // No-local
-		noWait,    // No-wait
-		args,      // Arguments
-	)
+	consume := func() (<-chan amqp091.Delivery, error) {
+		ch, err := c.channel()
+		if err != nil {
+			return nil, err
+		}
+		return ch.Consume(
+			queueName, // Name of the queue
+			consumer,  // Consumer tag
+			autoAck,   // Auto-acknowledgment
+			exclusive, // Exclusive
+			noLocal,   // No-local
+			noWait,    // No-wait
+			args,      // Arguments
+		)
+	}
+
+	deliveries, err := consume()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan amqp091.Delivery)
+	go func() {
+		defer close(out)
+		for {
+			for msg := range deliveries {
+				select {
+				case out <- msg:
+				case <-c.done:
+					return
+				}
+			}
+
+			// The channel closed: resume consuming once the client has reconnected
+			for {
+				if !c.waitConnected() {
+					return
+				}
+				deliveries, err = consume()
+				if err == nil {
+					c.logger.Info("Resumed consuming from RabbitMQ", zap.String("queue", queueName))
+					break
+				}
+				c.logger.Warn("Failed to resume consuming from RabbitMQ", zap.String("queue", queueName), zap.Error(err))
+				select {
+				case <-c.done:
+					return
+				case <-time.After(minReconnectDelay):
+				}
+			}
+		}
+	}()
+	return out, nil
 }
 
-// CCloses the RabbitMQ channel and connection
+// Close stops the reconnection supervision and closes the RabbitMQ channel and connection
 func (c *RabbitMQClient) Close() {
-	c.ch.Close()
-	c.conn.Close()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	close(c.done)
+	c.state = messaging.StateClosed
+	if c.ch != nil {
+		c.ch.Close()
+	}
+	if c.conn != nil {
+		c.conn.Close()
+	}
 }
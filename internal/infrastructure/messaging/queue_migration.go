@@ -0,0 +1,155 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+
+	"github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// migrationSuffix names the queue holding the messages of a work queue while it is declared again
+const migrationSuffix = ".migrating"
+
+// migrateQueue moves a work queue declared by a previous version, without the dead-letter arguments,
+// to the current ones; the broker refuses to declare an existing queue with other arguments
+// Its messages wait in a holding queue, bound like the work queue so that nothing published meanwhile
+// is lost, while the work queue is deleted and declared again. A migration interrupted halfway resumes
+// on the next declaration. The probes run on their own channels, since a refused declaration closes
+// the channel it was made on
+func (c *RabbitMQClient) migrateQueue(conn AMQPConnection, queueName string, bindings []messaging.Binding) error {
+	legacy, err := probeLegacyQueue(conn, queueName)
+	if err != nil {
+		return err
+	}
+	holding := queueName + migrationSuffix
+	if !legacy {
+		pending, err := queueExists(conn, holding)
+		if err != nil || !pending {
+			return err
+		}
+	}
+
+	c.logger.Warn("Migrating RabbitMQ queue to its dead-letter arguments", zap.String("queue", queueName))
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	if err := ch.Confirm(false); err != nil {
+		return err
+	}
+
+	if _, err := ch.QueueDeclare(holding, true, false, false, false, nil); err != nil {
+		return err
+	}
+	if legacy {
+		for _, binding := range bindings {
+			if err := ch.QueueBind(holding, binding.RoutingKey, binding.Exchange, false, nil); err != nil {
+				return err
+			}
+			if err := ch.QueueUnbind(queueName, binding.RoutingKey, binding.Exchange, nil); err != nil {
+				return err
+			}
+		}
+		if _, err := moveMessages(ch, queueName, holding); err != nil {
+			return err
+		}
+		// Refused while consumers of an older instance are attached, so their messages are not lost
+		if _, err := ch.QueueDelete(queueName, true, true, false); err != nil {
+			return err
+		}
+	}
+
+	if err := declareQueue(ch, queueName); err != nil {
+		return err
+	}
+	for _, binding := range bindings {
+		if err := ch.QueueBind(queueName, binding.RoutingKey, binding.Exchange, false, nil); err != nil {
+			return err
+		}
+		if err := ch.QueueUnbind(holding, binding.RoutingKey, binding.Exchange, nil); err != nil {
+			return err
+		}
+	}
+	moved, err := moveMessages(ch, holding, queueName)
+	if err != nil {
+		return err
+	}
+	if _, err := ch.QueueDelete(holding, false, true, false); err != nil {
+		return err
+	}
+
+	c.logger.Info("Migrated RabbitMQ queue to its dead-letter arguments", zap.String("queue", queueName), zap.Int("messages", moved))
+	return nil
+}
+
+// probeLegacyQueue reports whether the work queue exists with arguments other than the current ones
+func probeLegacyQueue(conn AMQPConnection, queueName string) (bool, error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		return false, err
+	}
+	defer ch.Close()
+	_, err = ch.QueueDeclare(queueName, true, false, false, false, workQueueArgs(queueName))
+	var amqpErr *amqp091.Error
+	if errors.As(err, &amqpErr) && amqpErr.Code == amqp091.PreconditionFailed {
+		return true, nil
+	}
+	return false, err
+}
+
+// queueExists reports whether a queue is declared on the broker
+func queueExists(conn AMQPConnection, queueName string) (bool, error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		return false, err
+	}
+	defer ch.Close()
+	_, err = ch.QueueDeclarePassive(queueName, true, false, false, false, nil)
+	var amqpErr *amqp091.Error
+	if errors.As(err, &amqpErr) && amqpErr.Code == amqp091.NotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// moveMessages republishes every message of a queue to another one, unchanged, and returns their number
+// Each message is only removed once the broker has confirmed its copy
+func moveMessages(ch AMQPChannel, from, to string) (int, error) {
+	moved := 0
+	for {
+		msg, ok, err := ch.Get(from, false)
+		if err != nil || !ok {
+			return moved, err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), confirmTimeout)
+		err = publish(ctx, ch, "", to, amqp091.Publishing{
+			Headers:         msg.Headers,
+			ContentType:     msg.ContentType,
+			ContentEncoding: msg.ContentEncoding,
+			DeliveryMode:    msg.DeliveryMode,
+			Priority:        msg.Priority,
+			CorrelationId:   msg.CorrelationId,
+			ReplyTo:         msg.ReplyTo,
+			Expiration:      msg.Expiration,
+			MessageId:       msg.MessageId,
+			Timestamp:       msg.Timestamp,
+			Type:            msg.Type,
+			UserId:          msg.UserId,
+			AppId:           msg.AppId,
+			Body:            msg.Body,
+		})
+		cancel()
+		if err != nil {
+			msg.Nack(false, true)
+			return moved, err
+		}
+		if err := msg.Ack(false); err != nil {
+			return moved, err
+		}
+		moved++
+	}
+}
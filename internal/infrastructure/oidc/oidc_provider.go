@@ -0,0 +1,106 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/identity"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// Ensure Provider implements the identity provider interface at compile time
+var _ identity.Provider = (*Provider)(nil)
+
+// Provider talks to an OpenID Connect identity provider discovered from its issuer URL
+// ID tokens are verified against the provider's JWKS, which go-oidc fetches and caches
+type Provider struct {
+	oauth       oauth2.Config
+	verifier    *gooidc.IDTokenVerifier
+	groupsClaim string
+	logger      *zap.Logger
+}
+
+// NewProvider runs OIDC discovery against the configured issuer and returns a ready provider
+func NewProvider(ctx context.Context, cfg *config.Configs, logger *zap.Logger) (*Provider, error) {
+	provider, err := gooidc.NewProvider(ctx, cfg.OIDCIssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+
+	return &Provider{
+		oauth: oauth2.Config{
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       cfg.OIDCScopes,
+		},
+		verifier:    provider.Verifier(&gooidc.Config{ClientID: cfg.OIDCClientID}),
+		groupsClaim: cfg.OIDCGroupsClaim,
+		logger:      logger,
+	}, nil
+}
+
+// AuthCodeURL returns the authorization endpoint URL with the state, nonce and S256 PKCE challenge
+func (p *Provider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	return p.oauth.AuthCodeURL(state,
+		gooidc.Nonce(nonce),
+		oauth2.SetAuthURLParam("code_challenge", codeChallenge),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"))
+}
+
+// Exchange redeems the code with its PKCE verifier, then verifies the ID token signature, audience, expiry and nonce
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*identity.Claims, error) {
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		p.logger.Warn("OIDC code exchange failed", zap.Error(err), zap.String("operation", "oidc_exchange"))
+		return nil, fmt.Errorf("code exchange failed: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		p.logger.Warn("OIDC ID token rejected", zap.Error(err), zap.String("operation", "oidc_exchange"))
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("ID token nonce does not match")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("invalid ID token claims: %w", err)
+	}
+
+	result := &identity.Claims{Subject: idToken.Subject}
+	result.Email, _ = claims["email"].(string)
+	result.EmailVerified, _ = claims["email_verified"].(bool)
+	result.Name, _ = claims["name"].(string)
+	result.Groups = stringList(claims[p.groupsClaim])
+	return result, nil
+}
+
+// stringList reads a claim holding either a list of strings or a single string
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository implements the repository interface for API key operations
+type APIKeyRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewAPIKeyRepository initializes a new APIKeyRepository with the provided database and logger
+func NewAPIKeyRepository(db *gorm.DB, logger *zap.Logger) repository.APIKeyRepositoryInterface {
+	return &APIKeyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create stores a new API key record
+func (r *APIKeyRepository) Create(key *model.APIKey) error {
+	if err := r.db.Create(key).Error; err != nil {
+		r.logger.Error("Error creating API key", zap.String("name", key.Name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// FindByHash retrieves an API key by the hash of its raw value
+// It returns nil without an error when no key matches
+func (r *APIKeyRepository) FindByHash(keyHash string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("API key not found")
+			return nil, nil
+		}
+		r.logger.Error("Error fetching API key", zap.Error(err))
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindByID retrieves an API key by its primary key
+// It returns nil without an error when no key matches
+func (r *APIKeyRepository) FindByID(id uint) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.First(&key, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("API key not found", zap.Uint("key_id", id))
+			return nil, nil
+		}
+		r.logger.Error("Error fetching API key", zap.Uint("key_id", id), zap.Error(err))
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List retrieves every API key, newest first
+func (r *APIKeyRepository) List() ([]*model.APIKey, error) {
+	var keys []*model.APIKey
+	if err := r.db.Order("created_at DESC").Find(&keys).Error; err != nil {
+		r.logger.Error("Error listing API keys", zap.Error(err))
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke marks an API key as revoked
+func (r *APIKeyRepository) Revoke(id uint) error {
+	err := r.db.Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.logger.Error("Error revoking API key", zap.Uint("key_id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// TouchLastUsed records the last time an API key was used
+func (r *APIKeyRepository) TouchLastUsed(id uint, usedAt time.Time) error {
+	err := r.db.Model(&model.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+	if err != nil {
+		r.logger.Warn("Error updating API key last use", zap.Uint("key_id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailDeliveryRepository implements the repository interface for the email delivery log
+type EmailDeliveryRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewEmailDeliveryRepository initializes a new EmailDeliveryRepository with the provided database and logger
+func NewEmailDeliveryRepository(db *gorm.DB, logger *zap.Logger) repository.EmailDeliveryRepositoryInterface {
+	return &EmailDeliveryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RecordAttempt inserts the delivery, or counts the attempt in the delivery with the same key
+// The first time the email is accepted is kept, even if a later attempt fails
+func (r *EmailDeliveryRepository) RecordAttempt(ctx context.Context, attempt *model.EmailDelivery) (*model.EmailDelivery, error) {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "delivery_key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"attempts":        gorm.Expr("email_deliveries.attempts + 1"),
+			"status":          attempt.Status,
+			"response_code":   attempt.ResponseCode,
+			"response":        attempt.Response,
+			"last_attempt_at": attempt.LastAttemptAt,
+			"sent_at":         gorm.Expr("COALESCE(email_deliveries.sent_at, ?)", attempt.SentAt),
+		}),
+	}).Create(attempt).Error
+	if err != nil {
+		r.logger.Error("Error recording email delivery", zap.String("kind", attempt.Kind), zap.Error(err), zap.String("operation", "email_delivery_record"))
+		return nil, err
+	}
+
+	var stored model.EmailDelivery
+	if err := r.db.WithContext(ctx).Where("delivery_key = ?", attempt.DeliveryKey).First(&stored).Error; err != nil {
+		r.logger.Error("Error reading recorded email delivery", zap.Error(err), zap.String("operation", "email_delivery_record"))
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// Search retrieves a page of the deliveries matching the filter, newest first, with the total number of matches
+func (r *EmailDeliveryRepository) Search(filter model.EmailDeliveryFilter, offset, limit int) ([]*model.EmailDelivery, int64, error) {
+	// Escape LIKE wildcards so the values are matched literally
+	escape := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace
+	query := r.db.Model(&model.EmailDelivery{})
+	if filter.Recipient != "" {
+		query = query.Where("recipients ILIKE ?", "%"+escape(filter.Recipient)+"%")
+	}
+	if filter.EventID != "" {
+		query = query.Where("',' || event_ids || ',' LIKE ?", "%,"+escape(filter.EventID)+",%")
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.From != nil {
+		query = query.Where("first_attempt_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("first_attempt_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("Error counting email deliveries", zap.Error(err), zap.String("operation", "email_delivery_search"))
+		return nil, 0, err
+	}
+
+	var deliveries []*model.EmailDelivery
+	if err := query.Order("first_attempt_at DESC, id DESC").Offset(offset).Limit(limit).Find(&deliveries).Error; err != nil {
+		r.logger.Error("Error searching email deliveries", zap.Error(err), zap.String("operation", "email_delivery_search"))
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// FindByID retrieves a delivery by its ID
+func (r *EmailDeliveryRepository) FindByID(id uint) (*model.EmailDelivery, error) {
+	var delivery model.EmailDelivery
+	if err := r.db.First(&delivery, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Error("Error finding email delivery", zap.Uint("delivery_id", id), zap.Error(err), zap.String("operation", "email_delivery_find"))
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// RedactBefore clears the personal data of the deliveries last attempted before the given instant
+// The subject and the server's response are cleared too, since they may name the recipient
+func (r *EmailDeliveryRepository) RedactBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.EmailDelivery{}).
+		Where("redacted_at IS NULL AND last_attempt_at < ?", before).
+		Updates(map[string]interface{}{"recipients": "", "subject": "", "text": "", "html": "", "response": "", "redacted_at": time.Now()})
+	if result.Error != nil {
+		r.logger.Error("Error redacting email deliveries", zap.Error(result.Error), zap.String("operation", "email_delivery_redact"))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// DeleteBefore removes the deliveries last attempted before the given instant
+func (r *EmailDeliveryRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("last_attempt_at < ?", before).Delete(&model.EmailDelivery{})
+	if result.Error != nil {
+		r.logger.Error("Error deleting email deliveries", zap.Error(result.Error), zap.String("operation", "email_delivery_cleanup"))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
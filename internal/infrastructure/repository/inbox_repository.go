@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// InboxRepository implements the repository interface for the in-app notification inbox
+type InboxRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewInboxRepository initializes a new InboxRepository with the provided database and logger
+func NewInboxRepository(db *gorm.DB, logger *zap.Logger) repository.InboxRepositoryInterface {
+	return &InboxRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Add stores new notifications in the inbox of their users
+func (r *InboxRepository) Add(ctx context.Context, notifications []*model.InboxNotification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Create(&notifications).Error; err != nil {
+		r.logger.Error("Error storing inbox notifications", zap.Int("count", len(notifications)), zap.Error(err), zap.String("operation", "inbox_add"))
+		return err
+	}
+	return nil
+}
+
+// List returns a page of the user's notifications, newest first, optionally only the unread ones
+func (r *InboxRepository) List(userID uint, unreadOnly bool, offset, limit int) ([]*model.InboxNotification, int64, error) {
+	query := r.db.Model(&model.InboxNotification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("Error counting inbox notifications", zap.Uint("user_id", userID), zap.Error(err))
+		return nil, 0, err
+	}
+
+	var notifications []*model.InboxNotification
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&notifications).Error; err != nil {
+		r.logger.Error("Error listing inbox notifications", zap.Uint("user_id", userID), zap.Error(err))
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+// CountUnread returns how many notifications of the user were not read yet
+func (r *InboxRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.InboxNotification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	if err != nil {
+		r.logger.Error("Error counting unread inbox notifications", zap.Uint("user_id", userID), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// SetReadAt changes the read time of one notification of the user
+// Notifications of other users are treated as missing
+func (r *InboxRepository) SetReadAt(userID, id uint, readAt *time.Time) (*model.InboxNotification, error) {
+	var notification model.InboxNotification
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching inbox notification", zap.Uint("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	if err := r.db.Model(&notification).Update("read_at", readAt).Error; err != nil {
+		r.logger.Error("Error updating inbox notification", zap.Uint("id", id), zap.Error(err))
+		return nil, err
+	}
+	notification.ReadAt = readAt
+	return &notification, nil
+}
+
+// MarkAllRead marks the unread notifications of the user as read in a single statement
+func (r *InboxRepository) MarkAllRead(userID uint, readAt time.Time) (int64, error) {
+	result := r.db.Model(&model.InboxNotification{}).Where("user_id = ? AND read_at IS NULL", userID).Update("read_at", readAt)
+	if result.Error != nil {
+		r.logger.Error("Error marking inbox notifications as read", zap.Uint("user_id", userID), zap.Error(result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// InvitationRepository implements the repository interface for invitations
+type InvitationRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewInvitationRepository initializes a new InvitationRepository with the provided database and logger
+func NewInvitationRepository(db *gorm.DB, logger *zap.Logger) repository.InvitationRepositoryInterface {
+	return &InvitationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create stores a new invitation
+func (r *InvitationRepository) Create(invitation *model.Invitation) error {
+	if err := r.db.Create(invitation).Error; err != nil {
+		r.logger.Error("Error creating invitation", zap.Uint("org_id", invitation.OrgID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// FindByID retrieves an invitation with its organization
+// It returns nil without an error when no invitation matches
+func (r *InvitationRepository) FindByID(id uint) (*model.Invitation, error) {
+	return r.findOne("id = ?", id)
+}
+
+// FindByHash retrieves an invitation with its organization by the hash of its raw token
+// It returns nil without an error when no invitation matches
+func (r *InvitationRepository) FindByHash(tokenHash string) (*model.Invitation, error) {
+	return r.findOne("token_hash = ?", tokenHash)
+}
+
+// findOne retrieves the first invitation matching the condition, preloading its organization
+func (r *InvitationRepository) findOne(query string, arg interface{}) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := r.db.Preload("Organization").Where(query, arg).First(&invitation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Invitation not found")
+			return nil, nil
+		}
+		r.logger.Error("Error fetching invitation", zap.Error(err))
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// ListByOrg returns the invitations of an organization, newest first
+func (r *InvitationRepository) ListByOrg(orgID uint) ([]*model.Invitation, error) {
+	var invitations []*model.Invitation
+	if err := r.db.Where("org_id = ?", orgID).Order("created_at DESC").Find(&invitations).Error; err != nil {
+		r.logger.Error("Error listing invitations", zap.Uint("org_id", orgID), zap.Error(err))
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// Update saves the token, expiry and send time of an invitation
+func (r *InvitationRepository) Update(invitation *model.Invitation) error {
+	err := r.db.Model(invitation).Select("TokenHash", "ExpiresAt", "SentAt").Updates(invitation).Error
+	if err != nil {
+		r.logger.Error("Error updating invitation", zap.Uint("invitation_id", invitation.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// MarkAccepted consumes a pending invitation, reporting false if it was already accepted or revoked
+func (r *InvitationRepository) MarkAccepted(id uint) (bool, error) {
+	result := r.db.Model(&model.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("accepted_at", time.Now())
+	if result.Error != nil {
+		r.logger.Error("Error accepting invitation", zap.Uint("invitation_id", id), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Revoke cancels a pending invitation, reporting false if it was already accepted or revoked
+func (r *InvitationRepository) Revoke(id uint) (bool, error) {
+	result := r.db.Model(&model.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		r.logger.Error("Error revoking invitation", zap.Uint("invitation_id", id), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RevokePending cancels every outstanding invitation of an email address to an organization
+func (r *InvitationRepository) RevokePending(orgID uint, email string) error {
+	err := r.db.Model(&model.Invitation{}).
+		Where("org_id = ? AND email = ? AND accepted_at IS NULL AND revoked_at IS NULL", orgID, email).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.logger.Error("Error revoking pending invitations", zap.Uint("org_id", orgID), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// LoginAttemptRepository implements the repository interface for login throttles and the authentication audit log
+type LoginAttemptRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewLoginAttemptRepository initializes a new LoginAttemptRepository with the provided database and logger
+func NewLoginAttemptRepository(db *gorm.DB, logger *zap.Logger) repository.LoginAttemptRepositoryInterface {
+	return &LoginAttemptRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// FindThrottle retrieves the throttle state for a key
+// It returns nil without an error when the key has no recorded failures
+func (r *LoginAttemptRepository) FindThrottle(key string) (*model.LoginThrottle, error) {
+	var throttle model.LoginThrottle
+	err := r.db.Where("key = ?", key).First(&throttle).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching login throttle", zap.String("key", key), zap.Error(err))
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// RegisterFailure increments the failure counter of a key in a single statement, so that concurrent
+// failures are all counted; failures outside the window or before an elapsed lockout start over at one
+func (r *LoginAttemptRepository) RegisterFailure(key string, now time.Time, window time.Duration) (int, error) {
+	var failures int
+	err := r.db.Raw(`INSERT INTO login_throttles (key, failures, last_failure_at, updated_at) VALUES (@key, 1, @now, @now)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN @expired THEN 1 ELSE login_throttles.failures + 1 END,
+			locked_until = CASE WHEN @expired THEN NULL ELSE login_throttles.locked_until END,
+			last_failure_at = EXCLUDED.last_failure_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING failures`,
+		map[string]interface{}{
+			"key":     key,
+			"now":     now,
+			"expired": gorm.Expr("(login_throttles.last_failure_at < ? OR login_throttles.locked_until <= ?)", now.Add(-window), now),
+		}).Scan(&failures).Error
+	if err != nil {
+		r.logger.Error("Error recording login failure", zap.String("key", key), zap.Error(err))
+		return 0, err
+	}
+	return failures, nil
+}
+
+// LockThrottle sets the lockout of a key that is not locked yet; of concurrent callers, only one locks it
+func (r *LoginAttemptRepository) LockThrottle(key string, until, now time.Time) (bool, error) {
+	result := r.db.Model(&model.LoginThrottle{}).
+		Where("key = ? AND (locked_until IS NULL OR locked_until <= ?)", key, now).
+		Updates(map[string]interface{}{"locked_until": until, "updated_at": now})
+	if result.Error != nil {
+		r.logger.Error("Error locking login throttle", zap.String("key", key), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteThrottle clears the throttle state for a key, unlocking it
+func (r *LoginAttemptRepository) DeleteThrottle(key string) error {
+	if err := r.db.Where("key = ?", key).Delete(&model.LoginThrottle{}).Error; err != nil {
+		r.logger.Error("Error deleting login throttle", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RecordAudit appends an entry to the authentication audit log
+func (r *LoginAttemptRepository) RecordAudit(entry *model.AuthAuditEntry) error {
+	if err := r.db.Create(entry).Error; err != nil {
+		r.logger.Error("Error recording auth audit entry", zap.String("outcome", entry.Outcome), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MFARepository implements the repository interface for recovery codes and two-factor policies
+type MFARepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewMFARepository initializes a new MFARepository with the provided database and logger
+func NewMFARepository(db *gorm.DB, logger *zap.Logger) repository.MFARepositoryInterface {
+	return &MFARepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ReplaceRecoveryCodes atomically discards a user's recovery codes and stores the new set
+func (r *MFARepository) ReplaceRecoveryCodes(userID uint, codes []*model.MFARecoveryCode) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		r.logger.Error("Error replacing recovery codes", zap.Uint("user_id", userID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used and reports whether one matched
+// The conditional update makes concurrent uses of the same code fail for all but one request
+func (r *MFARepository) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	result := r.db.Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		r.logger.Error("Error using recovery code", zap.Uint("user_id", userID), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteRecoveryCodes removes every recovery code of a user
+func (r *MFARepository) DeleteRecoveryCodes(userID uint) error {
+	if err := r.db.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+		r.logger.Error("Error deleting recovery codes", zap.Uint("user_id", userID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// FindPolicy retrieves the two-factor policy of a role
+// It returns nil without an error when no policy was stored for the role
+func (r *MFARepository) FindPolicy(role string) (*model.MFAPolicy, error) {
+	var policy model.MFAPolicy
+	err := r.db.Where("role = ?", role).First(&policy).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching MFA policy", zap.String("role", role), zap.Error(err))
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// ListPolicies retrieves every stored two-factor policy
+func (r *MFARepository) ListPolicies() ([]*model.MFAPolicy, error) {
+	var policies []*model.MFAPolicy
+	if err := r.db.Order("role").Find(&policies).Error; err != nil {
+		r.logger.Error("Error listing MFA policies", zap.Error(err))
+		return nil, err
+	}
+	return policies, nil
+}
+
+// SavePolicy inserts or updates the two-factor policy of a role
+func (r *MFARepository) SavePolicy(policy *model.MFAPolicy) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "role"}},
+		DoUpdates: clause.AssignmentColumns([]string{"required", "updated_at"}),
+	}).Create(policy).Error
+	if err != nil {
+		r.logger.Error("Error saving MFA policy", zap.String("role", policy.Role), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository implements the repository interface for notification preferences and digests
+type NotificationRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewNotificationRepository initializes a new NotificationRepository with the provided database and logger
+func NewNotificationRepository(db *gorm.DB, logger *zap.Logger) repository.NotificationRepositoryInterface {
+	return &NotificationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// FindPreferences returns the preferences stored for a user; events without one are not returned
+func (r *NotificationRepository) FindPreferences(userID uint) ([]*model.NotificationPreference, error) {
+	var preferences []*model.NotificationPreference
+	if err := r.db.Where("user_id = ?", userID).Order("event").Find(&preferences).Error; err != nil {
+		r.logger.Error("Error fetching notification preferences", zap.Uint("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// SavePreferences inserts or updates the given preferences of a user in a single transaction
+func (r *NotificationRepository) SavePreferences(userID uint, preferences []*model.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	for _, preference := range preferences {
+		preference.UserID = userID
+	}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event"}},
+		DoUpdates: clause.AssignmentColumns([]string{"frequency", "updated_at"}),
+	}).Create(&preferences).Error
+	if err != nil {
+		r.logger.Error("Error saving notification preferences", zap.Uint("user_id", userID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// IsEmailUnsubscribed reports whether the address, compared case-insensitively, turned off its notifications
+func (r *NotificationRepository) IsEmailUnsubscribed(email string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.UnsubscribedEmail{}).Where("email = ?", strings.ToLower(email)).Count(&count).Error; err != nil {
+		r.logger.Error("Error checking unsubscribed email", zap.Error(err), zap.String("operation", "unsubscribe"))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// UnsubscribeEmail records the address in lower case, keeping the first record when it is already there
+func (r *NotificationRepository) UnsubscribeEmail(email string) error {
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.UnsubscribedEmail{Email: strings.ToLower(email)}).Error
+	if err != nil {
+		r.logger.Error("Error saving unsubscribed email", zap.Error(err), zap.String("operation", "unsubscribe"))
+		return err
+	}
+	return nil
+}
+
+// AddDigestEntries stores notifications waiting for their digest
+// Events already stored for the user, when a failed batch is routed again, are skipped
+func (r *NotificationRepository) AddDigestEntries(ctx context.Context, entries []*model.DigestEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&entries).Error; err != nil {
+		r.logger.Error("Error storing digest entries", zap.Int("count", len(entries)), zap.Error(err), zap.String("operation", "digest_add"))
+		return err
+	}
+	return nil
+}
+
+// ClaimDueDigestEntries locks the oldest due entries, skipping those locked by another worker, and postpones
+// them by the lease so that a digest is not sent twice; a worker that dies releases them when it expires
+func (r *NotificationRepository) ClaimDueDigestEntries(ctx context.Context, limit int, lease time.Duration) ([]*model.DigestEntry, error) {
+	var entries []*model.DigestEntry
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("due_at <= ?", now).
+			Order("user_id, id").Limit(limit).Find(&entries).Error
+		if err != nil || len(entries) == 0 {
+			return err
+		}
+		ids := make([]uint, 0, len(entries))
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		return tx.Model(&model.DigestEntry{}).Where("id IN ?", ids).Update("due_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		r.logger.Error("Error claiming digest entries", zap.Error(err), zap.String("operation", "digest_claim"))
+		return nil, err
+	}
+	return entries, nil
+}
+
+// DeleteDigestEntries removes entries that were sent or are no longer wanted
+func (r *NotificationRepository) DeleteDigestEntries(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&model.DigestEntry{}).Error; err != nil {
+		r.logger.Error("Error deleting digest entries", zap.Int("count", len(ids)), zap.Error(err), zap.String("operation", "digest_delete"))
+		return err
+	}
+	return nil
+}
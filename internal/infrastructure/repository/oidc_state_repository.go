@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OIDCStateRepository implements the repository interface for pending OIDC login states
+type OIDCStateRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewOIDCStateRepository initializes a new OIDCStateRepository with the provided database and logger
+func NewOIDCStateRepository(db *gorm.DB, logger *zap.Logger) repository.OIDCStateRepositoryInterface {
+	return &OIDCStateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create stores a new login state, purging the expired ones left by abandoned logins
+func (r *OIDCStateRepository) Create(state *model.OIDCLoginState) error {
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&model.OIDCLoginState{}).Error; err != nil {
+		r.logger.Warn("Error purging expired OIDC states", zap.Error(err))
+	}
+	if err := r.db.Create(state).Error; err != nil {
+		r.logger.Error("Error creating OIDC state", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Consume deletes and returns the login state with the given hash, so each state is used at most once
+// It returns nil without an error when no state matches
+func (r *OIDCStateRepository) Consume(stateHash string) (*model.OIDCLoginState, error) {
+	var states []model.OIDCLoginState
+	err := r.db.Clauses(clause.Returning{}).Where("state_hash = ?", stateHash).Delete(&states).Error
+	if err != nil {
+		r.logger.Error("Error consuming OIDC state", zap.Error(err))
+		return nil, err
+	}
+	if len(states) == 0 {
+		return nil, nil
+	}
+	return &states[0], nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrganizationRepository implements the repository interface for organizations and memberships
+type OrganizationRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewOrganizationRepository initializes a new OrganizationRepository with the provided database and logger
+func NewOrganizationRepository(db *gorm.DB, logger *zap.Logger) repository.OrganizationRepositoryInterface {
+	return &OrganizationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create stores a new organization
+func (r *OrganizationRepository) Create(org *model.Organization) error {
+	if err := r.db.Create(org).Error; err != nil {
+		r.logger.Error("Error creating organization", zap.String("slug", org.Slug), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// FindByID retrieves an organization by its ID
+// It returns nil without an error when the organization does not exist
+func (r *OrganizationRepository) FindByID(id uint) (*model.Organization, error) {
+	var org model.Organization
+	err := r.db.First(&org, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching organization", zap.Uint("org_id", id), zap.Error(err))
+		return nil, err
+	}
+	return &org, nil
+}
+
+// FindBySlug retrieves an organization by its slug
+// It returns nil without an error when the organization does not exist
+func (r *OrganizationRepository) FindBySlug(slug string) (*model.Organization, error) {
+	var org model.Organization
+	err := r.db.Where("slug = ?", slug).First(&org).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching organization by slug", zap.String("slug", slug), zap.Error(err))
+		return nil, err
+	}
+	return &org, nil
+}
+
+// List retrieves every organization ordered by name
+func (r *OrganizationRepository) List() ([]*model.Organization, error) {
+	var orgs []*model.Organization
+	if err := r.db.Order("name").Find(&orgs).Error; err != nil {
+		r.logger.Error("Error listing organizations", zap.Error(err))
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// FindMembership retrieves a user's membership in an organization, with the organization loaded
+// It returns nil without an error when the user is not a member
+func (r *OrganizationRepository) FindMembership(userID, orgID uint) (*model.Membership, error) {
+	var membership model.Membership
+	err := r.db.Preload("Organization").Where("user_id = ? AND org_id = ?", userID, orgID).First(&membership).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching membership", zap.Uint("user_id", userID), zap.Uint("org_id", orgID), zap.Error(err))
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// ListMemberships retrieves the organizations a user belongs to, oldest organization first
+func (r *OrganizationRepository) ListMemberships(userID uint) ([]*model.Membership, error) {
+	var memberships []*model.Membership
+	err := r.db.Preload("Organization").Where("user_id = ?", userID).Order("org_id").Find(&memberships).Error
+	if err != nil {
+		r.logger.Error("Error listing memberships", zap.Uint("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// ListMembers retrieves the members of an organization with their user loaded
+// Deleted users are left out
+func (r *OrganizationRepository) ListMembers(orgID uint) ([]*model.Membership, error) {
+	var memberships []*model.Membership
+	err := r.db.InnerJoins("User").Where("memberships.org_id = ?", orgID).Order("memberships.user_id").Find(&memberships).Error
+	if err != nil {
+		r.logger.Error("Error listing organization members", zap.Uint("org_id", orgID), zap.Error(err))
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// CountAdmins counts the members holding the admin role in an organization
+func (r *OrganizationRepository) CountAdmins(orgID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Membership{}).Where("org_id = ? AND role = ?", orgID, model.RoleAdmin).Count(&count).Error
+	if err != nil {
+		r.logger.Error("Error counting organization admins", zap.Uint("org_id", orgID), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// SaveMembership adds a user to an organization, or changes the role of an existing member
+func (r *OrganizationRepository) SaveMembership(membership *model.Membership) error {
+	err := r.db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "org_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(membership).Error
+	if err != nil {
+		r.logger.Error("Error saving membership", zap.Uint("user_id", membership.UserID), zap.Uint("org_id", membership.OrgID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteMembership removes a user from an organization and reports whether they were a member
+func (r *OrganizationRepository) DeleteMembership(userID, orgID uint) (bool, error) {
+	result := r.db.Where("user_id = ? AND org_id = ?", userID, orgID).Delete(&model.Membership{})
+	if result.Error != nil {
+		r.logger.Error("Error deleting membership", zap.Uint("user_id", userID), zap.Uint("org_id", orgID), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository implements the repository interface for the event outbox
+type OutboxRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewOutboxRepository initializes a new OutboxRepository with the provided database and logger
+func NewOutboxRepository(db *gorm.DB, logger *zap.Logger) repository.OutboxRepositoryInterface {
+	return &OutboxRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// enqueueOutbox stores an event within the transaction of the change it describes
+// A nil event is ignored, for changes that publish nothing
+func enqueueOutbox(tx *gorm.DB, event *model.OutboxEvent) error {
+	if event == nil {
+		return nil
+	}
+	if event.AvailableAt.IsZero() {
+		event.AvailableAt = time.Now()
+	}
+	return tx.Create(event).Error
+}
+
+// ClaimPending locks the oldest due events, skipping those locked by another relay, and postpones
+// them by the lease so that they are not published twice; a relay that dies releases them when it expires
+func (r *OutboxRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEvent, error) {
+	var events []*model.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("sent_at IS NULL AND available_at <= ?", now).
+			Order("id").Limit(limit).Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		ids := make([]uint, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		return tx.Model(&model.OutboxEvent{}).Where("id IN ?", ids).Update("available_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		r.logger.Error("Error claiming outbox events", zap.Error(err), zap.String("operation", "outbox_claim"))
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkSent records that an event was published
+func (r *OutboxRepository) MarkSent(ctx context.Context, id uint) error {
+	err := r.db.WithContext(ctx).Model(&model.OutboxEvent{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"sent_at": time.Now(), "last_error": ""}).Error
+	if err != nil {
+		r.logger.Error("Error marking outbox event as sent", zap.Uint("event_id", id), zap.Error(err), zap.String("operation", "outbox_sent"))
+		return err
+	}
+	return nil
+}
+
+// MarkFailed counts a failed attempt and schedules the next one
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uint, lastError string, retryAt time.Time) error {
+	err := r.db.WithContext(ctx).Model(&model.OutboxEvent{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   lastError,
+			"available_at": retryAt,
+		}).Error
+	if err != nil {
+		r.logger.Error("Error recording outbox event failure", zap.Uint("event_id", id), zap.Error(err), zap.String("operation", "outbox_failed"))
+		return err
+	}
+	return nil
+}
+
+// DeleteSentBefore removes the events published before the given instant
+func (r *OutboxRepository) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("sent_at IS NOT NULL AND sent_at < ?", before).Delete(&model.OutboxEvent{})
+	if result.Error != nil {
+		r.logger.Error("Error deleting sent outbox events", zap.Error(result.Error), zap.String("operation", "outbox_cleanup"))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PasswordResetRepository implements the repository interface for password reset tokens
+type PasswordResetRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewPasswordResetRepository initializes a new PasswordResetRepository with the provided database and logger
+func NewPasswordResetRepository(db *gorm.DB, logger *zap.Logger) repository.PasswordResetRepositoryInterface {
+	return &PasswordResetRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create stores a new password reset token
+func (r *PasswordResetRepository) Create(token *model.PasswordResetToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		r.logger.Error("Error creating password reset token", zap.Uint("user_id", token.UserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// FindByHash retrieves a password reset token by the hash of its raw value
+// It returns nil without an error when no token matches
+func (r *PasswordResetRepository) FindByHash(tokenHash string) (*model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Password reset token not found")
+			return nil, nil
+		}
+		r.logger.Error("Error fetching password reset token", zap.Error(err))
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed consumes a token, reporting false if it had already been used
+func (r *PasswordResetRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&model.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		r.logger.Error("Error consuming password reset token", zap.Uint("token_id", id), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// InvalidateForUser consumes every outstanding reset token of a user
+func (r *PasswordResetRepository) InvalidateForUser(userID uint) error {
+	err := r.db.Model(&model.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		r.logger.Error("Error invalidating password reset tokens", zap.Uint("user_id", userID), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProcessedMessageRepository implements the repository interface for the messages processed by consumers
+type ProcessedMessageRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewProcessedMessageRepository initializes a new ProcessedMessageRepository with the provided database and logger
+func NewProcessedMessageRepository(db *gorm.DB, logger *zap.Logger) repository.ProcessedMessageRepositoryInterface {
+	return &ProcessedMessageRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Processed returns the IDs among ids that the consumer already processed
+func (r *ProcessedMessageRepository) Processed(ctx context.Context, consumer string, ids []string) (map[string]bool, error) {
+	processed := make(map[string]bool)
+	if len(ids) == 0 {
+		return processed, nil
+	}
+	var found []string
+	err := r.db.WithContext(ctx).Model(&model.ProcessedMessage{}).
+		Where("consumer = ? AND message_id IN ?", consumer, ids).Pluck("message_id", &found).Error
+	if err != nil {
+		r.logger.Error("Error looking up processed messages", zap.String("consumer", consumer), zap.Error(err), zap.String("operation", "processed_messages_lookup"))
+		return nil, err
+	}
+	for _, id := range found {
+		processed[id] = true
+	}
+	return processed, nil
+}
+
+// MarkProcessed records messages as processed, ignoring those already recorded by a concurrent delivery
+func (r *ProcessedMessageRepository) MarkProcessed(ctx context.Context, messages []*model.ProcessedMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(messages).Error
+	if err != nil {
+		r.logger.Error("Error recording processed messages", zap.Int("count", len(messages)), zap.Error(err), zap.String("operation", "processed_messages_record"))
+		return err
+	}
+	return nil
+}
+
+// DeleteProcessedBefore removes the records of messages processed before the given instant
+func (r *ProcessedMessageRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("processed_at < ?", before).Delete(&model.ProcessedMessage{})
+	if result.Error != nil {
+		r.logger.Error("Error deleting processed messages", zap.Error(result.Error), zap.String("operation", "processed_messages_cleanup"))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Amandasilvbr/products-crud/internal/domain/model"
 	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/tenant"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -25,14 +26,46 @@ func NewProductRepository(db *gorm.DB, logger *zap.Logger) repository.ProductRep
 	}
 }
 
+// scoped returns a session restricted to the organization carried by the context
+// Every product query goes through it, so a product of another organization is simply not found;
+// without an organization the query fails instead of reading across tenants
+func (r *ProductRepository) scoped(ctx context.Context) (*gorm.DB, uint, error) {
+	orgID, ok := tenant.OrgID(ctx)
+	if !ok {
+		r.logger.Error("Product query without an organization", zap.String("operation", "product_scope"))
+		return nil, 0, tenant.ErrNoOrganization
+	}
+	return r.db.WithContext(ctx).Where("org_id = ?", orgID).Session(&gorm.Session{}), orgID, nil
+}
+
+// failAll reports the same error for every SKU of a batch
+func failAll(skus []int, err error) map[int]string {
+	errors := make(map[int]string, len(skus))
+	for _, sku := range skus {
+		errors[sku] = err.Error()
+	}
+	return errors
+}
+
 // Create handles the creation of one or more products in the database
+// Products are created in the context's organization; SKUs only need to be unique within it
+// Each product is committed together with its outbox event
 // It performs validations for duplicates and existing SKUs, returning a map of any errors
-func (r *ProductRepository) Create(ctx context.Context, products []*model.Product) map[int]string {
+func (r *ProductRepository) Create(ctx context.Context, products []*model.Product, events map[int]*model.OutboxEvent) map[int]string {
     if len(products) == 0 {
         r.logger.Warn("No products provided for creation")
         return nil
     }
 
+    db, orgID, err := r.scoped(ctx)
+    if err != nil {
+        skus := make([]int, 0, len(products))
+        for _, product := range products {
+            skus = append(skus, product.SKU)
+        }
+        return failAll(skus, err)
+    }
+
     errors := make(map[int]string)
     skuSet := make(map[int]struct{})
     for _, product := range products {
@@ -57,9 +90,9 @@ func (r *ProductRepository) Create(ctx context.Context, products []*model.Produc
             continue 
         }
 
-        // Check if the SKU already exists in the database
+        // Check if the SKU already exists in the organization
         var existingProduct model.Product
-        if err := r.db.WithContext(ctx).Where("sku = ?", product.SKU).First(&existingProduct).Error; err == nil {
+        if err := db.Where("sku = ?", product.SKU).First(&existingProduct).Error; err == nil {
             r.logger.Warn("Product with SKU already exists", zap.Int("sku", product.SKU))
             errors[product.SKU] = fmt.Sprintf("Product with SKU %d already exists", product.SKU)
             continue
@@ -69,8 +102,15 @@ func (r *ProductRepository) Create(ctx context.Context, products []*model.Produc
             continue
         }
 
-        // Create the product
-        if err := r.db.WithContext(ctx).Create(product).Error; err != nil {
+        // Create the product and its event
+        product.OrgID = orgID
+        err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+            if err := tx.Create(product).Error; err != nil {
+                return err
+            }
+            return enqueueOutbox(tx, events[product.SKU])
+        })
+        if err != nil {
             r.logger.Error("Error creating product", zap.Int("sku", product.SKU), zap.Error(err))
             errors[product.SKU] = fmt.Sprintf("Error creating product with SKU %d: %s", product.SKU, err.Error())
         }
@@ -83,20 +123,28 @@ func (r *ProductRepository) Create(ctx context.Context, products []*model.Produc
     return nil
 }
 
-// GetAll retrieves all products from the database
+// GetAll retrieves all products of the context's organization
 func (r *ProductRepository) GetAll(ctx context.Context) ([]*model.Product, error) {
+	db, _, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var products []*model.Product
-	if err := r.db.WithContext(ctx).Find(&products).Error; err != nil {
+	if err := db.Find(&products).Error; err != nil {
 		r.logger.Error("Error fetching all products", zap.Error(err))
 		return nil, err
 	}
 	return products, nil
 }
 
-// GetBySKU retrieves a single product by its SKU
+// GetBySKU retrieves a single product of the context's organization by its SKU
 func (r *ProductRepository) GetBySKU(ctx context.Context, sku int) (*model.Product, error) {
+	db, _, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var product model.Product
-	result := r.db.WithContext(ctx).First(&product, "sku = ?", sku)
+	result := db.First(&product, "sku = ?", sku)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			r.logger.Warn("Product not found", zap.Int("sku", sku))
@@ -108,25 +156,43 @@ func (r *ProductRepository) GetBySKU(ctx context.Context, sku int) (*model.Produ
 	return &product, nil
 }
 
-// Update modifies one or more existing products in the database
+// Update modifies one or more existing products of the context's organization
+// Each product is committed together with its outbox event
 // It returns a map of errors for any products that failed to update
-func (r *ProductRepository) Update(ctx context.Context, products []*model.Product) map[int]string {
+func (r *ProductRepository) Update(ctx context.Context, products []*model.Product, events map[int]*model.OutboxEvent) map[int]string {
 	if len(products) == 0 {
 		r.logger.Warn("No products provided for update")
 		return nil
 	}
 
+	_, orgID, err := r.scoped(ctx)
+	if err != nil {
+		skus := make([]int, 0, len(products))
+		for _, product := range products {
+			skus = append(skus, product.SKU)
+		}
+		return failAll(skus, err)
+	}
+
 	errors := make(map[int]string)
 	for _, product := range products {
-		result := r.db.WithContext(ctx).Where("sku = ?", product.SKU).Updates(product)
-		if result.Error != nil {
-			r.logger.Error("Error updating product", zap.Int("sku", product.SKU), zap.Error(result.Error))
-			errors[product.SKU] = fmt.Sprintf("Error to update product with SKU %d: %s", product.SKU, result.Error.Error())
-			continue
-		}
-		if result.RowsAffected == 0 {
+		product.OrgID = orgID
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			result := tx.Where("org_id = ? AND sku = ?", orgID, product.SKU).Updates(product)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+			return enqueueOutbox(tx, events[product.SKU])
+		})
+		if err == gorm.ErrRecordNotFound {
 			r.logger.Warn("No product found to update", zap.Int("sku", product.SKU))
 			errors[product.SKU] = fmt.Sprintf("Product with SKU %d not found", product.SKU)
+		} else if err != nil {
+			r.logger.Error("Error updating product", zap.Int("sku", product.SKU), zap.Error(err))
+			errors[product.SKU] = fmt.Sprintf("Error to update product with SKU %d: %s", product.SKU, err.Error())
 		}
 	}
 
@@ -137,25 +203,38 @@ func (r *ProductRepository) Update(ctx context.Context, products []*model.Produc
 	return nil
 }
 
-// Delete removes a batch of products from the database by their SKUs
+// Delete removes a batch of products of the context's organization by their SKUs
+// Each deletion is committed together with its outbox event
 // It returns a map of errors for any SKUs that failed to delete
-func (r *ProductRepository) Delete(ctx context.Context, skus []int) map[int]string {
+func (r *ProductRepository) Delete(ctx context.Context, skus []int, events map[int]*model.OutboxEvent) map[int]string {
 	if len(skus) == 0 {
 		r.logger.Warn("No SKUs provided for deletion")
 		return nil
 	}
 
+	_, orgID, err := r.scoped(ctx)
+	if err != nil {
+		return failAll(skus, err)
+	}
+
 	errors := make(map[int]string)
 	for _, sku := range skus {
-		result := r.db.WithContext(ctx).Where("sku = ?", sku).Delete(&model.Product{})
-		if result.Error != nil {
-			r.logger.Error("Error deleting product", zap.Int("sku", sku), zap.Error(result.Error))
-			errors[sku] = fmt.Sprintf("Error to delete product with SKU %d: %s", sku, result.Error.Error())
-			continue
-		}
-		if result.RowsAffected == 0 {
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			result := tx.Where("org_id = ? AND sku = ?", orgID, sku).Delete(&model.Product{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+			return enqueueOutbox(tx, events[sku])
+		})
+		if err == gorm.ErrRecordNotFound {
 			r.logger.Warn("No product found to delete", zap.Int("sku", sku))
 			errors[sku] = fmt.Sprintf("Product with SKU %d not found", sku)
+		} else if err != nil {
+			r.logger.Error("Error deleting product", zap.Int("sku", sku), zap.Error(err))
+			errors[sku] = fmt.Sprintf("Error to delete product with SKU %d: %s", sku, err.Error())
 		}
 	}
 
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TokenRepository implements the repository interface for refresh tokens and revoked access tokens
+type TokenRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewTokenRepository initializes a new TokenRepository with the provided database and logger
+func NewTokenRepository(db *gorm.DB, logger *zap.Logger) repository.TokenRepositoryInterface {
+	return &TokenRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateRefreshToken stores a new refresh token record
+func (r *TokenRepository) CreateRefreshToken(token *model.RefreshToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		r.logger.Error("Error creating refresh token", zap.Uint("user_id", token.UserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// FindRefreshTokenByHash retrieves a refresh token by the hash of its raw value
+// It returns nil without an error when no token matches
+func (r *TokenRepository) FindRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Refresh token not found")
+			return nil, nil
+		}
+		r.logger.Error("Error fetching refresh token", zap.Error(err))
+		return nil, err
+	}
+	return &token, nil
+}
+
+// errAlreadyRotated rolls back a rotation whose old token was revoked concurrently
+var errAlreadyRotated = errors.New("refresh token was already rotated")
+
+// RotateRefreshToken revokes the old token and stores its replacement in a single transaction
+// It returns false, without storing the replacement, when the old token was revoked concurrently
+func (r *TokenRepository) RotateRefreshToken(oldID uint, newToken *model.RefreshToken) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newToken).Error; err != nil {
+			r.logger.Error("Error creating rotated refresh token", zap.Uint("user_id", newToken.UserID), zap.Error(err))
+			return err
+		}
+		// Only revoke the old token if it has not been revoked concurrently
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", oldID).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by_id": newToken.ID})
+		if result.Error != nil {
+			r.logger.Error("Error revoking rotated refresh token", zap.Uint("token_id", oldID), zap.Error(result.Error))
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			r.logger.Warn("Refresh token was already rotated", zap.Uint("token_id", oldID))
+			return errAlreadyRotated
+		}
+		return nil
+	})
+	if errors.Is(err, errAlreadyRotated) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// RevokeRefreshToken marks a single refresh token as revoked
+func (r *TokenRepository) RevokeRefreshToken(id uint) error {
+	err := r.db.Model(&model.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.logger.Error("Error revoking refresh token", zap.Uint("token_id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RevokeFamily revokes every token that descends from the same login
+func (r *TokenRepository) RevokeFamily(familyID string) error {
+	err := r.db.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.logger.Error("Error revoking refresh token family", zap.String("family_id", familyID), zap.Error(err))
+		return err
+	}
+	r.logger.Info("Refresh token family revoked", zap.String("family_id", familyID))
+	return nil
+}
+
+// RevokeAllForUser revokes every active refresh token belonging to a user
+func (r *TokenRepository) RevokeAllForUser(userID uint) error {
+	err := r.db.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.logger.Error("Error revoking user refresh tokens", zap.Uint("user_id", userID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RevokeJTI adds an access token identifier to the denylist until the token expires
+// Entries whose tokens have already expired are purged along the way
+func (r *TokenRepository) RevokeJTI(jti string, expiresAt time.Time) error {
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&model.RevokedToken{}).Error; err != nil {
+		r.logger.Warn("Error purging expired revoked tokens", zap.Error(err))
+	}
+	if err := r.db.Save(&model.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error; err != nil {
+		r.logger.Error("Error revoking access token", zap.String("jti", jti), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// IsJTIRevoked reports whether an access token identifier is in the denylist
+func (r *TokenRepository) IsJTIRevoked(jti string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		r.logger.Error("Error checking revoked token", zap.String("jti", jti), zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
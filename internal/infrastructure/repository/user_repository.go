@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/Amandasilvbr/products-crud/internal/domain/model"
 	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
@@ -41,6 +42,37 @@ func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
 	return &user, nil
 }
 
+// FindByID retrieves a user by their primary key
+func (r *UserRepository) FindByID(id uint) (*model.User, error) {
+	var user model.User
+	err := r.db.First(&user, id).Error
+	if err != nil {
+		// A missing user is reported as nil without an error, like FindByEmail
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("User not found", zap.Uint("user_id", id))
+			return nil, nil
+		}
+		r.logger.Error("Error fetching user by ID", zap.Uint("user_id", id), zap.Error(err))
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindByOIDCSubject retrieves the user linked to an identity provider subject
+// It returns nil without an error when no user is linked to the subject
+func (r *UserRepository) FindByOIDCSubject(subject string) (*model.User, error) {
+	var user model.User
+	err := r.db.Where("oidc_subject = ?", subject).First(&user).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching user by OIDC subject", zap.Error(err))
+		return nil, err
+	}
+	return &user, nil
+}
+
 // Create adds a new user to the database
 func (r *UserRepository) Create(user *model.User) error {
 	// Check for an existing user with the same email before creating a new one
@@ -63,3 +95,56 @@ func (r *UserRepository) Create(user *model.User) error {
 	r.logger.Info("User created successfully")
 	return nil
 }
+
+// Update persists all fields of an existing user
+func (r *UserRepository) Update(user *model.User) error {
+	if err := r.db.Save(user).Error; err != nil {
+		r.logger.Error("Error updating user", zap.Uint("user_id", user.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// List retrieves a page of users ordered by ID, optionally filtered by a case-insensitive
+// search on name or email, together with the total number of matching users
+func (r *UserRepository) List(search string, offset, limit int) ([]*model.User, int64, error) {
+	query := r.db.Model(&model.User{})
+	if search != "" {
+		// Escape LIKE wildcards so the search is matched literally
+		pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(search) + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("Error counting users", zap.Error(err))
+		return nil, 0, err
+	}
+
+	var users []*model.User
+	if err := query.Order("id").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		r.logger.Error("Error listing users", zap.Error(err))
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// NameTaken reports whether a user other than exceptID already uses the given name
+func (r *UserRepository) NameTaken(name string, exceptID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.User{}).Where("name = ? AND id <> ?", name, exceptID).Count(&count).Error
+	if err != nil {
+		r.logger.Error("Error checking user name", zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Delete soft-deletes a user, freeing their email for a new registration
+func (r *UserRepository) Delete(id uint) error {
+	if err := r.db.Delete(&model.User{}, id).Error; err != nil {
+		r.logger.Error("Error deleting user", zap.Uint("user_id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+
+	"go.uber.org/zap"
+)
+
+// Ensure WebhookNotifier implements the Notifier interface at compile time
+var _ messaging.Notifier = (*WebhookNotifier)(nil)
+
+// requestTimeout bounds each call to a webhook, so that a slow chat service does not stall the consumer
+const requestTimeout = 10 * time.Second
+
+// productActions describes each product event in the webhook messages
+var productActions = map[string]string{
+	model.EventProductCreated: "criado",
+	model.EventProductUpdated: "atualizado",
+	model.EventProductDeleted: "deletado",
+}
+
+// WebhookNotifier posts product notifications to an incoming webhook, such as a Slack or Microsoft Teams channel
+type WebhookNotifier struct {
+	channel string
+	url     string
+	payload func(title string, lines []string) interface{}
+	client  *http.Client
+	logger  *zap.Logger
+}
+
+// NewSlackNotifier creates a notifier posting Slack formatted messages to an incoming webhook URL
+func NewSlackNotifier(url string, logger *zap.Logger) *WebhookNotifier {
+	return newWebhookNotifier(messaging.ChannelSlack, url, slackPayload, logger)
+}
+
+// NewTeamsNotifier creates a notifier posting Microsoft Teams message cards to an incoming webhook URL
+func NewTeamsNotifier(url string, logger *zap.Logger) *WebhookNotifier {
+	return newWebhookNotifier(messaging.ChannelTeams, url, teamsPayload, logger)
+}
+
+func newWebhookNotifier(channel, url string, payload func(string, []string) interface{}, logger *zap.Logger) *WebhookNotifier {
+	return &WebhookNotifier{
+		channel: channel,
+		url:     url,
+		payload: payload,
+		client:  &http.Client{Timeout: requestTimeout},
+		logger:  logger,
+	}
+}
+
+// Channel returns the name of the webhook in the routing rules
+func (n *WebhookNotifier) Channel() string {
+	return n.channel
+}
+
+// Notify posts one message listing the product changes made by the recipient
+// Client errors other than timeouts and rate limits are permanent: the webhook was removed or rejects the payload
+func (n *WebhookNotifier) Notify(ctx context.Context, recipient string, notifications []model.ProductNotification) error {
+	title := fmt.Sprintf("Alterações de produtos por %s", recipient)
+	lines := make([]string, 0, len(notifications))
+	for _, notification := range notifications {
+		action, ok := productActions[notification.Event]
+		if !ok {
+			action = notification.Event
+		}
+		line := fmt.Sprintf("Produto %s (SKU: %d) foi %s", notification.Name, notification.SKU, action)
+		if notification.OrgName != "" {
+			line += fmt.Sprintf(" [%s]", notification.OrgName)
+		}
+		lines = append(lines, line)
+	}
+
+	body, err := json.Marshal(n.payload(title, lines))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %w", messaging.ErrPermanentDelivery, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		n.logger.Error("Failed to call webhook", zap.String("channel", n.channel), zap.Error(err))
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode >= 300 {
+		err := fmt.Errorf("webhook %s answered %s", n.channel, resp.Status)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			err = fmt.Errorf("%w: %w", messaging.ErrPermanentDelivery, err)
+		}
+		n.logger.Error("Webhook rejected notifications", zap.String("channel", n.channel), zap.Int("status", resp.StatusCode))
+		return err
+	}
+
+	n.logger.Info("Notifications posted to webhook", zap.String("channel", n.channel), zap.Int("event_count", len(notifications)))
+	return nil
+}
+
+// slackEscaper escapes the characters Slack reserves for links and mentions
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackPayload builds a Slack message with the title in bold and one bullet per change
+func slackPayload(title string, lines []string) interface{} {
+	var text strings.Builder
+	text.WriteString("*" + slackEscaper.Replace(title) + "*")
+	for _, line := range lines {
+		text.WriteString("\n• " + slackEscaper.Replace(line))
+	}
+	return map[string]string{"text": text.String()}
+}
+
+// teamsPayload builds a Microsoft Teams message card with one paragraph per change
+func teamsPayload(title string, lines []string) interface{} {
+	return map[string]string{
+		"@type":    "MessageCard",
+		"@context": "https://schema.org/extensions",
+		"summary":  title,
+		"title":    title,
+		"text":     strings.Join(lines, "\n\n"),
+	}
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/handler"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// NotifyShutdown returns a context cancelled on SIGINT or SIGTERM, which starts the graceful shutdown
+func NotifyShutdown(parent context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+}
+
+// Group runs the long-lived tasks of a process, such as the HTTP server and the worker jobs
+// Tasks stop when the group's context is cancelled; a task failing for another reason cancels the
+// others, so that the process exits and can be restarted
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	logger *zap.Logger
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewGroup creates a Group whose tasks run until ctx is cancelled
+func NewGroup(ctx context.Context, logger *zap.Logger) *Group {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{ctx: ctx, cancel: cancel, logger: logger}
+}
+
+// Context returns the context of the group's tasks, cancelled on shutdown or when a task fails
+func (g *Group) Context() context.Context {
+	return g.ctx
+}
+
+// Go starts a named task in its own goroutine
+func (g *Group) Go(name string, task func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		g.logger.Info("Starting task", zap.String("task", name))
+		err := task(g.ctx)
+		if err == nil || errors.Is(err, context.Canceled) {
+			g.logger.Info("Task stopped", zap.String("task", name))
+			return
+		}
+
+		g.logger.Error("Task failed, shutting down", zap.String("task", name), zap.Error(err))
+		g.mu.Lock()
+		if g.err == nil {
+			g.err = fmt.Errorf("%s: %w", name, err)
+		}
+		g.mu.Unlock()
+		g.cancel()
+	}()
+}
+
+// Wait waits for every task to stop and returns the first failure
+// A positive timeout bounds the wait once the context is cancelled, for tasks that do not stop in time
+func (g *Group) Wait(timeout time.Duration) error {
+	stopped := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(stopped)
+	}()
+
+	if timeout > 0 {
+		select {
+		case <-stopped:
+		case <-g.ctx.Done():
+			select {
+			case <-stopped:
+			case <-time.After(timeout):
+				g.logger.Warn("Tasks did not stop before the shutdown timeout", zap.Duration("timeout", timeout))
+				return fmt.Errorf("tasks did not stop within %s", timeout)
+			}
+		}
+	} else {
+		<-stopped
+	}
+
+	g.cancel()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+// StartHealth serves the health check alone, for processes without the API such as the standalone worker
+func StartHealth(ctx context.Context, addr string, health *handler.HealthHandler, logger *zap.Logger) error {
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.GET("/health", health.Health)
+
+	logger.Info("Starting health check server", zap.String("addr", addr))
+	return serve(ctx, &http.Server{Addr: addr, Handler: r})
+}
+
+// serve runs an HTTP server until the context is cancelled, then stops accepting connections and
+// waits for the requests in flight
+func serve(ctx context.Context, srv *http.Server) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	if err := srv.Shutdown(context.Background()); err != nil {
+		return err
+	}
+	return nil
+}
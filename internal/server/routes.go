@@ -1,6 +1,9 @@
 package server
 
 import (
+	"github.com/Amandasilvbr/products-crud/internal/domain/identity"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
 	"github.com/Amandasilvbr/products-crud/internal/handler"
 	"github.com/Amandasilvbr/products-crud/internal/handler/middleware"
 	"github.com/gin-gonic/gin"
@@ -9,23 +12,142 @@ import (
 	"go.uber.org/zap"
 )
 
+// Handlers groups the HTTP handlers and the dependencies the middlewares need
+type Handlers struct {
+	Auth         *handler.AuthHandler
+	Product      *handler.ProductHandler
+	User         *handler.UserHandler
+	APIKey       *handler.APIKeyHandler
+	Password     *handler.PasswordHandler
+	MFA          *handler.MFAHandler
+	OIDC         *handler.OIDCHandler // nil when no identity provider is configured
+	JWKS         *handler.JWKSHandler
+	Org          *handler.OrganizationHandler
+	Invite       *handler.InvitationHandler
+	Health       *handler.HealthHandler
+	DeadLetter   *handler.DeadLetterHandler
+	Notification *handler.NotificationHandler
+	Inbox        *handler.InboxHandler
+	Email        *handler.EmailDeliveryHandler
+
+	// LocalLogin exposes the email and password routes; it can be turned off when OIDC is the only sign-in
+	LocalLogin bool
+	// OpenRegistration exposes POST /register; otherwise accounts are only created from invitations
+	OpenRegistration bool
+
+	KeySet        identity.KeySet
+	AuthUsecase   usecase.AuthUsecaseInterface
+	APIKeyUsecase usecase.APIKeyUsecaseInterface
+	OrgUsecase    usecase.OrganizationUsecaseInterface
+}
+
 // SetupRoutes configures the API routes
-func SetupRoutes(r *gin.Engine, authHandler *handler.AuthHandler, productHandler *handler.ProductHandler, logger *zap.Logger) {
+func SetupRoutes(r *gin.Engine, h *Handlers, logger *zap.Logger) {
 	// Configure Swagger route
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Public keys for downstream services verifying our access tokens
+	r.GET("/.well-known/jwks.json", h.JWKS.JWKS)
+
 	// Group all API routes under the "/api" prefix
 	api := r.Group("/api")
 
+	// Health check for load balancers and orchestrators
+	api.GET("/health", h.Health.Health)
+
 	// Public routes for authentication and user registration
-	api.POST("/login", authHandler.Login)
-	api.POST("/register", authHandler.CreateUser)
-
-	// Protected routes with JWT middleware
-	api.Use(middleware.JWTMiddleware(logger))
-	api.POST("/products", productHandler.Create)
-	api.GET("/products", productHandler.GetAll)
-	api.GET("/products/:sku", productHandler.GetBySKU)
-	api.PUT("/products", productHandler.Update)
-	api.DELETE("/products", productHandler.Delete)
-}
\ No newline at end of file
+	if h.LocalLogin {
+		api.POST("/login", h.Auth.Login)
+		api.POST("/login/mfa", h.Auth.LoginMFA)
+		api.POST("/login/mfa/enroll", h.Auth.LoginMFAEnroll)
+		if h.OpenRegistration {
+			api.POST("/register", h.Auth.CreateUser)
+		}
+		api.POST("/invitations/:token/accept", h.Invite.Accept)
+		api.POST("/password/forgot", h.Password.Forgot)
+		api.POST("/password/reset", h.Password.Reset)
+		api.GET("/email/verify", h.Auth.VerifyEmail)
+		api.POST("/email/verify/resend", h.Auth.ResendVerification)
+	}
+	if h.OIDC != nil {
+		api.GET("/oidc/login", h.OIDC.Login)
+		api.GET("/oidc/callback", h.OIDC.Callback)
+	}
+	api.POST("/token/refresh", h.Auth.RefreshToken)
+	api.GET("/notifications/unsubscribe", h.Notification.Unsubscribe)
+
+	// Protected routes with JWT (or API key) middleware
+	api.Use(middleware.JWTMiddleware(h.KeySet, h.AuthUsecase, h.APIKeyUsecase, h.OrgUsecase, logger))
+
+	// Session routes are only meaningful for people, not API keys
+	session := api.Group("", middleware.RequireUserSession(logger))
+	session.POST("/logout", h.Auth.Logout)
+	session.POST("/sessions/revoke", h.Auth.RevokeSessions)
+	if h.LocalLogin {
+		session.POST("/password/change", h.Password.Change)
+	}
+	session.GET("/me", h.User.GetMe)
+	session.PUT("/me", h.User.UpdateMe)
+	session.GET("/me/notifications", h.Notification.GetPreferences)
+	session.PUT("/me/notifications", h.Notification.UpdatePreferences)
+	session.GET("/notifications", h.Inbox.List)
+	session.POST("/notifications/read-all", h.Inbox.MarkAllRead)
+	session.POST("/notifications/:id/read", h.Inbox.MarkRead)
+	session.POST("/notifications/:id/unread", h.Inbox.MarkUnread)
+	session.POST("/mfa/enroll", h.MFA.Enroll)
+	session.POST("/mfa/confirm", h.MFA.Confirm)
+	session.POST("/mfa/recovery-codes", h.MFA.RegenerateRecoveryCodes)
+	session.POST("/mfa/disable", h.MFA.Disable)
+	session.GET("/orgs", h.Org.ListMine)
+	session.POST("/orgs/:id/switch", h.Org.Switch)
+	session.GET("/orgs/:id/members", h.Org.ListMembers)
+	session.POST("/orgs/:id/members", h.Org.AddMember)
+	session.PUT("/orgs/:id/members/:userId", h.Org.UpdateMember)
+	session.DELETE("/orgs/:id/members/:userId", h.Org.RemoveMember)
+	session.GET("/orgs/:id/invitations", h.Invite.List)
+	session.POST("/orgs/:id/invitations", h.Invite.Create)
+	session.POST("/orgs/:id/invitations/:invitationId/resend", h.Invite.Resend)
+	session.DELETE("/orgs/:id/invitations/:invitationId", h.Invite.Revoke)
+
+	// Products always belong to the active organization
+	products := api.Group("", middleware.RequireOrganization(logger))
+
+	// Product reads are open to every role
+	readers := products.Group("", middleware.RequireScopes(logger, model.ScopeProductsRead))
+	readers.GET("/products", h.Product.GetAll)
+	readers.GET("/products/:sku", h.Product.GetBySKU)
+
+	// Product writes require the editor or admin role in the organization; ownership is checked per item
+	writers := products.Group("",
+		middleware.RequireScopes(logger, model.ScopeProductsWrite),
+		middleware.RequireOrgRoles(logger, model.RoleAdmin, model.RoleEditor))
+	writers.POST("/products", h.Product.Create)
+	writers.PUT("/products", h.Product.Update)
+	writers.DELETE("/products", h.Product.Delete)
+
+	// Administration routes
+	admin := api.Group("/admin", middleware.RequireUserSession(logger), middleware.RequireRoles(logger, model.RoleAdmin))
+	admin.GET("/users", h.User.List)
+	admin.GET("/users/:id", h.User.Get)
+	admin.PUT("/users/:id", h.User.Update)
+	admin.DELETE("/users/:id", h.User.Delete)
+	admin.POST("/users/:id/disable", h.User.Disable)
+	admin.POST("/users/:id/enable", h.User.Enable)
+	admin.PUT("/users/:id/role", h.User.AssignRole)
+	admin.POST("/users/:id/unlock", h.User.Unlock)
+	admin.DELETE("/users/:id/mfa", h.MFA.Reset)
+	admin.GET("/mfa/policies", h.MFA.ListPolicies)
+	admin.PUT("/mfa/policies/:role", h.MFA.SetPolicy)
+	admin.POST("/api-keys", h.APIKey.Create)
+	admin.GET("/api-keys", h.APIKey.List)
+	admin.DELETE("/api-keys/:id", h.APIKey.Revoke)
+	admin.POST("/orgs", h.Org.Create)
+	admin.GET("/orgs", h.Org.List)
+	admin.GET("/dead-letters/:queue", h.DeadLetter.List)
+	admin.POST("/dead-letters/:queue/requeue", h.DeadLetter.Requeue)
+	admin.DELETE("/dead-letters/:queue", h.DeadLetter.Purge)
+	admin.GET("/email-preview", h.Notification.Preview)
+	admin.GET("/email-deliveries", h.Email.List)
+	admin.GET("/email-deliveries/:id", h.Email.Get)
+	admin.POST("/email-deliveries/:id/resend", h.Email.Resend)
+}
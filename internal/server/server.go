@@ -2,15 +2,15 @@ package server
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/Amandasilvbr/products-crud/cmd/api/docs"
-	"github.com/Amandasilvbr/products-crud/internal/handler"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-// Start initializes and runs the HTTP server
-func Start(ctx context.Context, authHandler *handler.AuthHandler, productHandler *handler.ProductHandler, logger *zap.Logger) error {
+// Start initializes and runs the HTTP server until the context is cancelled, then shuts it down gracefully
+func Start(ctx context.Context, handlers *Handlers, logger *zap.Logger) error {
 	// Create a new Gin router with default middleware
 	r := gin.Default()
 
@@ -18,9 +18,9 @@ func Start(ctx context.Context, authHandler *handler.AuthHandler, productHandler
 	docs.SwaggerInfo.BasePath = "/api"
 
 	// Set up routes
-	SetupRoutes(r, authHandler, productHandler, logger)
+	SetupRoutes(r, handlers, logger)
 
 	// Run the server
 	logger.Info("Starting HTTP server on port :8988")
-	return r.Run(":8988")
+	return serve(ctx, &http.Server{Addr: ":8988", Handler: r})
 }
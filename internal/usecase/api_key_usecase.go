@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+)
+
+// apiKeyPrefix marks raw API keys so they are easy to recognize in secret scanners
+const apiKeyPrefix = "pck_"
+
+// lastUsedResolution limits how often the last use of a key is written to the database
+const lastUsedResolution = time.Minute
+
+// Standard errors returned by the API key use cases
+var (
+	ErrInvalidAPIKey   = errors.New("invalid API key")
+	ErrAPIKeyNotFound  = errors.New("API key not found")
+	ErrInvalidScope    = errors.New("invalid scope")
+	ErrInvalidKeyOwner = errors.New("API key owner not found")
+)
+
+// APIKeyUsecase implements the business logic for API keys
+type APIKeyUsecase struct {
+	apiKeyRepo repository.APIKeyRepositoryInterface
+	userRepo   repository.UserRepositoryInterface
+	orgs       usecase.OrganizationUsecaseInterface
+	logger     *zap.Logger
+}
+
+// NewAPIKeyUsecase creates a new instance of APIKeyUsecase
+func NewAPIKeyUsecase(apiKeyRepo repository.APIKeyRepositoryInterface, userRepo repository.UserRepositoryInterface, orgs usecase.OrganizationUsecaseInterface, logger *zap.Logger) usecase.APIKeyUsecaseInterface {
+	return &APIKeyUsecase{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		orgs:       orgs,
+		logger:     logger,
+	}
+}
+
+// CreateKey generates a new API key acting on behalf of ownerID within the organization orgID
+// The raw key is returned only here; afterwards only its hash is kept
+func (u *APIKeyUsecase) CreateKey(name string, scopes []string, expiresAt *time.Time, ownerID, orgID, createdByID uint) (*model.APIKey, string, error) {
+	for _, scope := range scopes {
+		if !model.IsValidScope(scope) {
+			u.logger.Warn("Invalid API key scope", zap.String("scope", scope), zap.String("operation", "create_api_key"))
+			return nil, "", ErrInvalidScope
+		}
+	}
+
+	owner, err := u.userRepo.FindByID(ownerID)
+	if err != nil {
+		u.logger.Error("Failed to load API key owner", zap.Uint("owner_id", ownerID), zap.Error(err), zap.String("operation", "create_api_key"))
+		return nil, "", err
+	}
+	if owner == nil {
+		u.logger.Warn("API key owner not found", zap.Uint("owner_id", ownerID), zap.String("operation", "create_api_key"))
+		return nil, "", ErrInvalidKeyOwner
+	}
+	if _, err := u.orgs.ResolveMembership(owner.ID, owner.Role, orgID); err != nil {
+		u.logger.Warn("API key owner cannot access organization", zap.Uint("owner_id", ownerID), zap.Uint("org_id", orgID), zap.Error(err), zap.String("operation", "create_api_key"))
+		return nil, "", err
+	}
+
+	secret, err := generateOpaqueToken(32)
+	if err != nil {
+		u.logger.Error("Failed to generate API key", zap.Error(err), zap.String("operation", "create_api_key"))
+		return nil, "", err
+	}
+	rawKey := apiKeyPrefix + secret
+
+	key := &model.APIKey{
+		Name:        name,
+		Prefix:      rawKey[:len(apiKeyPrefix)+8],
+		KeyHash:     hashToken(rawKey),
+		Scopes:      strings.Join(scopes, ","),
+		OwnerID:     owner.ID,
+		OrgID:       orgID,
+		CreatedByID: createdByID,
+		ExpiresAt:   expiresAt,
+	}
+	if err := u.apiKeyRepo.Create(key); err != nil {
+		u.logger.Error("Failed to store API key", zap.Error(err), zap.String("operation", "create_api_key"))
+		return nil, "", err
+	}
+
+	u.logger.Info("API key created", zap.Uint("key_id", key.ID), zap.String("name", name), zap.Strings("scopes", scopes), zap.String("operation", "create_api_key"))
+	return key, rawKey, nil
+}
+
+// ListKeys returns every API key without their secrets
+func (u *APIKeyUsecase) ListKeys() ([]*model.APIKey, error) {
+	keys, err := u.apiKeyRepo.List()
+	if err != nil {
+		u.logger.Error("Failed to list API keys", zap.Error(err), zap.String("operation", "list_api_keys"))
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeKey permanently disables an API key
+func (u *APIKeyUsecase) RevokeKey(id uint) error {
+	key, err := u.apiKeyRepo.FindByID(id)
+	if err != nil {
+		u.logger.Error("Failed to load API key", zap.Uint("key_id", id), zap.Error(err), zap.String("operation", "revoke_api_key"))
+		return err
+	}
+	if key == nil {
+		return ErrAPIKeyNotFound
+	}
+
+	if err := u.apiKeyRepo.Revoke(id); err != nil {
+		u.logger.Error("Failed to revoke API key", zap.Uint("key_id", id), zap.Error(err), zap.String("operation", "revoke_api_key"))
+		return err
+	}
+
+	u.logger.Info("API key revoked", zap.Uint("key_id", id), zap.String("operation", "revoke_api_key"))
+	return nil
+}
+
+// Authenticate resolves a raw API key to the actor it represents, within the key's organization
+// It fails for unknown, revoked or expired keys and for keys whose owner no longer exists or
+// lost access to the organization
+func (u *APIKeyUsecase) Authenticate(rawKey string) (*model.Actor, *model.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	key, err := u.apiKeyRepo.FindByHash(hashToken(rawKey))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if key == nil || !key.IsActive(now) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	owner, err := u.userRepo.FindByID(key.OwnerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if owner == nil {
+		u.logger.Warn("API key owner no longer exists", zap.Uint("key_id", key.ID), zap.Uint("owner_id", key.OwnerID))
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if owner.IsDisabled() {
+		u.logger.Warn("API key owner is disabled", zap.Uint("key_id", key.ID), zap.Uint("owner_id", key.OwnerID))
+		return nil, nil, ErrInvalidAPIKey
+	}
+	membership, err := u.orgs.ResolveMembership(owner.ID, owner.Role, key.OrgID)
+	if errors.Is(err, ErrOrgNotFound) {
+		u.logger.Warn("API key owner left the organization", zap.Uint("key_id", key.ID), zap.Uint("org_id", key.OrgID))
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Tracking is best effort and throttled so that every request does not cause a write
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := u.apiKeyRepo.TouchLastUsed(key.ID, now); err == nil {
+			key.LastUsedAt = &now
+		}
+	}
+
+	actor := &model.Actor{
+		ID:      owner.ID,
+		Name:    owner.Name,
+		Email:   owner.Email,
+		Role:    owner.Role,
+		OrgID:   membership.OrgID,
+		OrgRole: membership.Role,
+	}
+	if membership.Organization != nil {
+		actor.OrgName = membership.Organization.Name
+	}
+	return actor, key, nil
+}
@@ -5,76 +5,459 @@ import (
 	"time"
 
 	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/identity"
 	"github.com/Amandasilvbr/products-crud/internal/domain/model"
 	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
 	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
 
-	"github.com/dgrijalva/jwt-go"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Standard errors returned by the session management use cases
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrTokenRevoked        = errors.New("token has been revoked")
+	ErrEmailNotVerified    = errors.New("email address not verified")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+)
+
+// dummyPasswordHash is compared against when the email is unknown, so both failure paths take as long
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
 // AuthUsecase implements the business logic for authentication operations
 type AuthUsecase struct {
-	userRepo repository.UserRepositoryInterface
-	logger   *zap.Logger
+	userRepo  repository.UserRepositoryInterface
+	tokenRepo repository.TokenRepositoryInterface
+	lockout   usecase.LockoutUsecaseInterface
+	mfa       usecase.MFAUsecaseInterface
+	orgs      usecase.OrganizationUsecaseInterface
+	keys      identity.KeySet
+	cfg       *config.Configs
+	logger    *zap.Logger
 }
 
 // NewAuthUsecase creates a new instance of AuthUsecase
-func NewAuthUsecase(userRepo repository.UserRepositoryInterface, logger *zap.Logger) usecase.AuthUsecaseInterface {
+func NewAuthUsecase(userRepo repository.UserRepositoryInterface, tokenRepo repository.TokenRepositoryInterface, lockout usecase.LockoutUsecaseInterface, mfa usecase.MFAUsecaseInterface, orgs usecase.OrganizationUsecaseInterface, keys identity.KeySet, cfg *config.Configs, logger *zap.Logger) usecase.AuthUsecaseInterface {
 	return &AuthUsecase{
-		userRepo: userRepo,
-		logger:   logger,
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		lockout:   lockout,
+		mfa:       mfa,
+		orgs:      orgs,
+		keys:      keys,
+		cfg:       cfg,
+		logger:    logger,
 	}
 }
 
 // Login handles the user authentication process
-func (u *AuthUsecase) Login(email, password string) (string, error) {
+// Unknown emails and wrong passwords fail identically with ErrInvalidCredentials, and repeated
+// failures from the same account or IP are delayed and then locked out with ErrTooManyAttempts
+// Users with 2FA, or whose role requires it, get an MFA challenge to complete with CompleteMFA instead of tokens
+func (u *AuthUsecase) Login(email, password, ip string) (*model.LoginResult, error) {
+	if err := u.lockout.Check(email, ip); err != nil {
+		return nil, err
+	}
+
 	// Find the user in the repository by their email address
 	user, err := u.userRepo.FindByEmail(email)
 	if err != nil {
-		u.logger.Warn("User not found", zap.String("email", email), zap.Error(err), zap.String("operation", "login"))
-		return "", errors.New("user not found")
+		u.logger.Error("Failed to look up user", zap.String("email", email), zap.Error(err), zap.String("operation", "login"))
+		return nil, err
 	}
 
-	// Compare the provided password with the stored hashed password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		u.logger.Warn("Invalid password", zap.String("email", email), zap.String("operation", "login"))
-		return "", errors.New("incorrect password")
+	// Compare the provided password with the stored hashed password, or a dummy one for unknown emails
+	hash := dummyPasswordHash
+	if user != nil {
+		hash = []byte(user.Password)
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || user == nil {
+		u.logger.Warn("Invalid credentials", zap.String("email", email), zap.Bool("user_exists", user != nil), zap.String("operation", "login"))
+		u.lockout.RecordFailure(email, ip, user, model.AuthOutcomeInvalidCredentials)
+		return nil, ErrInvalidCredentials
 	}
 
-	// Create JWT claims, including user details and an expiration time
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":    user.ID,
-		"name":  user.Name,
-		"email": user.Email,
-		"exp":   time.Now().Add(time.Hour * 48).Unix(),
-	})
+	// Disabled accounts cannot log in, even with valid credentials
+	if user.IsDisabled() {
+		u.logger.Warn("Login refused for disabled account", zap.String("email", email), zap.String("operation", "login"))
+		u.lockout.RecordSuccess(email, ip, user, model.AuthOutcomeDisabled)
+		return nil, ErrUserDisabled
+	}
 
-	// Load configuration to retrieve the JWT secret key
-	cfg, err := config.New()
-	if err != nil {
-		u.logger.Error("Failed to load config", zap.Error(err), zap.String("operation", "login"))
-		return "", errors.New("failed to load config")
+	// Unverified accounts cannot log in unless explicitly allowed for development
+	if !user.IsVerified() && !u.cfg.AllowUnverifiedLogin {
+		u.logger.Warn("Login refused for unverified email", zap.String("email", email), zap.String("operation", "login"))
+		u.lockout.RecordSuccess(email, ip, user, model.AuthOutcomeNotVerified)
+		return nil, ErrEmailNotVerified
 	}
-	secret := cfg.JWTSecret
 
-	// Ensure the JWT secret key is configured
-	u.logger.Info("JWT_SECRET value", zap.String("secret", secret))
-	if secret == "" {
-		u.logger.Error("JWT_SECRET not set", zap.String("operation", "login"))
-		return "", errors.New("JWT secret key not configured")
+	// A second factor is checked before any token is issued
+	challenge, err := u.mfa.Challenge(user)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		u.lockout.RecordSuccess(email, ip, user, model.AuthOutcomeMFARequired)
+		u.logger.Info("MFA challenge issued", zap.String("email", user.Email), zap.Bool("enrollment", challenge.MFAEnrollmentRequired), zap.String("operation", "login"))
+		return challenge, nil
 	}
 
-	// Sign the token with the secret key to generate the final token string
-	tokenString, err := token.SignedString([]byte(secret))
+	tokens, err := u.StartSession(user)
 	if err != nil {
-		u.logger.Error("Failed to generate JWT", zap.Error(err), zap.String("operation", "login"))
-		return "", err
+		return nil, err
 	}
+	u.lockout.RecordSuccess(email, ip, user, model.AuthOutcomeSuccess)
 
 	u.logger.Info("User logged in", zap.String("email", user.Email), zap.String("operation", "login"))
-	return tokenString, nil
+	return &model.LoginResult{Tokens: tokens}, nil
+}
+
+// CompleteMFA finishes a login with the challenge token from Login and a TOTP or recovery code
+// For enrollment challenges, the code confirms the authenticator set up with BeginMFAEnrollment and
+// the new recovery codes are returned along with the tokens
+// Wrong codes count as failed logins, so they are throttled and locked out like wrong passwords
+func (u *AuthUsecase) CompleteMFA(mfaToken, code, ip string) (*model.LoginResult, error) {
+	userID, enrollment, err := u.mfa.ParseChallenge(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		u.logger.Error("Failed to look up user", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "login_mfa"))
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidMFAToken
+	}
+	if err := u.lockout.Check(user.Email, ip); err != nil {
+		return nil, err
+	}
+	if user.IsDisabled() {
+		u.lockout.RecordSuccess(user.Email, ip, user, model.AuthOutcomeDisabled)
+		return nil, ErrUserDisabled
+	}
+
+	result := &model.LoginResult{}
+	if enrollment {
+		result.RecoveryCodes, err = u.mfa.ConfirmEnrollment(user.ID, code)
+	} else {
+		err = u.mfa.Verify(user, code)
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			u.lockout.RecordFailure(user.Email, ip, user, model.AuthOutcomeInvalidMFACode)
+		}
+		return nil, err
+	}
+
+	result.Tokens, err = u.StartSession(user)
+	if err != nil {
+		return nil, err
+	}
+	u.lockout.RecordSuccess(user.Email, ip, user, model.AuthOutcomeSuccess)
+
+	u.logger.Info("User logged in with MFA", zap.String("email", user.Email), zap.Bool("enrolled", enrollment), zap.String("operation", "login_mfa"))
+	return result, nil
+}
+
+// BeginMFAEnrollment starts TOTP enrollment for a user holding an enrollment challenge from Login
+func (u *AuthUsecase) BeginMFAEnrollment(mfaToken string) (*model.MFAEnrollment, error) {
+	userID, enrollment, err := u.mfa.ParseChallenge(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	if !enrollment {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	return u.mfa.BeginEnrollment(userID)
+}
+
+// StartSession issues the tokens of a new session for an already authenticated user
+// Each login starts a new refresh token family, in the organization the user last switched to
+func (u *AuthUsecase) StartSession(user *model.User) (*model.TokenPair, error) {
+	familyID, err := generateOpaqueToken(16)
+	if err != nil {
+		u.logger.Error("Failed to generate token family", zap.Error(err), zap.String("operation", "login"))
+		return nil, err
+	}
+	orgID, err := u.defaultOrg(user)
+	if err != nil {
+		return nil, err
+	}
+	return u.issueTokens(user, familyID, nil, orgID)
+}
+
+// SwitchOrganization makes another organization active and issues tokens scoped to it
+// The choice is remembered for the user's next login; organizations without access are not found
+func (u *AuthUsecase) SwitchOrganization(userID, orgID uint) (*model.TokenPair, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		u.logger.Error("Failed to load user", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "switch_org"))
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.IsDisabled() {
+		return nil, ErrUserDisabled
+	}
+
+	if _, err := u.orgs.ResolveMembership(user.ID, user.Role, orgID); err != nil {
+		u.logger.Warn("Switch to inaccessible organization", zap.Uint("user_id", userID), zap.Uint("org_id", orgID), zap.Error(err), zap.String("operation", "switch_org"))
+		return nil, err
+	}
+	user.ActiveOrgID = &orgID
+	if err := u.userRepo.Update(user); err != nil {
+		u.logger.Error("Failed to store active organization", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "switch_org"))
+		return nil, err
+	}
+
+	familyID, err := generateOpaqueToken(16)
+	if err != nil {
+		u.logger.Error("Failed to generate token family", zap.Error(err), zap.String("operation", "switch_org"))
+		return nil, err
+	}
+
+	u.logger.Info("Active organization switched", zap.Uint("user_id", userID), zap.Uint("org_id", orgID), zap.String("operation", "switch_org"))
+	return u.issueTokens(user, familyID, nil, orgID)
+}
+
+// defaultOrg returns the organization a new session starts in, or 0 when the user belongs to none
+func (u *AuthUsecase) defaultOrg(user *model.User) (uint, error) {
+	membership, err := u.orgs.DefaultMembership(user)
+	if err != nil {
+		u.logger.Error("Failed to resolve organization", zap.Uint("user_id", user.ID), zap.Error(err), zap.String("operation", "login"))
+		return 0, err
+	}
+	if membership == nil {
+		return 0, nil
+	}
+	return membership.OrgID, nil
+}
+
+// RefreshToken exchanges a valid refresh token for a new access token and a rotated refresh token
+// Presenting a token that was already rotated revokes the whole family, since it indicates theft
+func (u *AuthUsecase) RefreshToken(refreshToken string) (*model.TokenPair, error) {
+	stored, err := u.tokenRepo.FindRefreshTokenByHash(hashToken(refreshToken))
+	if err != nil {
+		u.logger.Error("Failed to look up refresh token", zap.Error(err), zap.String("operation", "refresh"))
+		return nil, err
+	}
+	if stored == nil {
+		u.logger.Warn("Unknown refresh token", zap.String("operation", "refresh"))
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Reuse of a revoked token means the family is compromised
+	if stored.RevokedAt != nil {
+		u.logger.Warn("Refresh token reuse detected, revoking family",
+			zap.Uint("user_id", stored.UserID),
+			zap.String("family_id", stored.FamilyID),
+			zap.String("operation", "refresh"))
+		if err := u.tokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+			u.logger.Error("Failed to revoke refresh token family", zap.Error(err), zap.String("operation", "refresh"))
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		u.logger.Warn("Expired refresh token", zap.Uint("user_id", stored.UserID), zap.String("operation", "refresh"))
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := u.userRepo.FindByID(stored.UserID)
+	if err != nil {
+		u.logger.Error("Failed to load user for refresh", zap.Uint("user_id", stored.UserID), zap.Error(err), zap.String("operation", "refresh"))
+		return nil, err
+	}
+	if user == nil {
+		u.logger.Warn("Refresh token belongs to a missing user", zap.Uint("user_id", stored.UserID), zap.String("operation", "refresh"))
+		return nil, ErrInvalidRefreshToken
+	}
+	if user.IsDisabled() {
+		u.logger.Warn("Refresh refused for disabled account", zap.Uint("user_id", stored.UserID), zap.String("operation", "refresh"))
+		return nil, ErrUserDisabled
+	}
+
+	// The session stays in its organization unless the user lost access to it meanwhile
+	orgID := stored.OrgID
+	if orgID != 0 {
+		if _, err := u.orgs.ResolveMembership(user.ID, user.Role, orgID); errors.Is(err, ErrOrgNotFound) {
+			if orgID, err = u.defaultOrg(user); err != nil {
+				return nil, err
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	tokens, err := u.issueTokens(user, stored.FamilyID, stored, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.logger.Info("Refresh token rotated", zap.Uint("user_id", user.ID), zap.String("operation", "refresh"))
+	return tokens, nil
+}
+
+// Logout revokes the given refresh token and denylists the current access token
+// A refresh token of another user is refused with ErrInvalidRefreshToken, before anything is revoked
+func (u *AuthUsecase) Logout(userID uint, refreshToken, jti string, accessExpiresAt time.Time) error {
+	if refreshToken != "" {
+		stored, err := u.tokenRepo.FindRefreshTokenByHash(hashToken(refreshToken))
+		if err != nil {
+			u.logger.Error("Failed to look up refresh token", zap.Error(err), zap.String("operation", "logout"))
+			return err
+		}
+		if stored != nil && stored.UserID != userID {
+			u.logger.Warn("Refresh token belongs to another user", zap.Uint("user_id", userID), zap.String("operation", "logout"))
+			return ErrInvalidRefreshToken
+		}
+		if stored != nil {
+			if err := u.tokenRepo.RevokeRefreshToken(stored.ID); err != nil {
+				u.logger.Error("Failed to revoke refresh token", zap.Error(err), zap.String("operation", "logout"))
+				return err
+			}
+		}
+	}
+
+	if jti != "" {
+		if err := u.tokenRepo.RevokeJTI(jti, accessExpiresAt); err != nil {
+			u.logger.Error("Failed to revoke access token", zap.Error(err), zap.String("operation", "logout"))
+			return err
+		}
+	}
+
+	u.logger.Info("User logged out", zap.String("operation", "logout"))
+	return nil
+}
+
+// RevokeAllSessions revokes every refresh token of a user and invalidates all previously issued access tokens
+func (u *AuthUsecase) RevokeAllSessions(userID uint) error {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		u.logger.Error("Failed to load user", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "revoke_sessions"))
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if err := u.tokenRepo.RevokeAllForUser(userID); err != nil {
+		u.logger.Error("Failed to revoke refresh tokens", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "revoke_sessions"))
+		return err
+	}
+
+	now := time.Now()
+	user.SessionsRevokedAt = &now
+	if err := u.userRepo.Update(user); err != nil {
+		u.logger.Error("Failed to record session revocation", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "revoke_sessions"))
+		return err
+	}
+
+	u.logger.Info("All sessions revoked", zap.Uint("user_id", userID), zap.String("operation", "revoke_sessions"))
+	return nil
+}
+
+// ValidateAccessToken checks that an otherwise valid access token has not been revoked
+func (u *AuthUsecase) ValidateAccessToken(jti string, userID uint, issuedAt time.Time) error {
+	revoked, err := u.tokenRepo.IsJTIRevoked(jti)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrTokenRevoked
+	}
+	if user.IsDisabled() {
+		return ErrUserDisabled
+	}
+	// JWT timestamps have second precision, so compare against the truncated revocation time
+	if user.SessionsRevokedAt != nil && issuedAt.Before(user.SessionsRevokedAt.Truncate(time.Second)) {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// issueTokens signs a new access token and stores a new refresh token in the given family
+// When previous is set, it is rotated (revoked and linked to the new token) atomically
+// orgID is carried by both tokens as the active organization; 0 means none
+func (u *AuthUsecase) issueTokens(user *model.User, familyID string, previous *model.RefreshToken, orgID uint) (*model.TokenPair, error) {
+	jti, err := generateOpaqueToken(16)
+	if err != nil {
+		u.logger.Error("Failed to generate token ID", zap.Error(err), zap.String("operation", "issue_tokens"))
+		return nil, err
+	}
+
+	// Create JWT claims, including user details, a unique ID and an expiration time
+	now := time.Now()
+	claims := map[string]interface{}{
+		"id":     user.ID,
+		"name":   user.Name,
+		"email":  user.Email,
+		"role":   user.Role,
+		"org_id": orgID,
+		"jti":    jti,
+		"iat":    now.Unix(),
+		"exp":    now.Add(u.cfg.AccessTokenTTL).Unix(),
+	}
+
+	// Sign the token with the active key of the key set, which adds its kid header
+	accessToken, err := u.keys.Sign(claims)
+	if err != nil {
+		u.logger.Error("Failed to generate JWT", zap.Error(err), zap.String("operation", "issue_tokens"))
+		return nil, err
+	}
+
+	rawRefresh, err := generateOpaqueToken(32)
+	if err != nil {
+		u.logger.Error("Failed to generate refresh token", zap.Error(err), zap.String("operation", "issue_tokens"))
+		return nil, err
+	}
+	refresh := &model.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(rawRefresh),
+		FamilyID:  familyID,
+		OrgID:     orgID,
+		ExpiresAt: now.Add(u.cfg.RefreshTokenTTL),
+	}
+
+	rotated := true
+	if previous != nil {
+		rotated, err = u.tokenRepo.RotateRefreshToken(previous.ID, refresh)
+	} else {
+		err = u.tokenRepo.CreateRefreshToken(refresh)
+	}
+	if err != nil {
+		u.logger.Error("Failed to store refresh token", zap.Uint("user_id", user.ID), zap.Error(err), zap.String("operation", "issue_tokens"))
+		return nil, err
+	}
+	// Another request rotated the same token first: it was presented twice, which is treated as reuse
+	if !rotated {
+		u.logger.Warn("Concurrent refresh token reuse detected, revoking family",
+			zap.Uint("user_id", user.ID),
+			zap.String("family_id", familyID),
+			zap.String("operation", "refresh"))
+		if err := u.tokenRepo.RevokeFamily(familyID); err != nil {
+			u.logger.Error("Failed to revoke refresh token family", zap.Error(err), zap.String("operation", "refresh"))
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	return &model.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: rawRefresh,
+		ExpiresIn:    int64(u.cfg.AccessTokenTTL.Seconds()),
+	}, nil
 }
 
 // CreateUser handles the registration of a new user
@@ -87,10 +470,12 @@ func (u *AuthUsecase) CreateUser(name, email, password string) error {
 	}
 
 	// Create a new user model with the provided data
+	// Self-registered accounts are read-only until an admin grants them a higher role
 	user := &model.User{
 		Name:     name,
 		Email:    email,
 		Password: string(hashedPassword),
+		Role:     model.RoleViewer,
 	}
 
 	// Call the repository to create the user, handling potential errors like duplicates
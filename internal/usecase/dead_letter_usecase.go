@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+)
+
+// Limits for dead-letter listings
+const (
+	DefaultDeadLetterPeekLimit = 20
+	MaxDeadLetterPeekLimit     = 100
+)
+
+// Standard errors returned by the dead-letter use cases
+var (
+	ErrUnknownQueue = errors.New("unknown queue")
+	ErrInvalidLimit = errors.New("limit must not be negative")
+)
+
+// DeadLetterUsecase implements the administration of the dead-letter queues
+// Only the queues consumed by the application can be administered
+type DeadLetterUsecase struct {
+	queues messaging.DeadLetterQueues
+	logger *zap.Logger
+}
+
+// deadLetterQueues lists the work queues whose dead-letter queue can be administered
+var deadLetterQueues = []string{ProductEventsQueue, messaging.AccountEventsQueue}
+
+// NewDeadLetterUsecase creates a new instance of DeadLetterUsecase
+func NewDeadLetterUsecase(queues messaging.DeadLetterQueues, logger *zap.Logger) usecase.DeadLetterUsecaseInterface {
+	return &DeadLetterUsecase{
+		queues: queues,
+		logger: logger,
+	}
+}
+
+// Queues returns the work queues whose dead-letter queue can be administered
+func (u *DeadLetterUsecase) Queues() []string {
+	return append([]string(nil), deadLetterQueues...)
+}
+
+// Peek returns the oldest messages of a dead-letter queue without removing them
+func (u *DeadLetterUsecase) Peek(ctx context.Context, queueName string, limit int) ([]messaging.DeadLetter, error) {
+	if err := u.checkQueue(queueName, "peek_dead_letters"); err != nil {
+		return nil, err
+	}
+	if limit < 0 {
+		return nil, ErrInvalidLimit
+	}
+	if limit == 0 {
+		limit = DefaultDeadLetterPeekLimit
+	}
+	if limit > MaxDeadLetterPeekLimit {
+		limit = MaxDeadLetterPeekLimit
+	}
+
+	letters, err := u.queues.Peek(ctx, queueName, limit)
+	if err != nil {
+		u.logger.Error("Failed to peek dead-letter queue", zap.String("queue", queueName), zap.Error(err), zap.String("operation", "peek_dead_letters"))
+		return nil, err
+	}
+	return letters, nil
+}
+
+// Requeue moves messages of a dead-letter queue back to their work queue with a fresh attempt count
+// It is meant to be used once the cause of the failures, such as a broken SMTP server, is fixed
+func (u *DeadLetterUsecase) Requeue(ctx context.Context, queueName string, limit int) (int, error) {
+	if err := u.checkQueue(queueName, "requeue_dead_letters"); err != nil {
+		return 0, err
+	}
+	if limit < 0 {
+		return 0, ErrInvalidLimit
+	}
+
+	moved, err := u.queues.Requeue(ctx, queueName, limit)
+	if err != nil {
+		u.logger.Error("Failed to requeue dead-lettered messages", zap.String("queue", queueName), zap.Int("moved", moved), zap.Error(err), zap.String("operation", "requeue_dead_letters"))
+		return moved, err
+	}
+	return moved, nil
+}
+
+// Purge discards every message of a dead-letter queue
+func (u *DeadLetterUsecase) Purge(ctx context.Context, queueName string) (int, error) {
+	if err := u.checkQueue(queueName, "purge_dead_letters"); err != nil {
+		return 0, err
+	}
+
+	purged, err := u.queues.Purge(ctx, queueName)
+	if err != nil {
+		u.logger.Error("Failed to purge dead-letter queue", zap.String("queue", queueName), zap.Error(err), zap.String("operation", "purge_dead_letters"))
+		return 0, err
+	}
+	return purged, nil
+}
+
+// checkQueue refuses the queues the application does not consume
+func (u *DeadLetterUsecase) checkQueue(queueName, operation string) error {
+	for _, known := range deadLetterQueues {
+		if queueName == known {
+			return nil
+		}
+	}
+	u.logger.Warn("Unknown dead-letter queue", zap.String("queue", queueName), zap.String("operation", operation))
+	return ErrUnknownQueue
+}
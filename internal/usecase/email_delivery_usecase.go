@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+)
+
+// Delivery log pages hold DefaultEmailDeliveryPageSize deliveries unless asked otherwise, and never more
+// than MaxEmailDeliveryPageSize
+const (
+	DefaultEmailDeliveryPageSize = 20
+	MaxEmailDeliveryPageSize     = 100
+)
+
+// emailRetentionInterval is how often old deliveries are redacted and removed
+const emailRetentionInterval = time.Hour
+
+var (
+	// ErrEmailDeliveryNotFound is returned when there is no delivery with the given ID
+	ErrEmailDeliveryNotFound = errors.New("email delivery not found")
+	// ErrEmailDeliveryRedacted is returned when resending a delivery whose recipients and content were cleared
+	ErrEmailDeliveryRedacted = errors.New("email delivery was redacted and cannot be resent")
+	// ErrInvalidEmailDeliveryStatus is returned when searching by a status other than sent or failed
+	ErrInvalidEmailDeliveryStatus = errors.New("invalid delivery status: must be sent or failed")
+)
+
+// EmailDeliveryUsecase sends notification emails through a mailer and records every attempt
+type EmailDeliveryUsecase struct {
+	repo   repository.EmailDeliveryRepositoryInterface
+	mailer messaging.Mailer
+	cfg    *config.Configs
+	logger *zap.Logger
+}
+
+// NewEmailDeliveryUsecase creates a new instance of EmailDeliveryUsecase sending the emails with the given mailer
+func NewEmailDeliveryUsecase(repo repository.EmailDeliveryRepositoryInterface, mailer messaging.Mailer, cfg *config.Configs, logger *zap.Logger) usecase.EmailDeliveryUsecaseInterface {
+	return &EmailDeliveryUsecase{
+		repo:   repo,
+		mailer: mailer,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Send delivers the email and records the attempt when the email has a kind
+// Attempts to send the same email again, after a redelivery of its events, are counted in the same delivery
+func (u *EmailDeliveryUsecase) Send(ctx context.Context, email *messaging.Email) error {
+	if email.Kind == "" {
+		return u.mailer.Send(ctx, email)
+	}
+	key, err := deliveryKey(email)
+	if err != nil {
+		return err
+	}
+	_, err = u.deliver(ctx, email, key, nil)
+	return err
+}
+
+// deliver sends an email and records the attempt under the given key, returning the delivery and the sending error
+// The email is sent even when the log is unavailable; a failure to record it only loses the entry
+func (u *EmailDeliveryUsecase) deliver(ctx context.Context, email *messaging.Email, key string, resendOf *uint) (*model.EmailDelivery, error) {
+	sendErr := u.mailer.Send(ctx, email)
+
+	// The mailer does not expose the server's reply to an accepted email, so only failures record a response
+	now := time.Now()
+	attempt := &model.EmailDelivery{
+		DeliveryKey:    key,
+		Kind:           email.Kind,
+		Recipients:     strings.Join(email.To, ","),
+		Subject:        email.Subject,
+		EventIDs:       strings.Join(email.EventIDs, ","),
+		Text:           email.Text,
+		HTML:           email.HTML,
+		Status:         model.EmailDeliverySent,
+		Attempts:       1,
+		FirstAttemptAt: now,
+		LastAttemptAt:  now,
+		SentAt:         &now,
+		ResendOf:       resendOf,
+	}
+	if sendErr != nil {
+		attempt.Status = model.EmailDeliveryFailed
+		attempt.ResponseCode = smtpReplyCode(sendErr)
+		attempt.Response = sendErr.Error()
+		attempt.SentAt = nil
+	}
+
+	delivery, err := u.repo.RecordAttempt(context.WithoutCancel(ctx), attempt)
+	if err != nil {
+		return attempt, sendErr
+	}
+	return delivery, sendErr
+}
+
+// Search returns one page of the deliveries matching the filter, newest first
+func (u *EmailDeliveryUsecase) Search(filter model.EmailDeliveryFilter, page, pageSize int) ([]*model.EmailDelivery, int64, error) {
+	if filter.Status != "" && filter.Status != model.EmailDeliverySent && filter.Status != model.EmailDeliveryFailed {
+		return nil, 0, ErrInvalidEmailDeliveryStatus
+	}
+	return u.repo.Search(filter, (page-1)*pageSize, pageSize)
+}
+
+// Get returns a delivery with its content, unless it was redacted
+func (u *EmailDeliveryUsecase) Get(id uint) (*model.EmailDelivery, error) {
+	delivery, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil {
+		return nil, ErrEmailDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// Resend sends a delivery's content again as a new delivery, linked to the original one
+// The returned error is the sending error, with the delivery recording the SMTP reply
+func (u *EmailDeliveryUsecase) Resend(ctx context.Context, id uint) (*model.EmailDelivery, error) {
+	original, err := u.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if original.RedactedAt != nil {
+		return nil, ErrEmailDeliveryRedacted
+	}
+
+	key, err := generateOpaqueToken(16)
+	if err != nil {
+		return nil, err
+	}
+	email := &messaging.Email{
+		To:      strings.Split(original.Recipients, ","),
+		Subject: original.Subject,
+		Text:    original.Text,
+		HTML:    original.HTML,
+		Kind:    original.Kind,
+	}
+	if original.EventIDs != "" {
+		email.EventIDs = strings.Split(original.EventIDs, ",")
+	}
+
+	mailCtx, cancel := context.WithTimeout(ctx, mailTimeout)
+	defer cancel()
+	delivery, err := u.deliver(mailCtx, email, key, &original.ID)
+	if err != nil {
+		u.logger.Error("Failed to resend email", zap.Uint("delivery_id", id), zap.Error(err), zap.String("operation", "email_resend"))
+		return delivery, err
+	}
+	u.logger.Info("Email resent", zap.Uint("delivery_id", id), zap.Uint("resent_id", delivery.ID), zap.String("operation", "email_resend"))
+	return delivery, nil
+}
+
+// RunRetention applies the retention every emailRetentionInterval until the context is cancelled
+func (u *EmailDeliveryUsecase) RunRetention(ctx context.Context) error {
+	ticker := time.NewTicker(emailRetentionInterval)
+	defer ticker.Stop()
+	for {
+		u.applyRetention(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// applyRetention clears the personal data of deliveries older than EmailLogRedactAfter and removes
+// those older than EmailLogRetention
+func (u *EmailDeliveryUsecase) applyRetention(ctx context.Context) {
+	now := time.Now()
+	redacted, err := u.repo.RedactBefore(ctx, now.Add(-u.cfg.EmailLogRedactAfter))
+	if err == nil && redacted > 0 {
+		u.logger.Info("Redacted email deliveries", zap.Int64("count", redacted), zap.String("operation", "email_delivery_redact"))
+	}
+	deleted, err := u.repo.DeleteBefore(ctx, now.Add(-u.cfg.EmailLogRetention))
+	if err == nil && deleted > 0 {
+		u.logger.Info("Removed email deliveries", zap.Int64("count", deleted), zap.String("operation", "email_delivery_cleanup"))
+	}
+}
+
+// deliveryKey identifies an email by its kind, recipients and events, so that sending it again is recognized
+// Emails without events cannot be told apart and get a random key
+func deliveryKey(email *messaging.Email) (string, error) {
+	if len(email.EventIDs) == 0 {
+		return generateOpaqueToken(16)
+	}
+	recipients := make([]string, len(email.To))
+	for i, to := range email.To {
+		recipients[i] = strings.ToLower(strings.TrimSpace(to))
+	}
+	slices.Sort(recipients)
+	events := slices.Sorted(slices.Values(email.EventIDs))
+	return hashToken(email.Kind + "\n" + strings.Join(recipients, ",") + "\n" + strings.Join(events, ",")), nil
+}
+
+// smtpReplyCode returns the code of the SMTP server's refusal, or 0 when the server could not be reached
+func smtpReplyCode(err error) int {
+	var reply *textproto.Error
+	if errors.As(err, &reply) {
+		return reply.Code
+	}
+	return 0
+}
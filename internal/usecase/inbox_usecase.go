@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+)
+
+// Inbox pages hold DefaultInboxPageSize notifications unless asked otherwise, and never more than MaxInboxPageSize
+const (
+	DefaultInboxPageSize = 20
+	MaxInboxPageSize     = 100
+)
+
+// ErrInboxNotificationNotFound is returned when the user has no notification with the given ID
+var ErrInboxNotificationNotFound = errors.New("notification not found")
+
+// InboxUsecase implements the in-app notification inbox
+type InboxUsecase struct {
+	inboxRepo repository.InboxRepositoryInterface
+	userRepo  repository.UserRepositoryInterface
+	logger    *zap.Logger
+}
+
+// NewInboxUsecase creates a new instance of InboxUsecase
+func NewInboxUsecase(inboxRepo repository.InboxRepositoryInterface, userRepo repository.UserRepositoryInterface, logger *zap.Logger) usecase.InboxUsecaseInterface {
+	return &InboxUsecase{
+		inboxRepo: inboxRepo,
+		userRepo:  userRepo,
+		logger:    logger,
+	}
+}
+
+// Channel returns the name of the inbox in the routing rules
+func (u *InboxUsecase) Channel() string {
+	return messaging.ChannelInbox
+}
+
+// Notify stores the notifications, unread, in the inbox of the recipient
+// People without an account have no inbox and are skipped
+func (u *InboxUsecase) Notify(ctx context.Context, recipient string, notifications []model.ProductNotification) error {
+	user, err := u.userRepo.FindByEmail(recipient)
+	if err != nil {
+		u.logger.Error("Failed to look up inbox recipient", zap.Error(err), zap.String("operation", "inbox_notify"))
+		return err
+	}
+	if user == nil {
+		u.logger.Debug("Recipient has no account, skipping inbox", zap.String("operation", "inbox_notify"))
+		return nil
+	}
+
+	items := make([]*model.InboxNotification, 0, len(notifications))
+	for _, notification := range notifications {
+		items = append(items, &model.InboxNotification{
+			UserID:    user.ID,
+			Event:     notification.Event,
+			SKU:       notification.SKU,
+			Name:      notification.Name,
+			OrgID:     notification.OrgID,
+			OrgName:   notification.OrgName,
+			CreatedAt: notification.At,
+		})
+	}
+	return u.inboxRepo.Add(ctx, items)
+}
+
+// List returns one page of the user's notifications, newest first
+// Pages start at 1; the page size defaults to DefaultInboxPageSize and is capped at MaxInboxPageSize
+func (u *InboxUsecase) List(userID uint, unreadOnly bool, page, pageSize int) ([]*model.InboxNotification, int64, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultInboxPageSize
+	}
+	if pageSize > MaxInboxPageSize {
+		pageSize = MaxInboxPageSize
+	}
+
+	notifications, total, err := u.inboxRepo.List(userID, unreadOnly, (page-1)*pageSize, pageSize)
+	if err != nil {
+		u.logger.Error("Failed to list inbox", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "inbox_list"))
+		return nil, 0, 0, err
+	}
+	unread, err := u.inboxRepo.CountUnread(userID)
+	if err != nil {
+		u.logger.Error("Failed to count unread notifications", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "inbox_list"))
+		return nil, 0, 0, err
+	}
+	return notifications, total, unread, nil
+}
+
+// MarkRead marks one notification of the user as read, or back to unread
+func (u *InboxUsecase) MarkRead(userID, id uint, read bool) (*model.InboxNotification, error) {
+	var readAt *time.Time
+	if read {
+		now := time.Now()
+		readAt = &now
+	}
+	notification, err := u.inboxRepo.SetReadAt(userID, id, readAt)
+	if err != nil {
+		u.logger.Error("Failed to mark notification", zap.Uint("id", id), zap.Bool("read", read), zap.Error(err), zap.String("operation", "inbox_mark"))
+		return nil, err
+	}
+	if notification == nil {
+		return nil, ErrInboxNotificationNotFound
+	}
+	return notification, nil
+}
+
+// MarkAllRead marks every unread notification of the user as read
+func (u *InboxUsecase) MarkAllRead(userID uint) (int64, error) {
+	updated, err := u.inboxRepo.MarkAllRead(userID, time.Now())
+	if err != nil {
+		u.logger.Error("Failed to mark inbox as read", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "inbox_mark_all"))
+		return 0, err
+	}
+	return updated, nil
+}
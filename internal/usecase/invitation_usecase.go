@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Standard errors returned by the invitation use cases
+var (
+	ErrInvalidInvitation       = errors.New("invalid or expired invitation")
+	ErrInvitationNotFound      = errors.New("invitation not found")
+	ErrInvitationNotPending    = errors.New("invitation was already accepted or revoked")
+	ErrInvalidInvitationExpiry = errors.New("invitation expiry must be in the future")
+	ErrInviteeAlreadyUser      = errors.New("a user with this email already exists; add them as a member instead")
+)
+
+// InvitationUsecase implements invitation-based onboarding
+// Organization admins invite people by email; accepting creates the account and the membership at once
+type InvitationUsecase struct {
+	invitationRepo repository.InvitationRepositoryInterface
+	userRepo       repository.UserRepositoryInterface
+	orgRepo        repository.OrganizationRepositoryInterface
+	orgs           usecase.OrganizationUsecaseInterface
+	mailer         messaging.Mailer
+	cfg            *config.Configs
+	logger         *zap.Logger
+}
+
+// NewInvitationUsecase creates a new instance of InvitationUsecase
+func NewInvitationUsecase(invitationRepo repository.InvitationRepositoryInterface, userRepo repository.UserRepositoryInterface, orgRepo repository.OrganizationRepositoryInterface, orgs usecase.OrganizationUsecaseInterface, mailer messaging.Mailer, cfg *config.Configs, logger *zap.Logger) usecase.InvitationUsecaseInterface {
+	return &InvitationUsecase{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		orgRepo:        orgRepo,
+		orgs:           orgs,
+		mailer:         mailer,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+// CreateInvitation invites an email address to join an organization with the given role and emails the token
+// Without an expiry the invitation is valid for the configured INVITATION_TTL; a new invitation to the
+// same address replaces the pending one
+func (u *InvitationUsecase) CreateInvitation(actor *model.Actor, orgID uint, email, role string, expiresAt *time.Time) (*model.Invitation, error) {
+	if !model.IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	now := time.Now()
+	if expiresAt == nil {
+		defaultExpiry := now.Add(u.cfg.InvitationTTL)
+		expiresAt = &defaultExpiry
+	} else if !expiresAt.After(now) {
+		return nil, ErrInvalidInvitationExpiry
+	}
+	membership, err := u.orgs.AuthorizeAdmin(actor, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	existing, err := u.userRepo.FindByEmail(email)
+	if err != nil {
+		u.logger.Error("Failed to look up invitee", zap.Error(err), zap.String("operation", "create_invitation"))
+		return nil, err
+	}
+	if existing != nil {
+		u.logger.Warn("Invitation for an existing user", zap.Uint("org_id", orgID), zap.String("operation", "create_invitation"))
+		return nil, ErrInviteeAlreadyUser
+	}
+
+	// Only the most recent invitation to an address stays usable
+	if err := u.invitationRepo.RevokePending(orgID, email); err != nil {
+		return nil, err
+	}
+
+	rawToken, err := generateOpaqueToken(32)
+	if err != nil {
+		u.logger.Error("Failed to generate invitation token", zap.Error(err), zap.String("operation", "create_invitation"))
+		return nil, err
+	}
+	invitation := &model.Invitation{
+		OrgID:        orgID,
+		Email:        email,
+		Role:         role,
+		TokenHash:    hashToken(rawToken),
+		InvitedByID:  actor.ID,
+		ExpiresAt:    *expiresAt,
+		SentAt:       now,
+		Organization: membership.Organization,
+	}
+	if err := u.invitationRepo.Create(invitation); err != nil {
+		return nil, err
+	}
+
+	go u.sendInvitationEmail(invitation, actor, rawToken)
+
+	u.logger.Info("Invitation created", zap.Uint("invitation_id", invitation.ID), zap.Uint("org_id", orgID), zap.String("role", role), zap.String("by", actor.Email), zap.String("operation", "create_invitation"))
+	return invitation, nil
+}
+
+// ListInvitations returns the invitations of an organization; only its admins may list them
+func (u *InvitationUsecase) ListInvitations(actor *model.Actor, orgID uint) ([]*model.Invitation, error) {
+	if _, err := u.orgs.AuthorizeAdmin(actor, orgID); err != nil {
+		return nil, err
+	}
+	return u.invitationRepo.ListByOrg(orgID)
+}
+
+// ResendInvitation emails a pending or expired invitation again with a new token
+// The previous token stops working and the invitation gets its original validity again
+func (u *InvitationUsecase) ResendInvitation(actor *model.Actor, orgID, invitationID uint) (*model.Invitation, error) {
+	invitation, err := u.findInOrg(actor, orgID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+		return nil, ErrInvitationNotPending
+	}
+
+	rawToken, err := generateOpaqueToken(32)
+	if err != nil {
+		u.logger.Error("Failed to generate invitation token", zap.Error(err), zap.String("operation", "resend_invitation"))
+		return nil, err
+	}
+	now := time.Now()
+	validity := invitation.ExpiresAt.Sub(invitation.SentAt)
+	if validity <= 0 {
+		validity = u.cfg.InvitationTTL
+	}
+	invitation.TokenHash = hashToken(rawToken)
+	invitation.ExpiresAt = now.Add(validity)
+	invitation.SentAt = now
+	if err := u.invitationRepo.Update(invitation); err != nil {
+		return nil, err
+	}
+
+	go u.sendInvitationEmail(invitation, actor, rawToken)
+
+	u.logger.Info("Invitation resent", zap.Uint("invitation_id", invitation.ID), zap.Uint("org_id", orgID), zap.String("by", actor.Email), zap.String("operation", "resend_invitation"))
+	return invitation, nil
+}
+
+// RevokeInvitation cancels a pending invitation so its token can no longer be accepted
+func (u *InvitationUsecase) RevokeInvitation(actor *model.Actor, orgID, invitationID uint) error {
+	if _, err := u.findInOrg(actor, orgID, invitationID); err != nil {
+		return err
+	}
+
+	revoked, err := u.invitationRepo.Revoke(invitationID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrInvitationNotPending
+	}
+
+	u.logger.Info("Invitation revoked", zap.Uint("invitation_id", invitationID), zap.Uint("org_id", orgID), zap.String("by", actor.Email), zap.String("operation", "revoke_invitation"))
+	return nil
+}
+
+// AcceptInvitation creates the invited user with the chosen name and password and adds them to the organization
+// The email address is considered verified, since the token was delivered to it
+func (u *InvitationUsecase) AcceptInvitation(rawToken, name, password string) (*model.User, error) {
+	invitation, err := u.invitationRepo.FindByHash(hashToken(rawToken))
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil || invitation.Status(time.Now()) != model.InvitationPending {
+		u.logger.Warn("Invalid invitation token", zap.String("operation", "accept_invitation"))
+		return nil, ErrInvalidInvitation
+	}
+
+	existing, err := u.userRepo.FindByEmail(invitation.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrEmailTaken
+	}
+	taken, err := u.userRepo.NameTaken(name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrNameTaken
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		u.logger.Error("Failed to hash password", zap.Error(err), zap.String("operation", "accept_invitation"))
+		return nil, errors.New("failed to hash password")
+	}
+
+	// Consuming the invitation first guarantees it creates a single account, even under concurrent requests
+	accepted, err := u.invitationRepo.MarkAccepted(invitation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		u.logger.Warn("Invitation already used", zap.Uint("invitation_id", invitation.ID), zap.String("operation", "accept_invitation"))
+		return nil, ErrInvalidInvitation
+	}
+
+	// As with open registration, the platform role is editor; product access follows the organization role
+	verifiedAt := time.Now()
+	user := &model.User{
+		Name:       name,
+		Email:      invitation.Email,
+		Password:   string(hashedPassword),
+		Role:       model.RoleEditor,
+		VerifiedAt: &verifiedAt,
+	}
+	if err := u.userRepo.Create(user); err != nil {
+		u.logger.Error("Failed to create invited user", zap.Uint("invitation_id", invitation.ID), zap.Error(err), zap.String("operation", "accept_invitation"))
+		return nil, err
+	}
+
+	membership := &model.Membership{UserID: user.ID, OrgID: invitation.OrgID, Role: invitation.Role}
+	if err := u.orgRepo.SaveMembership(membership); err != nil {
+		u.logger.Error("Failed to add invited user to organization", zap.Uint("user_id", user.ID), zap.Uint("org_id", invitation.OrgID), zap.Error(err), zap.String("operation", "accept_invitation"))
+		return nil, err
+	}
+
+	u.logger.Info("Invitation accepted", zap.Uint("invitation_id", invitation.ID), zap.Uint("user_id", user.ID), zap.Uint("org_id", invitation.OrgID), zap.String("operation", "accept_invitation"))
+	return user, nil
+}
+
+// findInOrg loads an invitation after checking that the actor administers its organization
+// Invitations of other organizations are reported as not found
+func (u *InvitationUsecase) findInOrg(actor *model.Actor, orgID, invitationID uint) (*model.Invitation, error) {
+	if _, err := u.orgs.AuthorizeAdmin(actor, orgID); err != nil {
+		return nil, err
+	}
+	invitation, err := u.invitationRepo.FindByID(invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil || invitation.OrgID != orgID {
+		return nil, ErrInvitationNotFound
+	}
+	return invitation, nil
+}
+
+// sendInvitationEmail delivers the invitation token to the invitee; failures are only logged
+func (u *InvitationUsecase) sendInvitationEmail(invitation *model.Invitation, inviter *model.Actor, rawToken string) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+
+	orgName := ""
+	if invitation.Organization != nil {
+		orgName = invitation.Organization.Name
+	}
+	expiresAt := invitation.ExpiresAt.Format("02/01/2006 15:04")
+	textBody := fmt.Sprintf("Olá,\n\n%s convidou você para participar da organização %s com o papel %s.\n\n", inviter.Name, orgName, invitation.Role)
+	htmlBody := fmt.Sprintf("<p>Olá,</p><p>%s convidou você para participar da organização <strong>%s</strong> com o papel <strong>%s</strong>.</p>", html.EscapeString(inviter.Name), html.EscapeString(orgName), invitation.Role)
+	if u.cfg.InvitationURL != "" {
+		link := u.cfg.InvitationURL + "?token=" + url.QueryEscape(rawToken)
+		textBody += fmt.Sprintf("Acesse o link abaixo para criar sua conta:\n%s\n\n", link)
+		htmlBody += fmt.Sprintf(`<p><a href="%s">Clique aqui para criar sua conta</a></p>`, html.EscapeString(link))
+	}
+	textBody += fmt.Sprintf("Código do convite: %s\n\nO convite é válido até %s e pode ser usado apenas uma vez.\nSe você não esperava este convite, ignore este email.\n\nAtenciosamente,\nEquipe de Produtos", rawToken, expiresAt)
+	htmlBody += fmt.Sprintf("<p>Código do convite: <code>%s</code></p><p>O convite é válido até %s e pode ser usado apenas uma vez.<br>Se você não esperava este convite, ignore este email.</p><p>Atenciosamente,<br>Equipe de Produtos</p>", rawToken, expiresAt)
+
+	err := u.mailer.Send(ctx, &messaging.Email{
+		To:      []string{invitation.Email},
+		Subject: fmt.Sprintf("Convite para %s", orgName),
+		Text:    textBody,
+		HTML:    htmlBody,
+	})
+	if err != nil {
+		u.logger.Error("Failed to send invitation email", zap.Uint("invitation_id", invitation.ID), zap.Error(err), zap.String("operation", "send_invitation"))
+	}
+}
@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+)
+
+// Progressive delays start after this many consecutive failures and double up to maxLoginDelay
+const (
+	freeLoginFailures = 2
+	baseLoginDelay    = time.Second
+	maxLoginDelay     = 30 * time.Second
+)
+
+// ErrTooManyAttempts is returned while an account or IP is delayed or locked out
+var ErrTooManyAttempts = errors.New("too many failed login attempts")
+
+// AttemptsError reports how long the client must wait before trying to log in again
+// It matches ErrTooManyAttempts with errors.Is
+type AttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AttemptsError) Error() string {
+	return ErrTooManyAttempts.Error()
+}
+
+// Is lets errors.Is(err, ErrTooManyAttempts) match an AttemptsError
+func (e *AttemptsError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+// LockoutUsecase implements per-account and per-IP failed login tracking, delays and lockouts
+type LockoutUsecase struct {
+	repo      repository.LoginAttemptRepositoryInterface
+	userRepo  repository.UserRepositoryInterface
+	publisher messaging.Publisher
+	cfg       *config.Configs
+	logger    *zap.Logger
+}
+
+// NewLockoutUsecase creates a new instance of LockoutUsecase
+func NewLockoutUsecase(repo repository.LoginAttemptRepositoryInterface, userRepo repository.UserRepositoryInterface, publisher messaging.Publisher, cfg *config.Configs, logger *zap.Logger) usecase.LockoutUsecaseInterface {
+	return &LockoutUsecase{
+		repo:      repo,
+		userRepo:  userRepo,
+		publisher: publisher,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+// Check rejects a login attempt while the account or IP is locked or still within its progressive delay
+// Accounts are keyed by email, so unknown addresses are throttled exactly like existing ones
+func (u *LockoutUsecase) Check(email, ip string) error {
+	now := time.Now()
+
+	var retryAfter time.Duration
+	outcome := model.AuthOutcomeThrottled
+	for _, key := range []string{accountKey(email), ipKey(ip)} {
+		throttle, err := u.repo.FindThrottle(key)
+		if err != nil {
+			return err
+		}
+		if throttle == nil {
+			continue
+		}
+		if throttle.IsLocked(now) {
+			outcome = model.AuthOutcomeLocked
+			retryAfter = maxDuration(retryAfter, throttle.LockedUntil.Sub(now))
+			continue
+		}
+		if strings.HasPrefix(key, "account:") {
+			failures := u.activeFailures(throttle, now)
+			if wait := throttle.LastFailureAt.Add(loginDelay(failures)).Sub(now); wait > 0 {
+				retryAfter = maxDuration(retryAfter, wait)
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		u.audit(nil, email, ip, false, outcome)
+		u.logger.Warn("Login attempt rejected by throttling",
+			zap.String("email", email),
+			zap.String("ip", ip),
+			zap.String("outcome", outcome),
+			zap.Duration("retry_after", retryAfter),
+			zap.String("operation", "login"))
+		return &AttemptsError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login against the account and the IP, locking them when their limits are reached
+// When an existing account becomes locked, an account_locked event is published so its owner is notified
+func (u *LockoutUsecase) RecordFailure(email, ip string, user *model.User, outcome string) {
+	now := time.Now()
+	u.audit(user, email, ip, false, outcome)
+
+	accountLocked, err := u.registerFailure(accountKey(email), u.cfg.LoginMaxFailures, now)
+	if err != nil {
+		u.logger.Error("Failed to record account login failure", zap.String("email", email), zap.Error(err), zap.String("operation", "login"))
+	}
+	ipLocked, err := u.registerFailure(ipKey(ip), u.cfg.LoginIPMaxFailures, now)
+	if err != nil {
+		u.logger.Error("Failed to record IP login failure", zap.String("ip", ip), zap.Error(err), zap.String("operation", "login"))
+	}
+
+	if ipLocked {
+		u.logger.Warn("IP locked out after repeated login failures", zap.String("ip", ip), zap.String("operation", "login"))
+	}
+	if accountLocked {
+		u.logger.Warn("Account locked out after repeated login failures", zap.String("email", email), zap.String("ip", ip), zap.String("operation", "login"))
+		if user != nil {
+			u.audit(user, email, ip, false, model.AuthOutcomeLocked)
+			u.publishLockout(user, ip, now.Add(u.cfg.LoginLockoutDuration))
+		}
+	}
+}
+
+// RecordSuccess audits a login with valid credentials and clears the account's failure history
+// The outcome distinguishes logins that were still refused, such as unverified emails
+// A password waiting for its second factor keeps the history, so MFA codes cannot be guessed by logging in again
+func (u *LockoutUsecase) RecordSuccess(email, ip string, user *model.User, outcome string) {
+	u.audit(user, email, ip, true, outcome)
+	if outcome == model.AuthOutcomeMFARequired {
+		return
+	}
+	if err := u.repo.DeleteThrottle(accountKey(email)); err != nil {
+		u.logger.Error("Failed to reset login failures", zap.String("email", email), zap.Error(err), zap.String("operation", "login"))
+	}
+}
+
+// Unlock lifts the lockout of an account before it expires
+func (u *LockoutUsecase) Unlock(userID uint, adminID uint) error {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		u.logger.Error("Failed to look up user", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "unlock_user"))
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if err := u.repo.DeleteThrottle(accountKey(user.Email)); err != nil {
+		return err
+	}
+	u.audit(user, user.Email, "", true, model.AuthOutcomeUnlocked)
+
+	u.logger.Info("Account unlocked", zap.Uint("user_id", userID), zap.Uint("admin_id", adminID), zap.String("operation", "unlock_user"))
+	return nil
+}
+
+// registerFailure increments the failure counter for a key and reports whether this failure locked it
+// The counter is incremented by the repository, so that concurrent failures cannot overwrite each other,
+// and only one of the failures reaching the limit locks the key
+func (u *LockoutUsecase) registerFailure(key string, maxFailures int, now time.Time) (bool, error) {
+	failures, err := u.repo.RegisterFailure(key, now, u.cfg.LoginFailureWindow)
+	if err != nil || failures < maxFailures {
+		return false, err
+	}
+	return u.repo.LockThrottle(key, now.Add(u.cfg.LoginLockoutDuration), now)
+}
+
+// activeFailures returns the failures that still count: they expire with the failure window or an elapsed lockout
+func (u *LockoutUsecase) activeFailures(throttle *model.LoginThrottle, now time.Time) int {
+	if now.Sub(throttle.LastFailureAt) > u.cfg.LoginFailureWindow {
+		return 0
+	}
+	if throttle.LockedUntil != nil && !throttle.IsLocked(now) {
+		return 0
+	}
+	return throttle.Failures
+}
+
+// audit appends an entry to the authentication audit log; failures are only logged
+func (u *LockoutUsecase) audit(user *model.User, email, ip string, success bool, outcome string) {
+	entry := &model.AuthAuditEntry{
+		Email:   normalizeEmail(email),
+		IP:      ip,
+		Success: success,
+		Outcome: outcome,
+	}
+	if user != nil {
+		entry.UserID = &user.ID
+	}
+	if err := u.repo.RecordAudit(entry); err != nil {
+		u.logger.Error("Failed to record auth audit entry", zap.String("outcome", outcome), zap.Error(err), zap.String("operation", "auth_audit"))
+	}
+}
+
+// publishLockout emits an account_locked event so the consumer can warn the account owner
+func (u *LockoutUsecase) publishLockout(user *model.User, ip string, lockedUntil time.Time) {
+	msg, err := json.Marshal(map[string]interface{}{
+		"event":        "account_locked",
+		"user_id":      user.ID,
+		"name":         user.Name,
+		"email":        user.Email,
+		"ip":           ip,
+		"locked_until": lockedUntil,
+	})
+	if err != nil {
+		u.logger.Error("Failed to marshal lockout event", zap.Uint("user_id", user.ID), zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := u.publisher.Publish(ctx, messaging.AccountEventsQueue, string(msg)); err != nil {
+		u.logger.Error("Failed to publish lockout event", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+}
+
+// loginDelay returns the wait imposed after the given number of consecutive failures
+func loginDelay(failures int) time.Duration {
+	if failures < freeLoginFailures {
+		return 0
+	}
+	delay := baseLoginDelay << (failures - freeLoginFailures)
+	if delay > maxLoginDelay || delay <= 0 {
+		return maxLoginDelay
+	}
+	return delay
+}
+
+func accountKey(email string) string {
+	return "account:" + normalizeEmail(email)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// normalizeEmail makes throttling insensitive to case and surrounding spaces
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+)
+
+// processedCleanupInterval is how often messages processed longer than ProcessedMessageRetention ago are forgotten
+const processedCleanupInterval = time.Hour
+
+// MessageDeduplicator turns the at-least-once delivery of the broker into effectively-once processing:
+// consumers skip the messages recorded as processed and record the others before acknowledging them
+type MessageDeduplicator struct {
+	repo   repository.ProcessedMessageRepositoryInterface
+	cfg    *config.Configs
+	logger *zap.Logger
+}
+
+// NewMessageDeduplicator creates a new instance of MessageDeduplicator
+func NewMessageDeduplicator(repo repository.ProcessedMessageRepositoryInterface, cfg *config.Configs, logger *zap.Logger) usecase.MessageDeduplicatorInterface {
+	return &MessageDeduplicator{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Processed returns the IDs among ids that the consumer already processed
+// Empty IDs, of messages published without one, are never reported as processed
+func (d *MessageDeduplicator) Processed(ctx context.Context, consumer string, ids []string) (map[string]bool, error) {
+	return d.repo.Processed(ctx, consumer, messageIDs(ids))
+}
+
+// Record marks messages as processed by the consumer; empty IDs are ignored
+func (d *MessageDeduplicator) Record(ctx context.Context, consumer string, ids []string) error {
+	now := time.Now()
+	var messages []*model.ProcessedMessage
+	for _, id := range messageIDs(ids) {
+		messages = append(messages, &model.ProcessedMessage{Consumer: consumer, MessageID: id, ProcessedAt: now})
+	}
+	return d.repo.MarkProcessed(ctx, messages)
+}
+
+// RunCleanup forgets old messages every processedCleanupInterval until the context is cancelled
+func (d *MessageDeduplicator) RunCleanup(ctx context.Context) error {
+	ticker := time.NewTicker(processedCleanupInterval)
+	defer ticker.Stop()
+	for {
+		deleted, err := d.repo.DeleteProcessedBefore(ctx, time.Now().Add(-d.cfg.ProcessedMessageRetention))
+		if err == nil && deleted > 0 {
+			d.logger.Info("Removed processed message records", zap.Int64("count", deleted), zap.String("operation", "processed_messages_cleanup"))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// messageIDs returns the distinct non-empty IDs, in order
+func messageIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	var distinct []string
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			distinct = append(distinct, id)
+		}
+	}
+	return distinct
+}
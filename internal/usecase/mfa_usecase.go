@@ -0,0 +1,376 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"github.com/dgrijalva/jwt-go"
+	"go.uber.org/zap"
+)
+
+// Purposes of the MFA challenge tokens, so they cannot be used as access tokens and vice versa
+const (
+	mfaChallengePurpose  = "mfa_challenge"
+	mfaEnrollmentPurpose = "mfa_enrollment"
+)
+
+// recoveryCodeCount is the number of one-time recovery codes issued per user
+const recoveryCodeCount = 10
+
+// Standard errors returned by the two-factor authentication use cases
+var (
+	ErrInvalidMFAToken         = errors.New("invalid or expired MFA token")
+	ErrInvalidMFACode          = errors.New("invalid MFA code")
+	ErrMFAAlreadyEnabled       = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled           = errors.New("two-factor authentication is not enabled")
+	ErrMFAEnrollmentNotStarted = errors.New("two-factor enrollment has not been started")
+	ErrMFARequired             = errors.New("two-factor authentication is required for this role")
+)
+
+// MFAUsecase implements TOTP enrollment, verification, recovery codes and per-role policies
+type MFAUsecase struct {
+	userRepo repository.UserRepositoryInterface
+	mfaRepo  repository.MFARepositoryInterface
+	orgRepo  repository.OrganizationRepositoryInterface
+	cfg      *config.Configs
+	logger   *zap.Logger
+}
+
+// NewMFAUsecase creates a new instance of MFAUsecase
+// Policies apply to the highest role a user holds, platform-wide or in any of their organizations
+func NewMFAUsecase(userRepo repository.UserRepositoryInterface, mfaRepo repository.MFARepositoryInterface, orgRepo repository.OrganizationRepositoryInterface, cfg *config.Configs, logger *zap.Logger) usecase.MFAUsecaseInterface {
+	return &MFAUsecase{
+		userRepo: userRepo,
+		mfaRepo:  mfaRepo,
+		orgRepo:  orgRepo,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+// Challenge returns the pending login result for a user who needs a second factor, or nil when none is needed
+// Users whose role requires 2FA but who have not enrolled get an enrollment challenge instead
+func (u *MFAUsecase) Challenge(user *model.User) (*model.LoginResult, error) {
+	purpose := mfaChallengePurpose
+	if !user.MFAEnabled() {
+		required, err := u.required(user)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+		purpose = mfaEnrollmentPurpose
+	}
+
+	token, err := u.signChallenge(user, purpose)
+	if err != nil {
+		u.logger.Error("Failed to sign MFA challenge", zap.Uint("user_id", user.ID), zap.Error(err), zap.String("operation", "mfa_challenge"))
+		return nil, err
+	}
+	return &model.LoginResult{
+		MFAToken:              token,
+		MFAEnrollmentRequired: purpose == mfaEnrollmentPurpose,
+	}, nil
+}
+
+// ParseChallenge validates a challenge token and returns its user and whether it is an enrollment challenge
+func (u *MFAUsecase) ParseChallenge(rawToken string) (uint, bool, error) {
+	claims, err := parsePurposeToken(u.cfg.JWTSecret, rawToken, mfaChallengePurpose, mfaEnrollmentPurpose)
+	if err != nil {
+		u.logger.Warn("Invalid MFA token", zap.Error(err), zap.String("operation", "mfa_challenge"))
+		return 0, false, ErrInvalidMFAToken
+	}
+	userID, ok := claims["id"].(float64)
+	if !ok {
+		u.logger.Warn("MFA token without user", zap.String("operation", "mfa_challenge"))
+		return 0, false, ErrInvalidMFAToken
+	}
+	return uint(userID), claims["purpose"] == mfaEnrollmentPurpose, nil
+}
+
+// Verify accepts a current TOTP code or an unused recovery code for a user with 2FA enabled
+// Accepted TOTP codes cannot be replayed and recovery codes are consumed
+func (u *MFAUsecase) Verify(user *model.User, code string) error {
+	if !user.MFAEnabled() {
+		return ErrMFANotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := validateTOTP(user.MFASecret, code, time.Now(), user.MFALastStep); ok {
+		user.MFALastStep = step
+		if err := u.userRepo.Update(user); err != nil {
+			u.logger.Error("Failed to record TOTP step", zap.Uint("user_id", user.ID), zap.Error(err), zap.String("operation", "mfa_verify"))
+			return err
+		}
+		return nil
+	}
+
+	used, err := u.mfaRepo.UseRecoveryCode(user.ID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if used {
+		u.logger.Info("Recovery code used", zap.Uint("user_id", user.ID), zap.String("operation", "mfa_verify"))
+		return nil
+	}
+
+	u.logger.Warn("Invalid MFA code", zap.Uint("user_id", user.ID), zap.String("operation", "mfa_verify"))
+	return ErrInvalidMFACode
+}
+
+// BeginEnrollment generates a new TOTP secret for the user, replacing any unconfirmed one
+// 2FA only becomes active once ConfirmEnrollment receives a valid code for the secret
+func (u *MFAUsecase) BeginEnrollment(userID uint) (*model.MFAEnrollment, error) {
+	user, err := u.loadUser(userID, "mfa_enroll")
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		u.logger.Error("Failed to generate TOTP secret", zap.Error(err), zap.String("operation", "mfa_enroll"))
+		return nil, err
+	}
+	user.MFASecret = secret
+	user.MFALastStep = 0
+	if err := u.userRepo.Update(user); err != nil {
+		u.logger.Error("Failed to store TOTP secret", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "mfa_enroll"))
+		return nil, err
+	}
+
+	u.logger.Info("MFA enrollment started", zap.Uint("user_id", userID), zap.String("operation", "mfa_enroll"))
+	return &model.MFAEnrollment{
+		Secret: secret,
+		URI:    totpURI(u.cfg.MFAIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables 2FA once the user proves the authenticator works, returning the recovery codes
+// The raw recovery codes are only available in this response
+func (u *MFAUsecase) ConfirmEnrollment(userID uint, code string) ([]string, error) {
+	user, err := u.loadUser(userID, "mfa_confirm")
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == "" {
+		return nil, ErrMFAEnrollmentNotStarted
+	}
+
+	step, ok := validateTOTP(user.MFASecret, strings.TrimSpace(code), time.Now(), user.MFALastStep)
+	if !ok {
+		u.logger.Warn("Invalid code while confirming MFA", zap.Uint("user_id", userID), zap.String("operation", "mfa_confirm"))
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, err := u.issueRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user.MFAEnabledAt = &now
+	user.MFALastStep = step
+	if err := u.userRepo.Update(user); err != nil {
+		u.logger.Error("Failed to enable MFA", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "mfa_confirm"))
+		return nil, err
+	}
+
+	u.logger.Info("MFA enabled", zap.Uint("user_id", userID), zap.String("operation", "mfa_confirm"))
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of a user after checking a current code
+func (u *MFAUsecase) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	user, err := u.loadUser(userID, "mfa_recovery_codes")
+	if err != nil {
+		return nil, err
+	}
+	if err := u.Verify(user, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := u.issueRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	u.logger.Info("Recovery codes regenerated", zap.Uint("user_id", userID), zap.String("operation", "mfa_recovery_codes"))
+	return codes, nil
+}
+
+// Disable turns 2FA off after checking a current code, unless the user's role requires it
+func (u *MFAUsecase) Disable(userID uint, code string) error {
+	user, err := u.loadUser(userID, "mfa_disable")
+	if err != nil {
+		return err
+	}
+	if !user.MFAEnabled() {
+		return ErrMFANotEnabled
+	}
+	required, err := u.required(user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequired
+	}
+	if err := u.Verify(user, code); err != nil {
+		return err
+	}
+
+	if err := u.clear(user); err != nil {
+		return err
+	}
+	u.logger.Info("MFA disabled", zap.Uint("user_id", userID), zap.String("operation", "mfa_disable"))
+	return nil
+}
+
+// Reset removes a user's 2FA enrollment, e.g. after losing both the device and the recovery codes
+// If the role requires 2FA, the user must enroll again on the next login
+func (u *MFAUsecase) Reset(userID uint, adminID uint) error {
+	user, err := u.loadUser(userID, "mfa_reset")
+	if err != nil {
+		return err
+	}
+
+	if err := u.clear(user); err != nil {
+		return err
+	}
+	u.logger.Info("MFA reset", zap.Uint("user_id", userID), zap.Uint("admin_id", adminID), zap.String("operation", "mfa_reset"))
+	return nil
+}
+
+// ListPolicies returns the two-factor policy of every role, including roles without a stored policy
+func (u *MFAUsecase) ListPolicies() ([]*model.MFAPolicy, error) {
+	stored, err := u.mfaRepo.ListPolicies()
+	if err != nil {
+		return nil, err
+	}
+	byRole := make(map[string]*model.MFAPolicy, len(stored))
+	for _, policy := range stored {
+		byRole[policy.Role] = policy
+	}
+
+	policies := make([]*model.MFAPolicy, 0, 3)
+	for _, role := range []string{model.RoleAdmin, model.RoleEditor, model.RoleViewer} {
+		if policy, ok := byRole[role]; ok {
+			policies = append(policies, policy)
+		} else {
+			policies = append(policies, &model.MFAPolicy{Role: role})
+		}
+	}
+	return policies, nil
+}
+
+// SetPolicy requires or stops requiring 2FA for a role
+// Users already logged in keep their sessions; the policy applies from their next login
+func (u *MFAUsecase) SetPolicy(role string, required bool) error {
+	if !model.IsValidRole(role) {
+		return ErrInvalidRole
+	}
+	if err := u.mfaRepo.SavePolicy(&model.MFAPolicy{Role: role, Required: required}); err != nil {
+		return err
+	}
+	u.logger.Info("MFA policy changed", zap.String("role", role), zap.Bool("required", required), zap.String("operation", "mfa_policy"))
+	return nil
+}
+
+// required reports whether the policy of the highest role the user holds requires 2FA
+// Organization roles count as much as the platform-wide role, since they grant the same permissions on products
+func (u *MFAUsecase) required(user *model.User) (bool, error) {
+	memberships, err := u.orgRepo.ListMemberships(user.ID)
+	if err != nil {
+		u.logger.Error("Failed to load memberships", zap.Uint("user_id", user.ID), zap.Error(err), zap.String("operation", "mfa_policy"))
+		return false, err
+	}
+	role := user.Role
+	for _, membership := range memberships {
+		if roleRank[membership.Role] > roleRank[role] {
+			role = membership.Role
+		}
+	}
+
+	policy, err := u.mfaRepo.FindPolicy(role)
+	if err != nil {
+		u.logger.Error("Failed to load MFA policy", zap.String("role", role), zap.Error(err), zap.String("operation", "mfa_policy"))
+		return false, err
+	}
+	return policy != nil && policy.Required, nil
+}
+
+// loadUser returns the user with the given ID or ErrUserNotFound
+func (u *MFAUsecase) loadUser(userID uint, operation string) (*model.User, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		u.logger.Error("Failed to load user", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", operation))
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// clear removes the TOTP secret and recovery codes of a user
+func (u *MFAUsecase) clear(user *model.User) error {
+	user.MFASecret = ""
+	user.MFAEnabledAt = nil
+	user.MFALastStep = 0
+	if err := u.userRepo.Update(user); err != nil {
+		u.logger.Error("Failed to clear MFA enrollment", zap.Uint("user_id", user.ID), zap.Error(err), zap.String("operation", "mfa_clear"))
+		return err
+	}
+	return u.mfaRepo.DeleteRecoveryCodes(user.ID)
+}
+
+// issueRecoveryCodes replaces the recovery codes of a user and returns the new raw codes
+func (u *MFAUsecase) issueRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]*model.MFARecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			u.logger.Error("Failed to generate recovery code", zap.Error(err), zap.String("operation", "mfa_recovery_codes"))
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, &model.MFARecoveryCode{UserID: userID, CodeHash: hashToken(normalizeRecoveryCode(code))})
+	}
+
+	if err := u.mfaRepo.ReplaceRecoveryCodes(userID, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// signChallenge creates a short-lived token proving the user passed the password step
+func (u *MFAUsecase) signChallenge(user *model.User, purpose string) (string, error) {
+	return signPurposeToken(u.cfg.JWTSecret, purpose, jwt.MapClaims{"id": user.ID}, u.cfg.MFAChallengeTTL)
+}
+
+// generateRecoveryCode returns a random code formatted as two groups of five characters, e.g. "k3f9q-2mx7d"
+func generateRecoveryCode() (string, error) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+	code := strings.ToLower(secret[:10])
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode ignores case, spaces and dashes so codes can be typed loosely
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+)
+
+// AnyEvent is the routing rule key applying to events without a rule of their own
+const AnyEvent = "*"
+
+// NotificationDispatcher routes product notifications to their channels
+type NotificationDispatcher struct {
+	rules     map[string][]string
+	notifiers []messaging.Notifier
+	dedup     usecase.MessageDeduplicatorInterface
+	logger    *zap.Logger
+}
+
+// NewNotificationDispatcher creates a dispatcher from routing rules mapping events, or AnyEvent, to channels
+// Channels named by the rules without a notifier are reported and ignored
+// The deduplicator records the events each channel delivered, so that a retry only reaches the channels that failed
+func NewNotificationDispatcher(rules map[string][]string, notifiers []messaging.Notifier, dedup usecase.MessageDeduplicatorInterface, logger *zap.Logger) usecase.NotificationDispatcherInterface {
+	available := make(map[string]bool, len(notifiers))
+	for _, notifier := range notifiers {
+		available[notifier.Channel()] = true
+	}
+	for event, channels := range rules {
+		for _, channel := range channels {
+			if !available[channel] {
+				logger.Warn("Notification channel is not configured", zap.String("event", event), zap.String("channel", channel))
+			}
+		}
+	}
+	return &NotificationDispatcher{
+		rules:     rules,
+		notifiers: notifiers,
+		dedup:     dedup,
+		logger:    logger,
+	}
+}
+
+// Dispatch gives every notifier the notifications routed to its channel
+// All channels are tried even when one fails, so a broken webhook does not hold back the others
+// Notifications a channel already delivered, by an earlier attempt of the same events, are not sent to it again
+func (d *NotificationDispatcher) Dispatch(ctx context.Context, recipient string, notifications []model.ProductNotification) error {
+	var failures []string
+	permanent := true
+	for _, notifier := range d.notifiers {
+		var routed []model.ProductNotification
+		for _, notification := range notifications {
+			if d.routes(notification.Event, notifier.Channel()) {
+				routed = append(routed, notification)
+			}
+		}
+		if len(routed) == 0 {
+			continue
+		}
+
+		scope := channelScope(notifier.Channel())
+		routed, err := d.undelivered(ctx, scope, routed)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", notifier.Channel(), err))
+			permanent = false
+			continue
+		}
+		if len(routed) == 0 {
+			continue
+		}
+
+		if err := notifier.Notify(ctx, recipient, routed); err != nil {
+			d.logger.Error("Failed to deliver notifications", zap.String("channel", notifier.Channel()), zap.Int("count", len(routed)), zap.Error(err))
+			failures = append(failures, fmt.Sprintf("%s: %v", notifier.Channel(), err))
+			permanent = permanent && errors.Is(err, messaging.ErrPermanentDelivery)
+			continue
+		}
+		// The channel was delivered either way; without the record, a retry of the events may deliver it twice
+		if err := d.dedup.Record(context.WithoutCancel(ctx), scope, eventIDs(routed)); err != nil {
+			d.logger.Error("Failed to record delivered notifications", zap.String("channel", notifier.Channel()), zap.Int("count", len(routed)), zap.Error(err))
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+	if permanent {
+		return fmt.Errorf("%w: %s", messaging.ErrPermanentDelivery, strings.Join(failures, "; "))
+	}
+	// Failures are not wrapped, so that a permanent one does not hide a channel worth retrying
+	return fmt.Errorf("failed to deliver notifications: %s", strings.Join(failures, "; "))
+}
+
+// undelivered returns the notifications the channel has not delivered yet; notifications without an event ID
+// are always returned
+func (d *NotificationDispatcher) undelivered(ctx context.Context, scope string, notifications []model.ProductNotification) ([]model.ProductNotification, error) {
+	delivered, err := d.dedup.Processed(context.WithoutCancel(ctx), scope, eventIDs(notifications))
+	if err != nil {
+		d.logger.Error("Failed to look up delivered notifications", zap.String("scope", scope), zap.Int("count", len(notifications)), zap.Error(err))
+		return nil, err
+	}
+
+	pending := make([]model.ProductNotification, 0, len(notifications))
+	for _, notification := range notifications {
+		if notification.EventID != "" && delivered[notification.EventID] {
+			d.logger.Info("Skipping notification already delivered", zap.String("scope", scope), zap.String("event_id", notification.EventID), zap.Int("sku", notification.SKU))
+			continue
+		}
+		pending = append(pending, notification)
+	}
+	return pending, nil
+}
+
+// channelScope is the deduplication scope of the events delivered by a notification channel
+func channelScope(channel string) string {
+	return "notifications." + channel
+}
+
+// routes reports whether the rules send an event to a channel
+func (d *NotificationDispatcher) routes(event, channel string) bool {
+	channels, ok := d.rules[event]
+	if !ok {
+		channels = d.rules[AnyEvent]
+	}
+	for _, routed := range channels {
+		if routed == channel {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,565 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"github.com/dgrijalva/jwt-go"
+	"go.uber.org/zap"
+)
+
+// unsubscribePurpose marks unsubscribe tokens so they cannot be used as access tokens and vice versa
+const unsubscribePurpose = "unsubscribe"
+
+// Digests are looked for every digestPollInterval, in batches of digestBatchSize entries
+const (
+	digestPollInterval = time.Minute
+	digestBatchSize    = 500
+	// digestLease hides claimed entries from other workers while their digest is being sent
+	digestLease = 10 * time.Minute
+)
+
+// Standard errors returned by the notification use cases
+var (
+	ErrInvalidNotificationEvent = errors.New("invalid notification event")
+	ErrInvalidFrequency         = errors.New("invalid notification frequency")
+	ErrInvalidUnsubscribeToken  = errors.New("invalid unsubscribe token")
+	ErrUnsupportedLocale        = errors.New("unsupported locale")
+	ErrInvalidTimeZone          = errors.New("invalid time zone")
+)
+
+// NotificationUsecase implements the notification preferences and the hourly and daily digests
+type NotificationUsecase struct {
+	notificationRepo repository.NotificationRepositoryInterface
+	userRepo         repository.UserRepositoryInterface
+	mailer           messaging.Mailer
+	renderer         messaging.EmailRenderer
+	cfg              *config.Configs
+	logger           *zap.Logger
+}
+
+// NewNotificationUsecase creates a new instance of NotificationUsecase
+func NewNotificationUsecase(notificationRepo repository.NotificationRepositoryInterface, userRepo repository.UserRepositoryInterface, mailer messaging.Mailer, renderer messaging.EmailRenderer, cfg *config.Configs, logger *zap.Logger) usecase.NotificationUsecaseInterface {
+	return &NotificationUsecase{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		mailer:           mailer,
+		renderer:         renderer,
+		cfg:              cfg,
+		logger:           logger,
+	}
+}
+
+// GetPreferences returns the frequency of every configurable event, events never configured being
+// immediate, with the locale and time zone of the user's emails
+func (u *NotificationUsecase) GetPreferences(userID uint) (*model.NotificationSettings, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		u.logger.Error("Failed to load user", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "get_notification_preferences"))
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	preferences, err := u.frequencies(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.NotificationSettings{
+		Preferences: preferences,
+		Locale:      u.locale(user),
+		TimeZone:    u.timeZone(user),
+	}, nil
+}
+
+// frequencies returns the frequency of every configurable event for a user
+func (u *NotificationUsecase) frequencies(userID uint) (map[string]string, error) {
+	stored, err := u.notificationRepo.FindPreferences(userID)
+	if err != nil {
+		u.logger.Error("Failed to load notification preferences", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "get_notification_preferences"))
+		return nil, err
+	}
+
+	preferences := make(map[string]string, len(model.NotificationEvents))
+	for _, event := range model.NotificationEvents {
+		preferences[event] = model.FrequencyImmediate
+	}
+	for _, preference := range stored {
+		if model.IsNotificationEvent(preference.Event) {
+			preferences[preference.Event] = preference.Frequency
+		}
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences changes the frequency of the given events, and the locale and time zone when they are
+// given, then returns the resulting settings. Events that are not given keep their frequency
+func (u *NotificationUsecase) UpdatePreferences(userID uint, settings model.NotificationSettings) (*model.NotificationSettings, error) {
+	changes := make([]*model.NotificationPreference, 0, len(settings.Preferences))
+	for event, frequency := range settings.Preferences {
+		if !model.IsNotificationEvent(event) {
+			u.logger.Warn("Invalid notification event", zap.String("event", event), zap.String("operation", "update_notification_preferences"))
+			return nil, ErrInvalidNotificationEvent
+		}
+		if !model.IsValidFrequency(frequency) {
+			u.logger.Warn("Invalid notification frequency", zap.String("frequency", frequency), zap.String("operation", "update_notification_preferences"))
+			return nil, ErrInvalidFrequency
+		}
+		changes = append(changes, &model.NotificationPreference{Event: event, Frequency: frequency})
+	}
+	if settings.Locale != "" && !model.IsSupportedLocale(settings.Locale) {
+		u.logger.Warn("Unsupported locale", zap.String("locale", settings.Locale), zap.String("operation", "update_notification_preferences"))
+		return nil, ErrUnsupportedLocale
+	}
+	if settings.TimeZone != "" {
+		if _, err := time.LoadLocation(settings.TimeZone); err != nil {
+			u.logger.Warn("Invalid time zone", zap.String("time_zone", settings.TimeZone), zap.String("operation", "update_notification_preferences"))
+			return nil, ErrInvalidTimeZone
+		}
+	}
+
+	if settings.Locale != "" || settings.TimeZone != "" {
+		user, err := u.userRepo.FindByID(userID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrUserNotFound
+		}
+		if settings.Locale != "" {
+			user.Locale = settings.Locale
+		}
+		if settings.TimeZone != "" {
+			user.TimeZone = settings.TimeZone
+		}
+		if err := u.userRepo.Update(user); err != nil {
+			u.logger.Error("Failed to save email locale", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "update_notification_preferences"))
+			return nil, err
+		}
+	}
+
+	if err := u.notificationRepo.SavePreferences(userID, changes); err != nil {
+		u.logger.Error("Failed to save notification preferences", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "update_notification_preferences"))
+		return nil, err
+	}
+
+	u.logger.Info("Notification preferences updated", zap.Uint("user_id", userID), zap.String("operation", "update_notification_preferences"))
+	return u.GetPreferences(userID)
+}
+
+// Unsubscribe turns off one event, or every event, for the user the token was issued to
+// Tokens issued to an address without an account turn off every event, since there are no preferences
+// to keep the others in
+func (u *NotificationUsecase) Unsubscribe(rawToken, event string) error {
+	userID, address, err := u.parseToken(rawToken)
+	if err != nil {
+		u.logger.Warn("Invalid unsubscribe token", zap.Error(err), zap.String("operation", "unsubscribe"))
+		return ErrInvalidUnsubscribeToken
+	}
+
+	events := model.NotificationEvents
+	if event != "" {
+		if !model.IsNotificationEvent(event) {
+			return ErrInvalidNotificationEvent
+		}
+		events = []string{event}
+	}
+
+	if address != "" {
+		if err := u.notificationRepo.UnsubscribeEmail(address); err != nil {
+			return err
+		}
+		u.logger.Info("Address unsubscribed from notifications", zap.String("operation", "unsubscribe"))
+		return nil
+	}
+
+	preferences := make(map[string]string, len(events))
+	for _, e := range events {
+		preferences[e] = model.FrequencyOff
+	}
+	if _, err := u.UpdatePreferences(userID, model.NotificationSettings{Preferences: preferences}); err != nil {
+		return err
+	}
+
+	u.logger.Info("User unsubscribed from notifications", zap.Uint("user_id", userID), zap.String("event", event), zap.String("operation", "unsubscribe"))
+	return nil
+}
+
+// Route applies the recipient's preferences to their notifications
+// Hourly and daily notifications are stored for the next digest, off notifications are dropped, and the
+// others are rendered in one email for the caller to send now. Routing the same notifications again, after
+// the email failed, does not store them twice. Recipients without an account are notified
+// immediately, in the default locale, unless they unsubscribed from a previous email, in which case every
+// notification is off
+func (u *NotificationUsecase) Route(ctx context.Context, recipient string, notifications []model.ProductNotification) ([]string, *messaging.Email, error) {
+	routes := make([]string, len(notifications))
+	for i := range routes {
+		routes[i] = model.FrequencyImmediate
+	}
+
+	user, err := u.userRepo.FindByEmail(recipient)
+	if err != nil {
+		u.logger.Error("Failed to look up notification recipient", zap.Error(err), zap.String("operation", "route_notifications"))
+		return nil, nil, err
+	}
+	if user == nil {
+		unsubscribed, err := u.notificationRepo.IsEmailUnsubscribed(recipient)
+		if err != nil {
+			return nil, nil, err
+		}
+		if unsubscribed {
+			for i := range routes {
+				routes[i] = model.FrequencyOff
+			}
+			return routes, nil, nil
+		}
+		link, err := u.unsubscribeLink(0, recipient)
+		if err != nil {
+			return nil, nil, err
+		}
+		email, err := u.renderer.RenderProductDigest(&messaging.ProductDigest{
+			Frequency:      model.FrequencyImmediate,
+			Locale:         model.DefaultLocale,
+			TimeZone:       u.cfg.DefaultTimeZone,
+			Notifications:  notifications,
+			UnsubscribeURL: link,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		email.To = []string{recipient}
+		email.Kind, email.EventIDs = messaging.EmailKindProductNotification, eventIDs(notifications)
+		return routes, email, nil
+	}
+
+	preferences, err := u.frequencies(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	var entries []*model.DigestEntry
+	var immediate []model.ProductNotification
+	for i, notification := range notifications {
+		frequency, ok := preferences[notification.Event]
+		if !ok {
+			frequency = model.FrequencyImmediate
+		}
+		routes[i] = frequency
+		switch frequency {
+		case model.FrequencyImmediate:
+			immediate = append(immediate, notification)
+		case model.FrequencyHourly, model.FrequencyDaily:
+			entries = append(entries, &model.DigestEntry{
+				UserID:    user.ID,
+				Frequency: frequency,
+				Event:     notification.Event,
+				SKU:       notification.SKU,
+				Name:      notification.Name,
+				OrgID:     notification.OrgID,
+				OrgName:   notification.OrgName,
+				EventID:   notification.EventID,
+				DueAt:     u.nextDigest(frequency, now, u.timeZone(user)),
+				// The digest shows when the change happened; a zero time is filled in when the entry is stored
+				CreatedAt: notification.At,
+			})
+		}
+	}
+	if err := u.notificationRepo.AddDigestEntries(ctx, entries); err != nil {
+		return nil, nil, err
+	}
+	if len(immediate) == 0 {
+		return routes, nil, nil
+	}
+
+	link, err := u.unsubscribeLink(user.ID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	email, err := u.renderer.RenderProductDigest(&messaging.ProductDigest{
+		Frequency:      model.FrequencyImmediate,
+		RecipientName:  user.Name,
+		Locale:         u.locale(user),
+		TimeZone:       u.timeZone(user),
+		Notifications:  immediate,
+		UnsubscribeURL: link,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	email.To = []string{user.Email}
+	email.Kind, email.EventIDs = messaging.EmailKindProductNotification, eventIDs(immediate)
+	return routes, email, nil
+}
+
+// PreviewDigest renders a digest of sample notifications, as a user with the given locale and time zone
+// would receive it; empty values use the defaults
+func (u *NotificationUsecase) PreviewDigest(frequency, locale, timeZone string) (*messaging.Email, error) {
+	if frequency != model.FrequencyImmediate && frequency != model.FrequencyHourly && frequency != model.FrequencyDaily {
+		return nil, ErrInvalidFrequency
+	}
+	if locale == "" {
+		locale = model.DefaultLocale
+	}
+	if !model.IsSupportedLocale(locale) {
+		return nil, ErrUnsupportedLocale
+	}
+	if timeZone == "" {
+		timeZone = u.cfg.DefaultTimeZone
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+
+	now := time.Now()
+	return u.renderer.RenderProductDigest(&messaging.ProductDigest{
+		Frequency:     frequency,
+		RecipientName: "Maria Silva",
+		Locale:        locale,
+		TimeZone:      timeZone,
+		Notifications: []model.ProductNotification{
+			{Event: model.EventProductCreated, SKU: 1001, Name: "Caneta <Azul> & Cia", OrgName: "Acme", At: now.Add(-50 * time.Minute)},
+			{Event: model.EventProductCreated, SKU: 1002, Name: "Caderno", OrgName: "Acme", At: now.Add(-40 * time.Minute)},
+			{Event: model.EventProductUpdated, SKU: 1001, Name: "Caneta <Azul> & Cia", OrgName: "Acme", At: now.Add(-20 * time.Minute)},
+			{Event: model.EventProductDeleted, SKU: 987, Name: "Borracha", OrgName: "Acme", At: now.Add(-5 * time.Minute)},
+		},
+		UnsubscribeURL: u.cfg.UnsubscribeURL + "?token=preview",
+		SentAt:         now,
+	})
+}
+
+// RunDigests sends the due digests at every poll interval until the context is cancelled
+func (u *NotificationUsecase) RunDigests(ctx context.Context) error {
+	ticker := time.NewTicker(digestPollInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			sent, err := u.FlushDigests(ctx)
+			if err != nil || sent == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			u.logger.Info("Digest sender stopped", zap.String("operation", "send_digests"))
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// FlushDigests claims one batch of due entries and sends one digest per user
+// A failed digest is sent again once the lease of its entries expires
+func (u *NotificationUsecase) FlushDigests(ctx context.Context) (int, error) {
+	entries, err := u.notificationRepo.ClaimDueDigestEntries(ctx, digestBatchSize, digestLease)
+	if err != nil {
+		return 0, err
+	}
+
+	var order []uint
+	byUser := make(map[uint][]*model.DigestEntry)
+	for _, entry := range entries {
+		if _, ok := byUser[entry.UserID]; !ok {
+			order = append(order, entry.UserID)
+		}
+		byUser[entry.UserID] = append(byUser[entry.UserID], entry)
+	}
+
+	sent := 0
+	for _, userID := range order {
+		if ctx.Err() != nil {
+			break
+		}
+		if u.sendDigest(ctx, userID, byUser[userID]) {
+			sent++
+		}
+	}
+
+	if sent > 0 {
+		u.logger.Info("Sent notification digests", zap.Int("count", sent), zap.String("operation", "send_digests"))
+	}
+	return sent, nil
+}
+
+// sendDigest emails a user's pending entries and removes them, reporting whether an email was sent
+// Entries of removed or disabled users, and of events turned off since they were stored, are discarded
+func (u *NotificationUsecase) sendDigest(ctx context.Context, userID uint, entries []*model.DigestEntry) bool {
+	ids := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return false
+	}
+	if user == nil || user.IsDisabled() {
+		u.notificationRepo.DeleteDigestEntries(ctx, ids)
+		return false
+	}
+	preferences, err := u.frequencies(userID)
+	if err != nil {
+		return false
+	}
+	var wanted []*model.DigestEntry
+	for _, entry := range entries {
+		if preferences[entry.Event] != model.FrequencyOff {
+			wanted = append(wanted, entry)
+		}
+	}
+	if len(wanted) == 0 {
+		u.notificationRepo.DeleteDigestEntries(ctx, ids)
+		return false
+	}
+
+	email, err := u.digestEmail(user, wanted)
+	if err != nil {
+		return false
+	}
+	mailCtx, cancel := context.WithTimeout(ctx, mailTimeout)
+	defer cancel()
+	if err := u.mailer.Send(mailCtx, email); err != nil {
+		u.logger.Error("Failed to send notification digest", zap.Uint("user_id", userID), zap.Int("event_count", len(wanted)), zap.Error(err), zap.String("operation", "send_digests"))
+		return false
+	}
+	// A failure here only means the digest may be sent again after the lease
+	u.notificationRepo.DeleteDigestEntries(ctx, ids)
+	return true
+}
+
+// digestEmail renders the digest of a user's product notifications in their locale and time zone
+// A digest is hourly when it holds hourly notifications, which are sent more often than daily ones
+func (u *NotificationUsecase) digestEmail(user *model.User, entries []*model.DigestEntry) (*messaging.Email, error) {
+	frequency := model.FrequencyDaily
+	notifications := make([]model.ProductNotification, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Frequency == model.FrequencyHourly {
+			frequency = model.FrequencyHourly
+		}
+		notifications = append(notifications, model.ProductNotification{
+			Event:   entry.Event,
+			SKU:     entry.SKU,
+			Name:    entry.Name,
+			OrgID:   entry.OrgID,
+			OrgName: entry.OrgName,
+			At:      entry.CreatedAt,
+			EventID: entry.EventID,
+		})
+	}
+
+	link, err := u.unsubscribeLink(user.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	email, err := u.renderer.RenderProductDigest(&messaging.ProductDigest{
+		Frequency:      frequency,
+		RecipientName:  user.Name,
+		Locale:         u.locale(user),
+		TimeZone:       u.timeZone(user),
+		Notifications:  notifications,
+		UnsubscribeURL: link,
+	})
+	if err != nil {
+		u.logger.Error("Failed to render notification digest", zap.Uint("user_id", user.ID), zap.Error(err), zap.String("operation", "send_digests"))
+		return nil, err
+	}
+	email.To = []string{user.Email}
+	email.Kind, email.EventIDs = messaging.EmailKindProductDigest, eventIDs(notifications)
+	return email, nil
+}
+
+// eventIDs returns the IDs of the events of notifications, for the delivery log and the deduplicator; unknown IDs are left out
+func eventIDs(notifications []model.ProductNotification) []string {
+	var ids []string
+	for _, notification := range notifications {
+		if notification.EventID != "" {
+			ids = append(ids, notification.EventID)
+		}
+	}
+	return ids
+}
+
+// locale returns the locale of a user's emails
+func (u *NotificationUsecase) locale(user *model.User) string {
+	if model.IsSupportedLocale(user.Locale) {
+		return user.Locale
+	}
+	return model.DefaultLocale
+}
+
+// timeZone returns the time zone of a user's emails
+func (u *NotificationUsecase) timeZone(user *model.User) string {
+	if user.TimeZone != "" {
+		return user.TimeZone
+	}
+	return u.cfg.DefaultTimeZone
+}
+
+// nextDigest returns when a notification stored now is sent: at the start of the next hour for hourly
+// digests, or at the next configured hour of the day in the given time zone for daily digests
+// An unknown time zone falls back to UTC
+func (u *NotificationUsecase) nextDigest(frequency string, now time.Time, timeZone string) time.Time {
+	if frequency == model.FrequencyHourly {
+		return now.UTC().Truncate(time.Hour).Add(time.Hour)
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+	due := time.Date(now.Year(), now.Month(), now.Day(), u.cfg.DailyDigestHour, 0, 0, 0, loc)
+	if !due.After(now) {
+		due = time.Date(now.Year(), now.Month(), now.Day()+1, u.cfg.DailyDigestHour, 0, 0, 0, loc)
+	}
+	return due.UTC()
+}
+
+// unsubscribeLink returns the link that turns off the notifications of a user, or of an address without
+// an account when userID is zero
+func (u *NotificationUsecase) unsubscribeLink(userID uint, address string) (string, error) {
+	token, err := u.signToken(userID, address)
+	if err != nil {
+		u.logger.Error("Failed to sign unsubscribe token", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "unsubscribe_link"))
+		return "", err
+	}
+	return u.cfg.UnsubscribeURL + "?token=" + url.QueryEscape(token), nil
+}
+
+// signToken creates an unsubscribe token bound to the user's ID, or to the address when userID is zero
+// It does not expire, so that the links of old emails keep working
+func (u *NotificationUsecase) signToken(userID uint, address string) (string, error) {
+	claims := jwt.MapClaims{}
+	if userID != 0 {
+		claims["id"] = userID
+	} else {
+		claims["email"] = strings.ToLower(address)
+	}
+	return signPurposeToken(u.cfg.JWTSecret, unsubscribePurpose, claims, 0)
+}
+
+// parseToken validates the signature and purpose of an unsubscribe token and returns its user ID, or
+// its address when it was issued to a recipient without an account
+func (u *NotificationUsecase) parseToken(rawToken string) (uint, string, error) {
+	claims, err := parsePurposeToken(u.cfg.JWTSecret, strings.TrimSpace(rawToken), unsubscribePurpose)
+	if err != nil {
+		return 0, "", err
+	}
+	if address, _ := claims["email"].(string); address != "" {
+		return 0, address, nil
+	}
+	id, _ := claims["id"].(float64)
+	if id <= 0 {
+		return 0, "", errors.New("unsubscribe token without user")
+	}
+	return uint(id), "", nil
+}
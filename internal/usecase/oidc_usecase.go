@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/identity"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+)
+
+// oidcStateTTL is how long the user has to sign in at the identity provider
+const oidcStateTTL = 10 * time.Minute
+
+// Standard errors returned by the OIDC login use cases
+var (
+	ErrInvalidOIDCState     = errors.New("invalid or expired OIDC login state")
+	ErrOIDCLoginFailed      = errors.New("OIDC login failed")
+	ErrOIDCEmailNotVerified = errors.New("identity provider did not verify the email address")
+	ErrOIDCAccountNotFound  = errors.New("no local account for this identity")
+)
+
+// roleRank orders roles so the most privileged mapped group wins
+var roleRank = map[string]int{model.RoleViewer: 1, model.RoleEditor: 2, model.RoleAdmin: 3}
+
+// OIDCUsecase implements the authorization code flow with PKCE and maps IdP identities to local users
+type OIDCUsecase struct {
+	provider    identity.Provider
+	stateRepo   repository.OIDCStateRepositoryInterface
+	userRepo    repository.UserRepositoryInterface
+	authUsecase usecase.AuthUsecaseInterface
+	lockout     usecase.LockoutUsecaseInterface
+	cfg         *config.Configs
+	logger      *zap.Logger
+}
+
+// NewOIDCUsecase creates a new instance of OIDCUsecase
+func NewOIDCUsecase(provider identity.Provider, stateRepo repository.OIDCStateRepositoryInterface, userRepo repository.UserRepositoryInterface, authUsecase usecase.AuthUsecaseInterface, lockout usecase.LockoutUsecaseInterface, cfg *config.Configs, logger *zap.Logger) usecase.OIDCUsecaseInterface {
+	return &OIDCUsecase{
+		provider:    provider,
+		stateRepo:   stateRepo,
+		userRepo:    userRepo,
+		authUsecase: authUsecase,
+		lockout:     lockout,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+// BeginLogin stores a new state, nonce and PKCE verifier and returns the identity provider URL to redirect to
+func (u *OIDCUsecase) BeginLogin() (string, error) {
+	state, err := generateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := generateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := generateOpaqueToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	err = u.stateRepo.Create(&model.OIDCLoginState{
+		StateHash:    hashToken(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	})
+	if err != nil {
+		u.logger.Error("Failed to store OIDC state", zap.Error(err), zap.String("operation", "oidc_login"))
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	return u.provider.AuthCodeURL(state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:])), nil
+}
+
+// CompleteLogin handles the identity provider callback and issues the application's own tokens
+// The user is found by IdP subject, linked by verified email, or provisioned, and their role is
+// synchronized from the IdP groups whenever a group matches the configured mapping
+func (u *OIDCUsecase) CompleteLogin(ctx context.Context, state, code, ip string) (*model.TokenPair, error) {
+	stored, err := u.stateRepo.Consume(hashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil || time.Now().After(stored.ExpiresAt) {
+		u.logger.Warn("Unknown or expired OIDC state", zap.String("operation", "oidc_callback"))
+		return nil, ErrInvalidOIDCState
+	}
+
+	claims, err := u.provider.Exchange(ctx, code, stored.CodeVerifier, stored.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	user, err := u.resolveUser(claims)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsDisabled() {
+		u.logger.Warn("OIDC login refused for disabled account", zap.String("email", user.Email), zap.String("operation", "oidc_callback"))
+		u.lockout.RecordSuccess(user.Email, ip, user, model.AuthOutcomeDisabled)
+		return nil, ErrUserDisabled
+	}
+
+	tokens, err := u.authUsecase.StartSession(user)
+	if err != nil {
+		return nil, err
+	}
+	u.lockout.RecordSuccess(user.Email, ip, user, model.AuthOutcomeOIDC)
+
+	u.logger.Info("User logged in with OIDC", zap.String("email", user.Email), zap.String("role", user.Role), zap.String("operation", "oidc_callback"))
+	return tokens, nil
+}
+
+// resolveUser finds, links or provisions the local user for the IdP identity and applies the mapped role
+func (u *OIDCUsecase) resolveUser(claims *identity.Claims) (*model.User, error) {
+	role, mapped := u.mapRole(claims.Groups)
+
+	user, err := u.userRepo.FindByOIDCSubject(claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	changed := false
+
+	if user == nil {
+		if claims.Email == "" {
+			return nil, fmt.Errorf("%w: ID token has no email claim", ErrOIDCLoginFailed)
+		}
+		user, err = u.userRepo.FindByEmail(claims.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		if user != nil {
+			// Linking by an unverified email would let anyone with an IdP account take over a local one
+			if !claims.EmailVerified {
+				u.logger.Warn("Refusing to link account by unverified email", zap.String("email", claims.Email), zap.String("operation", "oidc_callback"))
+				return nil, ErrOIDCEmailNotVerified
+			}
+			subject := claims.Subject
+			user.OIDCSubject = &subject
+			if !user.IsVerified() {
+				now := time.Now()
+				user.VerifiedAt = &now
+			}
+			changed = true
+			u.logger.Info("Linked account to identity provider", zap.String("email", user.Email), zap.String("operation", "oidc_callback"))
+		} else {
+			if !u.cfg.OIDCAutoProvision {
+				u.logger.Warn("No local account for OIDC identity", zap.String("email", claims.Email), zap.String("operation", "oidc_callback"))
+				return nil, ErrOIDCAccountNotFound
+			}
+			if !mapped {
+				role = u.cfg.OIDCDefaultRole
+			}
+			return u.provision(claims, role)
+		}
+	}
+
+	if mapped && user.Role != role {
+		u.logger.Info("Role synchronized from identity provider groups", zap.String("email", user.Email), zap.String("from", user.Role), zap.String("to", role), zap.String("operation", "oidc_callback"))
+		user.Role = role
+		changed = true
+	}
+	if changed {
+		if err := u.userRepo.Update(user); err != nil {
+			u.logger.Error("Failed to update OIDC user", zap.String("email", user.Email), zap.Error(err), zap.String("operation", "oidc_callback"))
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// provision creates a local account for an IdP identity
+// The account has no local password, and its email counts as verified by the identity provider
+func (u *OIDCUsecase) provision(claims *identity.Claims, role string) (*model.User, error) {
+	name, err := u.availableName(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := claims.Subject
+	now := time.Now()
+	user := &model.User{
+		Name:        name,
+		Email:       claims.Email,
+		Role:        role,
+		VerifiedAt:  &now,
+		OIDCSubject: &subject,
+	}
+	if err := u.userRepo.Create(user); err != nil {
+		u.logger.Error("Failed to provision OIDC user", zap.String("email", claims.Email), zap.Error(err), zap.String("operation", "oidc_callback"))
+		return nil, err
+	}
+
+	u.logger.Info("Provisioned user from identity provider", zap.String("email", user.Email), zap.String("role", role), zap.String("operation", "oidc_callback"))
+	return user, nil
+}
+
+// availableName picks the IdP display name, or the email when the name is missing or already used,
+// since product ownership is recorded by name
+func (u *OIDCUsecase) availableName(claims *identity.Claims) (string, error) {
+	if claims.Name != "" {
+		taken, err := u.userRepo.NameTaken(claims.Name, 0)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return claims.Name, nil
+		}
+	}
+	return claims.Email, nil
+}
+
+// mapRole returns the most privileged role mapped from the IdP groups, and whether any group matched
+func (u *OIDCUsecase) mapRole(groups []string) (string, bool) {
+	best := ""
+	for _, group := range groups {
+		if role, ok := u.cfg.OIDCRoleMapping[group]; ok && roleRank[role] > roleRank[best] {
+			best = role
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+)
+
+// Standard errors returned by the organization use cases
+// Organizations the user does not belong to are reported as not found, never as forbidden
+var (
+	ErrOrgNotFound    = errors.New("organization not found")
+	ErrOrgSlugTaken   = errors.New("organization slug already in use")
+	ErrInvalidOrgSlug = errors.New("invalid organization slug")
+	ErrOrgForbidden   = errors.New("organization admin role required")
+	ErrMemberNotFound = errors.New("user is not a member of the organization")
+	ErrLastOrgAdmin   = errors.New("an organization must keep at least one admin")
+)
+
+// orgSlugPattern allows lowercase words separated by single hyphens, e.g. "acme-store"
+var orgSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// OrganizationUsecase implements the business logic for organizations and memberships
+// Platform admins may act in every organization as if they were one of its admins
+type OrganizationUsecase struct {
+	orgRepo  repository.OrganizationRepositoryInterface
+	userRepo repository.UserRepositoryInterface
+	logger   *zap.Logger
+}
+
+// NewOrganizationUsecase creates a new instance of OrganizationUsecase
+func NewOrganizationUsecase(orgRepo repository.OrganizationRepositoryInterface, userRepo repository.UserRepositoryInterface, logger *zap.Logger) usecase.OrganizationUsecaseInterface {
+	return &OrganizationUsecase{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+		logger:   logger,
+	}
+}
+
+// CreateOrganization creates an empty organization; members are added afterwards
+func (u *OrganizationUsecase) CreateOrganization(name, slug string) (*model.Organization, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if !orgSlugPattern.MatchString(slug) {
+		return nil, ErrInvalidOrgSlug
+	}
+
+	existing, err := u.orgRepo.FindBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		u.logger.Warn("Organization slug already in use", zap.String("slug", slug), zap.String("operation", "create_org"))
+		return nil, ErrOrgSlugTaken
+	}
+
+	org := &model.Organization{Name: strings.TrimSpace(name), Slug: slug}
+	if err := u.orgRepo.Create(org); err != nil {
+		u.logger.Error("Failed to create organization", zap.String("slug", slug), zap.Error(err), zap.String("operation", "create_org"))
+		return nil, err
+	}
+
+	u.logger.Info("Organization created", zap.Uint("org_id", org.ID), zap.String("slug", slug), zap.String("operation", "create_org"))
+	return org, nil
+}
+
+// ListOrganizations returns every organization
+func (u *OrganizationUsecase) ListOrganizations() ([]*model.Organization, error) {
+	return u.orgRepo.List()
+}
+
+// ListMemberships returns the organizations a user belongs to, with their role in each
+func (u *OrganizationUsecase) ListMemberships(userID uint) ([]*model.Membership, error) {
+	return u.orgRepo.ListMemberships(userID)
+}
+
+// ListMembers returns the members of an organization; only its admins may list them
+func (u *OrganizationUsecase) ListMembers(actor *model.Actor, orgID uint) ([]*model.Membership, error) {
+	if _, err := u.AuthorizeAdmin(actor, orgID); err != nil {
+		return nil, err
+	}
+	return u.orgRepo.ListMembers(orgID)
+}
+
+// AddMember adds the user with the given email to an organization, or changes their role if already a member
+func (u *OrganizationUsecase) AddMember(actor *model.Actor, orgID uint, email, role string) (*model.Membership, error) {
+	if !model.IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if _, err := u.AuthorizeAdmin(actor, orgID); err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByEmail(email)
+	if err != nil {
+		u.logger.Error("Failed to look up user", zap.String("email", email), zap.Error(err), zap.String("operation", "add_member"))
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return u.saveRole(actor, orgID, user.ID, role, "add_member")
+}
+
+// UpdateMember changes the role of an existing member
+func (u *OrganizationUsecase) UpdateMember(actor *model.Actor, orgID, userID uint, role string) (*model.Membership, error) {
+	if !model.IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if _, err := u.AuthorizeAdmin(actor, orgID); err != nil {
+		return nil, err
+	}
+
+	existing, err := u.orgRepo.FindMembership(userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, ErrMemberNotFound
+	}
+	return u.saveRole(actor, orgID, userID, role, "update_member")
+}
+
+// RemoveMember removes a user from an organization
+// The member's access ends with their current access token, since the middleware checks the membership on each request
+func (u *OrganizationUsecase) RemoveMember(actor *model.Actor, orgID, userID uint) error {
+	if _, err := u.AuthorizeAdmin(actor, orgID); err != nil {
+		return err
+	}
+
+	existing, err := u.orgRepo.FindMembership(userID, orgID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrMemberNotFound
+	}
+	if existing.Role == model.RoleAdmin {
+		if err := u.ensureAnotherAdmin(orgID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := u.orgRepo.DeleteMembership(userID, orgID); err != nil {
+		u.logger.Error("Failed to remove member", zap.Uint("org_id", orgID), zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "remove_member"))
+		return err
+	}
+
+	u.logger.Info("Member removed", zap.Uint("org_id", orgID), zap.Uint("user_id", userID), zap.String("by", actor.Email), zap.String("operation", "remove_member"))
+	return nil
+}
+
+// ResolveMembership returns the user's membership in an organization
+// Platform admins get an implicit admin membership in every existing organization
+// Organizations the user cannot access are reported with ErrOrgNotFound
+func (u *OrganizationUsecase) ResolveMembership(userID uint, platformRole string, orgID uint) (*model.Membership, error) {
+	if orgID == 0 {
+		return nil, ErrOrgNotFound
+	}
+
+	membership, err := u.orgRepo.FindMembership(userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if membership != nil {
+		return membership, nil
+	}
+
+	if platformRole == model.RoleAdmin {
+		org, err := u.orgRepo.FindByID(orgID)
+		if err != nil {
+			return nil, err
+		}
+		if org != nil {
+			return &model.Membership{UserID: userID, OrgID: org.ID, Role: model.RoleAdmin, Organization: org}, nil
+		}
+	}
+	return nil, ErrOrgNotFound
+}
+
+// DefaultMembership picks the organization a new session starts in: the one the user last switched to
+// while they still have access to it, otherwise their oldest membership
+// It returns nil when the user belongs to no organization
+func (u *OrganizationUsecase) DefaultMembership(user *model.User) (*model.Membership, error) {
+	if user.ActiveOrgID != nil {
+		membership, err := u.ResolveMembership(user.ID, user.Role, *user.ActiveOrgID)
+		if err == nil {
+			return membership, nil
+		}
+		if !errors.Is(err, ErrOrgNotFound) {
+			return nil, err
+		}
+	}
+
+	memberships, err := u.orgRepo.ListMemberships(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(memberships) == 0 {
+		return nil, nil
+	}
+	return memberships[0], nil
+}
+
+// AuthorizeAdmin checks that the actor administers the organization
+// Non-members get ErrOrgNotFound, so the organization's existence is not revealed
+func (u *OrganizationUsecase) AuthorizeAdmin(actor *model.Actor, orgID uint) (*model.Membership, error) {
+	membership, err := u.ResolveMembership(actor.ID, actor.Role, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if membership.Role != model.RoleAdmin {
+		u.logger.Warn("Organization admin role required", zap.Uint("org_id", orgID), zap.String("email", actor.Email), zap.String("operation", "authorize_org"))
+		return nil, ErrOrgForbidden
+	}
+	return membership, nil
+}
+
+// saveRole stores a member's role, refusing to demote the organization's last admin
+func (u *OrganizationUsecase) saveRole(actor *model.Actor, orgID, userID uint, role, operation string) (*model.Membership, error) {
+	if role != model.RoleAdmin {
+		existing, err := u.orgRepo.FindMembership(userID, orgID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.Role == model.RoleAdmin {
+			if err := u.ensureAnotherAdmin(orgID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	membership := &model.Membership{UserID: userID, OrgID: orgID, Role: role}
+	if err := u.orgRepo.SaveMembership(membership); err != nil {
+		u.logger.Error("Failed to save membership", zap.Uint("org_id", orgID), zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", operation))
+		return nil, err
+	}
+
+	u.logger.Info("Membership saved", zap.Uint("org_id", orgID), zap.Uint("user_id", userID), zap.String("role", role), zap.String("by", actor.Email), zap.String("operation", operation))
+	return membership, nil
+}
+
+// ensureAnotherAdmin fails when removing one admin would leave the organization without any
+func (u *OrganizationUsecase) ensureAnotherAdmin(orgID uint) error {
+	admins, err := u.orgRepo.CountAdmins(orgID)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return ErrLastOrgAdmin
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+)
+
+// Failed publications are retried after a delay doubling from baseOutboxBackoff up to OutboxMaxBackoff
+const (
+	baseOutboxBackoff = time.Second
+	// outboxLease hides claimed events from other relays while they are being published
+	outboxLease = time.Minute
+	// outboxPublishTimeout bounds a single publication so that a stuck broker does not block the relay
+	outboxPublishTimeout = 10 * time.Second
+	// outboxCleanupInterval is how often events published longer than OutboxRetention ago are removed
+	outboxCleanupInterval = time.Hour
+)
+
+// OutboxRelay publishes the events stored in the outbox, retrying until RabbitMQ accepts them
+// Delivery is at least once: an event published right before the relay stops may be published again
+type OutboxRelay struct {
+	repo      repository.OutboxRepositoryInterface
+	publisher messaging.Publisher
+	cfg       *config.Configs
+	logger    *zap.Logger
+}
+
+// NewOutboxRelay creates a new instance of OutboxRelay
+func NewOutboxRelay(repo repository.OutboxRepositoryInterface, publisher messaging.Publisher, cfg *config.Configs, logger *zap.Logger) usecase.OutboxRelayInterface {
+	return &OutboxRelay{
+		repo:      repo,
+		publisher: publisher,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+// Run polls the outbox, draining it batch by batch, until the context is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.OutboxPollInterval)
+	defer ticker.Stop()
+
+	var lastCleanup time.Time
+	for {
+		for {
+			sent, err := r.Flush(ctx)
+			if err != nil || sent < r.cfg.OutboxBatchSize {
+				break
+			}
+		}
+
+		if time.Since(lastCleanup) >= outboxCleanupInterval {
+			r.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Outbox relay stopped", zap.String("operation", "outbox_relay"))
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush claims one batch of due events and publishes them
+// Each failure is recorded on its event, which is retried later without holding back the others
+func (r *OutboxRelay) Flush(ctx context.Context) (int, error) {
+	events, err := r.repo.ClaimPending(ctx, r.cfg.OutboxBatchSize, outboxLease)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, event := range events {
+		if ctx.Err() != nil {
+			break
+		}
+
+		publishCtx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+		err := r.publish(publishCtx, event)
+		cancel()
+		if err != nil {
+			retryAt := time.Now().Add(r.backoff(event.Attempts))
+			r.logger.Warn("Failed to publish outbox event", zap.Uint("event_id", event.ID), zap.String("event", event.Event), zap.Int("attempts", event.Attempts+1), zap.Time("retry_at", retryAt), zap.Error(err), zap.String("operation", "outbox_relay"))
+			r.repo.MarkFailed(ctx, event.ID, err.Error(), retryAt)
+			continue
+		}
+
+		if err := r.repo.MarkSent(ctx, event.ID); err != nil {
+			// The lease expires and the event is published again; consumers receive it at least once
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		r.logger.Info("Published outbox events", zap.Int("count", sent), zap.String("operation", "outbox_relay"))
+	}
+	return sent, nil
+}
+
+// publish sends a stored event in the configured CloudEvents mode
+// Events stored before the topic exchange go to their queue through the default exchange, and those
+// stored before the CloudEvents envelope are published unchanged, as legacy messages
+func (r *OutboxRelay) publish(ctx context.Context, event *model.OutboxEvent) error {
+	cloudEvent, err := messaging.ParseCloudEvent([]byte(event.Payload))
+	if err != nil || cloudEvent == nil {
+		// Published again after a failure, the message keeps the ID of its outbox event
+		return r.publisher.Publish(messaging.WithMessageID(ctx, strconv.FormatUint(uint64(event.ID), 10)), event.Queue, event.Payload)
+	}
+
+	exchange, routingKey := event.Exchange, event.RoutingKey
+	if exchange == "" {
+		routingKey = event.Queue
+	}
+	return r.publisher.PublishEvent(ctx, exchange, routingKey, cloudEvent, r.cfg.CloudEventsMode)
+}
+
+// backoff returns the delay before the next attempt of an event that already failed the given number of times
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	delay := baseOutboxBackoff
+	for i := 0; i < attempts && delay < r.cfg.OutboxMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.cfg.OutboxMaxBackoff {
+		delay = r.cfg.OutboxMaxBackoff
+	}
+	return delay
+}
+
+// cleanup removes the events published longer than the retention ago
+func (r *OutboxRelay) cleanup(ctx context.Context) {
+	deleted, err := r.repo.DeleteSentBefore(ctx, time.Now().Add(-r.cfg.OutboxRetention))
+	if err == nil && deleted > 0 {
+		r.logger.Info("Removed published outbox events", zap.Int64("count", deleted), zap.String("operation", "outbox_cleanup"))
+	}
+}
@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// mailTimeout bounds how long a transactional email may take to be delivered
+const mailTimeout = 30 * time.Second
+
+// Standard errors returned by the password use cases
+var (
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+	ErrIncorrectPassword = errors.New("incorrect password")
+)
+
+// PasswordUsecase implements the business logic for password recovery and change
+type PasswordUsecase struct {
+	userRepo    repository.UserRepositoryInterface
+	resetRepo   repository.PasswordResetRepositoryInterface
+	authUsecase usecase.AuthUsecaseInterface
+	mailer      messaging.Mailer
+	cfg         *config.Configs
+	logger      *zap.Logger
+}
+
+// NewPasswordUsecase creates a new instance of PasswordUsecase
+func NewPasswordUsecase(userRepo repository.UserRepositoryInterface, resetRepo repository.PasswordResetRepositoryInterface, authUsecase usecase.AuthUsecaseInterface, mailer messaging.Mailer, cfg *config.Configs, logger *zap.Logger) usecase.PasswordUsecaseInterface {
+	return &PasswordUsecase{
+		userRepo:    userRepo,
+		resetRepo:   resetRepo,
+		authUsecase: authUsecase,
+		mailer:      mailer,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+// ForgotPassword emails a single-use reset token to the user with the given email
+// The outcome is the same whether or not the email exists, so callers cannot enumerate accounts;
+// the email is sent in the background so response times do not reveal it either
+func (u *PasswordUsecase) ForgotPassword(email string) error {
+	user, err := u.userRepo.FindByEmail(email)
+	if err != nil {
+		u.logger.Error("Failed to look up user", zap.Error(err), zap.String("operation", "forgot_password"))
+		return err
+	}
+	if user == nil {
+		u.logger.Info("Password reset requested for unknown email", zap.String("operation", "forgot_password"))
+		return nil
+	}
+
+	rawToken, err := generateOpaqueToken(32)
+	if err != nil {
+		u.logger.Error("Failed to generate reset token", zap.Error(err), zap.String("operation", "forgot_password"))
+		return err
+	}
+
+	// Only the most recent reset email stays usable
+	if err := u.resetRepo.InvalidateForUser(user.ID); err != nil {
+		return err
+	}
+	token := &model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(rawToken),
+		ExpiresAt: time.Now().Add(u.cfg.PasswordResetTTL),
+	}
+	if err := u.resetRepo.Create(token); err != nil {
+		return err
+	}
+
+	go u.sendResetEmail(user, rawToken)
+
+	u.logger.Info("Password reset requested", zap.Uint("user_id", user.ID), zap.String("operation", "forgot_password"))
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token and revokes every existing session
+func (u *PasswordUsecase) ResetPassword(rawToken, newPassword string) error {
+	token, err := u.resetRepo.FindByHash(hashToken(rawToken))
+	if err != nil {
+		return err
+	}
+	if token == nil || token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		u.logger.Warn("Invalid password reset token", zap.String("operation", "reset_password"))
+		return ErrInvalidResetToken
+	}
+
+	// Consuming the token first guarantees it can only be used once, even under concurrent requests
+	consumed, err := u.resetRepo.MarkUsed(token.ID)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		u.logger.Warn("Password reset token already used", zap.Uint("user_id", token.UserID), zap.String("operation", "reset_password"))
+		return ErrInvalidResetToken
+	}
+
+	user, err := u.userRepo.FindByID(token.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidResetToken
+	}
+
+	if err := u.setPassword(user, newPassword); err != nil {
+		return err
+	}
+	if err := u.resetRepo.InvalidateForUser(user.ID); err != nil {
+		u.logger.Warn("Failed to invalidate remaining reset tokens", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+
+	// Whoever may have stolen the old password loses their sessions
+	if err := u.authUsecase.RevokeAllSessions(user.ID); err != nil {
+		u.logger.Error("Failed to revoke sessions after password reset", zap.Uint("user_id", user.ID), zap.Error(err), zap.String("operation", "reset_password"))
+		return err
+	}
+
+	u.logger.Info("Password reset", zap.Uint("user_id", user.ID), zap.String("operation", "reset_password"))
+	return nil
+}
+
+// ChangePassword replaces the password of a logged-in user after checking the current one
+func (u *PasswordUsecase) ChangePassword(userID uint, currentPassword, newPassword string) error {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		u.logger.Warn("Incorrect current password", zap.Uint("user_id", userID), zap.String("operation", "change_password"))
+		return ErrIncorrectPassword
+	}
+
+	if err := u.setPassword(user, newPassword); err != nil {
+		return err
+	}
+
+	u.logger.Info("Password changed", zap.Uint("user_id", userID), zap.String("operation", "change_password"))
+	return nil
+}
+
+// setPassword hashes and stores a new password for the user
+func (u *PasswordUsecase) setPassword(user *model.User, newPassword string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		u.logger.Error("Failed to hash password", zap.Error(err), zap.String("operation", "set_password"))
+		return errors.New("failed to hash password")
+	}
+	user.Password = string(hashedPassword)
+	if err := u.userRepo.Update(user); err != nil {
+		u.logger.Error("Failed to store password", zap.Uint("user_id", user.ID), zap.Error(err), zap.String("operation", "set_password"))
+		return err
+	}
+	return nil
+}
+
+// sendResetEmail delivers the reset token to the user; failures are only logged
+func (u *PasswordUsecase) sendResetEmail(user *model.User, rawToken string) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+
+	validFor := u.cfg.PasswordResetTTL.Round(time.Minute)
+	textBody := fmt.Sprintf("Olá, %s,\n\nRecebemos uma solicitação para redefinir sua senha.\n\n", user.Name)
+	htmlBody := fmt.Sprintf("<p>Olá, %s,</p><p>Recebemos uma solicitação para redefinir sua senha.</p>", html.EscapeString(user.Name))
+	if u.cfg.PasswordResetURL != "" {
+		link := u.cfg.PasswordResetURL + "?token=" + url.QueryEscape(rawToken)
+		textBody += fmt.Sprintf("Acesse o link abaixo para escolher uma nova senha:\n%s\n\n", link)
+		htmlBody += fmt.Sprintf(`<p><a href="%s">Clique aqui para escolher uma nova senha</a></p>`, html.EscapeString(link))
+	}
+	textBody += fmt.Sprintf("Código de redefinição: %s\n\nO código é válido por %s e pode ser usado apenas uma vez.\nSe você não fez esta solicitação, ignore este email.\n\nAtenciosamente,\nEquipe de Produtos", rawToken, validFor)
+	htmlBody += fmt.Sprintf("<p>Código de redefinição: <code>%s</code></p><p>O código é válido por %s e pode ser usado apenas uma vez.<br>Se você não fez esta solicitação, ignore este email.</p><p>Atenciosamente,<br>Equipe de Produtos</p>", rawToken, validFor)
+
+	err := u.mailer.Send(ctx, &messaging.Email{
+		To:      []string{user.Email},
+		Subject: "Redefinição de senha",
+		Text:    textBody,
+		HTML:    htmlBody,
+	})
+	if err != nil {
+		u.logger.Error("Failed to send password reset email", zap.Uint("user_id", user.ID), zap.Error(err), zap.String("operation", "forgot_password"))
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
 	"github.com/Amandasilvbr/products-crud/internal/domain/model"
@@ -19,56 +21,54 @@ var (
 	ErrProductNotFound = errors.New("product not found")
 )
 
+// ProductEventsQueue is the queue of the product notifications, bound to the product events exchange
+const ProductEventsQueue = messaging.ProductEventsQueue
+
 // ProductUseCase implements the business logic for product-related operations
+// Product events are not published directly: they are stored in the outbox together with each change
+// and published by the outbox relay, so that no event is lost while RabbitMQ is unavailable
 type ProductUseCase struct {
 	productRepo repository.ProductRepositoryInterface
 	logger      *zap.Logger
-	rabbitMQ    messaging.Publisher
 }
 
 // NewProductUseCase creates a new instance of ProductUseCase
-func NewProductUseCase(repo repository.ProductRepositoryInterface, logger *zap.Logger, rabbitMQ messaging.Publisher) usecase.ProductUseCaseInterface {
+func NewProductUseCase(repo repository.ProductRepositoryInterface, logger *zap.Logger) usecase.ProductUseCaseInterface {
 	return &ProductUseCase{
 		productRepo: repo,
 		logger:      logger,
-		rabbitMQ:    rabbitMQ,
 	}
 }
 
 // Create handles the logic for creating new products
-func (uc *ProductUseCase) Create(ctx context.Context, products []*model.Product, userEmail string) (map[int]string, map[int]string) {
-    createErrors := uc.productRepo.Create(ctx, products)
-    publishErrors := make(map[int]string)
-
-    if len(createErrors) > 0 {
-        uc.logger.Warn("Failed to create some products", zap.Any("errors", createErrors), zap.Int("count", len(createErrors)))
-    }
+// The creation event of each product is committed with it
+func (uc *ProductUseCase) Create(ctx context.Context, products []*model.Product, actor *model.Actor) map[int]string {
+    userEmail := actor.Email
+    events := make(map[int]*model.OutboxEvent, len(products))
+    toCreate := make([]*model.Product, 0, len(products))
+    createErrors := make(map[int]string)
 
     for _, product := range products {
-        if _, exists := createErrors[product.SKU]; !exists {
-            // Verifica o contexto antes de publicar
-            if err := ctx.Err(); err != nil {
-                uc.logger.Error("Context cancelled before publishing", zap.Int("sku", product.SKU), zap.Error(err))
-                publishErrors[product.SKU] = fmt.Sprintf("Context cancelled: %s", err.Error())
-                continue
-            }
-
-            err := uc.publishToRabbitMQ(ctx, "product_created", product, userEmail)
-            if err != nil {
-                publishErrors[product.SKU] = fmt.Sprintf("Failed to publish to RabbitMQ: %v", err)
-                uc.logger.Error("Failed to publish product creation event", zap.Int("sku", product.SKU), zap.String("user_email", userEmail), zap.Error(err))
-                continue
-            }
-            uc.logger.Info("Published product creation event", zap.Int("sku", product.SKU), zap.String("user_email", userEmail))
+        event, err := uc.newEvent("product_created", product, nil, actor)
+        if err != nil {
+            createErrors[product.SKU] = err.Error()
+            continue
         }
+        events[product.SKU] = event
+        toCreate = append(toCreate, product)
     }
 
-    if len(createErrors) == 0 && len(publishErrors) == 0 {
-        uc.logger.Info("Created all products and published events successfully", zap.Int("count", len(products)), zap.String("operation", "create"))
-        return nil, nil
+    for sku, errMsg := range uc.productRepo.Create(ctx, toCreate, events) {
+        createErrors[sku] = errMsg
+    }
+
+    if len(createErrors) > 0 {
+        uc.logger.Warn("Failed to create some products", zap.Any("errors", createErrors), zap.Int("count", len(createErrors)))
+        return createErrors
     }
 
-    return createErrors, publishErrors
+    uc.logger.Info("Created all products and queued their events", zap.Int("count", len(products)), zap.String("user_email", userEmail), zap.String("operation", "create"))
+    return nil
 }
 
 // GetAll retrieves all products by calling the repository
@@ -98,9 +98,13 @@ func (uc *ProductUseCase) GetBySKU(ctx context.Context, sku int) (*model.Product
 }
 
 // Update handles the logic for updating existing products
-func (uc *ProductUseCase) Update(ctx context.Context, products []*model.Product, userEmail string) map[int]string {
+// Products the actor is not allowed to modify are reported as forbidden
+func (uc *ProductUseCase) Update(ctx context.Context, products []*model.Product, actor *model.Actor) map[int]string {
+	userEmail := actor.Email
 	// Store products to update and collect errors
 	productsToUpdate := make(map[int]*model.Product)
+	// Keep the current version of each product for the changes listed in its event
+	previous := make(map[int]*model.Product)
 	errors := make(map[int]string)
 
 	// Verify the existence of all products before attempting to update them
@@ -111,8 +115,16 @@ func (uc *ProductUseCase) Update(ctx context.Context, products []*model.Product,
 			errors[product.SKU] = fmt.Sprintf("Product with SKU %d not found", product.SKU)
 			continue 
 		}
+		// Editors may only change the products they created
+		if !actor.CanModify(existingProduct) {
+			uc.logger.Warn("Actor not allowed to update product", zap.Int("sku", product.SKU), zap.String("user_email", userEmail), zap.String("role", actor.OrgRole), zap.String("operation", "update"))
+			errors[product.SKU] = fmt.Sprintf("Forbidden: not allowed to modify product with SKU %d", product.SKU)
+			continue
+		}
+		previous[product.SKU] = existingProduct
 		// Use the existing product's metadata (e.g., CreatedAt, CreatedBy) and update only provided fields
 		updatedProduct := &model.Product{
+			OrgID:        existingProduct.OrgID,
 			SKU:          product.SKU,
 			Name:         product.Name,
 			Description:  product.Description,
@@ -123,6 +135,7 @@ func (uc *ProductUseCase) Update(ctx context.Context, products []*model.Product,
 			Availability: product.Availability,
 			CreatedAt:    existingProduct.CreatedAt,
 			CreatedBy:    existingProduct.CreatedBy,
+			CreatedByID:  existingProduct.CreatedByID,
 			UpdatedAt:    product.UpdatedAt, 
 		}
 		productsToUpdate[product.SKU] = updatedProduct
@@ -135,20 +148,25 @@ func (uc *ProductUseCase) Update(ctx context.Context, products []*model.Product,
 			validProducts = append(validProducts, product)
 		}
 
+		// Prepare the update event of each product, committed with the change
+		events := make(map[int]*model.OutboxEvent, len(validProducts))
+		toUpdate := make([]*model.Product, 0, len(validProducts))
+		for _, product := range validProducts {
+			event, err := uc.newEvent("product_updated", product, previous[product.SKU], actor)
+			if err != nil {
+				errors[product.SKU] = err.Error()
+				continue
+			}
+			events[product.SKU] = event
+			toUpdate = append(toUpdate, product)
+		}
+
 		// Call the repository to update the products
-		updateErrors := uc.productRepo.Update(ctx, validProducts)
+		updateErrors := uc.productRepo.Update(ctx, toUpdate, events)
 		for sku, errMsg := range updateErrors {
 			errors[sku] = errMsg
 			uc.logger.Warn("Failed to update product", zap.Int("sku", sku), zap.String("error", errMsg))
 		}
-
-		// Publish messages for successfully updated products
-		for _, product := range validProducts {
-			if _, exists := updateErrors[product.SKU]; !exists {
-				uc.publishToRabbitMQ(ctx, "product_updated", product, userEmail)
-				uc.logger.Info("Published product update event", zap.Int("sku", product.SKU), zap.String("user_email", userEmail))
-			}
-		}
 	}
 
 	if len(errors) == 0 {
@@ -161,7 +179,9 @@ func (uc *ProductUseCase) Update(ctx context.Context, products []*model.Product,
 }
 
 // Delete handles the logic for deleting products
-func (uc *ProductUseCase) Delete(ctx context.Context, skus []int, userEmail string) map[int]string {
+// Products the actor is not allowed to modify are reported as forbidden
+func (uc *ProductUseCase) Delete(ctx context.Context, skus []int, actor *model.Actor) map[int]string {
+	userEmail := actor.Email
 	// Store products to publish deletion events after successful deletion
 	productsToDelete := make(map[int]*model.Product)
 	errors := make(map[int]string)
@@ -174,6 +194,12 @@ func (uc *ProductUseCase) Delete(ctx context.Context, skus []int, userEmail stri
 			errors[sku] = fmt.Sprintf("Product with SKU %d not found", sku)
 			continue // Continua processando os outros SKUs
 		}
+		// Editors may only delete the products they created
+		if !actor.CanModify(product) {
+			uc.logger.Warn("Actor not allowed to delete product", zap.Int("sku", sku), zap.String("user_email", userEmail), zap.String("role", actor.OrgRole), zap.String("operation", "delete"))
+			errors[sku] = fmt.Sprintf("Forbidden: not allowed to modify product with SKU %d", sku)
+			continue
+		}
 		productsToDelete[sku] = product
 	}
 
@@ -184,20 +210,25 @@ func (uc *ProductUseCase) Delete(ctx context.Context, skus []int, userEmail stri
 			validSKUs = append(validSKUs, sku)
 		}
 
+		// Prepare the deletion event of each product, committed with the change
+		events := make(map[int]*model.OutboxEvent, len(validSKUs))
+		toDelete := make([]int, 0, len(validSKUs))
+		for _, sku := range validSKUs {
+			event, err := uc.newEvent("product_deleted", productsToDelete[sku], nil, actor)
+			if err != nil {
+				errors[sku] = err.Error()
+				continue
+			}
+			events[sku] = event
+			toDelete = append(toDelete, sku)
+		}
+
 		// Call the repository to delete valid products
-		deleteErrors := uc.productRepo.Delete(ctx, validSKUs)
+		deleteErrors := uc.productRepo.Delete(ctx, toDelete, events)
 		for sku, errMsg := range deleteErrors {
 			errors[sku] = errMsg
 			uc.logger.Warn("Failed to delete product", zap.Int("sku", sku), zap.String("error", errMsg))
 		}
-
-		// Publish events for successfully deleted products
-		for _, sku := range validSKUs {
-			if _, exists := deleteErrors[sku]; !exists {
-				uc.publishToRabbitMQ(ctx, "product_deleted", productsToDelete[sku], userEmail)
-				uc.logger.Info("Published product deletion event", zap.Int("sku", sku), zap.String("user_email", userEmail))
-			}
-		}
 	}
 
 	if len(errors) == 0 {
@@ -209,25 +240,39 @@ func (uc *ProductUseCase) Delete(ctx context.Context, skus []int, userEmail stri
 	return errors
 }
 
-// publishToRabbitMQ is a helper function to marshal and send product event messages
-func (uc *ProductUseCase) publishToRabbitMQ(ctx context.Context, event string, product *model.Product, userEmail string) error {
-    msg, err := json.Marshal(map[string]interface{}{
-        "event":             event,
-        "sku":               product.SKU,
-        "name":              product.Name,
-        "responsible_email": userEmail,
-    })
-    if err != nil {
-        uc.logger.Error("Failed to marshal RabbitMQ message", zap.Int("sku", product.SKU), zap.String("event", event), zap.Error(err))
-        return fmt.Errorf("failed to marshal message: %w", err)
+// newEvent is a helper function that builds the outbox event of a product change
+// The event is a CloudEvent carrying the product snapshot, the changes when the previous version is
+// given, and the organization of the product so that consumers can route them per tenant
+func (uc *ProductUseCase) newEvent(event string, product, previous *model.Product, actor *model.Actor) (*model.OutboxEvent, error) {
+    data := messaging.ProductEventData{
+        Product:          messaging.NewProductSnapshot(product),
+        ResponsibleEmail: actor.Email,
+        OrgID:            actor.OrgID,
+        OrgName:          actor.OrgName,
+    }
+    if previous != nil {
+        data.Changes = messaging.DiffProducts(previous, product)
     }
 
-    err = uc.rabbitMQ.Publish(ctx, "product_events", string(msg))
+    id, err := newEventID()
     if err != nil {
-        uc.logger.Error("Failed to publish to RabbitMQ", zap.Int("sku", product.SKU), zap.String("event", event), zap.String("message", string(msg)), zap.Error(err))
-        return fmt.Errorf("failed to publish to RabbitMQ: %w", err)
+        uc.logger.Error("Failed to generate product event ID", zap.Int("sku", product.SKU), zap.String("event", event), zap.Error(err))
+        return nil, fmt.Errorf("failed to generate event ID: %w", err)
+    }
+    cloudEvent, err := messaging.NewCloudEvent(id, messaging.ProductEventSource(actor.OrgID), messaging.ProductEventType(event), strconv.Itoa(product.SKU), messaging.ProductEventSchema, time.Now(), data)
+    var msg []byte
+    if err == nil {
+        msg, err = json.Marshal(cloudEvent)
+    }
+    if err != nil {
+        uc.logger.Error("Failed to marshal product event", zap.Int("sku", product.SKU), zap.String("event", event), zap.Error(err))
+        return nil, fmt.Errorf("failed to marshal message: %w", err)
     }
 
-    uc.logger.Info("Successfully published to RabbitMQ", zap.Int("sku", product.SKU), zap.String("event", event), zap.String("message", string(msg)))
-    return nil
+    return &model.OutboxEvent{
+        Exchange:   messaging.ProductEventsExchange,
+        RoutingKey: messaging.ProductRoutingKey(event, product.Category),
+        Event:      event,
+        Payload:    string(msg),
+    }, nil
 }
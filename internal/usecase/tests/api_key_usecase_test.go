@@ -0,0 +1,126 @@
+package usecase_test
+
+import (
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    ucdomain "github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/zap"
+)
+
+// mockAPIKeyRepo is an in-memory implementation of the API key repository for testing purposes
+type mockAPIKeyRepo struct {
+    keys []*model.APIKey
+}
+
+func (m *mockAPIKeyRepo) Create(key *model.APIKey) error {
+    key.ID = uint(len(m.keys) + 1)
+    m.keys = append(m.keys, key)
+    return nil
+}
+
+func (m *mockAPIKeyRepo) FindByHash(keyHash string) (*model.APIKey, error) {
+    for _, k := range m.keys {
+        if k.KeyHash == keyHash {
+            return k, nil
+        }
+    }
+    return nil, nil
+}
+
+func (m *mockAPIKeyRepo) FindByID(id uint) (*model.APIKey, error) {
+    if id == 0 || int(id) > len(m.keys) {
+        return nil, nil
+    }
+    return m.keys[id-1], nil
+}
+
+func (m *mockAPIKeyRepo) List() ([]*model.APIKey, error) {
+    return m.keys, nil
+}
+
+func (m *mockAPIKeyRepo) Revoke(id uint) error {
+    now := time.Now()
+    m.keys[id-1].RevokedAt = &now
+    return nil
+}
+
+func (m *mockAPIKeyRepo) TouchLastUsed(id uint, usedAt time.Time) error {
+    m.keys[id-1].LastUsedAt = &usedAt
+    return nil
+}
+
+// newAPIKeyTestUsecase returns an API key use case whose only user is an editor with ID 1, member of the organization 1
+func newAPIKeyTestUsecase() (*mockAPIKeyRepo, ucdomain.APIKeyUsecaseInterface) {
+    owner := &model.User{Name: "ERP", Email: "erp@test.com", Role: model.RoleEditor}
+    owner.ID = 1
+    repo := &mockAPIKeyRepo{}
+    userRepo := &mockUserRepo{user: owner}
+    orgs := usecase.NewOrganizationUsecase(newMockOrgRepo(map[uint]string{1: model.RoleEditor}), userRepo, zap.NewNop())
+    return repo, usecase.NewAPIKeyUsecase(repo, userRepo, orgs, zap.NewNop())
+}
+
+// TestAPIKeys tests creation, authentication and revocation of API keys
+func TestAPIKeys(t *testing.T) {
+    // Subtest: A created key authenticates as its owner and is stored hashed
+    t.Run("CreateAndAuthenticate", func(t *testing.T) {
+        repo, apiKeyUC := newAPIKeyTestUsecase()
+
+        key, rawKey, err := apiKeyUC.CreateKey("erp", []string{model.ScopeProductsRead, model.ScopeProductsWrite}, nil, 1, 1, 1)
+        assert.NoError(t, err)
+        assert.True(t, strings.HasPrefix(rawKey, "pck_"))
+        assert.NotEqual(t, rawKey, repo.keys[0].KeyHash)
+        assert.True(t, strings.HasPrefix(rawKey, key.Prefix))
+
+        actor, authKey, err := apiKeyUC.Authenticate(rawKey)
+
+        assert.NoError(t, err)
+        assert.Equal(t, "erp@test.com", actor.Email)
+        assert.Equal(t, "ERP", actor.Name)
+        assert.Equal(t, uint(1), actor.OrgID)
+        assert.Equal(t, "Acme", actor.OrgName)
+        assert.Equal(t, model.RoleEditor, actor.OrgRole)
+        assert.Equal(t, []string{model.ScopeProductsRead, model.ScopeProductsWrite}, authKey.ScopeList())
+        assert.NotNil(t, repo.keys[0].LastUsedAt)
+    })
+
+    // Subtest: Revoked keys no longer authenticate
+    t.Run("Revoked", func(t *testing.T) {
+        _, apiKeyUC := newAPIKeyTestUsecase()
+        key, rawKey, _ := apiKeyUC.CreateKey("erp", []string{model.ScopeProductsRead}, nil, 1, 1, 1)
+
+        assert.NoError(t, apiKeyUC.RevokeKey(key.ID))
+        _, _, err := apiKeyUC.Authenticate(rawKey)
+
+        assert.ErrorIs(t, err, usecase.ErrInvalidAPIKey)
+    })
+
+    // Subtest: Expired keys no longer authenticate
+    t.Run("Expired", func(t *testing.T) {
+        _, apiKeyUC := newAPIKeyTestUsecase()
+        expiresAt := time.Now().Add(-time.Minute)
+        _, rawKey, _ := apiKeyUC.CreateKey("erp", []string{model.ScopeProductsRead}, &expiresAt, 1, 1, 1)
+
+        _, _, err := apiKeyUC.Authenticate(rawKey)
+
+        assert.ErrorIs(t, err, usecase.ErrInvalidAPIKey)
+    })
+
+    // Subtest: Unknown scopes and owners are rejected
+    t.Run("InvalidInput", func(t *testing.T) {
+        _, apiKeyUC := newAPIKeyTestUsecase()
+
+        _, _, err := apiKeyUC.CreateKey("erp", []string{"products:admin"}, nil, 1, 1, 1)
+        assert.ErrorIs(t, err, usecase.ErrInvalidScope)
+
+        _, _, err = apiKeyUC.CreateKey("erp", []string{model.ScopeProductsRead}, nil, 99, 1, 1)
+        assert.ErrorIs(t, err, usecase.ErrInvalidKeyOwner)
+
+        _, _, err = apiKeyUC.CreateKey("erp", []string{model.ScopeProductsRead}, nil, 1, 2, 1)
+        assert.ErrorIs(t, err, usecase.ErrOrgNotFound)
+    })
+}
@@ -0,0 +1,115 @@
+package usecase_test
+
+import (
+    "context"
+    "fmt"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/rabbitmq/amqp091-go"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+)
+
+// mockDeadLetterQueues is a mock implementation of the dead-letter queues of the broker
+type mockDeadLetterQueues struct {
+    mock.Mock
+}
+
+func (m *mockDeadLetterQueues) Peek(ctx context.Context, queueName string, limit int) ([]messaging.DeadLetter, error) {
+    args := m.Called(ctx, queueName, limit)
+    letters, _ := args.Get(0).([]messaging.DeadLetter)
+    return letters, args.Error(1)
+}
+
+func (m *mockDeadLetterQueues) Requeue(ctx context.Context, queueName string, limit int) (int, error) {
+    args := m.Called(ctx, queueName, limit)
+    return args.Int(0), args.Error(1)
+}
+
+func (m *mockDeadLetterQueues) Purge(ctx context.Context, queueName string) (int, error) {
+    args := m.Called(ctx, queueName)
+    return args.Int(0), args.Error(1)
+}
+
+// TestDeadLetterUsecase tests the administration of the dead-letter queues
+func TestDeadLetterUsecase(t *testing.T) {
+    ctx := context.Background()
+
+    // Subtest: Only the queues consumed by the application can be administered
+    t.Run("UnknownQueue", func(t *testing.T) {
+        queues := &mockDeadLetterQueues{}
+        uc := usecase.NewDeadLetterUsecase(queues, zap.NewNop())
+
+        _, err := uc.Peek(ctx, "amq.rabbitmq.log", 10)
+        assert.ErrorIs(t, err, usecase.ErrUnknownQueue)
+        _, err = uc.Requeue(ctx, "product_events.dlq", 0)
+        assert.ErrorIs(t, err, usecase.ErrUnknownQueue)
+        _, err = uc.Purge(ctx, "")
+        assert.ErrorIs(t, err, usecase.ErrUnknownQueue)
+        assert.ElementsMatch(t, []string{"product_events", messaging.AccountEventsQueue}, uc.Queues())
+        queues.AssertNotCalled(t, "Peek", mock.Anything, mock.Anything, mock.Anything)
+        queues.AssertNotCalled(t, "Requeue", mock.Anything, mock.Anything, mock.Anything)
+        queues.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+    })
+
+    // Subtest: Peek defaults and caps the number of messages returned
+    t.Run("PeekLimits", func(t *testing.T) {
+        queues := &mockDeadLetterQueues{}
+        uc := usecase.NewDeadLetterUsecase(queues, zap.NewNop())
+        letter := messaging.DeadLetter{MessageID: "abc", Body: `{"event":"product_created"}`, Attempts: 5, LastError: "connection refused"}
+        queues.On("Peek", ctx, "product_events", usecase.DefaultDeadLetterPeekLimit).Return([]messaging.DeadLetter{letter}, nil).Once()
+        queues.On("Peek", ctx, "product_events", usecase.MaxDeadLetterPeekLimit).Return([]messaging.DeadLetter{}, nil).Once()
+
+        letters, err := uc.Peek(ctx, "product_events", 0)
+        require.NoError(t, err)
+        assert.Equal(t, []messaging.DeadLetter{letter}, letters)
+        _, err = uc.Peek(ctx, "product_events", 1000)
+        require.NoError(t, err)
+        _, err = uc.Peek(ctx, "product_events", -1)
+        assert.ErrorIs(t, err, usecase.ErrInvalidLimit)
+        queues.AssertExpectations(t)
+    })
+
+    // Subtest: Requeue and purge report how many messages were moved or removed
+    t.Run("RequeueAndPurge", func(t *testing.T) {
+        queues := &mockDeadLetterQueues{}
+        uc := usecase.NewDeadLetterUsecase(queues, zap.NewNop())
+        queues.On("Requeue", ctx, messaging.AccountEventsQueue, 0).Return(3, nil).Once()
+        queues.On("Requeue", ctx, "product_events", 2).Return(1, fmt.Errorf("channel closed")).Once()
+        queues.On("Purge", ctx, "product_events").Return(7, nil).Once()
+
+        moved, err := uc.Requeue(ctx, messaging.AccountEventsQueue, 0)
+        require.NoError(t, err)
+        assert.Equal(t, 3, moved)
+        moved, err = uc.Requeue(ctx, "product_events", 2)
+        assert.Error(t, err)
+        assert.Equal(t, 1, moved)
+        _, err = uc.Requeue(ctx, "product_events", -5)
+        assert.ErrorIs(t, err, usecase.ErrInvalidLimit)
+        purged, err := uc.Purge(ctx, "product_events")
+        require.NoError(t, err)
+        assert.Equal(t, 7, purged)
+        queues.AssertExpectations(t)
+    })
+}
+
+// TestRetryPolicy tests when failed messages are retried or dead-lettered
+func TestRetryPolicy(t *testing.T) {
+    policy := messaging.RetryPolicy{MaxAttempts: 3, Delay: 30 * time.Second}
+
+    assert.False(t, policy.ShouldDeadLetter(1, false))
+    assert.False(t, policy.ShouldDeadLetter(2, false))
+    assert.True(t, policy.ShouldDeadLetter(3, false))
+    // Permanent failures are never retried
+    assert.True(t, policy.ShouldDeadLetter(1, true))
+
+    // The attempt count travels in a header, whatever integer type the broker decoded it as
+    assert.Equal(t, 0, messaging.Attempts(amqp091.Delivery{}))
+    assert.Equal(t, 2, messaging.Attempts(amqp091.Delivery{Headers: amqp091.Table{messaging.AttemptsHeader: int32(2)}}))
+    assert.Equal(t, 4, messaging.Attempts(amqp091.Delivery{Headers: amqp091.Table{messaging.AttemptsHeader: int64(4)}}))
+}
@@ -0,0 +1,281 @@
+package usecase_test
+
+import (
+    "context"
+    "errors"
+    "net/textproto"
+    "slices"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/config"
+    "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+)
+
+// mockEmailDeliveryRepo is an in-memory implementation of the email delivery repository for testing purposes
+type mockEmailDeliveryRepo struct {
+    mu         sync.Mutex
+    deliveries []*model.EmailDelivery
+}
+
+func (m *mockEmailDeliveryRepo) RecordAttempt(ctx context.Context, attempt *model.EmailDelivery) (*model.EmailDelivery, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for _, d := range m.deliveries {
+        if d.DeliveryKey == attempt.DeliveryKey {
+            d.Attempts++
+            d.Status, d.ResponseCode, d.Response, d.LastAttemptAt = attempt.Status, attempt.ResponseCode, attempt.Response, attempt.LastAttemptAt
+            if d.SentAt == nil {
+                d.SentAt = attempt.SentAt
+            }
+            copied := *d
+            return &copied, nil
+        }
+    }
+    stored := *attempt
+    stored.ID = uint(len(m.deliveries) + 1)
+    m.deliveries = append(m.deliveries, &stored)
+    copied := stored
+    return &copied, nil
+}
+
+func (m *mockEmailDeliveryRepo) Search(filter model.EmailDeliveryFilter, offset, limit int) ([]*model.EmailDelivery, int64, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    var matches []*model.EmailDelivery
+    for i := len(m.deliveries) - 1; i >= 0; i-- {
+        d := m.deliveries[i]
+        if filter.Recipient != "" && !strings.Contains(strings.ToLower(d.Recipients), strings.ToLower(filter.Recipient)) {
+            continue
+        }
+        if filter.EventID != "" && !slices.Contains(strings.Split(d.EventIDs, ","), filter.EventID) {
+            continue
+        }
+        if (filter.Kind != "" && d.Kind != filter.Kind) || (filter.Status != "" && d.Status != filter.Status) {
+            continue
+        }
+        matches = append(matches, d)
+    }
+    total := int64(len(matches))
+    if offset >= len(matches) {
+        return nil, total, nil
+    }
+    return matches[offset:min(offset+limit, len(matches))], total, nil
+}
+
+func (m *mockEmailDeliveryRepo) FindByID(id uint) (*model.EmailDelivery, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for _, d := range m.deliveries {
+        if d.ID == id {
+            copied := *d
+            return &copied, nil
+        }
+    }
+    return nil, nil
+}
+
+func (m *mockEmailDeliveryRepo) RedactBefore(ctx context.Context, before time.Time) (int64, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    var redacted int64
+    now := time.Now()
+    for _, d := range m.deliveries {
+        if d.RedactedAt == nil && d.LastAttemptAt.Before(before) {
+            d.Recipients, d.Subject, d.Text, d.HTML, d.Response, d.RedactedAt = "", "", "", "", "", &now
+            redacted++
+        }
+    }
+    return redacted, nil
+}
+
+func (m *mockEmailDeliveryRepo) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    kept := m.deliveries[:0]
+    for _, d := range m.deliveries {
+        if !d.LastAttemptAt.Before(before) {
+            kept = append(kept, d)
+        }
+    }
+    deleted := int64(len(m.deliveries) - len(kept))
+    m.deliveries = kept
+    return deleted, nil
+}
+
+// scriptedMailer fails with the given errors in turn, then accepts every email, keeping what it was asked to send
+type scriptedMailer struct {
+    mu   sync.Mutex
+    errs []error
+    sent []*messaging.Email
+}
+
+func (m *scriptedMailer) Send(ctx context.Context, msg *messaging.Email) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.sent = append(m.sent, msg)
+    if len(m.errs) == 0 {
+        return nil
+    }
+    err := m.errs[0]
+    m.errs = m.errs[1:]
+    return err
+}
+
+// TestEmailDeliveryUsecase tests the email delivery log
+func TestEmailDeliveryUsecase(t *testing.T) {
+    ctx := context.Background()
+    cfg := &config.Configs{EmailLogRedactAfter: 30 * 24 * time.Hour, EmailLogRetention: 90 * 24 * time.Hour}
+    notification := func(eventIDs ...string) *messaging.Email {
+        return &messaging.Email{
+            To:       []string{"amanda@test.com"},
+            Subject:  "Resumo de Notificações de Produtos",
+            Text:     "Olá, Amanda",
+            HTML:     "<p>Olá, Amanda</p>",
+            Kind:     messaging.EmailKindProductNotification,
+            EventIDs: eventIDs,
+        }
+    }
+
+    // Subtest: Attempts to send the same email are counted in one delivery with the last SMTP reply
+    t.Run("RecordsAttempts", func(t *testing.T) {
+        repo := &mockEmailDeliveryRepo{}
+        mailer := &scriptedMailer{errs: []error{&textproto.Error{Code: 451, Msg: "mailbox busy"}}}
+        deliveries := usecase.NewEmailDeliveryUsecase(repo, mailer, cfg, zap.NewNop())
+
+        err := deliveries.Send(ctx, notification("evt-1", "evt-2"))
+        require.Error(t, err)
+        require.Len(t, repo.deliveries, 1)
+        assert.Equal(t, model.EmailDeliveryFailed, repo.deliveries[0].Status)
+        assert.Equal(t, 451, repo.deliveries[0].ResponseCode)
+        assert.Contains(t, repo.deliveries[0].Response, "mailbox busy")
+        assert.Nil(t, repo.deliveries[0].SentAt)
+
+        // A redelivery may gather the same events in another order
+        require.NoError(t, deliveries.Send(ctx, notification("evt-2", "evt-1")))
+        require.Len(t, repo.deliveries, 1)
+        delivery := repo.deliveries[0]
+        assert.Equal(t, 2, delivery.Attempts)
+        assert.Equal(t, model.EmailDeliverySent, delivery.Status)
+        // The reply to an accepted email is not known, so the refusal of the earlier attempt is cleared
+        assert.Equal(t, 0, delivery.ResponseCode)
+        assert.Empty(t, delivery.Response)
+        assert.NotNil(t, delivery.SentAt)
+        assert.Equal(t, "amanda@test.com", delivery.Recipients)
+        assert.Equal(t, "evt-1,evt-2", delivery.EventIDs)
+        assert.Equal(t, "<p>Olá, Amanda</p>", delivery.HTML)
+
+        // Other events make another email, and emails without a kind are sent without being recorded
+        require.NoError(t, deliveries.Send(ctx, notification("evt-3")))
+        require.NoError(t, deliveries.Send(ctx, &messaging.Email{To: []string{"amanda@test.com"}, Subject: "Redefinição de senha", Text: "token"}))
+        assert.Len(t, repo.deliveries, 2)
+        assert.Len(t, mailer.sent, 4)
+    })
+
+    // Subtest: The log is searched by recipient, event and status
+    t.Run("Search", func(t *testing.T) {
+        repo := &mockEmailDeliveryRepo{}
+        deliveries := usecase.NewEmailDeliveryUsecase(repo, &scriptedMailer{errs: []error{errors.New("connection refused")}}, cfg, zap.NewNop())
+        require.Error(t, deliveries.Send(ctx, notification("evt-1")))
+        require.NoError(t, deliveries.Send(ctx, notification("evt-2", "evt-3")))
+
+        found, total, err := deliveries.Search(model.EmailDeliveryFilter{EventID: "evt-3"}, 1, 20)
+        require.NoError(t, err)
+        assert.Equal(t, int64(1), total)
+        assert.Equal(t, "evt-2,evt-3", found[0].EventIDs)
+
+        found, total, err = deliveries.Search(model.EmailDeliveryFilter{Recipient: "AMANDA", Status: model.EmailDeliveryFailed}, 1, 20)
+        require.NoError(t, err)
+        assert.Equal(t, int64(1), total)
+        assert.Equal(t, 0, found[0].ResponseCode)
+        assert.Equal(t, "connection refused", found[0].Response)
+
+        _, _, err = deliveries.Search(model.EmailDeliveryFilter{Status: "bounced"}, 1, 20)
+        assert.ErrorIs(t, err, usecase.ErrInvalidEmailDeliveryStatus)
+    })
+
+    // Subtest: A resend sends the recorded content again as a new delivery linked to the original
+    t.Run("Resend", func(t *testing.T) {
+        repo := &mockEmailDeliveryRepo{}
+        mailer := &scriptedMailer{}
+        deliveries := usecase.NewEmailDeliveryUsecase(repo, mailer, cfg, zap.NewNop())
+        require.NoError(t, deliveries.Send(ctx, notification("evt-1")))
+
+        resent, err := deliveries.Resend(ctx, 1)
+        require.NoError(t, err)
+        assert.Equal(t, uint(2), resent.ID)
+        require.NotNil(t, resent.ResendOf)
+        assert.Equal(t, uint(1), *resent.ResendOf)
+        assert.Equal(t, 1, resent.Attempts)
+        require.Len(t, mailer.sent, 2)
+        assert.Equal(t, mailer.sent[0].To, mailer.sent[1].To)
+        assert.Equal(t, mailer.sent[0].HTML, mailer.sent[1].HTML)
+        assert.Equal(t, []string{"evt-1"}, mailer.sent[1].EventIDs)
+
+        _, err = deliveries.Resend(ctx, 9)
+        assert.ErrorIs(t, err, usecase.ErrEmailDeliveryNotFound)
+
+        // A refused resend is recorded and reported
+        mailer.errs = []error{&textproto.Error{Code: 550, Msg: "no such user"}}
+        failed, err := deliveries.Resend(ctx, 1)
+        require.Error(t, err)
+        assert.Equal(t, model.EmailDeliveryFailed, failed.Status)
+        assert.Equal(t, 550, failed.ResponseCode)
+    })
+
+    // Subtest: Recipients, subject, content and response are redacted after the delay and deliveries removed after the retention
+    t.Run("Retention", func(t *testing.T) {
+        repo := &mockEmailDeliveryRepo{}
+        refused := &textproto.Error{Code: 550, Msg: "<amanda@test.com>: mailbox unavailable"}
+        deliveries := usecase.NewEmailDeliveryUsecase(repo, &scriptedMailer{errs: []error{nil, refused}}, cfg, zap.NewNop())
+        for _, id := range []string{"recent", "old", "expired"} {
+            deliveries.Send(ctx, notification(id))
+        }
+        repo.deliveries[1].LastAttemptAt = time.Now().Add(-40 * 24 * time.Hour)
+        repo.deliveries[2].LastAttemptAt = time.Now().Add(-100 * 24 * time.Hour)
+
+        stopped, cancel := context.WithCancel(ctx)
+        cancel()
+        require.NoError(t, deliveries.RunRetention(stopped))
+        require.Len(t, repo.deliveries, 2)
+        assert.Equal(t, "amanda@test.com", repo.deliveries[0].Recipients)
+        assert.Nil(t, repo.deliveries[0].RedactedAt)
+
+        old := repo.deliveries[1]
+        assert.NotNil(t, old.RedactedAt)
+        assert.Empty(t, old.Recipients)
+        assert.Empty(t, old.Subject)
+        assert.Empty(t, old.HTML)
+        assert.Empty(t, old.Response)
+        assert.Equal(t, "old", old.EventIDs)
+        assert.Equal(t, 550, old.ResponseCode)
+
+        _, err := deliveries.Resend(ctx, old.ID)
+        assert.ErrorIs(t, err, usecase.ErrEmailDeliveryRedacted)
+    })
+
+    // Subtest: Notification emails carry their kind and the IDs of their events, which digest entries keep
+    t.Run("NotificationEmails", func(t *testing.T) {
+        uc, repo, _, _ := newNotificationTestSetup(t)
+        _, err := uc.UpdatePreferences(1, model.NotificationSettings{Preferences: map[string]string{model.EventProductDeleted: model.FrequencyDaily}})
+        require.NoError(t, err)
+
+        _, email, err := uc.Route(ctx, "amanda@test.com", []model.ProductNotification{
+            {Event: model.EventProductCreated, SKU: 1, Name: "Caneta", OrgName: "Acme", EventID: "evt-1"},
+            {Event: model.EventProductDeleted, SKU: 3, Name: "Borracha", OrgName: "Acme", EventID: "evt-3"},
+        })
+        require.NoError(t, err)
+        require.NotNil(t, email)
+        assert.Equal(t, messaging.EmailKindProductNotification, email.Kind)
+        assert.Equal(t, []string{"evt-1"}, email.EventIDs)
+        require.Equal(t, 1, repo.pending())
+        assert.Equal(t, "evt-3", repo.entries[0].EventID)
+    })
+}
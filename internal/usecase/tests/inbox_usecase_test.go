@@ -0,0 +1,176 @@
+package usecase_test
+
+import (
+    "context"
+    "sort"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    ucdomain "github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+)
+
+// mockInboxRepo is an in-memory implementation of the inbox repository for testing purposes
+type mockInboxRepo struct {
+    mu            sync.Mutex
+    notifications []*model.InboxNotification
+}
+
+func (m *mockInboxRepo) Add(ctx context.Context, notifications []*model.InboxNotification) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for _, notification := range notifications {
+        notification.ID = uint(len(m.notifications) + 1)
+        if notification.CreatedAt.IsZero() {
+            notification.CreatedAt = time.Now()
+        }
+        m.notifications = append(m.notifications, notification)
+    }
+    return nil
+}
+
+func (m *mockInboxRepo) List(userID uint, unreadOnly bool, offset, limit int) ([]*model.InboxNotification, int64, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    var matches []*model.InboxNotification
+    for _, notification := range m.notifications {
+        if notification.UserID == userID && (!unreadOnly || notification.ReadAt == nil) {
+            matches = append(matches, notification)
+        }
+    }
+    sort.SliceStable(matches, func(i, j int) bool { return matches[i].CreatedAt.After(matches[j].CreatedAt) })
+    total := int64(len(matches))
+    if offset >= len(matches) {
+        return nil, total, nil
+    }
+    matches = matches[offset:]
+    if len(matches) > limit {
+        matches = matches[:limit]
+    }
+    return matches, total, nil
+}
+
+func (m *mockInboxRepo) CountUnread(userID uint) (int64, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    var count int64
+    for _, notification := range m.notifications {
+        if notification.UserID == userID && notification.ReadAt == nil {
+            count++
+        }
+    }
+    return count, nil
+}
+
+func (m *mockInboxRepo) SetReadAt(userID, id uint, readAt *time.Time) (*model.InboxNotification, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for _, notification := range m.notifications {
+        if notification.ID == id && notification.UserID == userID {
+            notification.ReadAt = readAt
+            return notification, nil
+        }
+    }
+    return nil, nil
+}
+
+func (m *mockInboxRepo) MarkAllRead(userID uint, readAt time.Time) (int64, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    var updated int64
+    for _, notification := range m.notifications {
+        if notification.UserID == userID && notification.ReadAt == nil {
+            notification.ReadAt = &readAt
+            updated++
+        }
+    }
+    return updated, nil
+}
+
+// newInboxTestSetup builds an InboxUsecase around a single user
+func newInboxTestSetup() (ucdomain.InboxUsecaseInterface, *mockInboxRepo, *mockUserRepo) {
+    user := &model.User{Name: "Amanda", Email: "amanda@test.com", Role: model.RoleEditor}
+    user.ID = 1
+    repo := &mockInboxRepo{}
+    userRepo := &mockUserRepo{user: user}
+    return usecase.NewInboxUsecase(repo, userRepo, zap.NewNop()), repo, userRepo
+}
+
+// TestInboxUsecase tests the in-app notification inbox
+func TestInboxUsecase(t *testing.T) {
+    ctx := context.Background()
+    now := time.Now()
+    notifications := []model.ProductNotification{
+        {Event: model.EventProductCreated, SKU: 1, Name: "Caneta", OrgName: "Acme", At: now.Add(-2 * time.Minute)},
+        {Event: model.EventProductUpdated, SKU: 2, Name: "Lápis", OrgName: "Acme", At: now.Add(-time.Minute)},
+        {Event: model.EventProductDeleted, SKU: 3, Name: "Borracha", OrgName: "Acme", At: now},
+    }
+
+    // Subtest: Notifications are stored unread and listed newest first, with paging and the unread filter
+    t.Run("NotifyAndList", func(t *testing.T) {
+        uc, _, _ := newInboxTestSetup()
+        require.NoError(t, uc.Notify(ctx, "amanda@test.com", notifications))
+
+        items, total, unread, err := uc.List(1, false, 1, 2)
+        require.NoError(t, err)
+        assert.Equal(t, int64(3), total)
+        assert.Equal(t, int64(3), unread)
+        require.Len(t, items, 2)
+        assert.Equal(t, "Borracha", items[0].Name)
+        assert.Equal(t, "Lápis", items[1].Name)
+        assert.Nil(t, items[0].ReadAt)
+
+        items, _, _, err = uc.List(1, false, 2, 2)
+        require.NoError(t, err)
+        require.Len(t, items, 1)
+        assert.Equal(t, "Caneta", items[0].Name)
+
+        // Other users see an empty inbox
+        items, total, _, err = uc.List(2, false, 1, 20)
+        require.NoError(t, err)
+        assert.Empty(t, items)
+        assert.Equal(t, int64(0), total)
+    })
+
+    // Subtest: People without an account have no inbox
+    t.Run("UnknownRecipient", func(t *testing.T) {
+        uc, repo, userRepo := newInboxTestSetup()
+        userRepo.user = nil
+        require.NoError(t, uc.Notify(ctx, "someone@test.com", notifications))
+        assert.Empty(t, repo.notifications)
+    })
+
+    // Subtest: Notifications are marked read and unread one by one or all at once
+    t.Run("MarkRead", func(t *testing.T) {
+        uc, _, _ := newInboxTestSetup()
+        require.NoError(t, uc.Notify(ctx, "amanda@test.com", notifications))
+
+        notification, err := uc.MarkRead(1, 1, true)
+        require.NoError(t, err)
+        assert.NotNil(t, notification.ReadAt)
+        items, total, unread, _ := uc.List(1, true, 1, 20)
+        assert.Len(t, items, 2)
+        assert.Equal(t, int64(2), total)
+        assert.Equal(t, int64(2), unread)
+
+        notification, err = uc.MarkRead(1, 1, false)
+        require.NoError(t, err)
+        assert.Nil(t, notification.ReadAt)
+
+        _, err = uc.MarkRead(2, 1, true)
+        assert.ErrorIs(t, err, usecase.ErrInboxNotificationNotFound)
+        _, err = uc.MarkRead(1, 99, true)
+        assert.ErrorIs(t, err, usecase.ErrInboxNotificationNotFound)
+
+        updated, err := uc.MarkAllRead(1)
+        require.NoError(t, err)
+        assert.Equal(t, int64(3), updated)
+        _, _, unread, _ = uc.List(1, false, 1, 20)
+        assert.Equal(t, int64(0), unread)
+    })
+}
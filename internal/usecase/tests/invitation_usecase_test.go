@@ -0,0 +1,247 @@
+package usecase_test
+
+import (
+    "regexp"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/config"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    domainusecase "github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+    "golang.org/x/crypto/bcrypt"
+)
+
+// mockInvitationRepo is an in-memory implementation of the invitation repository for testing purposes
+type mockInvitationRepo struct {
+    invitations []*model.Invitation
+    orgs        *mockOrgRepo
+}
+
+func (m *mockInvitationRepo) Create(invitation *model.Invitation) error {
+    invitation.ID = uint(len(m.invitations) + 1)
+    invitation.CreatedAt = time.Now()
+    m.invitations = append(m.invitations, invitation)
+    return nil
+}
+
+func (m *mockInvitationRepo) FindByID(id uint) (*model.Invitation, error) {
+    if id == 0 || int(id) > len(m.invitations) {
+        return nil, nil
+    }
+    invitation := m.invitations[id-1]
+    invitation.Organization, _ = m.orgs.FindByID(invitation.OrgID)
+    return invitation, nil
+}
+
+func (m *mockInvitationRepo) FindByHash(tokenHash string) (*model.Invitation, error) {
+    for _, invitation := range m.invitations {
+        if invitation.TokenHash == tokenHash {
+            return invitation, nil
+        }
+    }
+    return nil, nil
+}
+
+func (m *mockInvitationRepo) ListByOrg(orgID uint) ([]*model.Invitation, error) {
+    var invitations []*model.Invitation
+    for _, invitation := range m.invitations {
+        if invitation.OrgID == orgID {
+            invitations = append(invitations, invitation)
+        }
+    }
+    return invitations, nil
+}
+
+func (m *mockInvitationRepo) Update(invitation *model.Invitation) error {
+    return nil
+}
+
+func (m *mockInvitationRepo) MarkAccepted(id uint) (bool, error) {
+    invitation := m.invitations[id-1]
+    if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+        return false, nil
+    }
+    now := time.Now()
+    invitation.AcceptedAt = &now
+    return true, nil
+}
+
+func (m *mockInvitationRepo) Revoke(id uint) (bool, error) {
+    invitation := m.invitations[id-1]
+    if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+        return false, nil
+    }
+    now := time.Now()
+    invitation.RevokedAt = &now
+    return true, nil
+}
+
+func (m *mockInvitationRepo) RevokePending(orgID uint, email string) error {
+    for _, invitation := range m.invitations {
+        if invitation.OrgID == orgID && invitation.Email == email {
+            m.Revoke(invitation.ID)
+        }
+    }
+    return nil
+}
+
+// invitationTokenPattern extracts the invitation token from the email body
+var invitationTokenPattern = regexp.MustCompile(`Código do convite: (\S+)`)
+
+type invitationSetup struct {
+    invitationUC domainusecase.InvitationUsecaseInterface
+    repo         *mockInvitationRepo
+    orgRepo      *mockOrgRepo
+    userRepo     *emailLookupRepo
+    mailer       *mockMailer
+}
+
+// newInvitationSetup returns an invitation use case in which user 1 administers the organization "Acme"
+func newInvitationSetup() *invitationSetup {
+    orgRepo := newMockOrgRepo(map[uint]string{1: model.RoleAdmin, 2: model.RoleEditor})
+    userRepo := &emailLookupRepo{mockUserRepo: &mockUserRepo{}}
+    repo := &mockInvitationRepo{orgs: orgRepo}
+    mailer := newMockMailer()
+    orgs := usecase.NewOrganizationUsecase(orgRepo, userRepo, zap.NewNop())
+    cfg := &config.Configs{InvitationTTL: 72 * time.Hour}
+    return &invitationSetup{
+        invitationUC: usecase.NewInvitationUsecase(repo, userRepo, orgRepo, orgs, mailer, cfg, zap.NewNop()),
+        repo:         repo,
+        orgRepo:      orgRepo,
+        userRepo:     userRepo,
+        mailer:       mailer,
+    }
+}
+
+// invite creates an invitation as the organization admin and returns it with the token emailed to the invitee
+func (s *invitationSetup) invite(t *testing.T, email, role string) (*model.Invitation, string) {
+    invitation, err := s.invitationUC.CreateInvitation(invitationAdmin, 1, email, role, nil)
+    require.NoError(t, err)
+    return invitation, s.token(t, invitation.Email)
+}
+
+// token waits for the next invitation email and returns its token
+func (s *invitationSetup) token(t *testing.T, email string) string {
+    msg := s.mailer.waitForEmail(t)
+    assert.Equal(t, []string{email}, msg.To)
+    match := invitationTokenPattern.FindStringSubmatch(msg.Text)
+    require.Len(t, match, 2)
+    return match[1]
+}
+
+var invitationAdmin = &model.Actor{ID: 1, Name: "Admin", Email: "admin@test.com", Role: model.RoleViewer}
+
+// TestInvitations tests invitation-based onboarding
+func TestInvitations(t *testing.T) {
+    // Subtest: Accepting creates a verified user that joins the organization with the invited role
+    t.Run("Accept", func(t *testing.T) {
+        s := newInvitationSetup()
+        invitation, token := s.invite(t, "Maria@Test.com", model.RoleViewer)
+        assert.Equal(t, "maria@test.com", invitation.Email)
+        assert.WithinDuration(t, time.Now().Add(72*time.Hour), invitation.ExpiresAt, time.Minute)
+
+        user, err := s.invitationUC.AcceptInvitation(token, "Maria", "secret123")
+
+        require.NoError(t, err)
+        assert.Equal(t, "maria@test.com", user.Email)
+        assert.True(t, user.IsVerified())
+        assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("secret123")))
+        membership, _ := s.orgRepo.FindMembership(user.ID, 1)
+        require.NotNil(t, membership)
+        assert.Equal(t, model.RoleViewer, membership.Role)
+        assert.Equal(t, model.InvitationAccepted, invitation.Status(time.Now()))
+
+        // The token cannot create a second account
+        _, err = s.invitationUC.AcceptInvitation(token, "Maria 2", "secret123")
+        assert.ErrorIs(t, err, usecase.ErrInvalidInvitation)
+    })
+
+    // Subtest: Revoked, expired and unknown invitations cannot be accepted
+    t.Run("InvalidTokens", func(t *testing.T) {
+        s := newInvitationSetup()
+        invitation, token := s.invite(t, "maria@test.com", model.RoleEditor)
+
+        require.NoError(t, s.invitationUC.RevokeInvitation(invitationAdmin, 1, invitation.ID))
+        _, err := s.invitationUC.AcceptInvitation(token, "Maria", "secret123")
+        assert.ErrorIs(t, err, usecase.ErrInvalidInvitation)
+        assert.ErrorIs(t, s.invitationUC.RevokeInvitation(invitationAdmin, 1, invitation.ID), usecase.ErrInvitationNotPending)
+
+        expired, token := s.invite(t, "joao@test.com", model.RoleEditor)
+        expired.ExpiresAt = time.Now().Add(-time.Minute)
+        _, err = s.invitationUC.AcceptInvitation(token, "Joao", "secret123")
+        assert.ErrorIs(t, err, usecase.ErrInvalidInvitation)
+
+        _, err = s.invitationUC.AcceptInvitation("unknown", "Joao", "secret123")
+        assert.ErrorIs(t, err, usecase.ErrInvalidInvitation)
+    })
+
+    // Subtest: Resending replaces the token and renews the validity of an expired invitation
+    t.Run("Resend", func(t *testing.T) {
+        s := newInvitationSetup()
+        invitation, oldToken := s.invite(t, "maria@test.com", model.RoleEditor)
+        invitation.SentAt = invitation.SentAt.Add(-100 * time.Hour)
+        invitation.ExpiresAt = invitation.ExpiresAt.Add(-100 * time.Hour)
+
+        resent, err := s.invitationUC.ResendInvitation(invitationAdmin, 1, invitation.ID)
+        require.NoError(t, err)
+        newToken := s.token(t, "maria@test.com")
+        assert.NotEqual(t, oldToken, newToken)
+        assert.Equal(t, model.InvitationPending, resent.Status(time.Now()))
+
+        _, err = s.invitationUC.AcceptInvitation(oldToken, "Maria", "secret123")
+        assert.ErrorIs(t, err, usecase.ErrInvalidInvitation)
+        _, err = s.invitationUC.AcceptInvitation(newToken, "Maria", "secret123")
+        assert.NoError(t, err)
+
+        _, err = s.invitationUC.ResendInvitation(invitationAdmin, 1, invitation.ID)
+        assert.ErrorIs(t, err, usecase.ErrInvitationNotPending)
+    })
+
+    // Subtest: Only organization admins manage invitations, and only for their organization
+    t.Run("Authorization", func(t *testing.T) {
+        s := newInvitationSetup()
+        invitation, _ := s.invite(t, "maria@test.com", model.RoleEditor)
+
+        editor := &model.Actor{ID: 2, Email: "editor@test.com", Role: model.RoleViewer}
+        _, err := s.invitationUC.CreateInvitation(editor, 1, "joao@test.com", model.RoleEditor, nil)
+        assert.ErrorIs(t, err, usecase.ErrOrgForbidden)
+        _, err = s.invitationUC.ListInvitations(editor, 1)
+        assert.ErrorIs(t, err, usecase.ErrOrgForbidden)
+
+        outsider := &model.Actor{ID: 9, Email: "outsider@test.com", Role: model.RoleEditor}
+        assert.ErrorIs(t, s.invitationUC.RevokeInvitation(outsider, 1, invitation.ID), usecase.ErrOrgNotFound)
+
+        s.orgRepo.orgs = append(s.orgRepo.orgs, &model.Organization{ID: 2, Name: "Globex", Slug: "globex"})
+        s.orgRepo.memberships = append(s.orgRepo.memberships, &model.Membership{UserID: 1, OrgID: 2, Role: model.RoleAdmin})
+        assert.ErrorIs(t, s.invitationUC.RevokeInvitation(invitationAdmin, 2, invitation.ID), usecase.ErrInvitationNotFound)
+
+        invitations, err := s.invitationUC.ListInvitations(invitationAdmin, 1)
+        require.NoError(t, err)
+        assert.Len(t, invitations, 1)
+    })
+
+    // Subtest: Invalid roles and expiries, existing users and taken names are rejected
+    t.Run("InvalidInput", func(t *testing.T) {
+        s := newInvitationSetup()
+        existing := &model.User{Name: "Maria", Email: "maria@test.com"}
+        existing.ID = 5
+        s.userRepo.user = existing
+
+        _, err := s.invitationUC.CreateInvitation(invitationAdmin, 1, "joao@test.com", "owner", nil)
+        assert.ErrorIs(t, err, usecase.ErrInvalidRole)
+        past := time.Now().Add(-time.Hour)
+        _, err = s.invitationUC.CreateInvitation(invitationAdmin, 1, "joao@test.com", model.RoleEditor, &past)
+        assert.ErrorIs(t, err, usecase.ErrInvalidInvitationExpiry)
+        _, err = s.invitationUC.CreateInvitation(invitationAdmin, 1, "maria@test.com", model.RoleEditor, nil)
+        assert.ErrorIs(t, err, usecase.ErrInviteeAlreadyUser)
+
+        _, token := s.invite(t, "joao@test.com", model.RoleEditor)
+        s.userRepo.nameTaken = true
+        _, err = s.invitationUC.AcceptInvitation(token, "Maria", "secret123")
+        assert.ErrorIs(t, err, usecase.ErrNameTaken)
+    })
+}
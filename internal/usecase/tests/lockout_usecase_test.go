@@ -0,0 +1,271 @@
+package usecase_test
+
+import (
+    "encoding/json"
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/config"
+    "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    domainusecase "github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+    "golang.org/x/crypto/bcrypt"
+)
+
+// mockLoginAttemptRepo is an in-memory implementation of the login attempt repository for testing purposes
+type mockLoginAttemptRepo struct {
+    mu        sync.Mutex
+    throttles map[string]*model.LoginThrottle
+    audit     []*model.AuthAuditEntry
+}
+
+func newMockLoginAttemptRepo() *mockLoginAttemptRepo {
+    return &mockLoginAttemptRepo{throttles: make(map[string]*model.LoginThrottle)}
+}
+
+func (m *mockLoginAttemptRepo) FindThrottle(key string) (*model.LoginThrottle, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if t, ok := m.throttles[key]; ok {
+        copied := *t
+        return &copied, nil
+    }
+    return nil, nil
+}
+
+func (m *mockLoginAttemptRepo) RegisterFailure(key string, now time.Time, window time.Duration) (int, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    throttle, ok := m.throttles[key]
+    if !ok {
+        throttle = &model.LoginThrottle{Key: key}
+        m.throttles[key] = throttle
+    }
+    if now.Sub(throttle.LastFailureAt) > window || throttle.LockedUntil != nil && !throttle.IsLocked(now) {
+        throttle.Failures, throttle.LockedUntil = 0, nil
+    }
+    throttle.Failures++
+    throttle.LastFailureAt = now
+    return throttle.Failures, nil
+}
+
+func (m *mockLoginAttemptRepo) LockThrottle(key string, until, now time.Time) (bool, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    throttle, ok := m.throttles[key]
+    if !ok || throttle.IsLocked(now) {
+        return false, nil
+    }
+    throttle.LockedUntil = &until
+    return true, nil
+}
+
+func (m *mockLoginAttemptRepo) DeleteThrottle(key string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    delete(m.throttles, key)
+    return nil
+}
+
+func (m *mockLoginAttemptRepo) RecordAudit(entry *model.AuthAuditEntry) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.audit = append(m.audit, entry)
+    return nil
+}
+
+// outcomes returns the recorded audit outcomes in order
+func (m *mockLoginAttemptRepo) outcomes() []string {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    var outcomes []string
+    for _, entry := range m.audit {
+        outcomes = append(outcomes, entry.Outcome)
+    }
+    return outcomes
+}
+
+// lockoutTestConfig returns lockout limits small enough to reach in a test
+func lockoutTestConfig() *config.Configs {
+    return &config.Configs{
+        LoginMaxFailures:     3,
+        LoginIPMaxFailures:   10,
+        LoginFailureWindow:   15 * time.Minute,
+        LoginLockoutDuration: 15 * time.Minute,
+    }
+}
+
+// newTestLockout returns a lockout use case with in-memory storage and no expected publications
+func newTestLockout() domainusecase.LockoutUsecaseInterface {
+    return usecase.NewLockoutUsecase(newMockLoginAttemptRepo(), &mockUserRepo{}, &MockRabbitMQClient{}, lockoutTestConfig(), zap.NewNop())
+}
+
+// expireDelay moves the last failure back in time so the progressive delay has elapsed
+func (m *mockLoginAttemptRepo) expireDelay(key string) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if t, ok := m.throttles[key]; ok {
+        t.LastFailureAt = t.LastFailureAt.Add(-time.Minute)
+    }
+}
+
+// TestLoginLockout tests failed attempt tracking, progressive delays and lockouts on login
+func TestLoginLockout(t *testing.T) {
+    logger := zap.NewNop()
+
+    // newLockoutSetup builds an AuthUsecase with a verified user whose password is "123456"
+    newLockoutSetup := func(t *testing.T) (domainusecase.AuthUsecaseInterface, domainusecase.LockoutUsecaseInterface, *mockLoginAttemptRepo, *MockRabbitMQClient, *mockUserRepo) {
+        hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+        user := &model.User{Name: "Amanda", Email: "amanda@test.com", Password: string(hashedPassword), VerifiedAt: verifiedNow()}
+        user.ID = 1
+        userRepo := &mockUserRepo{user: user}
+        attemptRepo := newMockLoginAttemptRepo()
+        publisher := &MockRabbitMQClient{}
+        lockout := usecase.NewLockoutUsecase(attemptRepo, userRepo, publisher, lockoutTestConfig(), logger)
+        return usecase.NewAuthUsecase(userRepo, newMockTokenRepo(), lockout, newTestMFA(), newTestOrgs(), newTestKeySet(), authTestConfig(), logger), lockout, attemptRepo, publisher, userRepo
+    }
+
+    // Subtest: repeated failures are delayed, then lock the account and publish a lockout event
+    t.Run("LocksAccount", func(t *testing.T) {
+        authUC, _, attemptRepo, publisher, _ := newLockoutSetup(t)
+        var published string
+        publisher.On("Publish", mock.Anything, messaging.AccountEventsQueue, mock.Anything).
+            Run(func(args mock.Arguments) { published = args.String(2) }).
+            Return(nil).Once()
+
+        _, err := authUC.Login("amanda@test.com", "wrong", "10.0.0.1")
+        assert.ErrorIs(t, err, usecase.ErrInvalidCredentials)
+        _, err = authUC.Login("amanda@test.com", "wrong", "10.0.0.1")
+        assert.ErrorIs(t, err, usecase.ErrInvalidCredentials)
+
+        // After the free attempts, an immediate retry is delayed
+        _, err = authUC.Login("amanda@test.com", "123456", "10.0.0.1")
+        var attemptsErr *usecase.AttemptsError
+        require.True(t, errors.As(err, &attemptsErr))
+        assert.Greater(t, attemptsErr.RetryAfter, time.Duration(0))
+
+        // Once the delay has passed, the third failure locks the account
+        attemptRepo.expireDelay("account:amanda@test.com")
+        _, err = authUC.Login("amanda@test.com", "wrong", "10.0.0.1")
+        assert.ErrorIs(t, err, usecase.ErrInvalidCredentials)
+
+        // Even the correct password is refused while locked
+        _, err = authUC.Login("amanda@test.com", "123456", "10.0.0.1")
+        assert.ErrorIs(t, err, usecase.ErrTooManyAttempts)
+
+        publisher.AssertExpectations(t)
+        var event map[string]interface{}
+        require.NoError(t, json.Unmarshal([]byte(published), &event))
+        assert.Equal(t, "account_locked", event["event"])
+        assert.Equal(t, "amanda@test.com", event["email"])
+        assert.Equal(t, "10.0.0.1", event["ip"])
+
+        assert.Equal(t, []string{
+            model.AuthOutcomeInvalidCredentials,
+            model.AuthOutcomeInvalidCredentials,
+            model.AuthOutcomeThrottled,
+            model.AuthOutcomeInvalidCredentials,
+            model.AuthOutcomeLocked,
+            model.AuthOutcomeLocked,
+        }, attemptRepo.outcomes())
+    })
+
+    // Subtest: unknown emails are throttled exactly like existing ones, without publishing events
+    t.Run("UnknownEmailUniform", func(t *testing.T) {
+        authUC, _, attemptRepo, publisher, userRepo := newLockoutSetup(t)
+        userRepo.user = nil
+
+        for i := 0; i < 3; i++ {
+            attemptRepo.expireDelay("account:ghost@test.com")
+            _, err := authUC.Login("ghost@test.com", "wrong", "10.0.0.1")
+            assert.ErrorIs(t, err, usecase.ErrInvalidCredentials)
+        }
+        _, err := authUC.Login("ghost@test.com", "wrong", "10.0.0.1")
+        assert.ErrorIs(t, err, usecase.ErrTooManyAttempts)
+        publisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+    })
+
+    // Subtest: an admin unlock lets the user log in again
+    t.Run("AdminUnlock", func(t *testing.T) {
+        authUC, lockout, attemptRepo, publisher, _ := newLockoutSetup(t)
+        publisher.On("Publish", mock.Anything, messaging.AccountEventsQueue, mock.Anything).Return(nil).Once()
+
+        for i := 0; i < 3; i++ {
+            attemptRepo.expireDelay("account:amanda@test.com")
+            authUC.Login("amanda@test.com", "wrong", "10.0.0.1")
+        }
+        _, err := authUC.Login("amanda@test.com", "123456", "10.0.0.1")
+        assert.ErrorIs(t, err, usecase.ErrTooManyAttempts)
+
+        assert.NoError(t, lockout.Unlock(1, 99))
+
+        result, err := authUC.Login("amanda@test.com", "123456", "10.0.0.1")
+        assert.NoError(t, err)
+        assert.NotEmpty(t, result.Tokens.AccessToken)
+    })
+
+    // Subtest: the lockout ends by itself once its duration has elapsed
+    t.Run("ExpiresWithTime", func(t *testing.T) {
+        authUC, _, attemptRepo, _, _ := newLockoutSetup(t)
+        lockedUntil := time.Now().Add(-time.Second)
+        attemptRepo.throttles["account:amanda@test.com"] = &model.LoginThrottle{
+            Key:           "account:amanda@test.com",
+            Failures:      3,
+            LastFailureAt: time.Now().Add(-15 * time.Minute),
+            LockedUntil:   &lockedUntil,
+        }
+
+        _, err := authUC.Login("amanda@test.com", "123456", "10.0.0.1")
+        assert.NoError(t, err)
+    })
+
+    // Subtest: concurrent failures are all counted and lock the account exactly once
+    t.Run("ConcurrentFailures", func(t *testing.T) {
+        _, lockout, attemptRepo, publisher, userRepo := newLockoutSetup(t)
+        publisher.On("Publish", mock.Anything, messaging.AccountEventsQueue, mock.Anything).Return(nil).Once()
+
+        var wg sync.WaitGroup
+        for i := 0; i < 20; i++ {
+            wg.Add(1)
+            go func() {
+                defer wg.Done()
+                lockout.RecordFailure("amanda@test.com", "10.0.0.1", userRepo.user, model.AuthOutcomeInvalidCredentials)
+            }()
+        }
+        wg.Wait()
+
+        throttle, err := attemptRepo.FindThrottle("account:amanda@test.com")
+        require.NoError(t, err)
+        assert.Equal(t, 20, throttle.Failures)
+        assert.True(t, throttle.IsLocked(time.Now()))
+        publisher.AssertNumberOfCalls(t, "Publish", 1)
+        locks := 0
+        for _, outcome := range attemptRepo.outcomes() {
+            if outcome == model.AuthOutcomeLocked {
+                locks++
+            }
+        }
+        assert.Equal(t, 1, locks)
+    })
+
+    // Subtest: many failures from one IP block it for every account
+    t.Run("LocksIP", func(t *testing.T) {
+        authUC, _, attemptRepo, _, _ := newLockoutSetup(t)
+        attemptRepo.throttles["ip:10.0.0.9"] = &model.LoginThrottle{Key: "ip:10.0.0.9", Failures: 9, LastFailureAt: time.Now()}
+
+        _, err := authUC.Login("other@test.com", "wrong", "10.0.0.9")
+        assert.ErrorIs(t, err, usecase.ErrInvalidCredentials)
+
+        _, err = authUC.Login("amanda@test.com", "123456", "10.0.0.9")
+        assert.ErrorIs(t, err, usecase.ErrTooManyAttempts)
+        _, err = authUC.Login("amanda@test.com", "123456", "10.0.0.2")
+        assert.NoError(t, err)
+    })
+}
@@ -0,0 +1,225 @@
+package usecase_test
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/config"
+    "github.com/Amandasilvbr/products-crud/internal/consumer"
+    domainmessaging "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    "github.com/Amandasilvbr/products-crud/internal/infrastructure/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/rabbitmq/amqp091-go"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+)
+
+// recordingDispatcher records the notifications the consumer dispatches
+type recordingDispatcher struct {
+    mu         sync.Mutex
+    dispatched chan []model.ProductNotification
+    recipients []string
+}
+
+func (d *recordingDispatcher) Dispatch(ctx context.Context, recipient string, notifications []model.ProductNotification) error {
+    d.mu.Lock()
+    d.recipients = append(d.recipients, recipient)
+    d.mu.Unlock()
+    d.dispatched <- notifications
+    return nil
+}
+
+// dialMemory opens a client of a memory broker, closed with the test
+func dialMemory(t *testing.T, broker *messaging.MemoryBroker, retry domainmessaging.RetryPolicy) domainmessaging.Broker {
+    client, err := broker.Dial(retry)
+    require.NoError(t, err)
+    t.Cleanup(client.Close)
+    return client
+}
+
+// receive returns the next delivery of a channel or fails the test after a timeout
+func receive(t *testing.T, deliveries <-chan amqp091.Delivery) amqp091.Delivery {
+    select {
+    case msg := <-deliveries:
+        return msg
+    case <-time.After(2 * time.Second):
+        t.Fatal("expected a delivery")
+        return amqp091.Delivery{}
+    }
+}
+
+// assertNoDelivery checks that nothing is delivered for a short while
+func assertNoDelivery(t *testing.T, deliveries <-chan amqp091.Delivery) {
+    select {
+    case msg := <-deliveries:
+        t.Fatalf("unexpected delivery %s", msg.Body)
+    case <-time.After(50 * time.Millisecond):
+    }
+}
+
+// TestMemoryBroker tests the in-process broker used by MESSAGING_DRIVER=memory
+func TestMemoryBroker(t *testing.T) {
+    ctx := context.Background()
+    retry := domainmessaging.RetryPolicy{MaxAttempts: 2, Delay: 10 * time.Millisecond}
+
+    // Subtest: Topic exchanges copy each message to every matching queue, and the default exchange routes by queue name
+    t.Run("Routing", func(t *testing.T) {
+        broker := messaging.NewMemoryBroker()
+        client := dialMemory(t, broker, retry)
+        require.NoError(t, client.DeclareTopology(domainmessaging.ProductNotificationsSubscription.Topology()))
+        search := domainmessaging.Subscription{Queue: "product_search", Exchange: "products", RoutingKeys: []string{"product.created.*"}}
+        require.NoError(t, client.DeclareTopology(search.Topology()))
+
+        notifications, err := client.Consume("product_events", "", false, false, false, false, nil)
+        require.NoError(t, err)
+        indexer, err := client.Consume("product_search", "", false, false, false, false, nil)
+        require.NoError(t, err)
+
+        require.NoError(t, client.PublishTo(ctx, "products", "product.created.moveis", "created"))
+        require.NoError(t, client.PublishTo(ctx, "products", "product.deleted.moveis", "deleted"))
+        require.NoError(t, client.Publish(ctx, "product_events", "direct"))
+        assert.ErrorIs(t, client.PublishTo(ctx, "unknown", "key", "lost"), messaging.ErrExchangeNotFound)
+
+        for _, body := range []string{"created", "deleted", "direct"} {
+            msg := receive(t, notifications)
+            assert.Equal(t, body, string(msg.Body))
+            assert.NoError(t, msg.Ack(false))
+        }
+        msg := receive(t, indexer)
+        assert.Equal(t, "created", string(msg.Body))
+        assert.Equal(t, "product.created.moveis", msg.RoutingKey)
+        assert.NoError(t, msg.Ack(false))
+        assertNoDelivery(t, indexer)
+    })
+
+    // Subtest: Prefetch holds deliveries until acknowledgement; nacked messages are requeued or dead-lettered,
+    // and closing a client gives its unacknowledged messages back
+    t.Run("Acknowledgements", func(t *testing.T) {
+        broker := messaging.NewMemoryBroker()
+        client := dialMemory(t, broker, retry)
+        require.NoError(t, client.DeclareQueue("jobs"))
+        require.NoError(t, client.SetPrefetch(1))
+        for _, body := range []string{"first", "second"} {
+            require.NoError(t, client.Publish(ctx, "jobs", body))
+        }
+
+        deliveries, err := client.Consume("jobs", "", false, false, false, false, nil)
+        require.NoError(t, err)
+        first := receive(t, deliveries)
+        assert.Equal(t, "first", string(first.Body))
+        assertNoDelivery(t, deliveries)
+
+        require.NoError(t, first.Nack(false, true))
+        again := receive(t, deliveries)
+        assert.Equal(t, "first", string(again.Body))
+        assert.True(t, again.Redelivered)
+
+        require.NoError(t, again.Nack(false, false))
+        letters, err := client.Peek(ctx, "jobs", 10)
+        require.NoError(t, err)
+        require.Len(t, letters, 1)
+        assert.Equal(t, "first", letters[0].Body)
+
+        second := receive(t, deliveries)
+        assert.Equal(t, "second", string(second.Body))
+        client.Close()
+        assert.Equal(t, domainmessaging.StateClosed, client.State())
+
+        other := dialMemory(t, broker, retry)
+        deliveries, err = other.Consume("jobs", "", false, false, false, false, nil)
+        require.NoError(t, err)
+        second = receive(t, deliveries)
+        assert.Equal(t, "second", string(second.Body))
+        assert.True(t, second.Redelivered)
+    })
+
+    // Subtest: Rejected messages are retried after the policy's delay, then dead-lettered, requeued or purged
+    t.Run("RetryAndDeadLetter", func(t *testing.T) {
+        broker := messaging.NewMemoryBroker()
+        client := dialMemory(t, broker, retry)
+        require.NoError(t, client.DeclareQueue("jobs"))
+        require.NoError(t, client.Publish(ctx, "jobs", "job"))
+        deliveries, err := client.Consume("jobs", "", false, false, false, false, nil)
+        require.NoError(t, err)
+
+        client.Reject(ctx, "jobs", receive(t, deliveries), errors.New("smtp down"), false)
+        retried := receive(t, deliveries)
+        assert.Equal(t, 1, domainmessaging.Attempts(retried))
+
+        client.Reject(ctx, "jobs", retried, errors.New("smtp down"), false)
+        assertNoDelivery(t, deliveries)
+        letters, err := client.Peek(ctx, "jobs", 10)
+        require.NoError(t, err)
+        require.Len(t, letters, 1)
+        assert.Equal(t, 2, letters[0].Attempts)
+        assert.Equal(t, "smtp down", letters[0].LastError)
+
+        moved, err := client.Requeue(ctx, "jobs", 0)
+        require.NoError(t, err)
+        assert.Equal(t, 1, moved)
+        requeued := receive(t, deliveries)
+        assert.Equal(t, 0, domainmessaging.Attempts(requeued))
+
+        client.Reject(ctx, "jobs", requeued, errors.New("invalid JSON"), true)
+        purged, err := client.Purge(ctx, "jobs")
+        require.NoError(t, err)
+        assert.Equal(t, 1, purged)
+    })
+
+    // Subtest: Product changes go from the outbox through the memory broker to the consumer, without RabbitMQ
+    t.Run("EndToEnd", func(t *testing.T) {
+        dial, err := messaging.NewDialer(domainmessaging.DriverMemory, "")
+        require.NoError(t, err)
+        publisher, err := dial(retry)
+        require.NoError(t, err)
+        defer publisher.Close()
+        require.NoError(t, publisher.DeclareTopology(domainmessaging.ProductNotificationsSubscription.Topology()))
+
+        dispatcher := &recordingDispatcher{dispatched: make(chan []model.ProductNotification, 1)}
+        products, err := consumer.NewConsumer(zap.NewNop(), dial, domainmessaging.ProductNotificationsSubscription, retry, domainmessaging.ConsumerOptions{Concurrency: 1, Prefetch: 1}, dispatcher, usecase.NewMessageDeduplicator(newMemoryProcessedRepo(), &config.Configs{}, zap.NewNop()))
+        require.NoError(t, err)
+        defer products.Close()
+        consumerCtx, cancel := context.WithCancel(ctx)
+        defer cancel()
+        go products.Start(consumerCtx)
+
+        // The product usecase stores the event, which the relay publishes
+        uc, repo, _ := setupTest(t)
+        var events map[int]*model.OutboxEvent
+        repo.On("Create", mock.Anything, mock.Anything, mock.Anything).Run(capturedEvents(&events)).Return(nil).Once()
+        require.Nil(t, uc.Create(ctx, []*model.Product{{OrgID: 1, SKU: 5, Name: "Lâmpada", Category: "Casa"}}, actor))
+
+        outbox := &mockOutboxRepo{}
+        stored := outbox.add("product_created")
+        stored.Queue, stored.Exchange, stored.RoutingKey, stored.Payload = "", events[5].Exchange, events[5].RoutingKey, events[5].Payload
+        cfg := outboxTestConfig()
+        cfg.CloudEventsMode = domainmessaging.CloudEventsBinary
+        sent, err := usecase.NewOutboxRelay(outbox, publisher, cfg, zap.NewNop()).Flush(ctx)
+        require.NoError(t, err)
+        assert.Equal(t, 1, sent)
+
+        select {
+        case notifications := <-dispatcher.dispatched:
+            require.Len(t, notifications, 1)
+            assert.Equal(t, model.EventProductCreated, notifications[0].Event)
+            assert.Equal(t, 5, notifications[0].SKU)
+            assert.Equal(t, "Acme", notifications[0].OrgName)
+        case <-time.After(2 * time.Second):
+            t.Fatal("expected the consumer to dispatch the event")
+        }
+        assert.Equal(t, []string{userEmail}, dispatcher.recipients)
+        assert.Equal(t, domainmessaging.StateConnected, products.State())
+    })
+
+    // Subtest: Unknown drivers are refused
+    t.Run("UnknownDriver", func(t *testing.T) {
+        _, err := messaging.NewDialer("kafka", "")
+        assert.Error(t, err)
+    })
+}
@@ -0,0 +1,203 @@
+package usecase_test
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/config"
+    "github.com/Amandasilvbr/products-crud/internal/consumer"
+    domainmessaging "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    "github.com/Amandasilvbr/products-crud/internal/infrastructure/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+)
+
+// memoryProcessedRepo is an in-memory store of processed messages
+// failures makes the next calls to MarkProcessed fail, as when the database is unavailable
+type memoryProcessedRepo struct {
+    mu       sync.Mutex
+    records  map[string]*model.ProcessedMessage
+    failures int
+}
+
+func newMemoryProcessedRepo() *memoryProcessedRepo {
+    return &memoryProcessedRepo{records: make(map[string]*model.ProcessedMessage)}
+}
+
+func (m *memoryProcessedRepo) Processed(ctx context.Context, consumer string, ids []string) (map[string]bool, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    processed := make(map[string]bool)
+    for _, id := range ids {
+        if _, ok := m.records[consumer+"/"+id]; ok {
+            processed[id] = true
+        }
+    }
+    return processed, nil
+}
+
+func (m *memoryProcessedRepo) MarkProcessed(ctx context.Context, messages []*model.ProcessedMessage) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if m.failures > 0 {
+        m.failures--
+        return errors.New("database unavailable")
+    }
+    for _, message := range messages {
+        key := message.Consumer + "/" + message.MessageID
+        if _, ok := m.records[key]; !ok {
+            m.records[key] = message
+        }
+    }
+    return nil
+}
+
+func (m *memoryProcessedRepo) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    var deleted int64
+    for key, message := range m.records {
+        if message.ProcessedAt.Before(before) {
+            delete(m.records, key)
+            deleted++
+        }
+    }
+    return deleted, nil
+}
+
+// count returns the number of recorded messages
+func (m *memoryProcessedRepo) count() int {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return len(m.records)
+}
+
+// productEvent builds the CloudEvent of a product created in the test organization
+func productEvent(t *testing.T, id string, sku int) *domainmessaging.CloudEvent {
+    return recipientEvent(t, id, sku, userEmail)
+}
+
+// nextDispatch returns the next notifications dispatched, or fails the test after a timeout
+func nextDispatch(t *testing.T, dispatcher *recordingDispatcher) []model.ProductNotification {
+    select {
+    case notifications := <-dispatcher.dispatched:
+        return notifications
+    case <-time.After(2 * time.Second):
+        t.Fatal("expected the consumer to dispatch an event")
+        return nil
+    }
+}
+
+// TestMessageDeduplication tests that consumers notify each message once despite redeliveries
+func TestMessageDeduplication(t *testing.T) {
+    ctx := context.Background()
+    retry := domainmessaging.RetryPolicy{MaxAttempts: 3, Delay: 10 * time.Millisecond}
+    cfg := &config.Configs{ProcessedMessageRetention: time.Hour}
+
+    // startConsumer runs a product consumer on a memory broker and returns a client to publish with
+    startConsumer := func(t *testing.T, repo *memoryProcessedRepo) (domainmessaging.Broker, *recordingDispatcher) {
+        broker := messaging.NewMemoryBroker()
+        dispatcher := &recordingDispatcher{dispatched: make(chan []model.ProductNotification, 4)}
+        dedup := usecase.NewMessageDeduplicator(repo, cfg, zap.NewNop())
+        products, err := consumer.NewConsumer(zap.NewNop(), broker.Dial, domainmessaging.ProductNotificationsSubscription, retry, domainmessaging.ConsumerOptions{Concurrency: 1, Prefetch: 1}, dispatcher, dedup)
+        require.NoError(t, err)
+        t.Cleanup(products.Close)
+        consumerCtx, cancel := context.WithCancel(ctx)
+        t.Cleanup(cancel)
+        go products.Start(consumerCtx)
+        return dialMemory(t, broker, retry), dispatcher
+    }
+
+    // Subtest: Records are scoped by consumer, ignore messages without an ID and expire after the retention
+    t.Run("Store", func(t *testing.T) {
+        repo := newMemoryProcessedRepo()
+        dedup := usecase.NewMessageDeduplicator(repo, cfg, zap.NewNop())
+
+        require.NoError(t, dedup.Record(ctx, "product_events", []string{"a", "", "a", "b"}))
+        processed, err := dedup.Processed(ctx, "product_events", []string{"a", "b", "c", ""})
+        require.NoError(t, err)
+        assert.Equal(t, map[string]bool{"a": true, "b": true}, processed)
+        processed, err = dedup.Processed(ctx, "product_search", []string{"a"})
+        require.NoError(t, err)
+        assert.Empty(t, processed)
+
+        repo.records["product_events/a"].ProcessedAt = time.Now().Add(-2 * time.Hour)
+        stopped, cancel := context.WithCancel(ctx)
+        cancel()
+        require.NoError(t, dedup.RunCleanup(stopped))
+        assert.Equal(t, 1, repo.count())
+    })
+
+    // Subtest: A redelivered event is acknowledged without notifying its recipient again
+    t.Run("SkipsRedelivery", func(t *testing.T) {
+        repo := newMemoryProcessedRepo()
+        publisher, dispatcher := startConsumer(t, repo)
+
+        event := productEvent(t, "evt-1", 5)
+        require.NoError(t, publisher.PublishEvent(ctx, domainmessaging.ProductEventsExchange, "product.created.casa", event, domainmessaging.CloudEventsBinary))
+        require.NoError(t, publisher.PublishEvent(ctx, domainmessaging.ProductEventsExchange, "product.created.casa", event, domainmessaging.CloudEventsStructured))
+        require.NoError(t, publisher.PublishEvent(ctx, domainmessaging.ProductEventsExchange, "product.created.casa", productEvent(t, "evt-2", 6), domainmessaging.CloudEventsBinary))
+
+        // The duplicate sits between the two events, so the second dispatch shows it was skipped
+        assert.Equal(t, 5, nextDispatch(t, dispatcher)[0].SKU)
+        assert.Equal(t, 6, nextDispatch(t, dispatcher)[0].SKU)
+        select {
+        case notifications := <-dispatcher.dispatched:
+            t.Fatalf("unexpected dispatch of SKU %d", notifications[0].SKU)
+        case <-time.After(50 * time.Millisecond):
+        }
+        assert.Equal(t, 2, repo.count())
+    })
+
+    // Subtest: An event is not acknowledged until it is recorded; when the record fails it is retried
+    t.Run("RetriesUnrecorded", func(t *testing.T) {
+        repo := newMemoryProcessedRepo()
+        repo.failures = 1
+        publisher, dispatcher := startConsumer(t, repo)
+
+        require.NoError(t, publisher.PublishEvent(ctx, domainmessaging.ProductEventsExchange, "product.created.casa", productEvent(t, "evt-3", 7), domainmessaging.CloudEventsBinary))
+
+        assert.Equal(t, 7, nextDispatch(t, dispatcher)[0].SKU)
+        assert.Equal(t, 7, nextDispatch(t, dispatcher)[0].SKU)
+        assert.Eventually(t, func() bool { return repo.count() == 1 }, time.Second, 10*time.Millisecond)
+        letters, err := publisher.Peek(ctx, domainmessaging.ProductEventsQueue, 10)
+        require.NoError(t, err)
+        assert.Empty(t, letters)
+    })
+
+    // Subtest: When one channel fails, the redelivered event only reaches that channel
+    t.Run("RedeliversFailedChannel", func(t *testing.T) {
+        repo := newMemoryProcessedRepo()
+        dedup := usecase.NewMessageDeduplicator(repo, cfg, zap.NewNop())
+        inbox := &flakyNotifier{channel: domainmessaging.ChannelInbox}
+        slack := &flakyNotifier{channel: domainmessaging.ChannelSlack, failures: 1}
+        routes := map[string][]string{usecase.AnyEvent: {domainmessaging.ChannelInbox, domainmessaging.ChannelSlack}}
+        dispatcher := usecase.NewNotificationDispatcher(routes, []domainmessaging.Notifier{inbox, slack}, dedup, zap.NewNop())
+
+        broker := messaging.NewMemoryBroker()
+        products, err := consumer.NewConsumer(zap.NewNop(), broker.Dial, domainmessaging.ProductNotificationsSubscription, retry, domainmessaging.ConsumerOptions{Concurrency: 1, Prefetch: 1}, dispatcher, dedup)
+        require.NoError(t, err)
+        defer products.Close()
+        consumerCtx, cancel := context.WithCancel(ctx)
+        defer cancel()
+        go products.Start(consumerCtx)
+        publisher := dialMemory(t, broker, retry)
+
+        require.NoError(t, publisher.PublishEvent(ctx, domainmessaging.ProductEventsExchange, "product.created.casa", productEvent(t, "evt-4", 8), domainmessaging.CloudEventsBinary))
+
+        assert.Eventually(t, func() bool { return len(slack.deliveredIDs()) == 1 }, 2*time.Second, 10*time.Millisecond)
+        assert.Equal(t, []string{"evt-4"}, slack.deliveredIDs())
+        assert.Equal(t, []string{"evt-4"}, inbox.deliveredIDs())
+        // The message and each channel's delivery are recorded once the retry succeeds
+        assert.Eventually(t, func() bool { return repo.count() == 3 }, time.Second, 10*time.Millisecond)
+        letters, err := publisher.Peek(ctx, domainmessaging.ProductEventsQueue, 10)
+        require.NoError(t, err)
+        assert.Empty(t, letters)
+    })
+}
@@ -0,0 +1,320 @@
+package usecase_test
+
+import (
+    "crypto/hmac"
+    "crypto/sha1"
+    "encoding/base32"
+    "encoding/binary"
+    "fmt"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/config"
+    "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    domainusecase "github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+    "golang.org/x/crypto/bcrypt"
+)
+
+// mockMFARepo is an in-memory implementation of the MFA repository for testing purposes
+type mockMFARepo struct {
+    codes    map[uint][]*model.MFARecoveryCode
+    policies map[string]*model.MFAPolicy
+}
+
+func newMockMFARepo() *mockMFARepo {
+    return &mockMFARepo{
+        codes:    make(map[uint][]*model.MFARecoveryCode),
+        policies: make(map[string]*model.MFAPolicy),
+    }
+}
+
+func (m *mockMFARepo) ReplaceRecoveryCodes(userID uint, codes []*model.MFARecoveryCode) error {
+    m.codes[userID] = codes
+    return nil
+}
+
+func (m *mockMFARepo) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+    for _, code := range m.codes[userID] {
+        if code.CodeHash == codeHash && code.UsedAt == nil {
+            now := time.Now()
+            code.UsedAt = &now
+            return true, nil
+        }
+    }
+    return false, nil
+}
+
+func (m *mockMFARepo) DeleteRecoveryCodes(userID uint) error {
+    delete(m.codes, userID)
+    return nil
+}
+
+func (m *mockMFARepo) FindPolicy(role string) (*model.MFAPolicy, error) {
+    return m.policies[role], nil
+}
+
+func (m *mockMFARepo) ListPolicies() ([]*model.MFAPolicy, error) {
+    var policies []*model.MFAPolicy
+    for _, policy := range m.policies {
+        policies = append(policies, policy)
+    }
+    return policies, nil
+}
+
+func (m *mockMFARepo) SavePolicy(policy *model.MFAPolicy) error {
+    m.policies[policy.Role] = policy
+    return nil
+}
+
+// mfaTestConfig returns the configuration used to sign MFA challenges in tests
+func mfaTestConfig() *config.Configs {
+    return &config.Configs{
+        JWTSecret:       "test-secret",
+        MFAChallengeTTL: 5 * time.Minute,
+        MFAIssuer:       "Products CRUD",
+    }
+}
+
+// newTestMFA returns an MFA use case with no stored policies, so users without 2FA log in directly
+func newTestMFA() domainusecase.MFAUsecaseInterface {
+    return usecase.NewMFAUsecase(&mockUserRepo{}, newMockMFARepo(), newMockOrgRepo(nil), mfaTestConfig(), zap.NewNop())
+}
+
+// totpAt computes the TOTP code of a base32 secret, offset by a number of 30-second steps from now
+func totpAt(t *testing.T, secret string, offset int64) string {
+    key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+    require.NoError(t, err)
+    var msg [8]byte
+    binary.BigEndian.PutUint64(msg[:], uint64(time.Now().Unix()/30+offset))
+    mac := hmac.New(sha1.New, key)
+    mac.Write(msg[:])
+    sum := mac.Sum(nil)
+    start := sum[len(sum)-1] & 0x0f
+    return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[start:start+4])&0x7fffffff)%1000000)
+}
+
+// newMFAUser returns a verified editor whose password is "123456"
+func newMFAUser() *model.User {
+    hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+    user := &model.User{Name: "Amanda", Email: "amanda@test.com", Password: string(hashedPassword), Role: model.RoleEditor, VerifiedAt: verifiedNow()}
+    user.ID = 1
+    return user
+}
+
+// enrollMFA enables 2FA for the repository's user and returns its secret and recovery codes
+func enrollMFA(t *testing.T, mfaUC domainusecase.MFAUsecaseInterface) (string, []string) {
+    enrollment, err := mfaUC.BeginEnrollment(1)
+    require.NoError(t, err)
+    codes, err := mfaUC.ConfirmEnrollment(1, totpAt(t, enrollment.Secret, -1))
+    require.NoError(t, err)
+    return enrollment.Secret, codes
+}
+
+// TestMFAEnrollment tests TOTP enrollment and confirmation
+func TestMFAEnrollment(t *testing.T) {
+    userRepo := &mockUserRepo{user: newMFAUser()}
+    mfaRepo := newMockMFARepo()
+    mfaUC := usecase.NewMFAUsecase(userRepo, mfaRepo, newMockOrgRepo(nil), mfaTestConfig(), zap.NewNop())
+
+    enrollment, err := mfaUC.BeginEnrollment(1)
+    require.NoError(t, err)
+    assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/Products%20CRUD:amanda@test.com?"))
+    assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+    assert.False(t, userRepo.user.MFAEnabled())
+
+    // The authenticator must be confirmed with a valid code before 2FA is enabled
+    _, err = mfaUC.ConfirmEnrollment(1, "000000")
+    assert.ErrorIs(t, err, usecase.ErrInvalidMFACode)
+
+    codes, err := mfaUC.ConfirmEnrollment(1, totpAt(t, enrollment.Secret, 0))
+    require.NoError(t, err)
+    assert.Len(t, codes, 10)
+    assert.True(t, userRepo.user.MFAEnabled())
+    // Only hashes of the recovery codes are stored
+    for _, stored := range mfaRepo.codes[1] {
+        assert.NotContains(t, codes, stored.CodeHash)
+    }
+
+    _, err = mfaUC.BeginEnrollment(1)
+    assert.ErrorIs(t, err, usecase.ErrMFAAlreadyEnabled)
+}
+
+// TestMFAVerify tests TOTP replay protection and one-time recovery codes
+func TestMFAVerify(t *testing.T) {
+    userRepo := &mockUserRepo{user: newMFAUser()}
+    mfaUC := usecase.NewMFAUsecase(userRepo, newMockMFARepo(), newMockOrgRepo(nil), mfaTestConfig(), zap.NewNop())
+    secret, codes := enrollMFA(t, mfaUC)
+
+    // Subtest: A code is accepted once, then rejected as a replay
+    t.Run("TOTPReplay", func(t *testing.T) {
+        code := totpAt(t, secret, 0)
+        assert.NoError(t, mfaUC.Verify(userRepo.user, code))
+        assert.ErrorIs(t, mfaUC.Verify(userRepo.user, code), usecase.ErrInvalidMFACode)
+    })
+
+    // Subtest: Recovery codes ignore case and dashes and work only once
+    t.Run("RecoveryCode", func(t *testing.T) {
+        typed := strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))
+        assert.NoError(t, mfaUC.Verify(userRepo.user, typed))
+        assert.ErrorIs(t, mfaUC.Verify(userRepo.user, codes[0]), usecase.ErrInvalidMFACode)
+        assert.NoError(t, mfaUC.Verify(userRepo.user, codes[1]))
+    })
+
+    // Subtest: Regenerating invalidates the previous recovery codes
+    t.Run("RegenerateRecoveryCodes", func(t *testing.T) {
+        fresh, err := mfaUC.RegenerateRecoveryCodes(1, codes[2])
+        require.NoError(t, err)
+        assert.ErrorIs(t, mfaUC.Verify(userRepo.user, codes[3]), usecase.ErrInvalidMFACode)
+        assert.NoError(t, mfaUC.Verify(userRepo.user, fresh[0]))
+    })
+}
+
+// TestLoginWithMFA tests the two-step login for users with 2FA enabled
+func TestLoginWithMFA(t *testing.T) {
+    // newMFASetup returns an AuthUsecase for a user with 2FA enabled, with its secret and lockout storage
+    newMFASetup := func(t *testing.T) (domainusecase.AuthUsecaseInterface, string, []string, *mockLoginAttemptRepo) {
+        userRepo := &mockUserRepo{user: newMFAUser()}
+        mfaUC := usecase.NewMFAUsecase(userRepo, newMockMFARepo(), newMockOrgRepo(nil), mfaTestConfig(), zap.NewNop())
+        secret, codes := enrollMFA(t, mfaUC)
+        attemptRepo := newMockLoginAttemptRepo()
+        publisher := &MockRabbitMQClient{}
+        publisher.On("Publish", mock.Anything, messaging.AccountEventsQueue, mock.Anything).Return(nil).Maybe()
+        lockout := usecase.NewLockoutUsecase(attemptRepo, userRepo, publisher, lockoutTestConfig(), zap.NewNop())
+        return usecase.NewAuthUsecase(userRepo, newMockTokenRepo(), lockout, mfaUC, newTestOrgs(), newTestKeySet(), authTestConfig(), zap.NewNop()), secret, codes, attemptRepo
+    }
+
+    // Subtest: The password step returns a challenge instead of tokens
+    t.Run("Success", func(t *testing.T) {
+        authUC, secret, _, _ := newMFASetup(t)
+
+        challenge, err := authUC.Login("amanda@test.com", "123456", "127.0.0.1")
+        require.NoError(t, err)
+        assert.True(t, challenge.MFARequired())
+        assert.False(t, challenge.MFAEnrollmentRequired)
+        assert.Nil(t, challenge.Tokens)
+
+        result, err := authUC.CompleteMFA(challenge.MFAToken, totpAt(t, secret, 0), "127.0.0.1")
+        require.NoError(t, err)
+        assert.NotEmpty(t, result.Tokens.AccessToken)
+        assert.NotEmpty(t, result.Tokens.RefreshToken)
+    })
+
+    // Subtest: A recovery code completes the login when the authenticator is unavailable
+    t.Run("RecoveryCode", func(t *testing.T) {
+        authUC, _, codes, _ := newMFASetup(t)
+        challenge, err := authUC.Login("amanda@test.com", "123456", "127.0.0.1")
+        require.NoError(t, err)
+
+        result, err := authUC.CompleteMFA(challenge.MFAToken, codes[0], "127.0.0.1")
+
+        require.NoError(t, err)
+        assert.NotEmpty(t, result.Tokens.AccessToken)
+    })
+
+    // Subtest: Wrong codes are throttled, and logging in again does not reset the count
+    t.Run("WrongCodesLockOut", func(t *testing.T) {
+        authUC, secret, _, attemptRepo := newMFASetup(t)
+        challenge, err := authUC.Login("amanda@test.com", "123456", "127.0.0.1")
+        require.NoError(t, err)
+
+        _, err = authUC.CompleteMFA(challenge.MFAToken, "000000", "127.0.0.1")
+        assert.ErrorIs(t, err, usecase.ErrInvalidMFACode)
+        _, err = authUC.CompleteMFA(challenge.MFAToken, "111111", "127.0.0.1")
+        assert.ErrorIs(t, err, usecase.ErrInvalidMFACode)
+
+        attemptRepo.expireDelay("account:amanda@test.com")
+        challenge, err = authUC.Login("amanda@test.com", "123456", "127.0.0.1")
+        require.NoError(t, err)
+        _, err = authUC.CompleteMFA(challenge.MFAToken, "222222", "127.0.0.1")
+        assert.ErrorIs(t, err, usecase.ErrInvalidMFACode)
+
+        // The third failure locks the account, even for a valid code
+        _, err = authUC.CompleteMFA(challenge.MFAToken, totpAt(t, secret, 0), "127.0.0.1")
+        assert.ErrorIs(t, err, usecase.ErrTooManyAttempts)
+        assert.Contains(t, attemptRepo.outcomes(), model.AuthOutcomeInvalidMFACode)
+    })
+
+    // Subtest: Only challenge tokens are accepted
+    t.Run("InvalidToken", func(t *testing.T) {
+        authUC, secret, _, _ := newMFASetup(t)
+
+        _, err := authUC.CompleteMFA("not-a-token", totpAt(t, secret, 0), "127.0.0.1")
+
+        assert.ErrorIs(t, err, usecase.ErrInvalidMFAToken)
+    })
+}
+
+// TestMFAPolicy tests the per-role 2FA policy, enrollment during login and the admin reset
+func TestMFAPolicy(t *testing.T) {
+    userRepo := &mockUserRepo{user: newMFAUser()}
+    mfaRepo := newMockMFARepo()
+    mfaUC := usecase.NewMFAUsecase(userRepo, mfaRepo, newMockOrgRepo(nil), mfaTestConfig(), zap.NewNop())
+    authUC := usecase.NewAuthUsecase(userRepo, newMockTokenRepo(), newTestLockout(), mfaUC, newTestOrgs(), newTestKeySet(), authTestConfig(), zap.NewNop())
+
+    assert.ErrorIs(t, mfaUC.SetPolicy("superuser", true), usecase.ErrInvalidRole)
+    require.NoError(t, mfaUC.SetPolicy(model.RoleEditor, true))
+    policies, err := mfaUC.ListPolicies()
+    require.NoError(t, err)
+    require.Len(t, policies, 3)
+    assert.False(t, policies[0].Required)
+    assert.Equal(t, model.RoleEditor, policies[1].Role)
+    assert.True(t, policies[1].Required)
+
+    // An editor without 2FA must enroll before receiving tokens
+    challenge, err := authUC.Login("amanda@test.com", "123456", "127.0.0.1")
+    require.NoError(t, err)
+    assert.True(t, challenge.MFAEnrollmentRequired)
+    assert.Nil(t, challenge.Tokens)
+
+    enrollment, err := authUC.BeginMFAEnrollment(challenge.MFAToken)
+    require.NoError(t, err)
+    result, err := authUC.CompleteMFA(challenge.MFAToken, totpAt(t, enrollment.Secret, 0), "127.0.0.1")
+    require.NoError(t, err)
+    assert.NotEmpty(t, result.Tokens.AccessToken)
+    assert.Len(t, result.RecoveryCodes, 10)
+    assert.True(t, userRepo.user.MFAEnabled())
+
+    // The policy prevents turning 2FA off, but an administrator can reset it
+    assert.ErrorIs(t, mfaUC.Disable(1, totpAt(t, enrollment.Secret, 1)), usecase.ErrMFARequired)
+    require.NoError(t, mfaUC.Reset(1, 99))
+    assert.False(t, userRepo.user.MFAEnabled())
+    assert.Empty(t, mfaRepo.codes[1])
+
+    challenge, err = authUC.Login("amanda@test.com", "123456", "127.0.0.1")
+    require.NoError(t, err)
+    assert.True(t, challenge.MFAEnrollmentRequired)
+}
+
+// TestMFAPolicyOrgRoles tests that policies apply to the roles users hold in their organizations
+func TestMFAPolicyOrgRoles(t *testing.T) {
+    userRepo := &mockUserRepo{user: newMFAUser()}
+    userRepo.user.Role = model.RoleViewer
+    mfaRepo := newMockMFARepo()
+    orgRepo := newMockOrgRepo(map[uint]string{1: model.RoleAdmin})
+    mfaUC := usecase.NewMFAUsecase(userRepo, mfaRepo, orgRepo, mfaTestConfig(), zap.NewNop())
+    require.NoError(t, mfaUC.SetPolicy(model.RoleAdmin, true))
+
+    // A platform viewer administering an organization must enroll
+    challenge, err := mfaUC.Challenge(userRepo.user)
+    require.NoError(t, err)
+    require.NotNil(t, challenge)
+    assert.True(t, challenge.MFAEnrollmentRequired)
+
+    // Once enrolled, the organization role prevents turning 2FA off
+    secret, _ := enrollMFA(t, mfaUC)
+    assert.ErrorIs(t, mfaUC.Disable(1, totpAt(t, secret, 1)), usecase.ErrMFARequired)
+
+    // A lower organization role does not raise the policy above the platform-wide role
+    orgRepo.memberships[0].Role = model.RoleViewer
+    require.NoError(t, mfaUC.Disable(1, totpAt(t, secret, 1)))
+    challenge, err = mfaUC.Challenge(userRepo.user)
+    require.NoError(t, err)
+    assert.Nil(t, challenge)
+}
@@ -0,0 +1,228 @@
+package usecase_test
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "net/textproto"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/config"
+    "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    ucdomain "github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+    "github.com/Amandasilvbr/products-crud/internal/infrastructure/mail"
+    "github.com/Amandasilvbr/products-crud/internal/infrastructure/webhook"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+)
+
+// webhookReceiver is a local incoming webhook recording the JSON payloads it receives
+type webhookReceiver struct {
+    *httptest.Server
+    payloads chan map[string]string
+    status   int
+}
+
+func newWebhookReceiver(t *testing.T) *webhookReceiver {
+    receiver := &webhookReceiver{payloads: make(chan map[string]string, 10), status: http.StatusOK}
+    receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var payload map[string]string
+        if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || r.Header.Get("Content-Type") != "application/json" {
+            w.WriteHeader(http.StatusBadRequest)
+            return
+        }
+        receiver.payloads <- payload
+        w.WriteHeader(receiver.status)
+    }))
+    t.Cleanup(receiver.Close)
+    return receiver
+}
+
+// waitForPayload returns the next received payload or fails the test after a timeout
+func (r *webhookReceiver) waitForPayload(t *testing.T) map[string]string {
+    select {
+    case payload := <-r.payloads:
+        return payload
+    case <-time.After(2 * time.Second):
+        t.Fatal("expected a webhook call")
+        return nil
+    }
+}
+
+// failingMailer rejects every email with the given error
+type failingMailer struct {
+    err error
+}
+
+func (m *failingMailer) Send(ctx context.Context, msg *messaging.Email) error {
+    return m.err
+}
+
+// newChannelDeduplicator returns a deduplicator recording what each channel delivered in memory
+func newChannelDeduplicator() ucdomain.MessageDeduplicatorInterface {
+    return usecase.NewMessageDeduplicator(newMemoryProcessedRepo(), &config.Configs{}, zap.NewNop())
+}
+
+// flakyNotifier is a channel failing its first failures deliveries and recording the event IDs of the others
+type flakyNotifier struct {
+    mu        sync.Mutex
+    channel   string
+    failures  int
+    delivered []string
+}
+
+func (n *flakyNotifier) Channel() string {
+    return n.channel
+}
+
+func (n *flakyNotifier) Notify(ctx context.Context, recipient string, notifications []model.ProductNotification) error {
+    n.mu.Lock()
+    defer n.mu.Unlock()
+    if n.failures > 0 {
+        n.failures--
+        return errors.New("channel unavailable")
+    }
+    for _, notification := range notifications {
+        n.delivered = append(n.delivered, notification.EventID)
+    }
+    return nil
+}
+
+// deliveredIDs returns the event IDs delivered so far
+func (n *flakyNotifier) deliveredIDs() []string {
+    n.mu.Lock()
+    defer n.mu.Unlock()
+    return append([]string(nil), n.delivered...)
+}
+
+// TestNotificationDispatcher tests the routing of notifications to the email, inbox and webhook channels
+func TestNotificationDispatcher(t *testing.T) {
+    ctx := context.Background()
+    logger := zap.NewNop()
+    notifications := []model.ProductNotification{
+        {Event: model.EventProductCreated, SKU: 1, Name: "Caneta <Azul> & Cia", OrgName: "Acme"},
+        {Event: model.EventProductUpdated, SKU: 2, Name: "Lápis", OrgName: "Acme"},
+        {Event: model.EventProductDeleted, SKU: 3, Name: "Borracha"},
+    }
+    rules := map[string][]string{
+        model.EventProductCreated: {messaging.ChannelEmail, messaging.ChannelInbox, messaging.ChannelSlack},
+        model.EventProductDeleted: {messaging.ChannelTeams},
+        usecase.AnyEvent:          {messaging.ChannelInbox},
+    }
+
+    // Subtest: Each channel receives only the events routed to it, in its own format
+    t.Run("Routing", func(t *testing.T) {
+        notificationUC, _, _, mailer := newNotificationTestSetup(t)
+        inboxUC, inboxRepo, _ := newInboxTestSetup()
+        slack := newWebhookReceiver(t)
+        teams := newWebhookReceiver(t)
+        dispatcher := usecase.NewNotificationDispatcher(rules, []messaging.Notifier{
+            mail.NewEmailNotifier(notificationUC, mailer, logger),
+            inboxUC,
+            webhook.NewSlackNotifier(slack.URL, logger),
+            webhook.NewTeamsNotifier(teams.URL, logger),
+        }, newChannelDeduplicator(), logger)
+
+        require.NoError(t, dispatcher.Dispatch(ctx, "amanda@test.com", notifications))
+
+        email := mailer.waitForEmail(t)
+        assert.Contains(t, email.Text, "Caneta <Azul> & Cia")
+        assert.NotContains(t, email.Text, "Lápis")
+        assert.NotContains(t, email.Text, "Borracha")
+
+        require.Len(t, inboxRepo.notifications, 2)
+        assert.Equal(t, model.EventProductCreated, inboxRepo.notifications[0].Event)
+        assert.Equal(t, model.EventProductUpdated, inboxRepo.notifications[1].Event)
+
+        payload := slack.waitForPayload(t)
+        assert.Equal(t, "*Alterações de produtos por amanda@test.com*\n• Produto Caneta &lt;Azul&gt; &amp; Cia (SKU: 1) foi criado [Acme]", payload["text"])
+
+        payload = teams.waitForPayload(t)
+        assert.Equal(t, "MessageCard", payload["@type"])
+        assert.Equal(t, "Alterações de produtos por amanda@test.com", payload["title"])
+        assert.Equal(t, "Produto Borracha (SKU: 3) foi deletado", payload["text"])
+    })
+
+    // Subtest: Channels without routed events are not called
+    t.Run("NothingRouted", func(t *testing.T) {
+        slack := newWebhookReceiver(t)
+        dispatcher := usecase.NewNotificationDispatcher(rules, []messaging.Notifier{webhook.NewSlackNotifier(slack.URL, logger)}, newChannelDeduplicator(), logger)
+
+        require.NoError(t, dispatcher.Dispatch(ctx, "amanda@test.com", notifications[1:]))
+        assert.Empty(t, slack.payloads)
+    })
+
+    // Subtest: Rejected webhooks and emails fail permanently, other failures are retried
+    t.Run("Failures", func(t *testing.T) {
+        gone := newWebhookReceiver(t)
+        gone.status = http.StatusGone
+        busy := newWebhookReceiver(t)
+        busy.status = http.StatusServiceUnavailable
+        notificationUC, _, _, _ := newNotificationTestSetup(t)
+        inboxUC, inboxRepo, _ := newInboxTestSetup()
+
+        dispatcher := usecase.NewNotificationDispatcher(rules, []messaging.Notifier{webhook.NewSlackNotifier(gone.URL, logger), inboxUC}, newChannelDeduplicator(), logger)
+        err := dispatcher.Dispatch(ctx, "amanda@test.com", notifications)
+        assert.ErrorIs(t, err, messaging.ErrPermanentDelivery)
+        // The other channels are still delivered
+        assert.Len(t, inboxRepo.notifications, 2)
+
+        dispatcher = usecase.NewNotificationDispatcher(rules, []messaging.Notifier{webhook.NewTeamsNotifier(busy.URL, logger)}, newChannelDeduplicator(), logger)
+        err = dispatcher.Dispatch(ctx, "amanda@test.com", notifications)
+        require.Error(t, err)
+        assert.False(t, errors.Is(err, messaging.ErrPermanentDelivery))
+
+        // A permanent failure does not prevent the retry of a transient one
+        dispatcher = usecase.NewNotificationDispatcher(rules, []messaging.Notifier{
+            webhook.NewSlackNotifier(gone.URL, logger),
+            webhook.NewTeamsNotifier(busy.URL, logger),
+        }, newChannelDeduplicator(), logger)
+        err = dispatcher.Dispatch(ctx, "amanda@test.com", notifications)
+        require.Error(t, err)
+        assert.False(t, errors.Is(err, messaging.ErrPermanentDelivery))
+
+        rejected := &failingMailer{err: &textproto.Error{Code: 550, Msg: "mailbox unavailable"}}
+        dispatcher = usecase.NewNotificationDispatcher(rules, []messaging.Notifier{mail.NewEmailNotifier(notificationUC, rejected, logger)}, newChannelDeduplicator(), logger)
+        assert.ErrorIs(t, dispatcher.Dispatch(ctx, "amanda@test.com", notifications), messaging.ErrPermanentDelivery)
+
+        unavailable := &failingMailer{err: &textproto.Error{Code: 421, Msg: "try again later"}}
+        dispatcher = usecase.NewNotificationDispatcher(rules, []messaging.Notifier{mail.NewEmailNotifier(notificationUC, unavailable, logger)}, newChannelDeduplicator(), logger)
+        err = dispatcher.Dispatch(ctx, "amanda@test.com", notifications)
+        require.Error(t, err)
+        assert.False(t, errors.Is(err, messaging.ErrPermanentDelivery))
+    })
+
+    // Subtest: A retry only reaches the channels that failed, with the events they did not deliver
+    t.Run("RetryFailedChannels", func(t *testing.T) {
+        routes := map[string][]string{usecase.AnyEvent: {messaging.ChannelInbox, messaging.ChannelSlack}}
+        inbox := &flakyNotifier{channel: messaging.ChannelInbox}
+        slack := &flakyNotifier{channel: messaging.ChannelSlack, failures: 1}
+        dispatcher := usecase.NewNotificationDispatcher(routes, []messaging.Notifier{inbox, slack}, newChannelDeduplicator(), logger)
+        first := []model.ProductNotification{
+            {Event: model.EventProductCreated, SKU: 1, EventID: "event-1"},
+            {Event: model.EventProductUpdated, SKU: 2, EventID: "event-2"},
+        }
+
+        require.Error(t, dispatcher.Dispatch(ctx, "amanda@test.com", first))
+        assert.Equal(t, []string{"event-1", "event-2"}, inbox.deliveredIDs())
+        assert.Empty(t, slack.deliveredIDs())
+
+        // The retried events come back with a new one
+        retried := append(first, model.ProductNotification{Event: model.EventProductDeleted, SKU: 3, EventID: "event-3"})
+        require.NoError(t, dispatcher.Dispatch(ctx, "amanda@test.com", retried))
+        assert.Equal(t, []string{"event-1", "event-2", "event-3"}, inbox.deliveredIDs())
+        assert.Equal(t, []string{"event-1", "event-2", "event-3"}, slack.deliveredIDs())
+
+        // Notifications without an event ID cannot be recognized and are always delivered
+        require.NoError(t, dispatcher.Dispatch(ctx, "amanda@test.com", []model.ProductNotification{{Event: model.EventProductCreated, SKU: 4}}))
+        require.NoError(t, dispatcher.Dispatch(ctx, "amanda@test.com", []model.ProductNotification{{Event: model.EventProductCreated, SKU: 4}}))
+        assert.Len(t, inbox.deliveredIDs(), 5)
+    })
+}
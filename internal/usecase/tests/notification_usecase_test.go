@@ -0,0 +1,440 @@
+package usecase_test
+
+import (
+    "context"
+    "errors"
+    "net/url"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/config"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    ucdomain "github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+    "github.com/Amandasilvbr/products-crud/internal/infrastructure/mail"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+)
+
+// mockNotificationRepo is an in-memory implementation of the notification repository for testing purposes
+type mockNotificationRepo struct {
+    mu          sync.Mutex
+    preferences  map[uint]map[string]string
+    entries      []*model.DigestEntry
+    unsubscribed map[string]bool
+}
+
+func newMockNotificationRepo() *mockNotificationRepo {
+    return &mockNotificationRepo{preferences: make(map[uint]map[string]string), unsubscribed: make(map[string]bool)}
+}
+
+func (m *mockNotificationRepo) FindPreferences(userID uint) ([]*model.NotificationPreference, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    var preferences []*model.NotificationPreference
+    for event, frequency := range m.preferences[userID] {
+        preferences = append(preferences, &model.NotificationPreference{UserID: userID, Event: event, Frequency: frequency})
+    }
+    return preferences, nil
+}
+
+func (m *mockNotificationRepo) SavePreferences(userID uint, preferences []*model.NotificationPreference) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if m.preferences[userID] == nil {
+        m.preferences[userID] = make(map[string]string)
+    }
+    for _, preference := range preferences {
+        m.preferences[userID][preference.Event] = preference.Frequency
+    }
+    return nil
+}
+
+func (m *mockNotificationRepo) IsEmailUnsubscribed(email string) (bool, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return m.unsubscribed[strings.ToLower(email)], nil
+}
+
+func (m *mockNotificationRepo) UnsubscribeEmail(email string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.unsubscribed[strings.ToLower(email)] = true
+    return nil
+}
+
+func (m *mockNotificationRepo) AddDigestEntries(ctx context.Context, entries []*model.DigestEntry) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for _, entry := range entries {
+        // Events are unique per user, as in the database
+        duplicate := false
+        for _, stored := range m.entries {
+            duplicate = duplicate || entry.EventID != "" && stored.UserID == entry.UserID && stored.EventID == entry.EventID
+        }
+        if duplicate {
+            continue
+        }
+        entry.ID = uint(len(m.entries) + 1)
+        if entry.CreatedAt.IsZero() {
+            entry.CreatedAt = time.Now()
+        }
+        m.entries = append(m.entries, entry)
+    }
+    return nil
+}
+
+func (m *mockNotificationRepo) ClaimDueDigestEntries(ctx context.Context, limit int, lease time.Duration) ([]*model.DigestEntry, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    now := time.Now()
+    var claimed []*model.DigestEntry
+    for _, entry := range m.entries {
+        if !entry.DueAt.After(now) && len(claimed) < limit {
+            entry.DueAt = now.Add(lease)
+            copied := *entry
+            claimed = append(claimed, &copied)
+        }
+    }
+    return claimed, nil
+}
+
+func (m *mockNotificationRepo) DeleteDigestEntries(ctx context.Context, ids []uint) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    kept := m.entries[:0]
+    for _, entry := range m.entries {
+        deleted := false
+        for _, id := range ids {
+            deleted = deleted || entry.ID == id
+        }
+        if !deleted {
+            kept = append(kept, entry)
+        }
+    }
+    m.entries = kept
+    return nil
+}
+
+// makeDue makes every stored entry due now
+func (m *mockNotificationRepo) makeDue() {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for _, entry := range m.entries {
+        entry.DueAt = time.Now()
+    }
+}
+
+// pending returns how many entries wait for a digest
+func (m *mockNotificationRepo) pending() int {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return len(m.entries)
+}
+
+// newNotificationTestSetup builds a NotificationUsecase around a single user, rendering the built-in templates
+func newNotificationTestSetup(t *testing.T) (ucdomain.NotificationUsecaseInterface, *mockNotificationRepo, *mockUserRepo, *mockMailer) {
+    return newNotificationTestSetupWithTemplates(t, "")
+}
+
+// newNotificationTestSetupWithTemplates builds a NotificationUsecase whose templates can be overridden by dir
+func newNotificationTestSetupWithTemplates(t *testing.T, dir string) (ucdomain.NotificationUsecaseInterface, *mockNotificationRepo, *mockUserRepo, *mockMailer) {
+    user := &model.User{Name: "Amanda", Email: "amanda@test.com", Role: model.RoleEditor}
+    user.ID = 1
+    cfg := &config.Configs{
+        JWTSecret:       "test-secret",
+        DailyDigestHour: 8,
+        UnsubscribeURL:  "https://api.test/api/notifications/unsubscribe",
+        DefaultTimeZone: "America/Sao_Paulo",
+    }
+    renderer, err := mail.NewTemplateRenderer(dir, cfg.DefaultTimeZone, zap.NewNop())
+    require.NoError(t, err)
+    repo := newMockNotificationRepo()
+    userRepo := &mockUserRepo{user: user}
+    mailer := newMockMailer()
+    return usecase.NewNotificationUsecase(repo, userRepo, mailer, renderer, cfg, zap.NewNop()), repo, userRepo, mailer
+}
+
+// unsubscribeLinkPattern matches the unsubscribe link of an email body
+var unsubscribeLinkPattern = regexp.MustCompile(`https://api\.test/api/notifications/unsubscribe\?token=\S+`)
+
+// unsubscribeToken extracts the token of the unsubscribe link of an email body
+func unsubscribeToken(t *testing.T, body string) string {
+    link := unsubscribeLinkPattern.FindString(body)
+    require.NotEmpty(t, link)
+    parsed, err := url.Parse(link)
+    require.NoError(t, err)
+    token := parsed.Query().Get("token")
+    require.NotEmpty(t, token)
+    return token
+}
+
+// TestNotificationUsecase tests the notification preferences and digests
+func TestNotificationUsecase(t *testing.T) {
+    ctx := context.Background()
+    notifications := []model.ProductNotification{
+        {Event: model.EventProductCreated, SKU: 1, Name: "Caneta", OrgName: "Acme"},
+        {Event: model.EventProductUpdated, SKU: 2, Name: "Lápis", OrgName: "Acme"},
+        {Event: model.EventProductDeleted, SKU: 3, Name: "Borracha", OrgName: "Acme"},
+    }
+
+    // Subtest: Every event is immediate until configured, and only known events and frequencies are accepted
+    t.Run("Preferences", func(t *testing.T) {
+        uc, _, _, _ := newNotificationTestSetup(t)
+
+        settings, err := uc.GetPreferences(1)
+        require.NoError(t, err)
+        assert.Equal(t, map[string]string{
+            model.EventProductCreated: model.FrequencyImmediate,
+            model.EventProductUpdated: model.FrequencyImmediate,
+            model.EventProductDeleted: model.FrequencyImmediate,
+        }, settings.Preferences)
+        assert.Equal(t, model.LocalePortuguese, settings.Locale)
+        assert.Equal(t, "America/Sao_Paulo", settings.TimeZone)
+
+        settings, err = uc.UpdatePreferences(1, model.NotificationSettings{Preferences: map[string]string{model.EventProductUpdated: model.FrequencyDaily}})
+        require.NoError(t, err)
+        assert.Equal(t, model.FrequencyDaily, settings.Preferences[model.EventProductUpdated])
+        assert.Equal(t, model.FrequencyImmediate, settings.Preferences[model.EventProductCreated])
+
+        settings, err = uc.UpdatePreferences(1, model.NotificationSettings{Locale: model.LocaleEnglish, TimeZone: "Europe/Lisbon"})
+        require.NoError(t, err)
+        assert.Equal(t, model.LocaleEnglish, settings.Locale)
+        assert.Equal(t, "Europe/Lisbon", settings.TimeZone)
+        assert.Equal(t, model.FrequencyDaily, settings.Preferences[model.EventProductUpdated])
+
+        _, err = uc.UpdatePreferences(1, model.NotificationSettings{Preferences: map[string]string{"user_created": model.FrequencyOff}})
+        assert.ErrorIs(t, err, usecase.ErrInvalidNotificationEvent)
+        _, err = uc.UpdatePreferences(1, model.NotificationSettings{Preferences: map[string]string{model.EventProductCreated: "weekly"}})
+        assert.ErrorIs(t, err, usecase.ErrInvalidFrequency)
+        _, err = uc.UpdatePreferences(1, model.NotificationSettings{Locale: "fr"})
+        assert.ErrorIs(t, err, usecase.ErrUnsupportedLocale)
+        _, err = uc.UpdatePreferences(1, model.NotificationSettings{TimeZone: "Mars/Olympus"})
+        assert.ErrorIs(t, err, usecase.ErrInvalidTimeZone)
+    })
+
+    // Subtest: Notifications are emailed now, stored for their digest or dropped according to the preferences
+    t.Run("Route", func(t *testing.T) {
+        uc, repo, _, _ := newNotificationTestSetup(t)
+        uc.UpdatePreferences(1, model.NotificationSettings{Preferences: map[string]string{
+            model.EventProductUpdated: model.FrequencyHourly,
+            model.EventProductDeleted: model.FrequencyOff,
+        }})
+
+        routes, email, err := uc.Route(ctx, "amanda@test.com", notifications)
+        require.NoError(t, err)
+        assert.Equal(t, []string{model.FrequencyImmediate, model.FrequencyHourly, model.FrequencyOff}, routes)
+        require.NotNil(t, email)
+        assert.Equal(t, []string{"amanda@test.com"}, email.To)
+        assert.Contains(t, email.Text, "Caneta")
+        assert.NotContains(t, email.Text, "Lápis")
+        assert.Regexp(t, unsubscribeLinkPattern, email.Text)
+        require.Equal(t, 1, repo.pending())
+        entry := repo.entries[0]
+        assert.Equal(t, 2, entry.SKU)
+        assert.Equal(t, time.Now().UTC().Truncate(time.Hour).Add(time.Hour), entry.DueAt)
+
+        // Daily digests are due at the configured hour of the default time zone
+        uc.UpdatePreferences(1, model.NotificationSettings{Preferences: map[string]string{model.EventProductCreated: model.FrequencyDaily}})
+        _, email, _ = uc.Route(ctx, "amanda@test.com", notifications[:1])
+        assert.Nil(t, email)
+        saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+        require.NoError(t, err)
+        due := repo.entries[1].DueAt
+        assert.Equal(t, 8, due.In(saoPaulo).Hour())
+        assert.True(t, due.After(time.Now()))
+        assert.True(t, due.Before(time.Now().Add(24*time.Hour)))
+    })
+
+    // Subtest: Notifications routed again after their email failed are stored once for the digest
+    t.Run("RedeliveryAfterFailedEmail", func(t *testing.T) {
+        uc, repo, _, _ := newNotificationTestSetup(t)
+        uc.UpdatePreferences(1, model.NotificationSettings{Preferences: map[string]string{model.EventProductUpdated: model.FrequencyDaily}})
+        mailer := &scriptedMailer{errs: []error{errors.New("smtp down"), errors.New("smtp down")}}
+        notifier := mail.NewEmailNotifier(uc, mailer, zap.NewNop())
+        redelivered := []model.ProductNotification{
+            {Event: model.EventProductCreated, SKU: 1, Name: "Caneta", OrgName: "Acme", EventID: "evt-created"},
+            {Event: model.EventProductUpdated, SKU: 2, Name: "Lápis", OrgName: "Acme", EventID: "evt-updated"},
+        }
+
+        assert.Error(t, notifier.Notify(ctx, "amanda@test.com", redelivered))
+        assert.Error(t, notifier.Notify(ctx, "amanda@test.com", redelivered))
+        assert.Len(t, mailer.sent, 2)
+        require.Equal(t, 1, repo.pending())
+        assert.Equal(t, "evt-updated", repo.entries[0].EventID)
+    })
+
+    // Subtest: Daily digests are due at the configured hour of the user's own time zone
+    t.Run("DailyDigestTimeZone", func(t *testing.T) {
+        uc, repo, userRepo, _ := newNotificationTestSetup(t)
+        userRepo.user.TimeZone = "Asia/Tokyo"
+        uc.UpdatePreferences(1, model.NotificationSettings{Preferences: map[string]string{model.EventProductCreated: model.FrequencyDaily}})
+
+        _, _, err := uc.Route(ctx, "amanda@test.com", notifications[:1])
+        require.NoError(t, err)
+        tokyo, err := time.LoadLocation("Asia/Tokyo")
+        require.NoError(t, err)
+        require.Equal(t, 1, repo.pending())
+        due := repo.entries[0].DueAt
+        assert.Equal(t, 8, due.In(tokyo).Hour())
+        assert.Equal(t, 0, due.In(tokyo).Minute())
+        assert.True(t, due.After(time.Now()))
+        assert.True(t, due.Before(time.Now().Add(24*time.Hour)))
+    })
+
+    // Subtest: People without an account are notified immediately, with a link that stops every notification
+    t.Run("UnknownRecipient", func(t *testing.T) {
+        uc, repo, userRepo, _ := newNotificationTestSetup(t)
+        userRepo.user = nil
+
+        routes, email, err := uc.Route(ctx, "someone@test.com", notifications)
+        require.NoError(t, err)
+        assert.Equal(t, []string{model.FrequencyImmediate, model.FrequencyImmediate, model.FrequencyImmediate}, routes)
+        require.NotNil(t, email)
+        assert.Equal(t, []string{"someone@test.com"}, email.To)
+        assert.Equal(t, 0, repo.pending())
+        token := unsubscribeToken(t, email.Text)
+
+        // Unsubscribing turns off every event, whatever the link asked for
+        require.NoError(t, uc.Unsubscribe(token, model.EventProductCreated))
+        routes, email, err = uc.Route(ctx, "Someone@Test.com", notifications)
+        require.NoError(t, err)
+        assert.Nil(t, email)
+        assert.Equal(t, []string{model.FrequencyOff, model.FrequencyOff, model.FrequencyOff}, routes)
+    })
+
+    // Subtest: The link of an email turns off one event or all of them
+    t.Run("Unsubscribe", func(t *testing.T) {
+        uc, _, _, _ := newNotificationTestSetup(t)
+        _, email, err := uc.Route(ctx, "amanda@test.com", notifications)
+        require.NoError(t, err)
+        token := unsubscribeToken(t, email.Text)
+
+        require.NoError(t, uc.Unsubscribe(token, model.EventProductCreated))
+        settings, _ := uc.GetPreferences(1)
+        assert.Equal(t, model.FrequencyOff, settings.Preferences[model.EventProductCreated])
+        assert.Equal(t, model.FrequencyImmediate, settings.Preferences[model.EventProductUpdated])
+
+        require.NoError(t, uc.Unsubscribe(token, ""))
+        settings, _ = uc.GetPreferences(1)
+        for _, frequency := range settings.Preferences {
+            assert.Equal(t, model.FrequencyOff, frequency)
+        }
+
+        assert.ErrorIs(t, uc.Unsubscribe("not-a-token", ""), usecase.ErrInvalidUnsubscribeToken)
+        assert.ErrorIs(t, uc.Unsubscribe(token, "user_created"), usecase.ErrInvalidNotificationEvent)
+    })
+
+    // Subtest: Due entries are sent in one digest per user with an unsubscribe link, then removed
+    t.Run("Digests", func(t *testing.T) {
+        uc, repo, _, mailer := newNotificationTestSetup(t)
+        uc.UpdatePreferences(1, model.NotificationSettings{Preferences: map[string]string{
+            model.EventProductCreated: model.FrequencyHourly,
+            model.EventProductUpdated: model.FrequencyDaily,
+        }})
+        uc.Route(ctx, "amanda@test.com", notifications)
+        require.Equal(t, 2, repo.pending())
+
+        // Nothing is sent before the digest is due
+        sent, err := uc.FlushDigests(ctx)
+        require.NoError(t, err)
+        assert.Equal(t, 0, sent)
+
+        repo.makeDue()
+        sent, err = uc.FlushDigests(ctx)
+        require.NoError(t, err)
+        assert.Equal(t, 1, sent)
+        msg := mailer.waitForEmail(t)
+        assert.Equal(t, []string{"amanda@test.com"}, msg.To)
+        assert.Contains(t, msg.Text, "Caneta")
+        assert.Contains(t, msg.Text, "Lápis")
+        assert.Contains(t, msg.Text, "https://api.test/api/notifications/unsubscribe?token=")
+        assert.Equal(t, 0, repo.pending())
+    })
+
+    // Subtest: Entries of events turned off after they were stored are discarded without an email
+    t.Run("DigestAfterUnsubscribe", func(t *testing.T) {
+        uc, repo, _, mailer := newNotificationTestSetup(t)
+        uc.UpdatePreferences(1, model.NotificationSettings{Preferences: map[string]string{model.EventProductCreated: model.FrequencyDaily}})
+        uc.Route(ctx, "amanda@test.com", notifications[:1])
+        uc.UpdatePreferences(1, model.NotificationSettings{Preferences: map[string]string{model.EventProductCreated: model.FrequencyOff}})
+
+        repo.makeDue()
+        sent, err := uc.FlushDigests(ctx)
+        require.NoError(t, err)
+        assert.Equal(t, 0, sent)
+        assert.Equal(t, 0, repo.pending())
+        assert.Empty(t, mailer.sent)
+    })
+
+    // Subtest: Emails follow the locale and time zone of the recipient, and product names are escaped in HTML
+    t.Run("Localization", func(t *testing.T) {
+        uc, repo, userRepo, mailer := newNotificationTestSetup(t)
+        userRepo.user.Name = "Amanda <b>"
+        named := []model.ProductNotification{{Event: model.EventProductCreated, SKU: 9, Name: "<script>alert(1)</script>", OrgName: "Acme"}}
+
+        _, email, err := uc.Route(ctx, "amanda@test.com", named)
+        require.NoError(t, err)
+        assert.Contains(t, email.Subject, "1 Produto foi criado")
+        assert.NotContains(t, email.HTML, "<script>")
+        assert.Contains(t, email.HTML, "&lt;script&gt;")
+        assert.NotContains(t, email.HTML, "Amanda <b>")
+        assert.Contains(t, email.Text, "<script>alert(1)</script>")
+
+        uc.UpdatePreferences(1, model.NotificationSettings{
+            Preferences: map[string]string{model.EventProductCreated: model.FrequencyHourly},
+            Locale:      model.LocaleEnglish,
+            TimeZone:    "Asia/Tokyo",
+        })
+        at := time.Date(2025, 3, 10, 15, 30, 0, 0, time.UTC)
+        uc.Route(ctx, "amanda@test.com", []model.ProductNotification{{Event: model.EventProductCreated, SKU: 9, Name: "Caneta", At: at}})
+        repo.makeDue()
+        _, err = uc.FlushDigests(ctx)
+        require.NoError(t, err)
+        msg := mailer.waitForEmail(t)
+        assert.Equal(t, "Hourly Product Summary: 1 Product was created", msg.Subject)
+        assert.Contains(t, msg.Text, "Mar 11, 2025 12:30 AM")
+    })
+
+    // Subtest: The preview renders every frequency in the requested locale and rejects unknown values
+    t.Run("Preview", func(t *testing.T) {
+        uc, _, _, mailer := newNotificationTestSetup(t)
+
+        for _, frequency := range []string{model.FrequencyImmediate, model.FrequencyHourly, model.FrequencyDaily} {
+            email, err := uc.PreviewDigest(frequency, model.LocaleSpanish, "")
+            require.NoError(t, err)
+            assert.NotEmpty(t, email.Subject)
+            assert.Contains(t, email.HTML, "Caneta &lt;Azul&gt; &amp; Cia")
+            assert.Contains(t, email.Text, "token=preview")
+        }
+        assert.Empty(t, mailer.sent)
+
+        _, err := uc.PreviewDigest(model.FrequencyOff, "", "")
+        assert.ErrorIs(t, err, usecase.ErrInvalidFrequency)
+        _, err = uc.PreviewDigest(model.FrequencyDaily, "fr", "")
+        assert.ErrorIs(t, err, usecase.ErrUnsupportedLocale)
+        _, err = uc.PreviewDigest(model.FrequencyDaily, "", "Mars/Olympus")
+        assert.ErrorIs(t, err, usecase.ErrInvalidTimeZone)
+    })
+
+    // Subtest: Templates and locale keys found in the template directory replace the built-in ones
+    t.Run("TemplateOverride", func(t *testing.T) {
+        dir := t.TempDir()
+        require.NoError(t, os.WriteFile(filepath.Join(dir, "product_digest.txt.tmpl"), []byte("{{.Title}}: {{range .Items}}{{.Name}};{{end}}"), 0o644))
+        require.NoError(t, os.MkdirAll(filepath.Join(dir, "locales"), 0o755))
+        require.NoError(t, os.WriteFile(filepath.Join(dir, "locales", "pt-BR.json"), []byte(`{"title.immediate": "Novidades"}`), 0o644))
+        uc, _, _, _ := newNotificationTestSetupWithTemplates(t, dir)
+
+        _, email, err := uc.Route(ctx, "amanda@test.com", notifications[:2])
+        require.NoError(t, err)
+        assert.Equal(t, "Novidades: Caneta;Lápis;", email.Text)
+        assert.Contains(t, email.HTML, "Novidades")
+        assert.Contains(t, email.Subject, "Produto")
+    })
+}
@@ -0,0 +1,393 @@
+package usecase_test
+
+import (
+    "context"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/json"
+    "math/big"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/config"
+    "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    domainusecase "github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+    "github.com/Amandasilvbr/products-crud/internal/infrastructure/oidc"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/dgrijalva/jwt-go"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+)
+
+// mockOIDCStateRepo is an in-memory implementation of the OIDC state repository for testing purposes
+type mockOIDCStateRepo struct {
+    states map[string]*model.OIDCLoginState
+}
+
+func newMockOIDCStateRepo() *mockOIDCStateRepo {
+    return &mockOIDCStateRepo{states: make(map[string]*model.OIDCLoginState)}
+}
+
+func (m *mockOIDCStateRepo) Create(state *model.OIDCLoginState) error {
+    m.states[state.StateHash] = state
+    return nil
+}
+
+func (m *mockOIDCStateRepo) Consume(stateHash string) (*model.OIDCLoginState, error) {
+    state := m.states[stateHash]
+    delete(m.states, stateHash)
+    return state, nil
+}
+
+// fakeIdentity is the account a user signs in with at the fake identity provider
+type fakeIdentity struct {
+    Subject       string
+    Email         string
+    EmailVerified bool
+    Name          string
+    Groups        []string
+}
+
+// fakeAuthorization is an authorization code waiting to be redeemed at the token endpoint
+type fakeAuthorization struct {
+    identity  fakeIdentity
+    challenge string
+    nonce     string
+}
+
+// fakeOIDCProvider is an in-process OpenID Connect provider serving discovery, JWKS and the token endpoint
+type fakeOIDCProvider struct {
+    server   *httptest.Server
+    key      *rsa.PrivateKey
+    clientID string
+
+    mu    sync.Mutex
+    codes map[string]fakeAuthorization
+}
+
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+    key, err := rsa.GenerateKey(rand.Reader, 2048)
+    require.NoError(t, err)
+    p := &fakeOIDCProvider{key: key, clientID: "products-crud", codes: make(map[string]fakeAuthorization)}
+
+    mux := http.NewServeMux()
+    mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, map[string]interface{}{
+            "issuer":                                p.server.URL,
+            "authorization_endpoint":                p.server.URL + "/authorize",
+            "token_endpoint":                        p.server.URL + "/token",
+            "jwks_uri":                              p.server.URL + "/keys",
+            "response_types_supported":              []string{"code"},
+            "subject_types_supported":               []string{"public"},
+            "id_token_signing_alg_values_supported": []string{"RS256"},
+        })
+    })
+    mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, map[string]interface{}{
+            "keys": []map[string]string{{
+                "kty": "RSA",
+                "kid": "test-key",
+                "alg": "RS256",
+                "use": "sig",
+                "n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+                "e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+            }},
+        })
+    })
+    mux.HandleFunc("/token", p.token)
+    p.server = httptest.NewServer(mux)
+    t.Cleanup(p.server.Close)
+    return p
+}
+
+// token redeems an authorization code, checking the PKCE verifier against the stored S256 challenge
+func (p *fakeOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+    if err := r.ParseForm(); err != nil {
+        http.Error(w, "bad request", http.StatusBadRequest)
+        return
+    }
+    p.mu.Lock()
+    auth, ok := p.codes[r.PostForm.Get("code")]
+    delete(p.codes, r.PostForm.Get("code"))
+    p.mu.Unlock()
+
+    sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+    if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != auth.challenge {
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(http.StatusBadRequest)
+        json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+        return
+    }
+
+    idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+        "iss":            p.server.URL,
+        "aud":            p.clientID,
+        "sub":            auth.identity.Subject,
+        "nonce":          auth.nonce,
+        "email":          auth.identity.Email,
+        "email_verified": auth.identity.EmailVerified,
+        "name":           auth.identity.Name,
+        "groups":         auth.identity.Groups,
+        "iat":            time.Now().Unix(),
+        "exp":            time.Now().Add(time.Hour).Unix(),
+    })
+    idToken.Header["kid"] = "test-key"
+    signed, err := idToken.SignedString(p.key)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    writeJSON(w, map[string]interface{}{
+        "access_token": "idp-access-token",
+        "token_type":   "Bearer",
+        "expires_in":   3600,
+        "id_token":     signed,
+    })
+}
+
+// authorize plays the browser and the provider's login page: it signs the identity in and
+// returns the code and state the provider would send to the redirect URL
+func (p *fakeOIDCProvider) authorize(t *testing.T, authURL string, identity fakeIdentity) (string, string) {
+    u, err := url.Parse(authURL)
+    require.NoError(t, err)
+    query := u.Query()
+    require.Equal(t, p.clientID, query.Get("client_id"))
+    require.Equal(t, "S256", query.Get("code_challenge_method"))
+    require.NotEmpty(t, query.Get("nonce"))
+
+    code := "code-" + query.Get("state")[:8]
+    p.mu.Lock()
+    p.codes[code] = fakeAuthorization{identity: identity, challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+    p.mu.Unlock()
+    return code, query.Get("state")
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(body)
+}
+
+// oidcTestConfig returns the OIDC settings pointing at the fake provider
+func oidcTestConfig(p *fakeOIDCProvider) *config.Configs {
+    return &config.Configs{
+        OIDCIssuerURL:     p.server.URL,
+        OIDCClientID:      p.clientID,
+        OIDCClientSecret:  "client-secret",
+        OIDCRedirectURL:   "http://localhost:8080/api/oidc/callback",
+        OIDCScopes:        []string{"openid", "email", "profile"},
+        OIDCGroupsClaim:   "groups",
+        OIDCRoleMapping:   map[string]string{"crud-admins": model.RoleAdmin, "crud-editors": model.RoleEditor},
+        OIDCDefaultRole:   model.RoleViewer,
+        OIDCAutoProvision: true,
+    }
+}
+
+// oidcSetup holds an OIDC use case wired to the fake provider and in-memory storage
+type oidcSetup struct {
+    provider    *fakeOIDCProvider
+    oidcUC      domainusecase.OIDCUsecaseInterface
+    stateRepo   *mockOIDCStateRepo
+    attemptRepo *mockLoginAttemptRepo
+}
+
+func newOIDCSetup(t *testing.T, userRepo *emailLookupRepo, configure func(*config.Configs)) *oidcSetup {
+    fake := newFakeOIDCProvider(t)
+    cfg := oidcTestConfig(fake)
+    if configure != nil {
+        configure(cfg)
+    }
+
+    provider, err := oidc.NewProvider(context.Background(), cfg, zap.NewNop())
+    require.NoError(t, err)
+
+    attemptRepo := newMockLoginAttemptRepo()
+    publisher := &MockRabbitMQClient{}
+    publisher.On("Publish", mock.Anything, messaging.AccountEventsQueue, mock.Anything).Return(nil).Maybe()
+    lockout := usecase.NewLockoutUsecase(attemptRepo, userRepo, publisher, lockoutTestConfig(), zap.NewNop())
+    authUC := usecase.NewAuthUsecase(userRepo, newMockTokenRepo(), lockout, newTestMFA(), newTestOrgs(), newTestKeySet(), authTestConfig(), zap.NewNop())
+    stateRepo := newMockOIDCStateRepo()
+
+    return &oidcSetup{
+        provider:    fake,
+        oidcUC:      usecase.NewOIDCUsecase(provider, stateRepo, userRepo, authUC, lockout, cfg, zap.NewNop()),
+        stateRepo:   stateRepo,
+        attemptRepo: attemptRepo,
+    }
+}
+
+// login runs the whole redirect flow for the identity and returns the application's tokens
+func (s *oidcSetup) login(t *testing.T, identity fakeIdentity) (*model.TokenPair, error) {
+    authURL, err := s.oidcUC.BeginLogin()
+    require.NoError(t, err)
+    code, state := s.provider.authorize(t, authURL, identity)
+    return s.oidcUC.CompleteLogin(context.Background(), state, code, "127.0.0.1")
+}
+
+// TestOIDCLogin tests the authorization code flow with PKCE against an in-process identity provider
+func TestOIDCLogin(t *testing.T) {
+    alice := fakeIdentity{Subject: "idp-alice", Email: "alice@corp.test", EmailVerified: true, Name: "Alice", Groups: []string{"crud-editors", "crud-admins"}}
+
+    // Subtest: An unknown identity is provisioned with the most privileged mapped role
+    t.Run("ProvisionsUser", func(t *testing.T) {
+        repo := &emailLookupRepo{mockUserRepo: &mockUserRepo{}}
+        s := newOIDCSetup(t, repo, nil)
+
+        tokens, err := s.login(t, alice)
+
+        require.NoError(t, err)
+        assert.NotEmpty(t, tokens.AccessToken)
+        assert.NotEmpty(t, tokens.RefreshToken)
+        require.NotNil(t, repo.user)
+        assert.Equal(t, "Alice", repo.user.Name)
+        assert.Equal(t, model.RoleAdmin, repo.user.Role)
+        assert.True(t, repo.user.IsVerified())
+        assert.Empty(t, repo.user.Password)
+        require.NotNil(t, repo.user.OIDCSubject)
+        assert.Equal(t, "idp-alice", *repo.user.OIDCSubject)
+        assert.Contains(t, s.attemptRepo.outcomes(), model.AuthOutcomeOIDC)
+
+        // Later logins find the account by subject and follow group changes
+        demoted := alice
+        demoted.Groups = []string{"crud-editors"}
+        _, err = s.login(t, demoted)
+        require.NoError(t, err)
+        assert.Equal(t, model.RoleEditor, repo.user.Role)
+    })
+
+    // Subtest: Without a mapped group the configured default role is used, and a taken name falls back to the email
+    t.Run("DefaultRole", func(t *testing.T) {
+        repo := &emailLookupRepo{mockUserRepo: &mockUserRepo{nameTaken: true}}
+        s := newOIDCSetup(t, repo, nil)
+
+        _, err := s.login(t, fakeIdentity{Subject: "idp-bob", Email: "bob@corp.test", EmailVerified: true, Name: "Bob", Groups: []string{"other"}})
+
+        require.NoError(t, err)
+        assert.Equal(t, model.RoleViewer, repo.user.Role)
+        assert.Equal(t, "bob@corp.test", repo.user.Name)
+    })
+
+    // Subtest: An existing local account is linked when the provider verified the email
+    t.Run("LinksByVerifiedEmail", func(t *testing.T) {
+        repo := &emailLookupRepo{mockUserRepo: &mockUserRepo{user: newMFAUser()}}
+        s := newOIDCSetup(t, repo, nil)
+
+        _, err := s.login(t, fakeIdentity{Subject: "idp-amanda", Email: "amanda@test.com", EmailVerified: true, Name: "Amanda"})
+
+        require.NoError(t, err)
+        assert.Equal(t, uint(1), repo.user.ID)
+        require.NotNil(t, repo.user.OIDCSubject)
+        assert.Equal(t, "idp-amanda", *repo.user.OIDCSubject)
+        assert.Equal(t, model.RoleEditor, repo.user.Role)
+    })
+
+    // Subtest: An unverified email never links to an existing account
+    t.Run("UnverifiedEmailNotLinked", func(t *testing.T) {
+        repo := &emailLookupRepo{mockUserRepo: &mockUserRepo{user: newMFAUser()}}
+        s := newOIDCSetup(t, repo, nil)
+
+        tokens, err := s.login(t, fakeIdentity{Subject: "idp-mallory", Email: "amanda@test.com", EmailVerified: false})
+
+        assert.ErrorIs(t, err, usecase.ErrOIDCEmailNotVerified)
+        assert.Nil(t, tokens)
+        assert.Nil(t, repo.user.OIDCSubject)
+    })
+
+    // Subtest: Without auto-provisioning unknown identities are refused
+    t.Run("AutoProvisionDisabled", func(t *testing.T) {
+        repo := &emailLookupRepo{mockUserRepo: &mockUserRepo{}}
+        s := newOIDCSetup(t, repo, func(cfg *config.Configs) { cfg.OIDCAutoProvision = false })
+
+        _, err := s.login(t, alice)
+
+        assert.ErrorIs(t, err, usecase.ErrOIDCAccountNotFound)
+        assert.Nil(t, repo.user)
+    })
+
+    // Subtest: Disabled accounts cannot sign in through the provider
+    t.Run("DisabledUser", func(t *testing.T) {
+        user := newMFAUser()
+        subject := "idp-amanda"
+        user.OIDCSubject = &subject
+        now := time.Now()
+        user.DisabledAt = &now
+        repo := &emailLookupRepo{mockUserRepo: &mockUserRepo{user: user}}
+        s := newOIDCSetup(t, repo, nil)
+
+        tokens, err := s.login(t, fakeIdentity{Subject: "idp-amanda", Email: "amanda@test.com", EmailVerified: true})
+
+        assert.ErrorIs(t, err, usecase.ErrUserDisabled)
+        assert.Nil(t, tokens)
+    })
+
+    // Subtest: States are single use, and unknown or expired states are rejected
+    t.Run("InvalidState", func(t *testing.T) {
+        repo := &emailLookupRepo{mockUserRepo: &mockUserRepo{}}
+        s := newOIDCSetup(t, repo, nil)
+
+        authURL, err := s.oidcUC.BeginLogin()
+        require.NoError(t, err)
+        code, state := s.provider.authorize(t, authURL, alice)
+        _, err = s.oidcUC.CompleteLogin(context.Background(), state, code, "127.0.0.1")
+        require.NoError(t, err)
+
+        _, err = s.oidcUC.CompleteLogin(context.Background(), state, code, "127.0.0.1")
+        assert.ErrorIs(t, err, usecase.ErrInvalidOIDCState)
+
+        _, err = s.oidcUC.CompleteLogin(context.Background(), "unknown", code, "127.0.0.1")
+        assert.ErrorIs(t, err, usecase.ErrInvalidOIDCState)
+
+        authURL, err = s.oidcUC.BeginLogin()
+        require.NoError(t, err)
+        code, state = s.provider.authorize(t, authURL, alice)
+        for _, stored := range s.stateRepo.states {
+            stored.ExpiresAt = time.Now().Add(-time.Second)
+        }
+        _, err = s.oidcUC.CompleteLogin(context.Background(), state, code, "127.0.0.1")
+        assert.ErrorIs(t, err, usecase.ErrInvalidOIDCState)
+    })
+
+    // Subtest: The code cannot be redeemed without the matching PKCE verifier
+    t.Run("PKCEVerifierMismatch", func(t *testing.T) {
+        repo := &emailLookupRepo{mockUserRepo: &mockUserRepo{}}
+        s := newOIDCSetup(t, repo, nil)
+
+        authURL, err := s.oidcUC.BeginLogin()
+        require.NoError(t, err)
+        code, state := s.provider.authorize(t, authURL, alice)
+        for _, stored := range s.stateRepo.states {
+            stored.CodeVerifier = "another-verifier-that-does-not-match-the-challenge"
+        }
+
+        _, err = s.oidcUC.CompleteLogin(context.Background(), state, code, "127.0.0.1")
+
+        assert.ErrorIs(t, err, usecase.ErrOIDCLoginFailed)
+        assert.Nil(t, repo.user)
+    })
+
+    // Subtest: An ID token issued for another login's nonce is rejected
+    t.Run("NonceMismatch", func(t *testing.T) {
+        repo := &emailLookupRepo{mockUserRepo: &mockUserRepo{}}
+        s := newOIDCSetup(t, repo, nil)
+
+        authURL, err := s.oidcUC.BeginLogin()
+        require.NoError(t, err)
+        code, state := s.provider.authorize(t, authURL, alice)
+        for _, stored := range s.stateRepo.states {
+            stored.Nonce = "another-nonce"
+        }
+
+        _, err = s.oidcUC.CompleteLogin(context.Background(), state, code, "127.0.0.1")
+
+        assert.ErrorIs(t, err, usecase.ErrOIDCLoginFailed)
+        assert.Nil(t, repo.user)
+    })
+}
@@ -0,0 +1,257 @@
+package usecase_test
+
+import (
+    "context"
+    "testing"
+
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    "github.com/Amandasilvbr/products-crud/internal/domain/tenant"
+    domainusecase "github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+)
+
+// mockOrgRepo is an in-memory implementation of the organization repository for testing purposes
+type mockOrgRepo struct {
+    orgs        []*model.Organization
+    memberships []*model.Membership
+}
+
+// newMockOrgRepo returns a repository holding the organization "Acme" with ID 1 and the given memberships in it
+func newMockOrgRepo(members map[uint]string) *mockOrgRepo {
+    repo := &mockOrgRepo{orgs: []*model.Organization{{ID: 1, Name: "Acme", Slug: "acme"}}}
+    for userID, role := range members {
+        repo.memberships = append(repo.memberships, &model.Membership{UserID: userID, OrgID: 1, Role: role})
+    }
+    return repo
+}
+
+func (m *mockOrgRepo) Create(org *model.Organization) error {
+    org.ID = uint(len(m.orgs) + 1)
+    m.orgs = append(m.orgs, org)
+    return nil
+}
+
+func (m *mockOrgRepo) FindByID(id uint) (*model.Organization, error) {
+    for _, org := range m.orgs {
+        if org.ID == id {
+            return org, nil
+        }
+    }
+    return nil, nil
+}
+
+func (m *mockOrgRepo) FindBySlug(slug string) (*model.Organization, error) {
+    for _, org := range m.orgs {
+        if org.Slug == slug {
+            return org, nil
+        }
+    }
+    return nil, nil
+}
+
+func (m *mockOrgRepo) List() ([]*model.Organization, error) {
+    return m.orgs, nil
+}
+
+func (m *mockOrgRepo) FindMembership(userID, orgID uint) (*model.Membership, error) {
+    for _, membership := range m.memberships {
+        if membership.UserID == userID && membership.OrgID == orgID {
+            membership.Organization, _ = m.FindByID(orgID)
+            return membership, nil
+        }
+    }
+    return nil, nil
+}
+
+func (m *mockOrgRepo) ListMemberships(userID uint) ([]*model.Membership, error) {
+    var memberships []*model.Membership
+    for _, membership := range m.memberships {
+        if membership.UserID == userID {
+            membership.Organization, _ = m.FindByID(membership.OrgID)
+            memberships = append(memberships, membership)
+        }
+    }
+    return memberships, nil
+}
+
+func (m *mockOrgRepo) ListMembers(orgID uint) ([]*model.Membership, error) {
+    var members []*model.Membership
+    for _, membership := range m.memberships {
+        if membership.OrgID == orgID {
+            members = append(members, membership)
+        }
+    }
+    return members, nil
+}
+
+func (m *mockOrgRepo) CountAdmins(orgID uint) (int64, error) {
+    var admins int64
+    for _, membership := range m.memberships {
+        if membership.OrgID == orgID && membership.Role == model.RoleAdmin {
+            admins++
+        }
+    }
+    return admins, nil
+}
+
+func (m *mockOrgRepo) SaveMembership(membership *model.Membership) error {
+    if existing, _ := m.FindMembership(membership.UserID, membership.OrgID); existing != nil {
+        existing.Role = membership.Role
+        return nil
+    }
+    m.memberships = append(m.memberships, membership)
+    return nil
+}
+
+func (m *mockOrgRepo) DeleteMembership(userID, orgID uint) (bool, error) {
+    for i, membership := range m.memberships {
+        if membership.UserID == userID && membership.OrgID == orgID {
+            m.memberships = append(m.memberships[:i], m.memberships[i+1:]...)
+            return true, nil
+        }
+    }
+    return false, nil
+}
+
+// newTestOrgs returns an organization use case without any organization, for tests that do not involve tenants
+func newTestOrgs() domainusecase.OrganizationUsecaseInterface {
+    return usecase.NewOrganizationUsecase(&mockOrgRepo{}, &mockUserRepo{}, zap.NewNop())
+}
+
+// TestOrganizations tests memberships, their administration and the resolution of the active organization
+func TestOrganizations(t *testing.T) {
+    admin := &model.Actor{ID: 1, Email: "admin@test.com", Role: model.RoleViewer}
+    outsider := &model.Actor{ID: 9, Email: "outsider@test.com", Role: model.RoleEditor}
+
+    // Subtest: Members resolve their own role; other organizations are not found
+    t.Run("ResolveMembership", func(t *testing.T) {
+        orgs := usecase.NewOrganizationUsecase(newMockOrgRepo(map[uint]string{1: model.RoleAdmin, 2: model.RoleViewer}), &mockUserRepo{}, zap.NewNop())
+
+        membership, err := orgs.ResolveMembership(2, model.RoleEditor, 1)
+        require.NoError(t, err)
+        assert.Equal(t, model.RoleViewer, membership.Role)
+        assert.Equal(t, "Acme", membership.Organization.Name)
+
+        _, err = orgs.ResolveMembership(9, model.RoleEditor, 1)
+        assert.ErrorIs(t, err, usecase.ErrOrgNotFound)
+        _, err = orgs.ResolveMembership(2, model.RoleViewer, 42)
+        assert.ErrorIs(t, err, usecase.ErrOrgNotFound)
+    })
+
+    // Subtest: Platform admins act as admins of every existing organization
+    t.Run("PlatformAdmin", func(t *testing.T) {
+        orgs := usecase.NewOrganizationUsecase(newMockOrgRepo(nil), &mockUserRepo{}, zap.NewNop())
+
+        membership, err := orgs.ResolveMembership(5, model.RoleAdmin, 1)
+        require.NoError(t, err)
+        assert.Equal(t, model.RoleAdmin, membership.Role)
+
+        _, err = orgs.ResolveMembership(5, model.RoleAdmin, 42)
+        assert.ErrorIs(t, err, usecase.ErrOrgNotFound)
+    })
+
+    // Subtest: Only organization admins manage members, and outsiders do not learn the organization exists
+    t.Run("ManageMembers", func(t *testing.T) {
+        member := &model.User{Name: "Maria", Email: "maria@test.com", Role: model.RoleViewer}
+        member.ID = 2
+        repo := newMockOrgRepo(map[uint]string{1: model.RoleAdmin})
+        orgs := usecase.NewOrganizationUsecase(repo, &mockUserRepo{user: member}, zap.NewNop())
+
+        _, err := orgs.AddMember(outsider, 1, "maria@test.com", model.RoleEditor)
+        assert.ErrorIs(t, err, usecase.ErrOrgNotFound)
+
+        membership, err := orgs.AddMember(admin, 1, "maria@test.com", model.RoleEditor)
+        require.NoError(t, err)
+        assert.Equal(t, model.RoleEditor, membership.Role)
+
+        editor := &model.Actor{ID: 2, Email: "maria@test.com", Role: model.RoleViewer}
+        _, err = orgs.ListMembers(editor, 1)
+        assert.ErrorIs(t, err, usecase.ErrOrgForbidden)
+
+        _, err = orgs.UpdateMember(admin, 1, 2, "owner")
+        assert.ErrorIs(t, err, usecase.ErrInvalidRole)
+
+        assert.NoError(t, orgs.RemoveMember(admin, 1, 2))
+        assert.ErrorIs(t, orgs.RemoveMember(admin, 1, 2), usecase.ErrMemberNotFound)
+    })
+
+    // Subtest: The last admin of an organization can neither be demoted nor removed
+    t.Run("LastAdmin", func(t *testing.T) {
+        orgs := usecase.NewOrganizationUsecase(newMockOrgRepo(map[uint]string{1: model.RoleAdmin}), &mockUserRepo{}, zap.NewNop())
+
+        _, err := orgs.UpdateMember(admin, 1, 1, model.RoleEditor)
+        assert.ErrorIs(t, err, usecase.ErrLastOrgAdmin)
+        assert.ErrorIs(t, orgs.RemoveMember(admin, 1, 1), usecase.ErrLastOrgAdmin)
+    })
+
+    // Subtest: Slugs must be well formed and unique
+    t.Run("CreateOrganization", func(t *testing.T) {
+        orgs := usecase.NewOrganizationUsecase(newMockOrgRepo(nil), &mockUserRepo{}, zap.NewNop())
+
+        org, err := orgs.CreateOrganization("Globex", "Globex-Corp")
+        require.NoError(t, err)
+        assert.Equal(t, "globex-corp", org.Slug)
+
+        _, err = orgs.CreateOrganization("Acme again", "acme")
+        assert.ErrorIs(t, err, usecase.ErrOrgSlugTaken)
+        _, err = orgs.CreateOrganization("Bad", "bad slug!")
+        assert.ErrorIs(t, err, usecase.ErrInvalidOrgSlug)
+    })
+
+    // Subtest: Sessions start in the last organization switched to and keep it across refreshes
+    t.Run("SwitchOrganization", func(t *testing.T) {
+        user := &model.User{Name: "Maria", Email: "maria@test.com", Role: model.RoleViewer}
+        user.ID = 2
+        userRepo := &mockUserRepo{user: user}
+        repo := newMockOrgRepo(map[uint]string{2: model.RoleEditor})
+        repo.orgs = append(repo.orgs, &model.Organization{ID: 2, Name: "Globex", Slug: "globex"})
+        repo.memberships = append(repo.memberships, &model.Membership{UserID: 2, OrgID: 2, Role: model.RoleViewer})
+        orgs := usecase.NewOrganizationUsecase(repo, userRepo, zap.NewNop())
+        keys := newTestKeySet()
+        authUC := usecase.NewAuthUsecase(userRepo, newMockTokenRepo(), newTestLockout(), newTestMFA(), orgs, keys, authTestConfig(), zap.NewNop())
+
+        tokens, err := authUC.StartSession(user)
+        require.NoError(t, err)
+        claims, err := keys.Verify(tokens.AccessToken)
+        require.NoError(t, err)
+        assert.Equal(t, float64(1), claims["org_id"])
+
+        _, err = authUC.SwitchOrganization(2, 3)
+        assert.ErrorIs(t, err, usecase.ErrOrgNotFound)
+
+        tokens, err = authUC.SwitchOrganization(2, 2)
+        require.NoError(t, err)
+        claims, _ = keys.Verify(tokens.AccessToken)
+        assert.Equal(t, float64(2), claims["org_id"])
+        require.NotNil(t, user.ActiveOrgID)
+        assert.Equal(t, uint(2), *user.ActiveOrgID)
+
+        refreshed, err := authUC.RefreshToken(tokens.RefreshToken)
+        require.NoError(t, err)
+        claims, _ = keys.Verify(refreshed.AccessToken)
+        assert.Equal(t, float64(2), claims["org_id"])
+
+        // Once removed from the organization, the session falls back to the remaining one
+        _, err = repo.DeleteMembership(2, 2)
+        require.NoError(t, err)
+        refreshed, err = authUC.RefreshToken(refreshed.RefreshToken)
+        require.NoError(t, err)
+        claims, _ = keys.Verify(refreshed.AccessToken)
+        assert.Equal(t, float64(1), claims["org_id"])
+    })
+
+    // Subtest: The tenant context only reports a real organization
+    t.Run("TenantContext", func(t *testing.T) {
+        _, ok := tenant.OrgID(context.Background())
+        assert.False(t, ok)
+        _, ok = tenant.OrgID(tenant.WithOrg(context.Background(), 0))
+        assert.False(t, ok)
+
+        orgID, ok := tenant.OrgID(tenant.WithOrg(context.Background(), 7))
+        assert.True(t, ok)
+        assert.Equal(t, uint(7), orgID)
+    })
+}
@@ -0,0 +1,209 @@
+package usecase_test
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/config"
+    "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+)
+
+// mockOutboxRepo is an in-memory implementation of the outbox repository for testing purposes
+type mockOutboxRepo struct {
+    mu     sync.Mutex
+    events []*model.OutboxEvent
+}
+
+// add stores an event that is due now, as the product repository does within its transaction
+func (m *mockOutboxRepo) add(event string) *model.OutboxEvent {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    e := &model.OutboxEvent{ID: uint(len(m.events) + 1), Queue: "product_events", Event: event, Payload: fmt.Sprintf(`{"event":%q}`, event), AvailableAt: time.Now()}
+    m.events = append(m.events, e)
+    return e
+}
+
+func (m *mockOutboxRepo) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEvent, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    now := time.Now()
+    var claimed []*model.OutboxEvent
+    for _, e := range m.events {
+        if e.SentAt == nil && !e.AvailableAt.After(now) && len(claimed) < limit {
+            e.AvailableAt = now.Add(lease)
+            copied := *e
+            claimed = append(claimed, &copied)
+        }
+    }
+    return claimed, nil
+}
+
+func (m *mockOutboxRepo) MarkSent(ctx context.Context, id uint) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    now := time.Now()
+    m.events[id-1].SentAt = &now
+    return nil
+}
+
+func (m *mockOutboxRepo) MarkFailed(ctx context.Context, id uint, lastError string, retryAt time.Time) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    e := m.events[id-1]
+    e.Attempts++
+    e.LastError = lastError
+    e.AvailableAt = retryAt
+    return nil
+}
+
+func (m *mockOutboxRepo) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+    return 0, nil
+}
+
+// sent reports whether the event was marked as published
+func (m *mockOutboxRepo) sent(e *model.OutboxEvent) bool {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return e.SentAt != nil
+}
+
+// makeDue makes every unsent event available to the relay again
+func (m *mockOutboxRepo) makeDue() {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for _, e := range m.events {
+        e.AvailableAt = time.Now()
+    }
+}
+
+func outboxTestConfig() *config.Configs {
+    return &config.Configs{
+        OutboxPollInterval: 10 * time.Millisecond,
+        OutboxBatchSize:    2,
+        OutboxMaxBackoff:   time.Minute,
+        OutboxRetention:    time.Hour,
+    }
+}
+
+// TestOutboxRelay tests the publication of the events stored in the outbox
+func TestOutboxRelay(t *testing.T) {
+    // Subtest: Events are kept and retried with growing delays while RabbitMQ is down, then delivered
+    t.Run("RetryUntilBrokerRecovers", func(t *testing.T) {
+        repo := &mockOutboxRepo{}
+        publisher := &MockRabbitMQClient{}
+        relay := usecase.NewOutboxRelay(repo, publisher, outboxTestConfig(), zap.NewNop())
+        created := repo.add("product_created")
+        updated := repo.add("product_updated")
+
+        publisher.On("Publish", mock.Anything, "product_events", mock.Anything).Return(fmt.Errorf("connection refused")).Twice()
+        sent, err := relay.Flush(context.Background())
+        require.NoError(t, err)
+        assert.Equal(t, 0, sent)
+        assert.Equal(t, 1, created.Attempts)
+        assert.Equal(t, "connection refused", created.LastError)
+        assert.WithinDuration(t, time.Now().Add(time.Second), created.AvailableAt, 500*time.Millisecond)
+
+        // Events are not retried before their delay
+        sent, _ = relay.Flush(context.Background())
+        assert.Equal(t, 0, sent)
+
+        repo.makeDue()
+        publisher.On("Publish", mock.Anything, "product_events", mock.Anything).Return(fmt.Errorf("connection refused")).Twice()
+        relay.Flush(context.Background())
+        assert.Equal(t, 2, updated.Attempts)
+        assert.WithinDuration(t, time.Now().Add(2*time.Second), updated.AvailableAt, 500*time.Millisecond)
+
+        repo.makeDue()
+        publisher.On("Publish", mock.Anything, "product_events", `{"event":"product_created"}`).Return(nil).Once()
+        publisher.On("Publish", mock.Anything, "product_events", `{"event":"product_updated"}`).Return(nil).Once()
+        sent, err = relay.Flush(context.Background())
+        require.NoError(t, err)
+        assert.Equal(t, 2, sent)
+        assert.True(t, repo.sent(created))
+        assert.True(t, repo.sent(updated))
+        publisher.AssertExpectations(t)
+    })
+
+    // Subtest: The delay between attempts doubles up to the configured maximum
+    t.Run("BackoffCapped", func(t *testing.T) {
+        repo := &mockOutboxRepo{}
+        publisher := &MockRabbitMQClient{}
+        relay := usecase.NewOutboxRelay(repo, publisher, outboxTestConfig(), zap.NewNop())
+        event := repo.add("product_deleted")
+        event.Attempts = 30
+
+        publisher.On("Publish", mock.Anything, "product_events", mock.Anything).Return(fmt.Errorf("timeout")).Once()
+        relay.Flush(context.Background())
+        assert.WithinDuration(t, time.Now().Add(time.Minute), event.AvailableAt, 500*time.Millisecond)
+    })
+
+    // Subtest: An event published again after a failure keeps its message ID, so consumers recognize it
+    t.Run("StableMessageID", func(t *testing.T) {
+        repo := &mockOutboxRepo{}
+        publisher := &MockRabbitMQClient{}
+        relay := usecase.NewOutboxRelay(repo, publisher, outboxTestConfig(), zap.NewNop())
+        repo.add("product_created")
+        event := repo.add("product_updated")
+
+        withEventID := mock.MatchedBy(func(ctx context.Context) bool {
+            id, ok := messaging.MessageID(ctx)
+            return ok && id == "2"
+        })
+        publisher.On("Publish", mock.Anything, "product_events", `{"event":"product_created"}`).Return(nil).Once()
+        publisher.On("Publish", withEventID, "product_events", `{"event":"product_updated"}`).Return(fmt.Errorf("timeout")).Once()
+        relay.Flush(context.Background())
+        require.Equal(t, 1, event.Attempts)
+
+        repo.makeDue()
+        publisher.On("Publish", withEventID, "product_events", `{"event":"product_updated"}`).Return(nil).Once()
+        sent, err := relay.Flush(context.Background())
+        require.NoError(t, err)
+        assert.Equal(t, 1, sent)
+        publisher.AssertExpectations(t)
+    })
+
+    // Subtest: The running relay drains the outbox in batches and stops with its context
+    t.Run("Run", func(t *testing.T) {
+        repo := &mockOutboxRepo{}
+        publisher := &MockRabbitMQClient{}
+        publisher.On("Publish", mock.Anything, "product_events", mock.Anything).Return(nil)
+        relay := usecase.NewOutboxRelay(repo, publisher, outboxTestConfig(), zap.NewNop())
+        var events []*model.OutboxEvent
+        for i := 0; i < 5; i++ {
+            events = append(events, repo.add("product_created"))
+        }
+
+        ctx, cancel := context.WithCancel(context.Background())
+        done := make(chan error)
+        go func() { done <- relay.Run(ctx) }()
+
+        assert.Eventually(t, func() bool {
+            for _, e := range events {
+                if !repo.sent(e) {
+                    return false
+                }
+            }
+            return true
+        }, time.Second, 10*time.Millisecond)
+
+        late := repo.add("product_updated")
+        assert.Eventually(t, func() bool { return repo.sent(late) }, time.Second, 10*time.Millisecond)
+
+        cancel()
+        select {
+        case err := <-done:
+            assert.NoError(t, err)
+        case <-time.After(time.Second):
+            t.Fatal("relay did not stop")
+        }
+    })
+}
@@ -0,0 +1,197 @@
+package usecase_test
+
+import (
+    "context"
+    "regexp"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/config"
+    "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    domainusecase "github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+    "golang.org/x/crypto/bcrypt"
+)
+
+// mockMailer captures sent emails on a channel instead of delivering them
+type mockMailer struct {
+    sent chan *messaging.Email
+}
+
+func newMockMailer() *mockMailer {
+    return &mockMailer{sent: make(chan *messaging.Email, 10)}
+}
+
+func (m *mockMailer) Send(ctx context.Context, msg *messaging.Email) error {
+    m.sent <- msg
+    return nil
+}
+
+// waitForEmail returns the next captured email or fails the test after a timeout
+func (m *mockMailer) waitForEmail(t *testing.T) *messaging.Email {
+    select {
+    case msg := <-m.sent:
+        return msg
+    case <-time.After(2 * time.Second):
+        t.Fatal("expected an email to be sent")
+        return nil
+    }
+}
+
+// mockPasswordResetRepo is an in-memory implementation of the password reset repository for testing purposes
+type mockPasswordResetRepo struct {
+    mu     sync.Mutex
+    tokens []*model.PasswordResetToken
+}
+
+func (m *mockPasswordResetRepo) Create(token *model.PasswordResetToken) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    token.ID = uint(len(m.tokens) + 1)
+    m.tokens = append(m.tokens, token)
+    return nil
+}
+
+func (m *mockPasswordResetRepo) FindByHash(tokenHash string) (*model.PasswordResetToken, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for _, t := range m.tokens {
+        if t.TokenHash == tokenHash {
+            copied := *t
+            return &copied, nil
+        }
+    }
+    return nil, nil
+}
+
+func (m *mockPasswordResetRepo) MarkUsed(id uint) (bool, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    t := m.tokens[id-1]
+    if t.UsedAt != nil {
+        return false, nil
+    }
+    now := time.Now()
+    t.UsedAt = &now
+    return true, nil
+}
+
+func (m *mockPasswordResetRepo) InvalidateForUser(userID uint) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    now := time.Now()
+    for _, t := range m.tokens {
+        if t.UserID == userID && t.UsedAt == nil {
+            t.UsedAt = &now
+        }
+    }
+    return nil
+}
+
+var resetTokenPattern = regexp.MustCompile(`Código de redefinição: (\S+)`)
+
+// newPasswordTestSetup builds a PasswordUsecase around a single user with password "123456"
+func newPasswordTestSetup(t *testing.T) (domainusecase.PasswordUsecaseInterface, *mockUserRepo, *mockTokenRepo, *mockMailer, *mockPasswordResetRepo) {
+    logger := zap.NewNop()
+    hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+    userRepo := &mockUserRepo{user: &model.User{Name: "Amanda", Email: "amanda@test.com", Password: string(hashedPassword), Role: model.RoleEditor, VerifiedAt: verifiedNow()}}
+    userRepo.user.ID = 1
+    tokenRepo := newMockTokenRepo()
+    mailer := newMockMailer()
+    resetRepo := &mockPasswordResetRepo{}
+    cfg := &config.Configs{PasswordResetTTL: time.Hour, PasswordResetURL: "https://app.test/reset"}
+    authUC := usecase.NewAuthUsecase(userRepo, tokenRepo, newTestLockout(), newTestMFA(), newTestOrgs(), newTestKeySet(), authTestConfig(), logger)
+    return usecase.NewPasswordUsecase(userRepo, resetRepo, authUC, mailer, cfg, logger), userRepo, tokenRepo, mailer, resetRepo
+}
+
+// TestForgotAndResetPassword tests the full password reset flow
+func TestForgotAndResetPassword(t *testing.T) {
+    // Subtest: the emailed token resets the password once and revokes existing sessions
+    t.Run("Success", func(t *testing.T) {
+        passwordUC, userRepo, tokenRepo, mailer, _ := newPasswordTestSetup(t)
+
+        // Log in first so there is a session to revoke
+        authUC := usecase.NewAuthUsecase(userRepo, tokenRepo, newTestLockout(), newTestMFA(), newTestOrgs(), newTestKeySet(), authTestConfig(), zap.NewNop())
+        login, err := authUC.Login("amanda@test.com", "123456", "127.0.0.1")
+        require.NoError(t, err)
+
+        assert.NoError(t, passwordUC.ForgotPassword("amanda@test.com"))
+        msg := mailer.waitForEmail(t)
+        assert.Equal(t, []string{"amanda@test.com"}, msg.To)
+        assert.Contains(t, msg.Text, "https://app.test/reset?token=")
+        match := resetTokenPattern.FindStringSubmatch(msg.Text)
+        require.Len(t, match, 2)
+
+        assert.NoError(t, passwordUC.ResetPassword(match[1], "nova-senha"))
+        assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(userRepo.user.Password), []byte("nova-senha")))
+
+        // The refresh token issued before the reset no longer works
+        _, err = authUC.RefreshToken(login.Tokens.RefreshToken)
+        assert.Error(t, err)
+
+        // The token cannot be reused
+        assert.ErrorIs(t, passwordUC.ResetPassword(match[1], "outra-senha"), usecase.ErrInvalidResetToken)
+    })
+
+    // Subtest: a newer reset request invalidates the previous token
+    t.Run("OnlyLatestTokenValid", func(t *testing.T) {
+        passwordUC, _, _, mailer, _ := newPasswordTestSetup(t)
+
+        assert.NoError(t, passwordUC.ForgotPassword("amanda@test.com"))
+        first := resetTokenPattern.FindStringSubmatch(mailer.waitForEmail(t).Text)
+        assert.NoError(t, passwordUC.ForgotPassword("amanda@test.com"))
+        second := resetTokenPattern.FindStringSubmatch(mailer.waitForEmail(t).Text)
+
+        assert.ErrorIs(t, passwordUC.ResetPassword(first[1], "nova-senha"), usecase.ErrInvalidResetToken)
+        assert.NoError(t, passwordUC.ResetPassword(second[1], "nova-senha"))
+    })
+
+    // Subtest: unknown emails succeed silently without sending anything
+    t.Run("UnknownEmail", func(t *testing.T) {
+        passwordUC, userRepo, _, mailer, resetRepo := newPasswordTestSetup(t)
+        userRepo.user = nil
+
+        assert.NoError(t, passwordUC.ForgotPassword("ghost@test.com"))
+        assert.Empty(t, resetRepo.tokens)
+        select {
+        case <-mailer.sent:
+            t.Fatal("no email should be sent for unknown addresses")
+        case <-time.After(100 * time.Millisecond):
+        }
+    })
+
+    // Subtest: expired tokens are rejected
+    t.Run("ExpiredToken", func(t *testing.T) {
+        passwordUC, _, _, mailer, resetRepo := newPasswordTestSetup(t)
+
+        assert.NoError(t, passwordUC.ForgotPassword("amanda@test.com"))
+        match := resetTokenPattern.FindStringSubmatch(mailer.waitForEmail(t).Text)
+        resetRepo.tokens[0].ExpiresAt = time.Now().Add(-time.Minute)
+
+        assert.ErrorIs(t, passwordUC.ResetPassword(match[1], "nova-senha"), usecase.ErrInvalidResetToken)
+    })
+}
+
+// TestChangePassword tests the ChangePassword functionality of the PasswordUsecase
+func TestChangePassword(t *testing.T) {
+    // Subtest: correct current password
+    t.Run("Success", func(t *testing.T) {
+        passwordUC, userRepo, _, _, _ := newPasswordTestSetup(t)
+
+        assert.NoError(t, passwordUC.ChangePassword(1, "123456", "nova-senha"))
+        assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(userRepo.user.Password), []byte("nova-senha")))
+    })
+
+    // Subtest: wrong current password
+    t.Run("WrongCurrentPassword", func(t *testing.T) {
+        passwordUC, userRepo, _, _, _ := newPasswordTestSetup(t)
+
+        assert.ErrorIs(t, passwordUC.ChangePassword(1, "errada", "nova-senha"), usecase.ErrIncorrectPassword)
+        assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(userRepo.user.Password), []byte("123456")))
+    })
+}
@@ -0,0 +1,131 @@
+package usecase_test
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sort"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/config"
+    "github.com/Amandasilvbr/products-crud/internal/consumer"
+    domainmessaging "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    "github.com/Amandasilvbr/products-crud/internal/infrastructure/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+)
+
+// recipientDispatcher fails the dispatches of some recipients with scripted errors and records every call
+type recipientDispatcher struct {
+    mu       sync.Mutex
+    failures map[string][]error
+    calls    []string
+}
+
+func (d *recipientDispatcher) Dispatch(ctx context.Context, recipient string, notifications []model.ProductNotification) error {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    for _, notification := range notifications {
+        d.calls = append(d.calls, fmt.Sprintf("%s/%d", recipient, notification.SKU))
+    }
+    if errs := d.failures[recipient]; len(errs) > 0 {
+        d.failures[recipient] = errs[1:]
+        return errs[0]
+    }
+    return nil
+}
+
+// dispatched returns the recipient and SKU of each notification dispatched so far, sorted
+func (d *recipientDispatcher) dispatched() []string {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    calls := append([]string(nil), d.calls...)
+    sort.Strings(calls)
+    return calls
+}
+
+// recipientEvent builds the CloudEvent of a product created by the given person
+func recipientEvent(t *testing.T, id string, sku int, email string) *domainmessaging.CloudEvent {
+    product := &model.Product{OrgID: 1, SKU: sku, Name: "Lâmpada", Category: "Casa"}
+    event, err := domainmessaging.NewCloudEvent(id, domainmessaging.ProductEventSource(1), domainmessaging.ProductEventType(model.EventProductCreated), "5", domainmessaging.ProductEventSchema, time.Now(), domainmessaging.ProductEventData{
+        Product:          domainmessaging.NewProductSnapshot(product),
+        ResponsibleEmail: email,
+        OrgID:            1,
+        OrgName:          "Acme",
+    })
+    require.NoError(t, err)
+    return event
+}
+
+// TestConsumerRecipients tests that the messages of a batch are settled according to their own recipient
+func TestConsumerRecipients(t *testing.T) {
+    ctx := context.Background()
+    retry := domainmessaging.RetryPolicy{MaxAttempts: 3, Delay: 10 * time.Millisecond}
+
+    // startConsumer publishes the events, then consumes them in batches of two
+    startConsumer := func(t *testing.T, dispatcher *recipientDispatcher, events ...*domainmessaging.CloudEvent) (domainmessaging.Broker, *memoryProcessedRepo) {
+        broker := messaging.NewMemoryBroker()
+        repo := newMemoryProcessedRepo()
+        dedup := usecase.NewMessageDeduplicator(repo, &config.Configs{}, zap.NewNop())
+        products, err := consumer.NewConsumer(zap.NewNop(), broker.Dial, domainmessaging.ProductNotificationsSubscription, retry, domainmessaging.ConsumerOptions{Concurrency: 1, Prefetch: 2}, dispatcher, dedup)
+        require.NoError(t, err)
+        t.Cleanup(products.Close)
+
+        publisher := dialMemory(t, broker, retry)
+        for _, event := range events {
+            require.NoError(t, publisher.PublishEvent(ctx, domainmessaging.ProductEventsExchange, "product.created.casa", event, domainmessaging.CloudEventsBinary))
+        }
+        consumerCtx, cancel := context.WithCancel(ctx)
+        t.Cleanup(cancel)
+        go products.Start(consumerCtx)
+        return publisher, repo
+    }
+
+    // Subtest: A transient failure requeues only the failed recipient's message, which succeeds on retry
+    t.Run("TransientFailure", func(t *testing.T) {
+        dispatcher := &recipientDispatcher{failures: map[string][]error{"maria@test.com": {errors.New("smtp down")}}}
+        publisher, repo := startConsumer(t, dispatcher,
+            recipientEvent(t, "evt-a", 1, "amanda@test.com"),
+            recipientEvent(t, "evt-b", 2, "maria@test.com"),
+        )
+
+        // The other recipient is acknowledged at once
+        assert.Eventually(t, func() bool { return len(dispatcher.dispatched()) == 2 }, 2*time.Second, 10*time.Millisecond)
+        assert.Eventually(t, func() bool { return repo.count() == 1 }, time.Second, 10*time.Millisecond)
+
+        // The retried message joins the next batch
+        require.NoError(t, publisher.PublishEvent(ctx, domainmessaging.ProductEventsExchange, "product.created.casa", recipientEvent(t, "evt-c", 3, "amanda@test.com"), domainmessaging.CloudEventsBinary))
+        assert.Eventually(t, func() bool { return repo.count() == 3 }, 2*time.Second, 10*time.Millisecond)
+        assert.Equal(t, []string{"amanda@test.com/1", "amanda@test.com/3", "maria@test.com/2", "maria@test.com/2"}, dispatcher.dispatched())
+
+        letters, err := publisher.Peek(ctx, domainmessaging.ProductEventsQueue, 10)
+        require.NoError(t, err)
+        assert.Empty(t, letters)
+    })
+
+    // Subtest: A permanent failure dead-letters only the failed recipient's message
+    t.Run("PermanentFailure", func(t *testing.T) {
+        gone := fmt.Errorf("%w: mailbox unavailable", domainmessaging.ErrPermanentDelivery)
+        dispatcher := &recipientDispatcher{failures: map[string][]error{"maria@test.com": {gone}}}
+        publisher, repo := startConsumer(t, dispatcher,
+            recipientEvent(t, "evt-d", 4, "maria@test.com"),
+            recipientEvent(t, "evt-e", 5, "amanda@test.com"),
+        )
+
+        assert.Eventually(t, func() bool { return repo.count() == 1 }, 2*time.Second, 10*time.Millisecond)
+        var letters []domainmessaging.DeadLetter
+        assert.Eventually(t, func() bool {
+            var err error
+            letters, err = publisher.Peek(ctx, domainmessaging.ProductEventsQueue, 10)
+            return err == nil && len(letters) == 1
+        }, time.Second, 10*time.Millisecond)
+        assert.Equal(t, "evt-d", letters[0].MessageID)
+        assert.Contains(t, letters[0].LastError, "mailbox unavailable")
+        assert.Equal(t, []string{"amanda@test.com/5", "maria@test.com/4"}, dispatcher.dispatched())
+    })
+}
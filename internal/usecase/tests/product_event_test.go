@@ -0,0 +1,192 @@
+package usecase_test
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+
+    "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/rabbitmq/amqp091-go"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+)
+
+// capturedEvents records the outbox events passed to the product repository
+func capturedEvents(captured *map[int]*model.OutboxEvent) func(mock.Arguments) {
+    return func(args mock.Arguments) {
+        *captured = args.Get(2).(map[int]*model.OutboxEvent)
+    }
+}
+
+// parseProductEvent reads the CloudEvent stored in an outbox event and its data
+func parseProductEvent(t *testing.T, event *model.OutboxEvent) (*messaging.CloudEvent, messaging.ProductEventData) {
+    cloudEvent, err := messaging.ParseCloudEvent([]byte(event.Payload))
+    require.NoError(t, err)
+    require.NotNil(t, cloudEvent)
+    var data messaging.ProductEventData
+    require.NoError(t, json.Unmarshal(cloudEvent.Data, &data))
+    return cloudEvent, data
+}
+
+// delivery builds the message a consumer receives for a publication
+func delivery(msg amqp091.Publishing) amqp091.Delivery {
+    return amqp091.Delivery{Headers: msg.Headers, ContentType: msg.ContentType, MessageId: msg.MessageId, Body: msg.Body}
+}
+
+// TestProductEvents tests the CloudEvents envelope of product events
+func TestProductEvents(t *testing.T) {
+    // Subtest: Product events are versioned CloudEvents with the product snapshot as data
+    t.Run("Envelope", func(t *testing.T) {
+        uc, repo, ctx := setupTest(t)
+        var events map[int]*model.OutboxEvent
+        repo.On("Create", mock.Anything, mock.Anything, mock.Anything).Run(capturedEvents(&events)).Return(nil).Once()
+
+        product := &model.Product{OrgID: 1, SKU: 7, Name: "Cadeira", Price: 99.9, Category: "Móveis", Availability: "in stock"}
+        require.Nil(t, uc.Create(ctx, []*model.Product{product}, actor))
+
+        cloudEvent, data := parseProductEvent(t, events[7])
+        assert.Equal(t, "1.0", cloudEvent.SpecVersion)
+        assert.Len(t, cloudEvent.ID, 36)
+        assert.Equal(t, "com.products.product.created.v1", cloudEvent.Type)
+        assert.Equal(t, "/organizations/1/products", cloudEvent.Source)
+        assert.Equal(t, "7", cloudEvent.Subject)
+        assert.Equal(t, messaging.ProductEventSchema, cloudEvent.DataSchema)
+        assert.Equal(t, "application/json", cloudEvent.DataContentType)
+        assert.False(t, cloudEvent.Time.IsZero())
+        assert.Equal(t, messaging.NewProductSnapshot(product), data.Product)
+        assert.Empty(t, data.Changes)
+        assert.Equal(t, userEmail, data.ResponsibleEmail)
+        assert.Equal(t, "Acme", data.OrgName)
+        assert.Equal(t, "products", events[7].Exchange)
+        assert.Equal(t, "product.created.móveis", events[7].RoutingKey)
+
+        // Each event has its own ID
+        repo.On("Create", mock.Anything, mock.Anything, mock.Anything).Run(capturedEvents(&events)).Return(nil).Once()
+        require.Nil(t, uc.Create(ctx, []*model.Product{product}, actor))
+        other, _ := parseProductEvent(t, events[7])
+        assert.NotEqual(t, cloudEvent.ID, other.ID)
+    })
+
+    // Subtest: Update events list the changed fields
+    t.Run("UpdateDiff", func(t *testing.T) {
+        uc, repo, ctx := setupTest(t)
+        existing := &model.Product{OrgID: 1, SKU: 8, Name: "Mesa", Price: 200, Category: "Móveis", Availability: "in stock", CreatedBy: "Teste"}
+        var events map[int]*model.OutboxEvent
+        repo.On("GetBySKU", mock.Anything, 8).Return(existing, nil).Once()
+        repo.On("Update", mock.Anything, mock.Anything, mock.Anything).Run(capturedEvents(&events)).Return(nil).Once()
+
+        update := &model.Product{SKU: 8, Name: "Mesa", Price: 150, Category: "Móveis", Availability: "out of stock"}
+        require.Nil(t, uc.Update(ctx, []*model.Product{update}, actor))
+
+        cloudEvent, data := parseProductEvent(t, events[8])
+        assert.Equal(t, "com.products.product.updated.v1", cloudEvent.Type)
+        assert.Equal(t, "out of stock", data.Product.Availability)
+        assert.Equal(t, "Teste", data.Product.CreatedBy)
+        assert.Equal(t, []messaging.FieldChange{
+            {Field: "price", From: 200.0, To: 150.0},
+            {Field: "availability", From: "in stock", To: "out of stock"},
+        }, data.Changes)
+    })
+
+    // Subtest: Both content modes carry the same event, and legacy messages are told apart
+    t.Run("ContentModes", func(t *testing.T) {
+        product := &model.Product{OrgID: 1, SKU: 9, Name: "Sofá"}
+        event, err := messaging.NewCloudEvent("id-9", messaging.ProductEventSource(1), messaging.ProductEventType("product_deleted"), "9", messaging.ProductEventSchema, product.CreatedAt, messaging.ProductEventData{Product: messaging.NewProductSnapshot(product)})
+        require.NoError(t, err)
+
+        structured, err := event.Publishing(messaging.CloudEventsStructured)
+        require.NoError(t, err)
+        assert.Equal(t, "application/cloudevents+json", structured.ContentType)
+        assert.Equal(t, "id-9", structured.MessageId)
+
+        binary, err := event.Publishing(messaging.CloudEventsBinary)
+        require.NoError(t, err)
+        assert.Equal(t, "application/json", binary.ContentType)
+        assert.Equal(t, "com.products.product.deleted.v1", binary.Headers["cloudEvents:type"])
+        assert.JSONEq(t, string(event.Data), string(binary.Body))
+
+        for _, msg := range []amqp091.Publishing{structured, binary} {
+            decoded, err := messaging.DecodeCloudEvent(delivery(msg))
+            require.NoError(t, err)
+            require.NotNil(t, decoded)
+            assert.Equal(t, event.ID, decoded.ID)
+            assert.Equal(t, event.Type, decoded.Type)
+            assert.Equal(t, event.Subject, decoded.Subject)
+            assert.True(t, event.Time.Equal(decoded.Time))
+            assert.JSONEq(t, string(event.Data), string(decoded.Data))
+        }
+
+        legacy, err := messaging.DecodeCloudEvent(amqp091.Delivery{ContentType: "text/plain", Body: []byte(`{"event":"product_deleted","sku":9}`)})
+        assert.NoError(t, err)
+        assert.Nil(t, legacy)
+
+        _, err = messaging.DecodeCloudEvent(amqp091.Delivery{Headers: amqp091.Table{"cloudEvents:specversion": "1.0"}})
+        assert.ErrorIs(t, err, messaging.ErrInvalidCloudEvent)
+
+        name, err := messaging.ProductEventName("com.products.product.deleted.v1")
+        assert.NoError(t, err)
+        assert.Equal(t, "product_deleted", name)
+        _, err = messaging.ProductEventName("com.products.product.deleted.v2")
+        assert.Error(t, err)
+    })
+
+    // Subtest: Routing keys carry the action and the category, and subscriptions bind their own queue
+    t.Run("Routing", func(t *testing.T) {
+        assert.Equal(t, "product.updated.home-garden", messaging.ProductRoutingKey("product_updated", " Home & Garden."))
+        assert.Equal(t, "product.deleted.uncategorized", messaging.ProductRoutingKey("product_deleted", ""))
+
+        search := messaging.Subscription{Queue: "product_search", Exchange: "products", RoutingKeys: []string{"product.created.*", "product.updated.*"}}
+        assert.Equal(t, messaging.Topology{
+            Exchanges: []messaging.Exchange{{Name: "products", Kind: "topic"}},
+            Queues:    []string{"product_search"},
+            Bindings: []messaging.Binding{
+                {Queue: "product_search", Exchange: "products", RoutingKey: "product.created.*"},
+                {Queue: "product_search", Exchange: "products", RoutingKey: "product.updated.*"},
+            },
+        }, search.Topology())
+        assert.Equal(t, "product_events", messaging.ProductNotificationsSubscription.Queue)
+        assert.Equal(t, []string{"product.#"}, messaging.ProductNotificationsSubscription.RoutingKeys)
+    })
+
+    // Subtest: The relay publishes CloudEvents to the exchange in the configured mode, and events stored
+    // before the exchange or the CloudEvents envelope to their queue
+    t.Run("Relay", func(t *testing.T) {
+        repo := &mockOutboxRepo{}
+        publisher := &MockRabbitMQClient{}
+        cfg := outboxTestConfig()
+        cfg.CloudEventsMode = messaging.CloudEventsBinary
+        relay := usecase.NewOutboxRelay(repo, publisher, cfg, zap.NewNop())
+
+        legacy := repo.add("product_created")
+        queued := repo.add("product_updated")
+        queued.Payload = `{"specversion":"1.0","id":"id-1","source":"/organizations/1/products","type":"com.products.product.updated.v1","time":"2026-01-02T03:04:05Z","subject":"1","data":{}}`
+
+        publisher.On("Publish", mock.Anything, "product_events", `{"event":"product_created"}`).Return(nil).Once()
+        publisher.On("PublishEvent", mock.Anything, "", "product_events", mock.MatchedBy(func(event *messaging.CloudEvent) bool {
+            return event.ID == "id-1" && event.Type == "com.products.product.updated.v1"
+        }), messaging.CloudEventsBinary).Return(nil).Once()
+
+        sent, err := relay.Flush(context.Background())
+        require.NoError(t, err)
+        assert.Equal(t, 2, sent)
+        assert.True(t, repo.sent(legacy))
+        assert.True(t, repo.sent(queued))
+
+        routed := repo.add("product_deleted")
+        routed.Queue, routed.Exchange, routed.RoutingKey = "", "products", "product.deleted.moveis"
+        routed.Payload = `{"specversion":"1.0","id":"id-2","source":"/organizations/1/products","type":"com.products.product.deleted.v1","time":"2026-01-02T03:04:05Z","subject":"2","data":{}}`
+        publisher.On("PublishEvent", mock.Anything, "products", "product.deleted.moveis", mock.MatchedBy(func(event *messaging.CloudEvent) bool {
+            return event.ID == "id-2"
+        }), messaging.CloudEventsBinary).Return(nil).Once()
+
+        sent, err = relay.Flush(context.Background())
+        require.NoError(t, err)
+        assert.Equal(t, 1, sent)
+        assert.True(t, repo.sent(routed))
+        publisher.AssertExpectations(t)
+    })
+}
@@ -3,8 +3,10 @@ package usecase_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
 	"github.com/Amandasilvbr/products-crud/internal/domain/model"
 	ucdomain "github.com/Amandasilvbr/products-crud/internal/domain/usecase"
 	"github.com/Amandasilvbr/products-crud/internal/usecase"
@@ -20,8 +22,8 @@ type MockProductRepository struct {
 	mock.Mock
 }
 
-func (m *MockProductRepository) Create(ctx context.Context, products []*model.Product) map[int]string {
-	args := m.Called(ctx, products)
+func (m *MockProductRepository) Create(ctx context.Context, products []*model.Product, events map[int]*model.OutboxEvent) map[int]string {
+	args := m.Called(ctx, products, events)
 	if args.Get(0) == nil {
 		return nil
 	}
@@ -41,16 +43,16 @@ func (m *MockProductRepository) GetBySKU(ctx context.Context, sku int) (*model.P
 	return args.Get(0).(*model.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) Update(ctx context.Context, products []*model.Product) map[int]string {
-	args := m.Called(ctx, products)
+func (m *MockProductRepository) Update(ctx context.Context, products []*model.Product, events map[int]*model.OutboxEvent) map[int]string {
+	args := m.Called(ctx, products, events)
 	if args.Get(0) == nil {
 		return nil
 	}
 	return args.Get(0).(map[int]string)
 }
 
-func (m *MockProductRepository) Delete(ctx context.Context, skus []int) map[int]string {
-	args := m.Called(ctx, skus)
+func (m *MockProductRepository) Delete(ctx context.Context, skus []int, events map[int]*model.OutboxEvent) map[int]string {
+	args := m.Called(ctx, skus, events)
 	if args.Get(0) == nil {
 		return nil
 	}
@@ -67,6 +69,21 @@ func (m *MockRabbitMQClient) Publish(ctx context.Context, queueName, body string
 	return args.Error(0)
 }
 
+func (m *MockRabbitMQClient) PublishTo(ctx context.Context, exchange, routingKey, body string) error {
+	args := m.Called(ctx, exchange, routingKey, body)
+	return args.Error(0)
+}
+
+func (m *MockRabbitMQClient) PublishEvent(ctx context.Context, exchange, routingKey string, event *messaging.CloudEvent, mode string) error {
+	args := m.Called(ctx, exchange, routingKey, event, mode)
+	return args.Error(0)
+}
+
+func (m *MockRabbitMQClient) DeclareTopology(topology messaging.Topology) error {
+	args := m.Called(topology)
+	return args.Error(0)
+}
+
 func (m *MockRabbitMQClient) Consume(queueName, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp091.Table) (<-chan amqp091.Delivery, error) {
 	ret := m.Called(queueName, consumer, autoAck, exclusive, noLocal, noWait, args)
 	return ret.Get(0).(<-chan amqp091.Delivery), ret.Error(1)
@@ -77,27 +94,48 @@ func (m *MockRabbitMQClient) Close() {
 }
 
 // setupTest cria um ambiente de teste com mocks e contexto.
-func setupTest(t *testing.T) (ucdomain.ProductUseCaseInterface, *MockProductRepository, *MockRabbitMQClient, context.Context) {
+func setupTest(t *testing.T) (ucdomain.ProductUseCaseInterface, *MockProductRepository, context.Context) {
 	ctx := context.Background()
 	logger := zap.NewNop()
 	repo := &MockProductRepository{}
-	rabbitMQ := &MockRabbitMQClient{}
-	uc := usecase.NewProductUseCase(repo, logger, rabbitMQ)
-	return uc, repo, rabbitMQ, ctx
+	uc := usecase.NewProductUseCase(repo, logger)
+	return uc, repo, ctx
+}
+
+// eventsFor verifica que cada SKU recebe um evento do tipo esperado na exchange de produtos, com a organização do ator.
+func eventsFor(event string, skus ...int) interface{} {
+	return mock.MatchedBy(func(events map[int]*model.OutboxEvent) bool {
+		if len(events) != len(skus) {
+			return false
+		}
+		for _, sku := range skus {
+			e, ok := events[sku]
+			if !ok || e.Exchange != "products" || !strings.HasPrefix(e.RoutingKey, strings.Replace(event, "_", ".", 1)+".") || e.Event != event {
+				return false
+			}
+			if !strings.Contains(e.Payload, fmt.Sprintf(`"sku":%d`, sku)) || !strings.Contains(e.Payload, `"org_name":"Acme"`) {
+				return false
+			}
+		}
+		return true
+	})
 }
 
 // Dados de teste
-var product1 = &model.Product{SKU: 1, Name: "Produto 1", Price: 10.0}
-var product2 = &model.Product{SKU: 2, Name: "", Price: 20.0} // Inválido
-var product3 = &model.Product{SKU: 3, Name: "Produto 3", Price: 30.0}
+var product1 = &model.Product{OrgID: 1, SKU: 1, Name: "Produto 1", Price: 10.0}
+var product2 = &model.Product{OrgID: 1, SKU: 2, Name: "", Price: 20.0} // Inválido
+var product3 = &model.Product{OrgID: 1, SKU: 3, Name: "Produto 3", Price: 30.0}
 var products = []*model.Product{product1, product2, product3}
 var userEmail = "teste@exemplo.com"
+var actor = &model.Actor{ID: 1, Name: "Teste", Email: userEmail, Role: model.RoleViewer, OrgID: 1, OrgName: "Acme", OrgRole: model.RoleAdmin}
+var editor = &model.Actor{ID: 2, Name: "Editor", Email: "editor@exemplo.com", Role: model.RoleViewer, OrgID: 1, OrgName: "Acme", OrgRole: model.RoleEditor}
+var otherUsersProduct = &model.Product{OrgID: 1, SKU: 4, Name: "Produto 4", Price: 40.0, CreatedBy: "Outro", CreatedByID: 3}
 
 // TestProductUseCase executa todos os casos de teste para o ProductUseCase.
 func TestProductUseCase(t *testing.T) {
 	tests := []struct {
 		name     string
-		setup    func(*MockProductRepository, *MockRabbitMQClient)
+		setup    func(*MockProductRepository)
 		execute  func(ucdomain.ProductUseCaseInterface, context.Context) interface{}
 		expected interface{}
 		hasError bool
@@ -105,81 +143,33 @@ func TestProductUseCase(t *testing.T) {
 		// Teste para criação bem-sucedida de produtos
 		{
 			name: "Create_Success",
-			setup: func(repo *MockProductRepository, rabbitMQ *MockRabbitMQClient) {
-				repo.On("Create", mock.Anything, []*model.Product{product1}).Return(nil).Once()
-				rabbitMQ.On("Publish", mock.Anything, "product_events", mock.Anything).Return(nil).Once()
+			setup: func(repo *MockProductRepository) {
+				repo.On("Create", mock.Anything, []*model.Product{product1}, eventsFor("product_created", 1)).Return(nil).Once()
 			},
 			execute: func(uc ucdomain.ProductUseCaseInterface, ctx context.Context) interface{} {
-				createErrors, publishErrors := uc.Create(ctx, []*model.Product{product1}, userEmail)
-				return []interface{}{createErrors, publishErrors}
+				return uc.Create(ctx, []*model.Product{product1}, actor)
 			},
-			expected: []interface{}{nil, nil},
+			expected: nil,
 			hasError: false,
 		},
 		// Teste para criação com erros de validação
 		{
 			name: "Create_WithErrors",
-			setup: func(repo *MockProductRepository, rabbitMQ *MockRabbitMQClient) {
-				repo.On("Create", mock.Anything, products).Return(map[int]string{
+			setup: func(repo *MockProductRepository) {
+				repo.On("Create", mock.Anything, products, eventsFor("product_created", 1, 2, 3)).Return(map[int]string{
 					2: "name cannot be empty",
 				}).Once()
-				rabbitMQ.On("Publish", mock.Anything, "product_events", mock.Anything).Return(nil).Twice() // SKU 1 e 3
 			},
 			execute: func(uc ucdomain.ProductUseCaseInterface, ctx context.Context) interface{} {
-				createErrors, publishErrors := uc.Create(ctx, products, userEmail)
-				return []interface{}{createErrors, publishErrors}
-			},
-			expected: []interface{}{
-				map[int]string{2: "name cannot be empty"},
-				nil,
-			},
-			hasError: true,
-		},
-		// Teste para criação com erro de publicação
-		{
-			name: "Create_WithPublishErrors",
-			setup: func(repo *MockProductRepository, rabbitMQ *MockRabbitMQClient) {
-				repo.On("Create", mock.Anything, products).Return(nil).Once()
-				rabbitMQ.On("Publish", mock.Anything, "product_events", mock.Anything).Return(fmt.Errorf("connection timeout")).Times(3) // SKU 1, 2, 3
-			},
-			execute: func(uc ucdomain.ProductUseCaseInterface, ctx context.Context) interface{} {
-				createErrors, publishErrors := uc.Create(ctx, products, userEmail)
-				return []interface{}{createErrors, publishErrors}
-			},
-			expected: []interface{}{
-				nil,
-				map[int]string{
-					1: "Failed to publish to RabbitMQ: failed to publish to RabbitMQ: connection timeout",
-					2: "Failed to publish to RabbitMQ: failed to publish to RabbitMQ: connection timeout",
-					3: "Failed to publish to RabbitMQ: failed to publish to RabbitMQ: connection timeout",
-				},
-			},
-			hasError: true,
-		},
-		// Teste para criação com erros mistos (validação e publicação)
-		{
-			name: "Create_WithMixedErrors",
-			setup: func(repo *MockProductRepository, rabbitMQ *MockRabbitMQClient) {
-				repo.On("Create", mock.Anything, products).Return(map[int]string{
-					2: "name cannot be empty",
-				}).Once()
-				rabbitMQ.On("Publish", mock.Anything, "product_events", mock.Anything).Return(nil).Once()                              // SKU 1
-				rabbitMQ.On("Publish", mock.Anything, "product_events", mock.Anything).Return(fmt.Errorf("connection timeout")).Once() // SKU 3
-			},
-			execute: func(uc ucdomain.ProductUseCaseInterface, ctx context.Context) interface{} {
-				createErrors, publishErrors := uc.Create(ctx, products, userEmail)
-				return []interface{}{createErrors, publishErrors}
-			},
-			expected: []interface{}{
-				map[int]string{2: "name cannot be empty"},
-				map[int]string{3: "Failed to publish to RabbitMQ: failed to publish to RabbitMQ: connection timeout"},
+				return uc.Create(ctx, products, actor)
 			},
+			expected: map[int]string{2: "name cannot be empty"},
 			hasError: true,
 		},
 		// Teste para recuperar todos os produtos com sucesso
 		{
 			name: "GetAll_Success",
-			setup: func(repo *MockProductRepository, rabbitMQ *MockRabbitMQClient) {
+			setup: func(repo *MockProductRepository) {
 				repo.On("GetAll", mock.Anything).Return([]*model.Product{product1}, nil).Once()
 			},
 			execute: func(uc ucdomain.ProductUseCaseInterface, ctx context.Context) interface{} {
@@ -192,7 +182,7 @@ func TestProductUseCase(t *testing.T) {
 		// Teste para recuperar um produto por SKU com sucesso
 		{
 			name: "GetBySKU_Success",
-			setup: func(repo *MockProductRepository, rabbitMQ *MockRabbitMQClient) {
+			setup: func(repo *MockProductRepository) {
 				repo.On("GetBySKU", mock.Anything, 1).Return(product1, nil).Once()
 			},
 			execute: func(uc ucdomain.ProductUseCaseInterface, ctx context.Context) interface{} {
@@ -205,7 +195,7 @@ func TestProductUseCase(t *testing.T) {
 		// Teste para recuperar um produto por SKU não encontrado
 		{
 			name: "GetBySKU_NotFound",
-			setup: func(repo *MockProductRepository, rabbitMQ *MockRabbitMQClient) {
+			setup: func(repo *MockProductRepository) {
 				repo.On("GetBySKU", mock.Anything, 1).Return(nil, usecase.ErrProductNotFound).Once()
 			},
 			execute: func(uc ucdomain.ProductUseCaseInterface, ctx context.Context) interface{} {
@@ -218,13 +208,12 @@ func TestProductUseCase(t *testing.T) {
 		// Teste para atualização bem-sucedida
 		{
 			name: "Update_Success",
-			setup: func(repo *MockProductRepository, rabbitMQ *MockRabbitMQClient) {
+			setup: func(repo *MockProductRepository) {
 				repo.On("GetBySKU", mock.Anything, 1).Return(product1, nil).Once()
-				repo.On("Update", mock.Anything, []*model.Product{product1}).Return(nil).Once()
-				rabbitMQ.On("Publish", mock.Anything, "product_events", mock.Anything).Return(nil).Once()
+				repo.On("Update", mock.Anything, []*model.Product{product1}, eventsFor("product_updated", 1)).Return(nil).Once()
 			},
 			execute: func(uc ucdomain.ProductUseCaseInterface, ctx context.Context) interface{} {
-				return uc.Update(ctx, []*model.Product{product1}, userEmail)
+				return uc.Update(ctx, []*model.Product{product1}, actor)
 			},
 			expected: nil,
 			hasError: false,
@@ -232,11 +221,11 @@ func TestProductUseCase(t *testing.T) {
 		// Teste para atualização de produto não encontrado
 		{
 			name: "Update_NotFound",
-			setup: func(repo *MockProductRepository, rabbitMQ *MockRabbitMQClient) {
+			setup: func(repo *MockProductRepository) {
 				repo.On("GetBySKU", mock.Anything, 1).Return(nil, usecase.ErrProductNotFound).Once()
 			},
 			execute: func(uc ucdomain.ProductUseCaseInterface, ctx context.Context) interface{} {
-				return uc.Update(ctx, []*model.Product{product1}, userEmail)
+				return uc.Update(ctx, []*model.Product{product1}, actor)
 			},
 			expected: map[int]string{1: "Product with SKU 1 not found"},
 			hasError: true,
@@ -244,25 +233,48 @@ func TestProductUseCase(t *testing.T) {
 		// Teste para exclusão bem-sucedida
 		{
 			name: "Delete_Success",
-			setup: func(repo *MockProductRepository, rabbitMQ *MockRabbitMQClient) {
+			setup: func(repo *MockProductRepository) {
 				repo.On("GetBySKU", mock.Anything, 1).Return(product1, nil).Once()
-				repo.On("Delete", mock.Anything, []int{1}).Return(nil).Once()
-				rabbitMQ.On("Publish", mock.Anything, "product_events", mock.Anything).Return(nil).Once()
+				repo.On("Delete", mock.Anything, []int{1}, eventsFor("product_deleted", 1)).Return(nil).Once()
 			},
 			execute: func(uc ucdomain.ProductUseCaseInterface, ctx context.Context) interface{} {
-				return uc.Delete(ctx, []int{1}, userEmail)
+				return uc.Delete(ctx, []int{1}, actor)
 			},
 			expected: nil,
 			hasError: false,
 		},
+		// Teste para atualização proibida de produto criado por outro usuário
+		{
+			name: "Update_ForbiddenForEditor",
+			setup: func(repo *MockProductRepository) {
+				repo.On("GetBySKU", mock.Anything, 4).Return(otherUsersProduct, nil).Once()
+			},
+			execute: func(uc ucdomain.ProductUseCaseInterface, ctx context.Context) interface{} {
+				return uc.Update(ctx, []*model.Product{otherUsersProduct}, editor)
+			},
+			expected: map[int]string{4: "Forbidden: not allowed to modify product with SKU 4"},
+			hasError: true,
+		},
+		// Teste para exclusão proibida de produto criado por outro usuário
+		{
+			name: "Delete_ForbiddenForEditor",
+			setup: func(repo *MockProductRepository) {
+				repo.On("GetBySKU", mock.Anything, 4).Return(otherUsersProduct, nil).Once()
+			},
+			execute: func(uc ucdomain.ProductUseCaseInterface, ctx context.Context) interface{} {
+				return uc.Delete(ctx, []int{4}, editor)
+			},
+			expected: map[int]string{4: "Forbidden: not allowed to modify product with SKU 4"},
+			hasError: true,
+		},
 		// Teste para exclusão de produto não encontrado
 		{
 			name: "Delete_NotFound",
-			setup: func(repo *MockProductRepository, rabbitMQ *MockRabbitMQClient) {
+			setup: func(repo *MockProductRepository) {
 				repo.On("GetBySKU", mock.Anything, 1).Return(nil, usecase.ErrProductNotFound).Once()
 			},
 			execute: func(uc ucdomain.ProductUseCaseInterface, ctx context.Context) interface{} {
-				return uc.Delete(ctx, []int{1}, userEmail)
+				return uc.Delete(ctx, []int{1}, actor)
 			},
 			expected: map[int]string{1: "Product with SKU 1 not found"},
 			hasError: true,
@@ -271,23 +283,23 @@ func TestProductUseCase(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			uc, repo, rabbitMQ, ctx := setupTest(t)
-			tt.setup(repo, rabbitMQ)
+			uc, repo, ctx := setupTest(t)
+			tt.setup(repo)
 			result := tt.execute(uc, ctx)
 
-			if !tt.hasError && tt.name != "GetAll_Success" && tt.name != "GetBySKU_Success" && tt.name != "Create_Success" {
-				// Para casos de sucesso que retornam um mapa (Update, Delete), espera nil
+			if !tt.hasError && tt.name != "GetAll_Success" && tt.name != "GetBySKU_Success" {
+				// Para casos de sucesso que retornam um mapa (Create, Update, Delete), espera nil
 				assert.Empty(t, result, "Unexpected result for %s", tt.name)
 			} else if slice, ok := result.([]interface{}); ok {
-				// Para métodos que retornam (valor, erro) ou (createErrors, publishErrors)
+				// Para métodos que retornam (valor, erro)
 				expectedSlice := tt.expected.([]interface{})
-				// Comparar createErrors
+				// Comparar o valor
 				if expectedSlice[0] == nil {
 					assert.Empty(t, slice[0], "Unexpected first result for %s", tt.name)
 				} else {
 					assert.Equal(t, expectedSlice[0], slice[0], "Unexpected first result for %s", tt.name)
 				}
-				// Comparar publishErrors
+				// Comparar o erro
 				if expectedSlice[1] == nil {
 					assert.Empty(t, slice[1], "Unexpected second result for %s", tt.name)
 				} else {
@@ -303,7 +315,6 @@ func TestProductUseCase(t *testing.T) {
 			}
 
 			repo.AssertExpectations(t)
-			rabbitMQ.AssertExpectations(t)
 		})
 	}
 }
@@ -0,0 +1,766 @@
+package usecase_test
+
+import (
+    "context"
+    "errors"
+    "sort"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    domainmessaging "github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+    "github.com/Amandasilvbr/products-crud/internal/infrastructure/messaging"
+    "github.com/rabbitmq/amqp091-go"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// Confirmations a fake broker gives to published messages
+const (
+    confirmAck  = "ack"
+    confirmNack = "nack"
+    // confirmNever leaves the publisher waiting until its context ends
+    confirmNever = "never"
+)
+
+// fakeAMQP is an AMQP broker whose connections can be dropped, for testing the RabbitMQ client without a server
+// It keeps the queues, their arguments and messages, and the bindings, routing published messages like RabbitMQ
+type fakeAMQP struct {
+    mu           sync.Mutex
+    conns        []*fakeAMQPConn
+    dialErr      error
+    confirmation string
+    queues       map[string]*fakeQueue
+    bindings     []domainmessaging.Binding
+    // declared and deleted record the names of the queues declared and deleted, in order
+    declared []string
+    deleted  []string
+    seq      uint64
+}
+
+// fakeQueue is a queue of a fakeAMQP broker
+type fakeQueue struct {
+    args     amqp091.Table
+    messages []fakeMessage
+}
+
+// fakeMessage is a message waiting in a queue; seq keeps the order of requeued messages
+type fakeMessage struct {
+    seq uint64
+    msg amqp091.Publishing
+}
+
+func newFakeAMQP() *fakeAMQP {
+    return &fakeAMQP{confirmation: confirmAck, queues: make(map[string]*fakeQueue)}
+}
+
+func (f *fakeAMQP) dial(url string) (messaging.AMQPConnection, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if f.dialErr != nil {
+        return nil, f.dialErr
+    }
+    conn := &fakeAMQPConn{server: f}
+    f.conns = append(f.conns, conn)
+    return conn, nil
+}
+
+// setConfirmation changes how the broker answers the next publications
+func (f *fakeAMQP) setConfirmation(confirmation string) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.confirmation = confirmation
+}
+
+// dials returns how many connections were opened
+func (f *fakeAMQP) dials() int {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return len(f.conns)
+}
+
+// current returns the last connection opened
+func (f *fakeAMQP) current() *fakeAMQPConn {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return f.conns[len(f.conns)-1]
+}
+
+// addQueue creates a queue with arguments and messages, as left on the broker by a previous deployment
+func (f *fakeAMQP) addQueue(name string, args amqp091.Table, messageIDs ...string) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    queue := &fakeQueue{args: args}
+    for _, id := range messageIDs {
+        f.seq++
+        queue.messages = append(queue.messages, fakeMessage{seq: f.seq, msg: amqp091.Publishing{MessageId: id, Body: []byte("body of " + id)}})
+    }
+    f.queues[name] = queue
+}
+
+// queue returns the arguments and the message IDs of a queue, or false when it does not exist
+func (f *fakeAMQP) queue(name string) (amqp091.Table, []string, bool) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    queue, ok := f.queues[name]
+    if !ok {
+        return nil, nil, false
+    }
+    var ids []string
+    for _, message := range queue.messages {
+        ids = append(ids, message.msg.MessageId)
+    }
+    return queue.args, ids, true
+}
+
+// messages returns the messages waiting in a queue, in order
+func (f *fakeAMQP) messages(name string) []amqp091.Publishing {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    var messages []amqp091.Publishing
+    if queue, ok := f.queues[name]; ok {
+        for _, message := range queue.messages {
+            messages = append(messages, message.msg)
+        }
+    }
+    return messages
+}
+
+// boundTo returns the routing keys binding a queue to an exchange
+func (f *fakeAMQP) boundTo(queue, exchange string) []string {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    var keys []string
+    for _, binding := range f.bindings {
+        if binding.Queue == queue && binding.Exchange == exchange {
+            keys = append(keys, binding.RoutingKey)
+        }
+    }
+    return keys
+}
+
+// history returns the queues declared and deleted so far
+func (f *fakeAMQP) history() ([]string, []string) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return append([]string(nil), f.declared...), append([]string(nil), f.deleted...)
+}
+
+// route stores a message in the queues its exchange and routing key lead to; the caller holds the lock
+// Wildcard bindings match every routing key, which is enough for the tests
+func (f *fakeAMQP) route(exchange, key string, msg amqp091.Publishing) {
+    var targets []string
+    if exchange == "" {
+        targets = []string{key}
+    }
+    for _, binding := range f.bindings {
+        if binding.Exchange == exchange && (binding.RoutingKey == key || binding.RoutingKey == "" || strings.ContainsAny(binding.RoutingKey, "*#")) {
+            targets = append(targets, binding.Queue)
+        }
+    }
+    for _, name := range targets {
+        if queue, ok := f.queues[name]; ok {
+            f.seq++
+            queue.messages = append(queue.messages, fakeMessage{seq: f.seq, msg: msg})
+        }
+    }
+}
+
+// fakeAMQPConn is a connection of a fakeAMQP broker
+type fakeAMQPConn struct {
+    server   *fakeAMQP
+    mu       sync.Mutex
+    channels []*fakeAMQPChannel
+    notify   []chan *amqp091.Error
+    closed   bool
+}
+
+func (c *fakeAMQPConn) Channel() (messaging.AMQPChannel, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.closed {
+        return nil, amqp091.ErrClosed
+    }
+    ch := &fakeAMQPChannel{server: c.server, unacked: make(map[uint64]fakeDelivery)}
+    c.channels = append(c.channels, ch)
+    return ch, nil
+}
+
+func (c *fakeAMQPConn) NotifyClose(receiver chan *amqp091.Error) chan *amqp091.Error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.notify = append(c.notify, receiver)
+    return receiver
+}
+
+func (c *fakeAMQPConn) Close() error {
+    c.shutdown(nil)
+    return nil
+}
+
+// drop closes the connection as the broker would when it goes away
+func (c *fakeAMQPConn) drop() {
+    c.shutdown(&amqp091.Error{Code: amqp091.ConnectionForced, Reason: "broker restarted"})
+}
+
+// shutdown closes the connection and its channels, telling the listeners why
+func (c *fakeAMQPConn) shutdown(reason *amqp091.Error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.closed {
+        return
+    }
+    c.closed = true
+    for _, receiver := range c.notify {
+        if reason != nil {
+            receiver <- reason
+        }
+        close(receiver)
+    }
+    for _, ch := range c.channels {
+        ch.Close()
+    }
+}
+
+// channel returns the channel the client opened first on the connection, which it publishes and consumes on
+func (c *fakeAMQPConn) channel() *fakeAMQPChannel {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.channels[0]
+}
+
+// fakeDelivery is a message got from a queue and not settled yet
+type fakeDelivery struct {
+    queue   string
+    message fakeMessage
+}
+
+// fakeAMQPChannel is a channel of a fakeAMQP broker recording what the client declares and publishes
+type fakeAMQPChannel struct {
+    server    *fakeAMQP
+    mu        sync.Mutex
+    confirm   bool
+    prefetch  int
+    queues    []string
+    published []amqp091.Publishing
+    consumers []chan amqp091.Delivery
+    notify    []chan *amqp091.Error
+    unacked   map[uint64]fakeDelivery
+    tag       uint64
+    closed    bool
+}
+
+func (c *fakeAMQPChannel) Confirm(noWait bool) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.confirm = true
+    return nil
+}
+
+func (c *fakeAMQPChannel) Qos(prefetchCount, prefetchSize int, global bool) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.prefetch = prefetchCount
+    return nil
+}
+
+func (c *fakeAMQPChannel) NotifyClose(receiver chan *amqp091.Error) chan *amqp091.Error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.notify = append(c.notify, receiver)
+    return receiver
+}
+
+// fail closes the channel with an error, as the broker does when it refuses an operation
+func (c *fakeAMQPChannel) fail(code int, reason string) error {
+    c.Close()
+    return &amqp091.Error{Code: code, Reason: reason}
+}
+
+func (c *fakeAMQPChannel) ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp091.Table) error {
+    return nil
+}
+
+func (c *fakeAMQPChannel) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp091.Table) (amqp091.Queue, error) {
+    c.server.mu.Lock()
+    queue, ok := c.server.queues[name]
+    if ok && queue.args["x-dead-letter-exchange"] != args["x-dead-letter-exchange"] {
+        c.server.mu.Unlock()
+        return amqp091.Queue{}, c.fail(amqp091.PreconditionFailed, "inequivalent arg 'x-dead-letter-exchange' for queue "+name)
+    }
+    if !ok {
+        queue = &fakeQueue{args: args}
+        c.server.queues[name] = queue
+    }
+    c.server.declared = append(c.server.declared, name)
+    messages := len(queue.messages)
+    c.server.mu.Unlock()
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.queues = append(c.queues, name)
+    return amqp091.Queue{Name: name, Messages: messages}, nil
+}
+
+func (c *fakeAMQPChannel) QueueDeclarePassive(name string, durable, autoDelete, exclusive, noWait bool, args amqp091.Table) (amqp091.Queue, error) {
+    c.server.mu.Lock()
+    queue, ok := c.server.queues[name]
+    c.server.mu.Unlock()
+    if !ok {
+        return amqp091.Queue{}, c.fail(amqp091.NotFound, "no queue "+name)
+    }
+    return amqp091.Queue{Name: name, Messages: len(queue.messages)}, nil
+}
+
+func (c *fakeAMQPChannel) QueueBind(name, key, exchange string, noWait bool, args amqp091.Table) error {
+    c.server.mu.Lock()
+    defer c.server.mu.Unlock()
+    binding := domainmessaging.Binding{Queue: name, Exchange: exchange, RoutingKey: key}
+    for _, existing := range c.server.bindings {
+        if existing == binding {
+            return nil
+        }
+    }
+    c.server.bindings = append(c.server.bindings, binding)
+    return nil
+}
+
+func (c *fakeAMQPChannel) QueueUnbind(name, key, exchange string, args amqp091.Table) error {
+    c.server.mu.Lock()
+    defer c.server.mu.Unlock()
+    kept := c.server.bindings[:0]
+    for _, binding := range c.server.bindings {
+        if binding != (domainmessaging.Binding{Queue: name, Exchange: exchange, RoutingKey: key}) {
+            kept = append(kept, binding)
+        }
+    }
+    c.server.bindings = kept
+    return nil
+}
+
+func (c *fakeAMQPChannel) QueueDelete(name string, ifUnused, ifEmpty, noWait bool) (int, error) {
+    c.server.mu.Lock()
+    queue, ok := c.server.queues[name]
+    if ok && ifEmpty && len(queue.messages) > 0 {
+        c.server.mu.Unlock()
+        return 0, c.fail(amqp091.PreconditionFailed, "queue "+name+" not empty")
+    }
+    delete(c.server.queues, name)
+    kept := c.server.bindings[:0]
+    for _, binding := range c.server.bindings {
+        if binding.Queue != name {
+            kept = append(kept, binding)
+        }
+    }
+    c.server.bindings = kept
+    c.server.deleted = append(c.server.deleted, name)
+    c.server.mu.Unlock()
+    if !ok {
+        return 0, nil
+    }
+    return len(queue.messages), nil
+}
+
+func (c *fakeAMQPChannel) QueuePurge(name string, noWait bool) (int, error) {
+    c.server.mu.Lock()
+    defer c.server.mu.Unlock()
+    queue, ok := c.server.queues[name]
+    if !ok {
+        return 0, nil
+    }
+    purged := len(queue.messages)
+    queue.messages = nil
+    return purged, nil
+}
+
+func (c *fakeAMQPChannel) Get(name string, autoAck bool) (amqp091.Delivery, bool, error) {
+    c.server.mu.Lock()
+    queue, ok := c.server.queues[name]
+    if !ok {
+        c.server.mu.Unlock()
+        return amqp091.Delivery{}, false, c.fail(amqp091.NotFound, "no queue "+name)
+    }
+    if len(queue.messages) == 0 {
+        c.server.mu.Unlock()
+        return amqp091.Delivery{}, false, nil
+    }
+    message := queue.messages[0]
+    queue.messages = queue.messages[1:]
+    c.server.mu.Unlock()
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.tag++
+    c.unacked[c.tag] = fakeDelivery{queue: name, message: message}
+    msg := message.msg
+    return amqp091.Delivery{
+        Acknowledger:  c,
+        DeliveryTag:   c.tag,
+        Headers:       msg.Headers,
+        ContentType:   msg.ContentType,
+        DeliveryMode:  msg.DeliveryMode,
+        Expiration:    msg.Expiration,
+        MessageId:     msg.MessageId,
+        Timestamp:     msg.Timestamp,
+        Body:          msg.Body,
+    }, true, nil
+}
+
+// Ack settles a message got from a queue
+func (c *fakeAMQPChannel) Ack(tag uint64, multiple bool) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    delete(c.unacked, tag)
+    return nil
+}
+
+// Nack settles a message got from a queue, putting it back in its place when requeue is set
+func (c *fakeAMQPChannel) Nack(tag uint64, multiple, requeue bool) error {
+    c.mu.Lock()
+    delivery, ok := c.unacked[tag]
+    delete(c.unacked, tag)
+    c.mu.Unlock()
+    if !ok || !requeue {
+        return nil
+    }
+
+    c.server.mu.Lock()
+    defer c.server.mu.Unlock()
+    queue, ok := c.server.queues[delivery.queue]
+    if !ok {
+        return nil
+    }
+    queue.messages = append(queue.messages, delivery.message)
+    sort.Slice(queue.messages, func(i, j int) bool { return queue.messages[i].seq < queue.messages[j].seq })
+    return nil
+}
+
+func (c *fakeAMQPChannel) Reject(tag uint64, requeue bool) error {
+    return c.Nack(tag, false, requeue)
+}
+
+func (c *fakeAMQPChannel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp091.Table) (<-chan amqp091.Delivery, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.closed {
+        return nil, amqp091.ErrClosed
+    }
+    deliveries := make(chan amqp091.Delivery, 1)
+    c.consumers = append(c.consumers, deliveries)
+    return deliveries, nil
+}
+
+func (c *fakeAMQPChannel) PublishConfirmed(ctx context.Context, exchange, key string, msg amqp091.Publishing) (messaging.Confirmation, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.closed {
+        return nil, amqp091.ErrClosed
+    }
+    c.published = append(c.published, msg)
+    c.server.mu.Lock()
+    defer c.server.mu.Unlock()
+    if c.server.confirmation == confirmAck {
+        c.server.route(exchange, key, msg)
+    }
+    return fakeConfirmation(c.server.confirmation), nil
+}
+
+func (c *fakeAMQPChannel) Close() error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.closed {
+        return nil
+    }
+    c.closed = true
+    for _, receiver := range c.notify {
+        close(receiver)
+    }
+    for _, deliveries := range c.consumers {
+        close(deliveries)
+    }
+    return nil
+}
+
+// deliver hands a message to the channel's consumer
+func (c *fakeAMQPChannel) deliver(t *testing.T, body string) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    require.NotEmpty(t, c.consumers)
+    c.consumers[len(c.consumers)-1] <- amqp091.Delivery{Body: []byte(body)}
+}
+
+// state returns the prefetch and the queues declared on the channel, and whether it is in confirm mode
+func (c *fakeAMQPChannel) state() (bool, int, []string) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.confirm, c.prefetch, append([]string(nil), c.queues...)
+}
+
+// publications returns how many messages were published on the channel
+func (c *fakeAMQPChannel) publications() int {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return len(c.published)
+}
+
+// fakeConfirmation answers a publication as the broker was told to
+type fakeConfirmation string
+
+func (f fakeConfirmation) WaitContext(ctx context.Context) (bool, error) {
+    switch f {
+    case confirmAck:
+        return true, nil
+    case confirmNack:
+        return false, nil
+    }
+    <-ctx.Done()
+    return false, ctx.Err()
+}
+
+// TestRabbitMQClient tests the reconnection and publisher confirms of the RabbitMQ client against a fake broker
+func TestRabbitMQClient(t *testing.T) {
+    ctx := context.Background()
+    retry := domainmessaging.RetryPolicy{MaxAttempts: 3, Delay: time.Second}
+
+    // connect opens a client of the fake broker, closed with the test
+    connect := func(t *testing.T, server *fakeAMQP) *messaging.RabbitMQClient {
+        client, err := messaging.NewRabbitMQClientWithDial("amqp://fake", retry, server.dial)
+        require.NoError(t, err)
+        t.Cleanup(client.Close)
+        return client
+    }
+
+    // Subtest: A dropped connection is re-established with its topology, prefetch and consumers
+    t.Run("Reconnect", func(t *testing.T) {
+        server := newFakeAMQP()
+        client := connect(t, server)
+        require.NoError(t, client.DeclareTopology(domainmessaging.ProductNotificationsSubscription.Topology()))
+        require.NoError(t, client.SetPrefetch(2))
+        deliveries, err := client.Consume(domainmessaging.ProductEventsQueue, "", false, false, false, false, nil)
+        require.NoError(t, err)
+        first := server.current()
+
+        first.drop()
+        assert.Eventually(t, func() bool { return client.State() == domainmessaging.StateReconnecting }, time.Second, 10*time.Millisecond)
+        assert.ErrorIs(t, client.PublishTo(ctx, "", "jobs", "{}"), messaging.ErrNotConnected)
+
+        assert.Eventually(t, func() bool { return client.State() == domainmessaging.StateConnected }, 5*time.Second, 10*time.Millisecond)
+        require.Equal(t, 2, server.dials())
+        ch := server.current().channel()
+        confirm, prefetch, queues := ch.state()
+        assert.True(t, confirm)
+        assert.Equal(t, 2, prefetch)
+        assert.Contains(t, queues, domainmessaging.ProductEventsQueue)
+
+        // Consumption resumes on the new channel
+        assert.Eventually(t, func() bool {
+            ch.mu.Lock()
+            defer ch.mu.Unlock()
+            return len(ch.consumers) == 1
+        }, 5*time.Second, 10*time.Millisecond)
+        ch.deliver(t, "after reconnect")
+        assert.Equal(t, "after reconnect", string(receive(t, deliveries).Body))
+
+        require.NoError(t, client.PublishTo(ctx, "", "jobs", "{}"))
+        assert.Equal(t, 1, ch.publications())
+        assert.Equal(t, 0, first.channel().publications())
+    })
+
+    // Subtest: The first connection must succeed
+    t.Run("FirstDialFails", func(t *testing.T) {
+        server := newFakeAMQP()
+        server.dialErr = errors.New("connection refused")
+        _, err := messaging.NewRabbitMQClientWithDial("amqp://fake", retry, server.dial)
+        assert.ErrorContains(t, err, "connection refused")
+    })
+
+    // Subtest: A message the broker refuses is a publish error
+    t.Run("Nack", func(t *testing.T) {
+        server := newFakeAMQP()
+        client := connect(t, server)
+        server.setConfirmation(confirmNack)
+
+        assert.ErrorIs(t, client.PublishTo(ctx, "", "jobs", "{}"), messaging.ErrPublishNacked)
+        server.setConfirmation(confirmAck)
+        assert.NoError(t, client.PublishTo(ctx, "", "jobs", "{}"))
+    })
+
+    // Subtest: A confirmation that does not arrive before the deadline is a publish error
+    t.Run("ConfirmTimeout", func(t *testing.T) {
+        server := newFakeAMQP()
+        client := connect(t, server)
+        server.setConfirmation(confirmNever)
+
+        timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+        defer cancel()
+        started := time.Now()
+        err := client.PublishTo(timeout, "", "jobs", "{}")
+        assert.ErrorIs(t, err, context.DeadlineExceeded)
+        assert.Less(t, time.Since(started), time.Second)
+    })
+
+    // Subtest: A message is published with the ID the caller gives, or a random one
+    t.Run("MessageID", func(t *testing.T) {
+        server := newFakeAMQP()
+        client := connect(t, server)
+
+        require.NoError(t, client.PublishTo(domainmessaging.WithMessageID(ctx, "outbox-7"), "", "jobs", "{}"))
+        require.NoError(t, client.PublishTo(ctx, "", "jobs", "{}"))
+        require.NoError(t, client.PublishTo(ctx, "", "jobs", "{}"))
+        ch := server.current().channel()
+        ch.mu.Lock()
+        defer ch.mu.Unlock()
+        require.Len(t, ch.published, 3)
+        assert.Equal(t, "outbox-7", ch.published[0].MessageId)
+        assert.NotEmpty(t, ch.published[1].MessageId)
+        assert.NotEqual(t, ch.published[1].MessageId, ch.published[2].MessageId)
+    })
+
+    topology := domainmessaging.ProductNotificationsSubscription.Topology()
+    queueName := domainmessaging.ProductEventsQueue
+    holding := queueName + ".migrating"
+    currentArgs := amqp091.Table{"x-dead-letter-exchange": queueName + domainmessaging.DeadLetterExchangeSuffix}
+
+    // assertBound checks that the queue, and not the holding queue, has every binding of the topology
+    assertBound := func(t *testing.T, server *fakeAMQP) {
+        for _, binding := range topology.Bindings {
+            assert.Contains(t, server.boundTo(queueName, binding.Exchange), binding.RoutingKey)
+        }
+        assert.Empty(t, server.boundTo(holding, domainmessaging.ProductEventsExchange))
+    }
+
+    // Subtest: A queue declared without the dead-letter arguments is declared again with them, keeping its messages in order
+    t.Run("MigrateLegacyQueue", func(t *testing.T) {
+        server := newFakeAMQP()
+        server.addQueue(queueName, nil, "msg-1", "msg-2", "msg-3")
+        client := connect(t, server)
+
+        require.NoError(t, client.DeclareTopology(topology))
+        args, ids, ok := server.queue(queueName)
+        require.True(t, ok)
+        assert.Equal(t, currentArgs, args)
+        assert.Equal(t, []string{"msg-1", "msg-2", "msg-3"}, ids)
+        assert.Equal(t, "body of msg-2", string(server.messages(queueName)[1].Body))
+        _, _, ok = server.queue(holding)
+        assert.False(t, ok, "the holding queue is deleted")
+        _, deleted := server.history()
+        assert.Equal(t, []string{queueName, holding}, deleted)
+        assertBound(t, server)
+    })
+
+    // Subtest: A queue already declared with the dead-letter arguments is left alone
+    t.Run("AlreadyMigrated", func(t *testing.T) {
+        server := newFakeAMQP()
+        server.addQueue(queueName, currentArgs, "msg-1")
+        client := connect(t, server)
+
+        require.NoError(t, client.DeclareTopology(topology))
+        declared, deleted := server.history()
+        assert.NotContains(t, declared, holding)
+        assert.Empty(t, deleted)
+        _, ids, _ := server.queue(queueName)
+        assert.Equal(t, []string{"msg-1"}, ids)
+        assertBound(t, server)
+    })
+
+    // Subtest: A migration interrupted after the work queue was declared again resumes from the holding queue
+    t.Run("ResumeMigration", func(t *testing.T) {
+        server := newFakeAMQP()
+        server.addQueue(queueName, currentArgs, "msg-3")
+        server.addQueue(holding, nil, "msg-1", "msg-2")
+        client := connect(t, server)
+
+        require.NoError(t, client.DeclareTopology(topology))
+        _, ids, _ := server.queue(queueName)
+        assert.ElementsMatch(t, []string{"msg-1", "msg-2", "msg-3"}, ids)
+        _, _, ok := server.queue(holding)
+        assert.False(t, ok)
+        assertBound(t, server)
+    })
+
+    // Subtest: A message the broker refuses to move aborts the migration before the legacy queue is deleted
+    t.Run("MigrationFailureKeepsQueue", func(t *testing.T) {
+        server := newFakeAMQP()
+        server.addQueue(queueName, nil, "msg-1", "msg-2")
+        client := connect(t, server)
+
+        server.setConfirmation(confirmNack)
+        err := client.DeclareTopology(topology)
+        assert.ErrorIs(t, err, messaging.ErrPublishNacked)
+        args, ids, ok := server.queue(queueName)
+        require.True(t, ok)
+        assert.Nil(t, args)
+        assert.Equal(t, []string{"msg-1", "msg-2"}, ids)
+        _, deleted := server.history()
+        assert.Empty(t, deleted)
+
+        // The next declaration completes the migration
+        server.setConfirmation(confirmAck)
+        require.NoError(t, client.DeclareTopology(topology))
+        args, ids, _ = server.queue(queueName)
+        assert.Equal(t, currentArgs, args)
+        assert.Equal(t, []string{"msg-1", "msg-2"}, ids)
+        assertBound(t, server)
+    })
+
+    // Subtest: A topology declared several times is declared once after a reconnection
+    t.Run("TopologyDeclaredOnce", func(t *testing.T) {
+        server := newFakeAMQP()
+        client := connect(t, server)
+        require.NoError(t, client.DeclareTopology(topology))
+        require.NoError(t, client.DeclareTopology(topology))
+
+        server.current().drop()
+        assert.Eventually(t, func() bool { return server.dials() == 2 && client.State() == domainmessaging.StateConnected }, 5*time.Second, 10*time.Millisecond)
+        _, _, queues := server.current().channel().state()
+        assert.Equal(t, []string{queueName + domainmessaging.DeadLetterSuffix, queueName + domainmessaging.RetrySuffix, queueName}, queues)
+    })
+
+    // Subtest: Dead-lettered messages can be inspected, moved back to the work queue with a fresh attempt count, and purged
+    t.Run("DeadLetters", func(t *testing.T) {
+        server := newFakeAMQP()
+        client := connect(t, server)
+        require.NoError(t, client.DeclareTopology(topology))
+        dlq := queueName + domainmessaging.DeadLetterSuffix
+
+        // deadLetter sends a message to the dead-letter queue as a consumer would after a permanent failure
+        deadLetter := func(id string) {
+            client.Reject(ctx, queueName, amqp091.Delivery{
+                MessageId: id,
+                Headers:   amqp091.Table{"trace": id},
+                Body:      []byte("body of " + id),
+            }, errors.New("invalid payload"), true)
+        }
+        deadLetter("dead-1")
+        deadLetter("dead-2")
+
+        letters, err := client.Peek(ctx, queueName, 1)
+        require.NoError(t, err)
+        require.Len(t, letters, 1)
+        assert.Equal(t, "dead-1", letters[0].MessageID)
+        assert.Equal(t, "body of dead-1", letters[0].Body)
+        assert.Equal(t, 1, letters[0].Attempts)
+        assert.Equal(t, "invalid payload", letters[0].LastError)
+        _, ids, _ := server.queue(dlq)
+        assert.Equal(t, []string{"dead-1", "dead-2"}, ids, "peeking leaves the messages in place")
+
+        moved, err := client.Requeue(ctx, queueName, 0)
+        require.NoError(t, err)
+        assert.Equal(t, 2, moved)
+        _, ids, _ = server.queue(dlq)
+        assert.Empty(t, ids)
+        requeued := server.messages(queueName)
+        require.Len(t, requeued, 2)
+        assert.Equal(t, "dead-1", requeued[0].MessageId)
+        assert.Equal(t, amqp091.Table{"trace": "dead-1"}, requeued[0].Headers)
+
+        deadLetter("dead-3")
+        deadLetter("dead-4")
+        purged, err := client.Purge(ctx, queueName)
+        require.NoError(t, err)
+        assert.Equal(t, 2, purged)
+        _, ids, _ = server.queue(dlq)
+        assert.Empty(t, ids)
+    })
+}
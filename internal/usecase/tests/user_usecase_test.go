@@ -0,0 +1,246 @@
+package usecase_test
+
+import (
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/zap"
+    "golang.org/x/crypto/bcrypt"
+)
+
+// TestAssignRole tests the role assignment performed by administrators
+func TestAssignRole(t *testing.T) {
+    logger := zap.NewNop()
+
+    // Subtest: A valid role is stored on the user
+    t.Run("Success", func(t *testing.T) {
+        user := &model.User{Name: "Amanda", Email: "amanda@test.com", Role: model.RoleEditor}
+        user.ID = 1
+        repo := &mockUserRepo{user: user}
+        userUC := usecase.NewUserUsecase(repo, nil, logger)
+
+        err := userUC.AssignRole(1, model.RoleViewer)
+
+        assert.NoError(t, err)
+        assert.Equal(t, model.RoleViewer, repo.user.Role)
+    })
+
+    // Subtest: Unknown roles are rejected
+    t.Run("InvalidRole", func(t *testing.T) {
+        userUC := usecase.NewUserUsecase(&mockUserRepo{}, nil, logger)
+
+        err := userUC.AssignRole(1, "superuser")
+
+        assert.ErrorIs(t, err, usecase.ErrInvalidRole)
+    })
+
+    // Subtest: Missing users are reported
+    t.Run("UserNotFound", func(t *testing.T) {
+        userUC := usecase.NewUserUsecase(&mockUserRepo{}, nil, logger)
+
+        err := userUC.AssignRole(42, model.RoleAdmin)
+
+        assert.ErrorIs(t, err, usecase.ErrUserNotFound)
+    })
+}
+
+// TestEnsureAdmins tests the startup promotion of configured administrators
+func TestEnsureAdmins(t *testing.T) {
+    user := &model.User{Name: "Amanda", Email: "amanda@test.com", Role: model.RoleEditor}
+    repo := &mockUserRepo{user: user}
+    userUC := usecase.NewUserUsecase(repo, nil, zap.NewNop())
+
+    err := userUC.EnsureAdmins([]string{"amanda@test.com"})
+
+    assert.NoError(t, err)
+    assert.Equal(t, model.RoleAdmin, repo.user.Role)
+}
+
+// TestActorCanModify tests the ownership rules applied to product changes
+func TestActorCanModify(t *testing.T) {
+    product := &model.Product{OrgID: 1, SKU: 1, CreatedBy: "Amanda", CreatedByID: 1}
+
+    assert.True(t, (&model.Actor{ID: 2, Name: "Other", OrgID: 1, OrgRole: model.RoleAdmin}).CanModify(product))
+    assert.True(t, (&model.Actor{ID: 1, Name: "Amanda", OrgID: 1, OrgRole: model.RoleEditor}).CanModify(product))
+    assert.False(t, (&model.Actor{ID: 2, Name: "Other", OrgID: 1, OrgRole: model.RoleEditor}).CanModify(product))
+    assert.False(t, (&model.Actor{ID: 1, Name: "Amanda", OrgID: 1, OrgRole: model.RoleViewer}).CanModify(product))
+
+    // Ownership follows the user ID, not the name
+    assert.False(t, (&model.Actor{ID: 2, Name: "Amanda", OrgID: 1, OrgRole: model.RoleEditor}).CanModify(product))
+    assert.True(t, (&model.Actor{ID: 1, Name: "Amanda Silva", OrgID: 1, OrgRole: model.RoleEditor}).CanModify(product))
+
+    // Products whose creator could not be identified are left to admins
+    assert.False(t, (&model.Actor{ID: 1, Name: "Amanda", OrgID: 1, OrgRole: model.RoleEditor}).CanModify(&model.Product{OrgID: 1, SKU: 2, CreatedBy: "Amanda"}))
+
+    // Roles only apply within their organization, whatever the platform-wide role
+    assert.False(t, (&model.Actor{ID: 2, Name: "Other", Role: model.RoleAdmin, OrgID: 2, OrgRole: model.RoleAdmin}).CanModify(product))
+    assert.False(t, (&model.Actor{ID: 1, Name: "Amanda", Role: model.RoleEditor, OrgID: 1, OrgRole: model.RoleViewer}).CanModify(product))
+}
+
+// mockVerificationUsecase records the addresses verification emails were sent to
+type mockVerificationUsecase struct {
+    sentTo []string
+}
+
+func (m *mockVerificationUsecase) SendVerification(email string) error {
+    m.sentTo = append(m.sentTo, email)
+    return nil
+}
+
+func (m *mockVerificationUsecase) VerifyEmail(token string) error { return nil }
+
+func (m *mockVerificationUsecase) ResendVerification(email string) error { return nil }
+
+// newManagedUser returns a verified editor used by the user management tests
+func newManagedUser() *model.User {
+    user := &model.User{Name: "Amanda", Email: "amanda@test.com", Role: model.RoleEditor, VerifiedAt: verifiedNow()}
+    user.ID = 1
+    return user
+}
+
+// TestListUsers tests the paginated user listing
+func TestListUsers(t *testing.T) {
+    userUC := usecase.NewUserUsecase(&mockUserRepo{user: newManagedUser()}, nil, zap.NewNop())
+
+    users, total, err := userUC.ListUsers("amanda", 1, 0)
+    assert.NoError(t, err)
+    assert.Len(t, users, 1)
+    assert.Equal(t, int64(1), total)
+
+    // Pages past the end are empty but still report the total
+    users, total, err = userUC.ListUsers("", 2, usecase.MaxUserPageSize+50)
+    assert.NoError(t, err)
+    assert.Empty(t, users)
+    assert.Equal(t, int64(1), total)
+}
+
+// TestUpdateUser tests profile changes made by administrators or by the user
+func TestUpdateUser(t *testing.T) {
+    logger := zap.NewNop()
+
+    // Subtest: A rename keeps the account verified
+    t.Run("Rename", func(t *testing.T) {
+        userUC := usecase.NewUserUsecase(&mockUserRepo{user: newManagedUser()}, nil, logger)
+
+        user, err := userUC.UpdateUser(1, "Amanda Silva", "")
+
+        assert.NoError(t, err)
+        assert.Equal(t, "Amanda Silva", user.Name)
+        assert.True(t, user.IsVerified())
+    })
+
+    // Subtest: A new email must be verified again
+    t.Run("EmailChangeResetsVerification", func(t *testing.T) {
+        repo := &emailLookupRepo{mockUserRepo: &mockUserRepo{user: newManagedUser()}}
+        verification := &mockVerificationUsecase{}
+        userUC := usecase.NewUserUsecase(repo, verification, logger)
+
+        _, err := userUC.UpdateUser(1, "", "new@test.com")
+
+        assert.NoError(t, err)
+        assert.Equal(t, "new@test.com", repo.user.Email)
+        assert.False(t, repo.user.IsVerified())
+        assert.Equal(t, []string{"new@test.com"}, verification.sentTo)
+    })
+
+    // Subtest: Names are unique because products are owned by name
+    t.Run("NameTaken", func(t *testing.T) {
+        repo := &mockUserRepo{user: newManagedUser(), nameTaken: true}
+        userUC := usecase.NewUserUsecase(repo, nil, logger)
+
+        _, err := userUC.UpdateUser(1, "Other", "")
+
+        assert.ErrorIs(t, err, usecase.ErrNameTaken)
+        assert.Equal(t, "Amanda", repo.user.Name)
+    })
+
+    // Subtest: Another account already uses the email
+    t.Run("EmailTaken", func(t *testing.T) {
+        userUC := usecase.NewUserUsecase(&mockUserRepo{user: newManagedUser()}, nil, logger)
+
+        _, err := userUC.UpdateUser(1, "", "other@test.com")
+
+        assert.ErrorIs(t, err, usecase.ErrEmailTaken)
+    })
+
+    // Subtest: Missing users are reported
+    t.Run("UserNotFound", func(t *testing.T) {
+        userUC := usecase.NewUserUsecase(&mockUserRepo{}, nil, logger)
+
+        _, err := userUC.UpdateUser(42, "Other", "")
+
+        assert.ErrorIs(t, err, usecase.ErrUserNotFound)
+    })
+}
+
+// emailLookupRepo narrows the mock's FindByEmail to the stored user's own address
+type emailLookupRepo struct {
+    *mockUserRepo
+}
+
+func (r *emailLookupRepo) FindByEmail(email string) (*model.User, error) {
+    if r.user == nil || r.user.Email != email {
+        return nil, nil
+    }
+    return r.user, nil
+}
+
+// TestDisableAndDeleteUser tests disabling, re-enabling and deleting accounts
+func TestDisableAndDeleteUser(t *testing.T) {
+    logger := zap.NewNop()
+
+    // Subtest: Disabling and enabling toggles DisabledAt
+    t.Run("DisableAndEnable", func(t *testing.T) {
+        repo := &mockUserRepo{user: newManagedUser()}
+        userUC := usecase.NewUserUsecase(repo, nil, logger)
+
+        assert.NoError(t, userUC.SetDisabled(1, true, 99))
+        assert.True(t, repo.user.IsDisabled())
+        assert.NoError(t, userUC.SetDisabled(1, false, 99))
+        assert.False(t, repo.user.IsDisabled())
+    })
+
+    // Subtest: Administrators cannot lock themselves out
+    t.Run("CannotModifySelf", func(t *testing.T) {
+        repo := &mockUserRepo{user: newManagedUser()}
+        userUC := usecase.NewUserUsecase(repo, nil, logger)
+
+        assert.ErrorIs(t, userUC.SetDisabled(1, true, 1), usecase.ErrCannotModifySelf)
+        assert.ErrorIs(t, userUC.DeleteUser(1, 1), usecase.ErrCannotModifySelf)
+        assert.NotNil(t, repo.user)
+        assert.False(t, repo.user.IsDisabled())
+    })
+
+    // Subtest: Deleting removes the user
+    t.Run("Delete", func(t *testing.T) {
+        repo := &mockUserRepo{user: newManagedUser()}
+        userUC := usecase.NewUserUsecase(repo, nil, logger)
+
+        assert.NoError(t, userUC.DeleteUser(1, 99))
+        assert.Nil(t, repo.user)
+        assert.ErrorIs(t, userUC.DeleteUser(1, 99), usecase.ErrUserNotFound)
+    })
+}
+
+// TestDisabledUserRejected tests that a disabled account loses access everywhere
+func TestDisabledUserRejected(t *testing.T) {
+    hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+    user := &model.User{Name: "Amanda", Email: "amanda@test.com", Password: string(hashedPassword), VerifiedAt: verifiedNow()}
+    user.ID = 1
+    repo := &mockUserRepo{user: user}
+    authUC := usecase.NewAuthUsecase(repo, newMockTokenRepo(), newTestLockout(), newTestMFA(), newTestOrgs(), newTestKeySet(), authTestConfig(), zap.NewNop())
+    login, err := authUC.Login("amanda@test.com", "123456", "127.0.0.1")
+    assert.NoError(t, err)
+
+    disabledAt := time.Now()
+    user.DisabledAt = &disabledAt
+
+    _, err = authUC.Login("amanda@test.com", "123456", "127.0.0.1")
+    assert.ErrorIs(t, err, usecase.ErrUserDisabled)
+    _, err = authUC.RefreshToken(login.Tokens.RefreshToken)
+    assert.ErrorIs(t, err, usecase.ErrUserDisabled)
+    assert.ErrorIs(t, authUC.ValidateAccessToken("jti-1", 1, time.Now()), usecase.ErrUserDisabled)
+}
@@ -0,0 +1,136 @@
+package usecase_test
+
+import (
+    "net/url"
+    "regexp"
+    "testing"
+    "time"
+
+    "github.com/Amandasilvbr/products-crud/internal/config"
+    "github.com/Amandasilvbr/products-crud/internal/domain/model"
+    "github.com/Amandasilvbr/products-crud/internal/usecase"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+)
+
+var verificationLinkPattern = regexp.MustCompile(`\?token=(\S+)`)
+
+// newVerificationTestSetup builds a VerificationUsecase around a single unverified user
+func newVerificationTestSetup() (*mockUserRepo, *mockMailer, *config.Configs) {
+    user := &model.User{Name: "Amanda", Email: "amanda@test.com", Role: model.RoleEditor}
+    user.ID = 1
+    cfg := &config.Configs{
+        JWTSecret:                       "test-secret",
+        EmailVerificationTTL:            time.Hour,
+        EmailVerificationURL:            "https://api.test/api/email/verify",
+        EmailVerificationResendInterval: time.Minute,
+    }
+    return &mockUserRepo{user: user}, newMockMailer(), cfg
+}
+
+// extractVerificationToken returns the token carried by the link in a verification email
+func extractVerificationToken(t *testing.T, text string) string {
+    match := verificationLinkPattern.FindStringSubmatch(text)
+    require.Len(t, match, 2)
+    token, err := url.QueryUnescape(match[1])
+    require.NoError(t, err)
+    return token
+}
+
+// TestEmailVerification tests sending and confirming verification links
+func TestEmailVerification(t *testing.T) {
+    logger := zap.NewNop()
+
+    // Subtest: the emailed link verifies the user
+    t.Run("Success", func(t *testing.T) {
+        repo, mailer, cfg := newVerificationTestSetup()
+        verificationUC := usecase.NewVerificationUsecase(repo, mailer, cfg, logger)
+
+        assert.NoError(t, verificationUC.SendVerification("amanda@test.com"))
+        msg := mailer.waitForEmail(t)
+        assert.Equal(t, []string{"amanda@test.com"}, msg.To)
+
+        assert.NoError(t, verificationUC.VerifyEmail(extractVerificationToken(t, msg.Text)))
+        assert.True(t, repo.user.IsVerified())
+    })
+
+    // Subtest: tampered tokens are rejected
+    t.Run("InvalidSignature", func(t *testing.T) {
+        repo, mailer, cfg := newVerificationTestSetup()
+        verificationUC := usecase.NewVerificationUsecase(repo, mailer, cfg, logger)
+
+        assert.NoError(t, verificationUC.SendVerification("amanda@test.com"))
+        token := extractVerificationToken(t, mailer.waitForEmail(t).Text)
+
+        assert.ErrorIs(t, verificationUC.VerifyEmail(token+"x"), usecase.ErrInvalidVerificationToken)
+        assert.False(t, repo.user.IsVerified())
+    })
+
+    // Subtest: a link issued for a previous email address does not verify the new one
+    t.Run("EmailChanged", func(t *testing.T) {
+        repo, mailer, cfg := newVerificationTestSetup()
+        verificationUC := usecase.NewVerificationUsecase(repo, mailer, cfg, logger)
+
+        assert.NoError(t, verificationUC.SendVerification("amanda@test.com"))
+        token := extractVerificationToken(t, mailer.waitForEmail(t).Text)
+        repo.user.Email = "new@test.com"
+
+        assert.ErrorIs(t, verificationUC.VerifyEmail(token), usecase.ErrInvalidVerificationToken)
+    })
+
+    // Subtest: expired links are rejected
+    t.Run("Expired", func(t *testing.T) {
+        repo, mailer, cfg := newVerificationTestSetup()
+        cfg.EmailVerificationTTL = -time.Minute
+        verificationUC := usecase.NewVerificationUsecase(repo, mailer, cfg, logger)
+
+        assert.NoError(t, verificationUC.SendVerification("amanda@test.com"))
+        token := extractVerificationToken(t, mailer.waitForEmail(t).Text)
+
+        assert.ErrorIs(t, verificationUC.VerifyEmail(token), usecase.ErrInvalidVerificationToken)
+    })
+}
+
+// TestResendVerification tests the throttled resend of verification emails
+func TestResendVerification(t *testing.T) {
+    logger := zap.NewNop()
+
+    // Subtest: a second resend within the interval sends nothing but succeeds, like one for an unknown email
+    t.Run("Throttled", func(t *testing.T) {
+        repo, mailer, cfg := newVerificationTestSetup()
+        verificationUC := usecase.NewVerificationUsecase(repo, mailer, cfg, logger)
+
+        assert.NoError(t, verificationUC.ResendVerification("amanda@test.com"))
+        mailer.waitForEmail(t)
+
+        assert.NoError(t, verificationUC.ResendVerification("amanda@test.com"))
+        assert.NoError(t, verificationUC.ResendVerification("unknown@test.com"))
+        select {
+        case <-mailer.sent:
+            t.Fatal("unexpected verification email")
+        case <-time.After(50 * time.Millisecond):
+        }
+    })
+
+    // Subtest: resending is allowed again once the interval has passed
+    t.Run("AfterInterval", func(t *testing.T) {
+        repo, mailer, cfg := newVerificationTestSetup()
+        sentAt := time.Now().Add(-2 * time.Minute)
+        repo.user.VerificationSentAt = &sentAt
+        verificationUC := usecase.NewVerificationUsecase(repo, mailer, cfg, logger)
+
+        assert.NoError(t, verificationUC.ResendVerification("amanda@test.com"))
+        mailer.waitForEmail(t)
+    })
+
+    // Subtest: verified users do not receive new emails
+    t.Run("AlreadyVerified", func(t *testing.T) {
+        repo, mailer, cfg := newVerificationTestSetup()
+        repo.user.VerifiedAt = verifiedNow()
+        verificationUC := usecase.NewVerificationUsecase(repo, mailer, cfg, logger)
+
+        assert.NoError(t, verificationUC.ResendVerification("amanda@test.com"))
+        assert.Empty(t, mailer.sent)
+    })
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// errTokenPurpose is returned for a valid token issued for another purpose
+var errTokenPurpose = errors.New("token issued for another purpose")
+
+// generateOpaqueToken returns a URL-safe random string built from n bytes of entropy
+func generateOpaqueToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex-encoded SHA-256 digest used to store opaque tokens at rest
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newEventID returns a random version 4 UUID identifying a published event
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// signPurposeToken signs an HS256 token carrying the claims and its purpose, so that it cannot be used
+// as an access token or in place of a token of another purpose; a zero ttl gives a token that does not expire
+func signPurposeToken(secret, purpose string, claims jwt.MapClaims, ttl time.Duration) (string, error) {
+	if secret == "" {
+		return "", errors.New("JWT secret key not configured")
+	}
+	signed := jwt.MapClaims{"purpose": purpose}
+	for key, value := range claims {
+		if key != "purpose" {
+			signed[key] = value
+		}
+	}
+	if ttl != 0 {
+		signed["exp"] = time.Now().Add(ttl).Unix()
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, signed).SignedString([]byte(secret))
+}
+
+// parsePurposeToken validates the signature and expiry of an HS256 token and returns its claims
+// when its purpose is one of the given ones
+func parsePurposeToken(secret, rawToken string, purposes ...string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(rawToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	for _, purpose := range purposes {
+		if claims["purpose"] == purpose {
+			return claims, nil
+		}
+	}
+	return nil, errTokenPurpose
+}
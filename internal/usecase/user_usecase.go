@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"go.uber.org/zap"
+)
+
+// Standard errors returned by the user administration use cases
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrEmailTaken       = errors.New("email already in use")
+	ErrNameTaken        = errors.New("name already in use")
+	ErrCannotModifySelf = errors.New("administrators cannot disable or delete their own account")
+	ErrUserDisabled     = errors.New("user account is disabled")
+)
+
+// Pagination limits for user listings
+const (
+	DefaultUserPageSize = 20
+	MaxUserPageSize     = 100
+)
+
+// UserUsecase implements the business logic for user administration
+type UserUsecase struct {
+	userRepo            repository.UserRepositoryInterface
+	verificationUsecase usecase.VerificationUsecaseInterface
+	logger              *zap.Logger
+}
+
+// NewUserUsecase creates a new instance of UserUsecase
+func NewUserUsecase(userRepo repository.UserRepositoryInterface, verificationUsecase usecase.VerificationUsecaseInterface, logger *zap.Logger) usecase.UserUsecaseInterface {
+	return &UserUsecase{
+		userRepo:            userRepo,
+		verificationUsecase: verificationUsecase,
+		logger:              logger,
+	}
+}
+
+// AssignRole changes the role of a user
+// The new role is embedded in the user's tokens from their next login or token refresh
+func (u *UserUsecase) AssignRole(userID uint, role string) error {
+	if !model.IsValidRole(role) {
+		u.logger.Warn("Invalid role", zap.String("role", role), zap.String("operation", "assign_role"))
+		return ErrInvalidRole
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		u.logger.Error("Failed to load user", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "assign_role"))
+		return err
+	}
+	if user == nil {
+		u.logger.Warn("User not found", zap.Uint("user_id", userID), zap.String("operation", "assign_role"))
+		return ErrUserNotFound
+	}
+
+	user.Role = role
+	if err := u.userRepo.Update(user); err != nil {
+		u.logger.Error("Failed to assign role", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "assign_role"))
+		return err
+	}
+
+	u.logger.Info("Role assigned", zap.Uint("user_id", userID), zap.String("role", role), zap.String("operation", "assign_role"))
+	return nil
+}
+
+// EnsureAdmins grants the admin role to the existing users with the given emails
+// It is used at startup to bootstrap the first administrators
+func (u *UserUsecase) EnsureAdmins(emails []string) error {
+	for _, email := range emails {
+		user, err := u.userRepo.FindByEmail(email)
+		if err != nil {
+			u.logger.Error("Failed to load user", zap.String("email", email), zap.Error(err), zap.String("operation", "ensure_admins"))
+			return err
+		}
+		if user == nil {
+			u.logger.Warn("Configured admin does not exist yet", zap.String("email", email), zap.String("operation", "ensure_admins"))
+			continue
+		}
+		if user.Role == model.RoleAdmin {
+			continue
+		}
+
+		user.Role = model.RoleAdmin
+		if err := u.userRepo.Update(user); err != nil {
+			u.logger.Error("Failed to promote admin", zap.String("email", email), zap.Error(err), zap.String("operation", "ensure_admins"))
+			return err
+		}
+		u.logger.Info("User promoted to admin", zap.String("email", email), zap.String("operation", "ensure_admins"))
+	}
+	return nil
+}
+
+// ListUsers returns one page of users matching the search, with the total number of matches
+// Pages start at 1; the page size defaults to DefaultUserPageSize and is capped at MaxUserPageSize
+func (u *UserUsecase) ListUsers(search string, page, pageSize int) ([]*model.User, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultUserPageSize
+	}
+	if pageSize > MaxUserPageSize {
+		pageSize = MaxUserPageSize
+	}
+
+	users, total, err := u.userRepo.List(search, (page-1)*pageSize, pageSize)
+	if err != nil {
+		u.logger.Error("Failed to list users", zap.Error(err), zap.String("operation", "list_users"))
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// GetUser returns a single user by ID
+func (u *UserUsecase) GetUser(userID uint) (*model.User, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		u.logger.Error("Failed to load user", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "get_user"))
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// UpdateUser changes the name and/or email of a user; empty values are left unchanged
+// A new email must be verified again, and a new name takes over the products created under the old one
+func (u *UserUsecase) UpdateUser(userID uint, name, email string) (*model.User, error) {
+	user, err := u.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed := name != "" && name != user.Name
+	emailChanged := email != "" && email != user.Email
+
+	if renamed {
+		// Names are shown as product authors and in audit listings, so two users should not share one
+		taken, err := u.userRepo.NameTaken(name, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			u.logger.Warn("Name already in use", zap.Uint("user_id", userID), zap.String("operation", "update_user"))
+			return nil, ErrNameTaken
+		}
+		user.Name = name
+	}
+	if emailChanged {
+		existing, err := u.userRepo.FindByEmail(email)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			u.logger.Warn("Email already in use", zap.Uint("user_id", userID), zap.String("operation", "update_user"))
+			return nil, ErrEmailTaken
+		}
+		user.Email = email
+		user.VerifiedAt = nil
+		user.VerificationSentAt = nil
+	}
+	if !renamed && !emailChanged {
+		return user, nil
+	}
+
+	if err := u.userRepo.Update(user); err != nil {
+		u.logger.Error("Failed to update user", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "update_user"))
+		return nil, err
+	}
+
+	if emailChanged {
+		if err := u.verificationUsecase.SendVerification(user.Email); err != nil {
+			u.logger.Error("Failed to send verification email", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "update_user"))
+		}
+	}
+
+	u.logger.Info("User updated", zap.Uint("user_id", userID), zap.Bool("renamed", renamed), zap.Bool("email_changed", emailChanged), zap.String("operation", "update_user"))
+	return user, nil
+}
+
+// SetDisabled disables or re-enables an account
+// Disabled users cannot log in, refresh tokens or use their existing access tokens
+func (u *UserUsecase) SetDisabled(userID uint, disabled bool, actorID uint) error {
+	if disabled && userID == actorID {
+		return ErrCannotModifySelf
+	}
+
+	user, err := u.GetUser(userID)
+	if err != nil {
+		return err
+	}
+	if user.IsDisabled() == disabled {
+		return nil
+	}
+
+	if disabled {
+		now := time.Now()
+		user.DisabledAt = &now
+	} else {
+		user.DisabledAt = nil
+	}
+	if err := u.userRepo.Update(user); err != nil {
+		u.logger.Error("Failed to change account status", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "set_disabled"))
+		return err
+	}
+
+	u.logger.Info("Account status changed", zap.Uint("user_id", userID), zap.Bool("disabled", disabled), zap.Uint("admin_id", actorID), zap.String("operation", "set_disabled"))
+	return nil
+}
+
+// DeleteUser removes an account; its tokens stop working because the user no longer exists
+func (u *UserUsecase) DeleteUser(userID uint, actorID uint) error {
+	if userID == actorID {
+		return ErrCannotModifySelf
+	}
+	if _, err := u.GetUser(userID); err != nil {
+		return err
+	}
+
+	if err := u.userRepo.Delete(userID); err != nil {
+		u.logger.Error("Failed to delete user", zap.Uint("user_id", userID), zap.Error(err), zap.String("operation", "delete_user"))
+		return err
+	}
+
+	u.logger.Info("User deleted", zap.Uint("user_id", userID), zap.Uint("admin_id", actorID), zap.String("operation", "delete_user"))
+	return nil
+}
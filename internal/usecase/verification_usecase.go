@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"time"
+
+	"github.com/Amandasilvbr/products-crud/internal/config"
+	"github.com/Amandasilvbr/products-crud/internal/domain/messaging"
+	"github.com/Amandasilvbr/products-crud/internal/domain/model"
+	"github.com/Amandasilvbr/products-crud/internal/domain/repository"
+	"github.com/Amandasilvbr/products-crud/internal/domain/usecase"
+
+	"github.com/dgrijalva/jwt-go"
+	"go.uber.org/zap"
+)
+
+// verificationPurpose marks verification tokens so they cannot be used as access tokens and vice versa
+const verificationPurpose = "email_verification"
+
+// Standard errors returned by the email verification use cases
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+)
+
+// VerificationUsecase implements the business logic for confirming user email addresses
+type VerificationUsecase struct {
+	userRepo repository.UserRepositoryInterface
+	mailer   messaging.Mailer
+	cfg      *config.Configs
+	logger   *zap.Logger
+}
+
+// NewVerificationUsecase creates a new instance of VerificationUsecase
+func NewVerificationUsecase(userRepo repository.UserRepositoryInterface, mailer messaging.Mailer, cfg *config.Configs, logger *zap.Logger) usecase.VerificationUsecaseInterface {
+	return &VerificationUsecase{
+		userRepo: userRepo,
+		mailer:   mailer,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+// SendVerification emails a signed verification link to a newly registered user
+func (u *VerificationUsecase) SendVerification(email string) error {
+	user, err := u.userRepo.FindByEmail(email)
+	if err != nil {
+		u.logger.Error("Failed to look up user", zap.Error(err), zap.String("operation", "send_verification"))
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if user.IsVerified() {
+		return nil
+	}
+	return u.send(user)
+}
+
+// ResendVerification sends a new verification link, at most once per configured interval
+// Unknown, verified and throttled accounts all get the same silent success
+// Unknown and already verified addresses are ignored so callers cannot enumerate accounts
+func (u *VerificationUsecase) ResendVerification(email string) error {
+	user, err := u.userRepo.FindByEmail(email)
+	if err != nil {
+		u.logger.Error("Failed to look up user", zap.Error(err), zap.String("operation", "resend_verification"))
+		return err
+	}
+	if user == nil || user.IsVerified() {
+		u.logger.Info("Verification resend ignored", zap.String("operation", "resend_verification"))
+		return nil
+	}
+
+	// Throttled requests succeed like the ignored ones, so the response does not reveal that the account exists
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < u.cfg.EmailVerificationResendInterval {
+		u.logger.Warn("Verification resend throttled", zap.Uint("user_id", user.ID), zap.String("operation", "resend_verification"))
+		return nil
+	}
+	return u.send(user)
+}
+
+// VerifyEmail checks the signed token from the verification link and marks the user as verified
+func (u *VerificationUsecase) VerifyEmail(rawToken string) error {
+	claims, err := u.parseToken(rawToken)
+	if err != nil {
+		u.logger.Warn("Invalid verification token", zap.Error(err), zap.String("operation", "verify_email"))
+		return ErrInvalidVerificationToken
+	}
+
+	id, _ := claims["id"].(float64)
+	user, err := u.userRepo.FindByID(uint(id))
+	if err != nil {
+		return err
+	}
+	// A link sent before an email change must not verify the new address
+	if user == nil || user.Email != claims["email"] {
+		u.logger.Warn("Verification token does not match user", zap.String("operation", "verify_email"))
+		return ErrInvalidVerificationToken
+	}
+	if user.IsVerified() {
+		return nil
+	}
+
+	now := time.Now()
+	user.VerifiedAt = &now
+	if err := u.userRepo.Update(user); err != nil {
+		u.logger.Error("Failed to mark user as verified", zap.Uint("user_id", user.ID), zap.Error(err), zap.String("operation", "verify_email"))
+		return err
+	}
+
+	u.logger.Info("Email verified", zap.Uint("user_id", user.ID), zap.String("operation", "verify_email"))
+	return nil
+}
+
+// send records the send time and delivers the verification email in the background
+func (u *VerificationUsecase) send(user *model.User) error {
+	token, err := u.signToken(user)
+	if err != nil {
+		u.logger.Error("Failed to sign verification token", zap.Error(err), zap.String("operation", "send_verification"))
+		return err
+	}
+
+	now := time.Now()
+	user.VerificationSentAt = &now
+	if err := u.userRepo.Update(user); err != nil {
+		u.logger.Error("Failed to record verification email", zap.Uint("user_id", user.ID), zap.Error(err), zap.String("operation", "send_verification"))
+		return err
+	}
+
+	go u.sendVerificationEmail(user, token)
+
+	u.logger.Info("Verification email queued", zap.Uint("user_id", user.ID), zap.String("operation", "send_verification"))
+	return nil
+}
+
+// signToken creates a verification token bound to the user's ID and current email address
+func (u *VerificationUsecase) signToken(user *model.User) (string, error) {
+	return signPurposeToken(u.cfg.JWTSecret, verificationPurpose, jwt.MapClaims{
+		"id":    user.ID,
+		"email": user.Email,
+	}, u.cfg.EmailVerificationTTL)
+}
+
+// parseToken validates the signature, expiry and purpose of a verification token
+func (u *VerificationUsecase) parseToken(rawToken string) (jwt.MapClaims, error) {
+	return parsePurposeToken(u.cfg.JWTSecret, rawToken, verificationPurpose)
+}
+
+// sendVerificationEmail delivers the verification link to the user; failures are only logged
+func (u *VerificationUsecase) sendVerificationEmail(user *model.User, token string) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+
+	validFor := u.cfg.EmailVerificationTTL.Round(time.Minute)
+	textBody := fmt.Sprintf("Olá, %s,\n\nBem-vindo! Confirme seu endereço de email para ativar sua conta.\n\n", user.Name)
+	htmlBody := fmt.Sprintf("<p>Olá, %s,</p><p>Bem-vindo! Confirme seu endereço de email para ativar sua conta.</p>", html.EscapeString(user.Name))
+	if u.cfg.EmailVerificationURL != "" {
+		link := u.cfg.EmailVerificationURL + "?token=" + url.QueryEscape(token)
+		textBody += fmt.Sprintf("Acesse o link abaixo para confirmar:\n%s\n\n", link)
+		htmlBody += fmt.Sprintf(`<p><a href="%s">Clique aqui para confirmar seu email</a></p>`, html.EscapeString(link))
+	} else {
+		textBody += fmt.Sprintf("Código de verificação: %s\n\n", token)
+		htmlBody += fmt.Sprintf("<p>Código de verificação: <code>%s</code></p>", html.EscapeString(token))
+	}
+	textBody += fmt.Sprintf("O link é válido por %s.\nSe você não criou esta conta, ignore este email.\n\nAtenciosamente,\nEquipe de Produtos", validFor)
+	htmlBody += fmt.Sprintf("<p>O link é válido por %s.<br>Se você não criou esta conta, ignore este email.</p><p>Atenciosamente,<br>Equipe de Produtos</p>", validFor)
+
+	err := u.mailer.Send(ctx, &messaging.Email{
+		To:      []string{user.Email},
+		Subject: "Confirme seu email",
+		Text:    textBody,
+		HTML:    htmlBody,
+	})
+	if err != nil {
+		u.logger.Error("Failed to send verification email", zap.Uint("user_id", user.ID), zap.Error(err), zap.String("operation", "send_verification"))
+	}
+}